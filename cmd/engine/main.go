@@ -19,7 +19,6 @@ package main
 import (
 	"flag"
 	"fmt"
-	"github.com/kappital/kappital/pkg/utils/cryption"
 	"os"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -37,6 +36,8 @@ import (
 	"github.com/kappital/kappital/pkg/apis"
 	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
 	controller "github.com/kappital/kappital/pkg/engine"
+	"github.com/kappital/kappital/pkg/utils/certificate"
+	"github.com/kappital/kappital/pkg/utils/cryption"
 	"github.com/kappital/kappital/pkg/utils/gateway"
 	"github.com/kappital/kappital/pkg/utils/version"
 )
@@ -59,8 +60,11 @@ func main() {
 		os.Exit(0)
 	}
 	var enableLeaderElection bool
-	var probeAddr string
+	var probeAddr, certFile, keyFile string
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&certFile, "tls-cert-file", "", "The certificate of the probe endpoint, it is reloaded "+
+		"when changed. Empty means using the self-signed certificate generated when starting.")
+	flag.StringVar(&keyFile, "tls-key-file", "", "The private key of the certificate given by --tls-cert-file.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controllers kappital-manager. "+
 			"Enabling this will ensure there is only one active controllers kappital-engine.")
@@ -88,11 +92,12 @@ func main() {
 	if err != nil {
 		klog.Fatalf("unable to start kappital-engine, error: %v.", err)
 	}
-	cert, key, err := cryption.GetSelfCertAndKey()
+	ctx := ctrl.SetupSignalHandler()
+	getCertificate, err := getProbeCertificate(ctx.Done(), certFile, keyFile)
 	if err != nil {
-		klog.Fatalf("cannot get the self https certificate, err: %s", err)
+		klog.Fatalf("cannot get the https certificate, err: %s", err)
 	}
-	go gateway.HealthAndReadinessProvider(gateway.ReplaceIP(probeAddr, ip, "8081"), cert, key)
+	go gateway.HealthAndReadinessProvider(gateway.ReplaceIP(probeAddr, ip, "8081"), getCertificate)
 
 	if err = (&controller.ServicePackageReconciler{
 		Client: mgr.GetClient(),
@@ -106,7 +111,32 @@ func main() {
 	}
 
 	setupLog.Info("starting kappital-engine")
-	if err = mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err = mgr.Start(ctx); err != nil {
 		klog.Fatalf("problem running kappital-engine, error: %v.", err)
 	}
 }
+
+// getProbeCertificate load the given certificate and reload it when it is changed, or generate the self-signed one
+// if the certificate is not given
+func getProbeCertificate(stopCh <-chan struct{}, certFile, keyFile string) (certificate.GetCertificateFunc, error) {
+	if len(certFile) == 0 && len(keyFile) == 0 {
+		cert, key, err := cryption.GetSelfCertAndKey()
+		if err != nil {
+			return nil, err
+		}
+		return certificate.StaticCertificate(cert, key)
+	}
+	if len(certFile) == 0 || len(keyFile) == 0 {
+		return nil, fmt.Errorf("both --tls-cert-file and --tls-key-file should be given")
+	}
+	reloader, err := certificate.NewReloader(certFile, keyFile, "")
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := reloader.Watch(stopCh); err != nil {
+			klog.Errorf("cannot watch the certificate, it will not be reloaded, err: %v", err)
+		}
+	}()
+	return reloader.GetCertificate, nil
+}
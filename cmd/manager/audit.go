@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/kappital/kappital/pkg/utils/audit"
+)
+
+const (
+	auditCommand       = "audit"
+	auditVerifyCommand = "verify"
+)
+
+// runAuditCommand run the audit subcommand such as "audit verify", and return the exit code
+func runAuditCommand(args []string, out io.Writer) int {
+	if len(args) == 0 || args[0] != auditVerifyCommand {
+		fmt.Fprintf(out, "Usage: kappital-manager %s %s [--key <key file>] <audit log files>\n", auditCommand,
+			auditVerifyCommand)
+		return 2
+	}
+	return verifyAuditLog(args[1:], out)
+}
+
+// verifyAuditLog verify the hash chain of the audit log files, the exit code is 1 if the chain is broken, or the key is
+// given but not all records are covered by the signatures
+func verifyAuditLog(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet(auditCommand+" "+auditVerifyCommand, flag.ContinueOnError)
+	fs.SetOutput(out)
+	keyFile := fs.String("key", "", "The ed25519 public key in PKIX PEM, or the private key in PKCS#8 PEM, which "+
+		"verifies the signatures of the chain. Empty means only verifying the hashes.")
+	fs.Usage = func() {
+		fmt.Fprintf(out, "Usage: kappital-manager %s %s [--key <key file>] <audit log files>\n\n"+
+			"Verify the hash chain of the audit log files which are given from the oldest to the newest, such as\n"+
+			"the rotated files audit-*.log and then audit.log.\n\n", auditCommand, auditVerifyCommand)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	var key ed25519.PublicKey
+	if len(*keyFile) > 0 {
+		var err error
+		if key, err = audit.LoadVerifyingKey(*keyFile); err != nil {
+			fmt.Fprintf(out, "cannot load the verifying key, err: %v\n", err)
+			return 2
+		}
+	}
+	report, err := audit.VerifyChain(fs.Args(), key)
+	if err != nil {
+		fmt.Fprintf(out, "cannot verify the audit log, err: %v\n", err)
+		return 2
+	}
+	if report.Broken != nil {
+		fmt.Fprintf(out, "the audit chain is broken after %d records, the first broken link is %s\n",
+			report.Records, report.Broken)
+		return 1
+	}
+	fmt.Fprintf(out, "the audit chain of %d records is intact\n", report.Records)
+	fmt.Fprintf(out, "the prev_hash %q of the first record in %s is not checked, the records removed before it "+
+		"can only be found by comparing it with the hash of the last archived record\n", report.FirstPrevHash,
+		fs.Arg(0))
+	switch {
+	case key == nil:
+		fmt.Fprintln(out, "the signatures are not verified, please give the key by --key")
+	case report.Signatures == 0:
+		fmt.Fprintln(out, "no signature is found, the records can be rewritten without breaking the chain")
+		return 1
+	case report.Unsigned == 0:
+		fmt.Fprintf(out, "%d signatures are verified, all records are signed\n", report.Signatures)
+	default:
+		fmt.Fprintf(out, "%d signatures are verified, but the last %d records are not signed, they can be removed "+
+			"or rewritten without breaking the chain\n", report.Signatures, report.Unsigned)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net"
+
+	"github.com/beego/beego/v2/server/web"
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/grpcserver"
+)
+
+// startGRPCServer serve the gRPC API with the TLS config of the API server, thus the certificates, the client
+// authentication and the reloading are the same as the REST API. The returned server is nil if it is disabled.
+func startGRPCServer(cfg *grpcserver.Config, identityCheck bool) *grpcserver.Server {
+	if len(cfg.BindAddress) == 0 {
+		klog.Info("the gRPC API is disabled")
+		return nil
+	}
+	lis, err := net.Listen("tcp", cfg.BindAddress)
+	if err != nil {
+		klog.Fatalf("failed to listen the gRPC API at %s, err: %v", cfg.BindAddress, err)
+	}
+	var tlsConfig = web.BeeApp.Server.TLSConfig
+	if tlsConfig != nil {
+		tlsConfig = tlsConfig.Clone()
+	}
+	server := grpcserver.NewServer(cfg, tlsConfig, identityCheck)
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			klog.Fatalf("failed to serve the gRPC API, err: %v", err)
+		}
+	}()
+	klog.Infof("serve the gRPC API at %s", cfg.BindAddress)
+	return server
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/models"
+	mo "github.com/kappital/kappital/pkg/models/operation"
+	"github.com/kappital/kappital/pkg/processor"
+	"github.com/kappital/kappital/pkg/utils/health"
+	"github.com/kappital/kappital/pkg/utils/leaderelection"
+	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/watcher"
+)
+
+// startHealthServer serve the liveness and readiness probes with the server certificate of the API server, and the
+// client certificate is not required. The returned server is nil if the probes are disabled.
+func startHealthServer(cfg *health.Config) *http.Server {
+	if len(cfg.BindAddress) == 0 {
+		klog.Info("the liveness and readiness probes are disabled")
+		return nil
+	}
+	registry := health.NewRegistry(cfg.CheckTimeout)
+	// the failed processors cannot be recovered without restarting, but the unreachable dependencies can be
+	registry.AddLivenessCheck("processors", checkProcessors)
+	registry.AddReadinessCheck("database", checkDatabase)
+	registry.AddReadinessCheck("clusters", checkClusters)
+	registry.AddReadinessCheck("processors", checkProcessors)
+	registry.AddReadinessCheck("watcher", checkWatcher(cfg.WatcherSaturation))
+
+	var tlsConfig *tls.Config
+	if web.BeeApp.Server.TLSConfig != nil {
+		tlsConfig = web.BeeApp.Server.TLSConfig.Clone()
+		// the handshake config of the API server requires the client certificate, thus only its GetCertificate is used
+		tlsConfig.ClientAuth, tlsConfig.ClientCAs, tlsConfig.GetConfigForClient = tls.NoClientCert, nil, nil
+	}
+	server := health.NewServer(cfg.BindAddress, registry, tlsConfig)
+	go func() {
+		var err error
+		if tlsConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			klog.Fatalf("failed to serve the liveness and readiness probes, err: %v", err)
+		}
+	}()
+	klog.Infof("serve the liveness and readiness probes at %s", cfg.BindAddress)
+	return server
+}
+
+func checkDatabase(ctx context.Context) (string, error) {
+	if err := models.PingDatabase(ctx); err != nil {
+		return "", fmt.Errorf("cannot connect the database, err: %v", err)
+	}
+	return "connected", nil
+}
+
+// checkClusters ping the API servers of the default cluster and the clusters which the service bindings and instances
+// are registered in. Only the default cluster which the manager is running with fails the readiness, the other ones
+// are reported in the message, otherwise a record of the cluster which is unknown or gone takes down all replicas.
+func checkClusters(ctx context.Context) (string, error) {
+	if err := co.GetClusterOperation().Ping(ctx, apis.DefaultCluster); err != nil {
+		return "", fmt.Errorf("the cluster %s is unreachable, err: %v", apis.DefaultCluster, err)
+	}
+	names, err := mo.ListClusterNames(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cannot list the registered clusters, err: %v", err)
+	}
+	clusters := sets.NewString(names...)
+	clusters.Delete(apis.DefaultCluster)
+	reachable, unreachable := []string{apis.DefaultCluster}, []string{}
+	for _, name := range clusters.List() {
+		if err = co.GetClusterOperation().Ping(ctx, name); err != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s (%v)", name, err))
+			continue
+		}
+		reachable = append(reachable, name)
+	}
+	sort.Strings(reachable)
+	message := fmt.Sprintf("reachable clusters: %s", strings.Join(reachable, ", "))
+	if len(unreachable) > 0 {
+		message += fmt.Sprintf("; unreachable clusters: %s", strings.Join(unreachable, ", "))
+	}
+	return message, nil
+}
+
+// checkProcessors the processors only run in the leader replica, thus the standby replica is always passed
+func checkProcessors(context.Context) (string, error) {
+	if !leaderelection.IsLeader() {
+		return "standby replica, the processors run in the leader", nil
+	}
+	running, registered := processor.RunningProcessors()
+	if running < registered {
+		return "", fmt.Errorf("%d of %d processors are running", running, registered)
+	}
+	return fmt.Sprintf("%d of %d processors are running", running, registered), nil
+}
+
+// checkWatcher the events are blocked when the channel of the watcher is full, thus the replica is not ready when the
+// used buffer reaches the saturation
+func checkWatcher(saturation float64) health.Checker {
+	return func(context.Context) (string, error) {
+		length, capacity := watcher.ChannelUsage()
+		if capacity == 0 {
+			return "the watcher is not started", nil
+		}
+		if float64(length) >= saturation*float64(capacity) {
+			return "", fmt.Errorf("the event buffer is saturated, %d of %d used", length, capacity)
+		}
+		return fmt.Sprintf("%d of %d event buffer used", length, capacity), nil
+	}
+}
@@ -17,8 +17,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/beego/beego/v2/server/web"
 	_ "github.com/lib/pq"
@@ -26,11 +31,22 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/cmd/options"
+	"github.com/kappital/kappital/pkg/apis"
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	"github.com/kappital/kappital/pkg/grpcserver"
 	"github.com/kappital/kappital/pkg/models"
+	mo "github.com/kappital/kappital/pkg/models/operation"
 	"github.com/kappital/kappital/pkg/processor"
+	"github.com/kappital/kappital/pkg/resource"
 	"github.com/kappital/kappital/pkg/routers/flowcontroller"
 	"github.com/kappital/kappital/pkg/routers/manager"
 	"github.com/kappital/kappital/pkg/utils/audit"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/leaderelection"
+	"github.com/kappital/kappital/pkg/utils/metrics"
+	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/tracing"
 	"github.com/kappital/kappital/pkg/utils/version"
 	"github.com/kappital/kappital/pkg/watcher"
 )
@@ -40,35 +56,143 @@ func main() {
 		fmt.Println(version.Get(version.ServiceNameManager).String())
 		os.Exit(0)
 	}
-	if err := audit.InitAuditLog(audit.DefaultAuditLogConfig()); err != nil {
-		klog.Fatalf("cannot init the audit log, err: %s", err)
+	if len(os.Args) > 1 && os.Args[1] == auditCommand {
+		os.Exit(runAuditCommand(os.Args[2:], os.Stdout))
 	}
-
 	cfg, err := options.NewServerRunOptions(version.ServiceNameManager)
 	if err != nil {
 		klog.Fatalf("failed to configure server running options: %s", err)
 	}
-	manager.InitRouters()
+	if err = audit.InitAuditLog(*cfg.AuditConfig); err != nil {
+		klog.Fatalf("cannot init the audit log, err: %s", err)
+	}
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.TracingConfig, version.ServiceNameManager)
+	if err != nil {
+		klog.Fatalf("failed to initialize tracing, error: %v", err)
+	}
+	if err = authorization.Init(cfg.AuthorizationConfig); err != nil {
+		klog.Fatalf("failed to initialize authorization, error: %v", err)
+	}
+	manager.InitRouters(cfg.TLS.CheckIdentity)
+	apis.SetProcessTimeoutConfig(cfg.ProcessTimeoutConfig)
 	flowcontroller.Init(cfg.FlowControllerConfig)
 	// init sql driver
 	if err = models.GetDatabase().InitSQLDriver(cfg.DBConfig, models.Manager); err != nil {
 		klog.Fatalf("failed to initialize sql driver, error: %v", err)
 	}
+	// the API tokens are only accepted with the authorization policy, otherwise every token holder can do everything
+	var tokenStore authentication.TokenStore
+	if authorization.Enabled() {
+		tokenStore = &resource.APITokenResource{}
+	}
+	if err = authentication.Init(cfg.AuthenticationConfig, tokenStore); err != nil {
+		klog.Fatalf("failed to initialize authentication, error: %v", err)
+	}
+	resource.SetFeatures(infov1alpha1.Features{
+		AuthenticationMethods: authentication.Methods(),
+		DatabaseDriver:        cfg.DBConfig.SQLDriver,
+		MultiCluster:          co.MultiCluster,
+		LeaderElection:        cfg.LeaderElectionConfig.Enable,
+		GRPC:                  len(cfg.GRPCConfig.BindAddress) > 0,
+	})
+	metrics.MustRegister(mo.NewStatusCollector())
 
-	notifyWatcher := watcher.NewWatcher()
+	notifyWatcher := watcher.NewWatcher(cfg.DBWatcherConfig.ResyncPeriod)
 	for _, proc := range processor.GetProcesses() {
 		if err = notifyWatcher.Watch(proc.ProcessObject(), proc.ProcessType(), proc); err != nil {
 			klog.Fatalf("create watch for processor %s failed, err: %s", proc.ProcessType(), err)
 		}
 	}
+	// only the leader replica can change the resources in cluster, and the cluster calls are traced
+	co.SetClusterOperation(co.NewTracedOperation(
+		co.NewFencedOperation(co.GetClusterOperation(), leaderelection.IsLeader)))
+	healthServer := startHealthServer(cfg.HealthConfig)
+	grpcServer := startGRPCServer(cfg.GRPCConfig, cfg.TLS.CheckIdentity)
 	// start modules
-	jobStopCh := make(chan struct{})
-	// processor modules
-	processor.StartAllProcessors(jobStopCh)
-	if err = notifyWatcher.StartProcessor(); err != nil {
-		klog.Fatalf("start processor failed, error: %s", err)
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go cfg.WatchConfigFile(ctx.Done())
+	go cfg.WatchCertificates(ctx.Done())
+	// the processors are stopped before releasing the leadership, thus the in-flight steps can be finished
+	processorCtx, stopProcessors := context.WithCancel(ctx)
+	// processor modules, the REST APIs are served by all replicas, but processors only run in the leader
+	go func() {
+		if err := leaderelection.Run(ctx, cfg.LeaderElectionConfig, func(_ <-chan struct{}) {
+			processor.StartAllProcessors(processorCtx.Done())
+			if err := notifyWatcher.StartProcessor(); err != nil {
+				klog.Fatalf("start processor failed, error: %s", err)
+			}
+		}, func() {
+			if ctx.Err() != nil {
+				klog.Info("leadership released, kappital-manager processors stopped")
+				return
+			}
+			klog.Fatalf("leader election lost, kappital-manager processors stopped")
+		}); err != nil {
+			klog.Fatalf("start leader election failed, error: %s", err)
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	serverDone, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case sig := <-sigCh:
+			klog.Infof("received signal %s, start to shut down kappital-manager", sig)
+		case <-serverDone:
+			klog.Warning("http server stopped unexpectedly, start to shut down kappital-manager")
+		}
+		signal.Stop(sigCh)
+		shutdown(cfg.GetShutdownTimeout(), grpcServer, stopProcessors, notifyWatcher, cancel, shutdownTracing,
+			healthServer)
+	}()
 	web.Run()
-	close(jobStopCh)
+	close(serverDone)
+	<-stopped
 	klog.Info("kappital-manager server stopped")
 }
+
+// shutdown stop the modules in order: stop accepting the requests and finish the in-flight ones of both REST and gRPC
+// (the gRPC watches are ended at once since they never finish by themselves), stop the processors and wait for the
+// in-flight steps, release the leadership, and then flush the audit log and the spans, and close the database and the
+// probes. The steps which are not finished before the timeout will be abandoned.
+func shutdown(timeout time.Duration, grpcServer *grpcserver.Server, stopProcessors context.CancelFunc,
+	notifyWatcher watcher.Watcher, releaseLeadership context.CancelFunc, shutdownTracing func(context.Context) error,
+	healthServer *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if grpcServer != nil {
+		go func() {
+			if err := grpcServer.Shutdown(ctx); err != nil {
+				klog.Errorf("failed to shut down the gRPC server gracefully, err: %v", err)
+			}
+		}()
+	}
+	if err := web.BeeApp.Server.Shutdown(ctx); err != nil {
+		klog.Errorf("failed to shut down the http server gracefully, err: %v", err)
+	}
+	stopProcessors()
+	if err := processor.WaitForProcessors(ctx); err != nil {
+		klog.Errorf("failed to wait for the processors finishing in-flight steps, err: %v", err)
+	}
+	notifyWatcher.Stop()
+	releaseLeadership()
+
+	if err := audit.Close(); err != nil {
+		klog.Errorf("failed to close the audit log, err: %v", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		klog.Errorf("failed to flush the tracing spans, err: %v", err)
+	}
+	if err := models.CloseDatabase(); err != nil {
+		klog.Errorf("failed to close the database, err: %v", err)
+	}
+	if healthServer != nil {
+		if err := healthServer.Close(); err != nil {
+			klog.Errorf("failed to close the probe server, err: %v", err)
+		}
+	}
+	klog.Flush()
+}
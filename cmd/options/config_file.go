@@ -0,0 +1,359 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package options
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/routers/flowcontroller"
+)
+
+const (
+	configFileFlag = "config"
+	// tlsSection the section of the config file which is not bound to the flags
+	tlsSection = "tls"
+)
+
+// configFileFlags the keys of the config file and the flags which they are bound to
+var configFileFlags = map[string]string{
+	"listen.httpsPort": "https-port",
+
+	"database.driver":               "sql-driver",
+	"database.maxIdle":              "sql-max-idle",
+	"database.maxConn":              "sql-max-conn",
+	"database.maxLifetime":          "db-max-lifetime",
+	"database.tlsEnable":            "sql-tls-enable",
+	"database.minReconnectInterval": "min-database-reconnect-interval",
+	"database.maxReconnectInterval": "max-database-reconnect-interval",
+	"database.resyncPeriod":         "database-resync-period",
+
+	"flowControl.enable":              "flow-control-enable",
+	"flowControl.qps":                 "flow-control-qps",
+	"flowControl.burst":               "flow-control-burst",
+	"flowControl.mutationQPS":         "flow-control-mutation-qps",
+	"flowControl.mutationBurst":       "flow-control-mutation-burst",
+	"flowControl.clientReadQPS":       "flow-control-client-read-qps",
+	"flowControl.clientReadBurst":     "flow-control-client-read-burst",
+	"flowControl.clientMutationQPS":   "flow-control-client-mutation-qps",
+	"flowControl.clientMutationBurst": "flow-control-client-mutation-burst",
+
+	"timeouts.shutdown":       "shutdown-timeout",
+	"timeouts.install":        "install-timeout",
+	"timeouts.upgrade":        "upgrade-timeout",
+	"timeouts.delete":         "delete-timeout",
+	"timeouts.bindingReady":   "binding-ready-timeout",
+	"timeouts.longestProcess": "longest-process-duration",
+
+	"authentication.oidcConfigFile": "oidc-config-file",
+	"authorization.policyFile":      "authorization-policy-file",
+
+	"audit.file":           "audit-log-file",
+	"audit.maxSize":        "audit-log-max-size",
+	"audit.rotationPeriod": "audit-log-rotation-period",
+	"audit.maxBackups":     "audit-log-max-backups",
+	"audit.maxBackupAge":   "audit-log-max-backup-age",
+	"audit.sinks":          "audit-log-sinks",
+	"audit.syslogNetwork":  "audit-log-syslog-network",
+	"audit.syslogAddress":  "audit-log-syslog-address",
+	"audit.syslogTag":      "audit-log-syslog-tag",
+	"audit.webhookURL":     "audit-log-webhook-url",
+	"audit.webhookTimeout": "audit-log-webhook-timeout",
+	"audit.hashChain":      "audit-log-hash-chain",
+	"audit.signingKeyFile": "audit-log-signing-key-file",
+	"audit.signInterval":   "audit-log-sign-interval",
+
+	"leaderElection.enable":            "leader-elect",
+	"leaderElection.leaseDuration":     "leader-elect-lease-duration",
+	"leaderElection.renewDeadline":     "leader-elect-renew-deadline",
+	"leaderElection.retryPeriod":       "leader-elect-retry-period",
+	"leaderElection.resourceName":      "leader-elect-resource-name",
+	"leaderElection.resourceNamespace": "leader-elect-resource-namespace",
+
+	"tracing.exporter":    "tracing-exporter",
+	"tracing.endpoint":    "tracing-endpoint",
+	"tracing.insecure":    "tracing-insecure",
+	"tracing.file":        "tracing-file",
+	"tracing.sampleRatio": "tracing-sample-ratio",
+
+	"health.bindAddress":       "health-probe-bind-address",
+	"health.checkTimeout":      "health-check-timeout",
+	"health.watcherSaturation": "health-watcher-saturation",
+
+	"grpc.bindAddress":   "grpc-bind-address",
+	"grpc.watchInterval": "grpc-watch-interval",
+
+	"log.level": "v",
+}
+
+// listFlags the flags of the comma separated lists, their values can be the lists in the config file
+var listFlags = map[string]bool{"audit-log-sinks": true}
+
+// reloadableFlags the flags which take effect without restarting when the config file is reloaded
+var reloadableFlags = map[string]bool{
+	"flow-control-enable": true, "flow-control-qps": true, "flow-control-burst": true,
+	"flow-control-mutation-qps": true, "flow-control-mutation-burst": true,
+	"flow-control-client-read-qps": true, "flow-control-client-read-burst": true,
+	"flow-control-client-mutation-qps": true, "flow-control-client-mutation-burst": true,
+	"shutdown-timeout": true, "install-timeout": true, "upgrade-timeout": true, "delete-timeout": true,
+	"binding-ready-timeout": true, "longest-process-duration": true,
+	"v": true,
+}
+
+// TLSConfig of the https server and the identity check, the legacy environment variables override it
+type TLSConfig struct {
+	// Enable the https server, the manager cannot be started without https
+	Enable bool `json:"enable"`
+	// CertFile the server certificate
+	CertFile string `json:"certFile"`
+	// KeyFile the private key of the server certificate
+	KeyFile string `json:"keyFile"`
+	// TrustCAFile the root ca of the client certificates
+	TrustCAFile string `json:"trustCAFile"`
+	// EnableMutual check the client certificates by the TrustCAFile
+	EnableMutual bool `json:"enableMutual"`
+	// ClientAuth the method of checking the client certificate, such as REQUIRE_AND_VERIFY_CLIENT_CERT
+	ClientAuth string `json:"clientAuth"`
+	// CheckIdentity reject the requests without the accepted principal
+	CheckIdentity bool `json:"checkIdentity"`
+}
+
+// DefaultTLSConfig the default TLS config
+func DefaultTLSConfig() TLSConfig {
+	return TLSConfig{EnableMutual: true, ClientAuth: requireAndVerifyClientCert}
+}
+
+// getValueFromEnv override the config by the legacy environment variables
+func (c *TLSConfig) getValueFromEnv() {
+	if v, ok := os.LookupEnv(enableHTTPSEnvKey); ok {
+		c.Enable = parseBool(v)
+	}
+	if v := os.Getenv(httpsCertFilePathEnv); len(v) > 0 {
+		c.CertFile = v
+	}
+	if v := os.Getenv(httpsKeyFilePathEnv); len(v) > 0 {
+		c.KeyFile = v
+	}
+	if v := os.Getenv(httpsTrustCaFilePathEnv); len(v) > 0 {
+		c.TrustCAFile = v
+	}
+	if v, ok := os.LookupEnv(enableMutualHTTPSEnv); ok {
+		enable, err := strconv.ParseBool(v)
+		if err != nil {
+			klog.Warningf("cannot trans the env [%s] to the bool type, will set the value with true", enableMutualHTTPSEnv)
+			enable = true
+		}
+		c.EnableMutual = enable
+	}
+	if v := os.Getenv(tlsConfigEnv); len(v) > 0 {
+		c.ClientAuth = v
+	}
+	if v, ok := os.LookupEnv(checkIdentityEnv); ok {
+		check, err := strconv.ParseBool(v)
+		c.CheckIdentity = check && err == nil
+	}
+}
+
+// Validate the TLS config
+func (c *TLSConfig) Validate() error {
+	switch c.ClientAuth {
+	case "", noClientCert, requestClientCert, requireAnyClientCert, verifyClientCertIfGiven,
+		requireAndVerifyClientCert:
+		return nil
+	default:
+		return fmt.Errorf("the client auth %s is invalid", c.ClientAuth)
+	}
+}
+
+// loadConfigFile set the flags which are not overridden by the config file, and read the TLS config
+func (s *ServerRunOptions) loadConfigFile(overridden map[string]bool) error {
+	if len(s.ConfigFile) == 0 {
+		return nil
+	}
+	content, err := ioutil.ReadFile(s.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("cannot read the config file %s, err: %v", s.ConfigFile, err)
+	}
+	values, err := parseConfigFile(content, &s.TLS)
+	if err != nil {
+		return fmt.Errorf("the config file %s is invalid, err: %v", s.ConfigFile, err)
+	}
+	for name, value := range values {
+		if overridden[name] {
+			klog.Infof("the flag %s is overridden by the flag or the environment variable, ignore the config file", name)
+			continue
+		}
+		if err = s.fs.Set(name, value); err != nil {
+			return fmt.Errorf("the value %s of flag %s in the config file %s is invalid, err: %v",
+				value, name, s.ConfigFile, err)
+		}
+	}
+	return nil
+}
+
+// parseConfigFile parse the config file into the values of the flags by name, and the TLS section into tlsConfig.
+// The unknown keys are rejected, thus the typos cannot be ignored silently.
+func parseConfigFile(content []byte, tlsConfig *TLSConfig) (map[string]string, error) {
+	sections := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &sections); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for section, fields := range sections {
+		if section == tlsSection {
+			buf, err := yaml.Marshal(fields)
+			if err != nil {
+				return nil, err
+			}
+			if err = yaml.UnmarshalStrict(buf, tlsConfig); err != nil {
+				return nil, fmt.Errorf("invalid section %s, err: %v", section, err)
+			}
+			continue
+		}
+		fieldMap, ok := fields.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the section %s should be a map", section)
+		}
+		for field, value := range fieldMap {
+			key := section + "." + field
+			name, ok := configFileFlags[key]
+			if !ok {
+				return nil, fmt.Errorf("unknown key %s", key)
+			}
+			switch v := value.(type) {
+			case float64:
+				values[name] = strconv.FormatFloat(v, 'f', -1, 64)
+			case string, bool:
+				values[name] = fmt.Sprint(v)
+			case []interface{}:
+				if !listFlags[name] {
+					return nil, fmt.Errorf("the value of %s should be a scalar", key)
+				}
+				items := make([]string, 0, len(v))
+				for _, item := range v {
+					items = append(items, fmt.Sprint(item))
+				}
+				values[name] = strings.Join(items, ",")
+			default:
+				return nil, fmt.Errorf("the value of %s should be a scalar", key)
+			}
+		}
+	}
+	return values, nil
+}
+
+// WatchConfigFile reload the config file when it is changed or the SIGHUP is received until the stopCh is closed
+func (s *ServerRunOptions) WatchConfigFile(stopCh <-chan struct{}) {
+	if len(s.ConfigFile) == 0 {
+		return
+	}
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	var tick <-chan time.Time
+	if s.ConfigReloadPeriod > 0 {
+		ticker := time.NewTicker(s.ConfigReloadPeriod)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	lastSum := fileChecksum(s.ConfigFile)
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-sighup:
+			klog.Infof("received SIGHUP, reload the config file %s", s.ConfigFile)
+		case <-tick:
+			// the ConfigMap volume is updated by replacing the symlink, thus the content is compared
+			sum := fileChecksum(s.ConfigFile)
+			if bytes.Equal(sum, lastSum) {
+				continue
+			}
+			lastSum = sum
+			klog.Infof("the config file %s is changed, reload it", s.ConfigFile)
+		}
+		if err := s.Reload(); err != nil {
+			klog.Errorf("cannot reload the config file %s, keep the current config, err: %v", s.ConfigFile, err)
+		}
+	}
+}
+
+func fileChecksum(name string) []byte {
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(content)
+	return sum[:]
+}
+
+// Reload resolve the config again, and apply the flow control, the timeouts and the log level. The flags and the
+// environment variables still override the config file, and the changes of the other fields are ignored until
+// restarting.
+func (s *ServerRunOptions) Reload() error {
+	n := newServerRunOptions(s.component, s.prefix, s.Listen.HTTPSAddr)
+	if err := n.getFlagSetValue(s.prefix); err != nil {
+		return err
+	}
+	n.TLS.getValueFromEnv()
+	if err := n.validate(); err != nil {
+		return err
+	}
+
+	var restartRequired []string
+	n.fs.VisitAll(func(f *flag.Flag) {
+		current := s.fs.Lookup(f.Name)
+		if !reloadableFlags[f.Name] && current != nil && current.Value.String() != f.Value.String() {
+			restartRequired = append(restartRequired, f.Name)
+		}
+	})
+	if !reflect.DeepEqual(s.TLS, n.TLS) {
+		restartRequired = append(restartRequired, tlsSection)
+	}
+	if len(restartRequired) > 0 {
+		sort.Strings(restartRequired)
+		klog.Warningf("the changes of %s take effect after restarting", strings.Join(restartRequired, ", "))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !reflect.DeepEqual(s.FlowControllerConfig, n.FlowControllerConfig) {
+		flowcontroller.Init(n.FlowControllerConfig)
+		s.FlowControllerConfig = n.FlowControllerConfig
+	}
+	apis.SetProcessTimeoutConfig(n.ProcessTimeoutConfig)
+	s.ProcessTimeoutConfig = n.ProcessTimeoutConfig
+	s.ShutdownTimeout = n.ShutdownTimeout
+	// the log level has been set when parsing the flags, because the klog flags are bound to its global config
+	klog.Infof("the config file %s is reloaded", s.ConfigFile)
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kappital/kappital/pkg/apis"
+)
+
+const testConfigFile = `
+listen:
+  httpsPort: 30331
+tls:
+  enable: true
+  certFile: /opt/kappital/certs/conf/server.crt
+  clientAuth: VERIFY_CLIENT_CERT_IF_GIVEN
+  checkIdentity: true
+database:
+  maxConn: 1000000
+flowControl:
+  qps: 20.5
+  enable: false
+timeouts:
+  install: 5m
+log:
+  level: 2
+`
+
+func Test_parseConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantTLS TLSConfig
+		wantErr bool
+	}{
+		{
+			name:    "all sections",
+			content: testConfigFile,
+			want: map[string]string{"https-port": "30331", "sql-max-conn": "1000000", "flow-control-qps": "20.5",
+				"flow-control-enable": "false", "install-timeout": "5m", "v": "2"},
+			wantTLS: TLSConfig{Enable: true, CertFile: "/opt/kappital/certs/conf/server.crt", EnableMutual: true,
+				ClientAuth: verifyClientCertIfGiven, CheckIdentity: true},
+		},
+		{name: "empty file", want: map[string]string{}, wantTLS: DefaultTLSConfig()},
+		{name: "unknown key", content: "database:\n  maxConnection: 10\n", wantErr: true},
+		{name: "unknown tls key", content: "tls:\n  cert: server.crt\n", wantErr: true},
+		{name: "section is not a map", content: "timeouts: 5m\n", wantErr: true},
+		{name: "value is not a scalar", content: "timeouts:\n  install: [5m]\n", wantErr: true},
+		{name: "list value", content: "audit:\n  sinks: [file, webhook]\n",
+			want: map[string]string{"audit-log-sinks": "file,webhook"}, wantTLS: DefaultTLSConfig()},
+		{name: "invalid yaml", content: "timeouts: [", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig := DefaultTLSConfig()
+			got, err := parseConfigFile([]byte(tt.content), &tlsConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseConfigFile() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tlsConfig, tt.wantTLS) {
+				t.Errorf("parseConfigFile() tls = %+v, want %+v", tlsConfig, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestServerRunOptions_loadConfigFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(testConfigFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer func(args []string) { os.Args = args }(os.Args)
+	os.Args = []string{"manager", "--config", configFile, "--https-port", "30332"}
+	t.Setenv("MANAGER_INSTALL_TIMEOUT", "10m")
+	t.Setenv(tlsConfigEnv, requireAnyClientCert)
+
+	s := newServerRunOptions("test", managerEnvPrefix, "127.0.0.1")
+	if err := s.getFlagSetValue(managerEnvPrefix); err != nil {
+		t.Fatalf("getFlagSetValue() error = %v", err)
+	}
+	s.TLS.getValueFromEnv()
+	if s.Listen.HTTPSPort != 30332 {
+		t.Errorf("the flag should override the config file, got https port %d", s.Listen.HTTPSPort)
+	}
+	if s.ProcessTimeoutConfig.InstallTimeout != 10*time.Minute {
+		t.Errorf("the env should override the config file, got install timeout %s",
+			s.ProcessTimeoutConfig.InstallTimeout)
+	}
+	if s.FlowControllerConfig.QPS != 20.5 || s.FlowControllerConfig.Enable {
+		t.Errorf("the flow control should be read from the config file, got %+v", s.FlowControllerConfig)
+	}
+	if s.TLS.ClientAuth != requireAnyClientCert || !s.TLS.CheckIdentity {
+		t.Errorf("the legacy env should override the tls config, got %+v", s.TLS)
+	}
+	if err := s.validate(); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
+
+func TestServerRunOptions_Reload(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("timeouts:\n  delete: 4m\n  shutdown: 20s\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer func(args []string) { os.Args = args }(os.Args)
+	os.Args = []string{"manager", "--config", configFile}
+	s := newServerRunOptions("test", managerEnvPrefix, "127.0.0.1")
+	if err := s.getFlagSetValue(managerEnvPrefix); err != nil {
+		t.Fatalf("getFlagSetValue() error = %v", err)
+	}
+
+	if err := os.WriteFile(configFile, []byte("timeouts:\n  delete: 6m\n  shutdown: 40s\n"+
+		"listen:\n  httpsPort: 30333\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := apis.GetProcessTimeoutConfig().DeleteTimeout; got != 6*time.Minute {
+		t.Errorf("the delete timeout should be reloaded, got %s", got)
+	}
+	if got := s.GetShutdownTimeout(); got != 40*time.Second {
+		t.Errorf("the shutdown timeout should be reloaded, got %s", got)
+	}
+	if s.Listen.HTTPSPort != httpsPort {
+		t.Errorf("the https port should not be reloaded, got %d", s.Listen.HTTPSPort)
+	}
+
+	if err := os.WriteFile(configFile, []byte("flowControl:\n  qps: -1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err == nil {
+		t.Errorf("Reload() should reject the invalid config")
+	}
+	apis.SetProcessTimeoutConfig(apis.DefaultProcessTimeoutConfig())
+}
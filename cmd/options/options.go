@@ -20,24 +20,35 @@ import (
 	"crypto/tls"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/beego/beego/v2/server/web"
 	"k8s.io/klog/v2"
 
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/grpcserver"
 	"github.com/kappital/kappital/pkg/models"
 	"github.com/kappital/kappital/pkg/routers/flowcontroller"
+	"github.com/kappital/kappital/pkg/utils/audit"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/certificate"
 	"github.com/kappital/kappital/pkg/utils/file"
 	"github.com/kappital/kappital/pkg/utils/gateway"
+	"github.com/kappital/kappital/pkg/utils/health"
+	"github.com/kappital/kappital/pkg/utils/leaderelection"
+	"github.com/kappital/kappital/pkg/utils/tracing"
 	"github.com/kappital/kappital/pkg/utils/version"
 )
 
 const (
 	managerEnvPrefix = "MANAGER"
 
+	checkIdentityEnv        = "CHECK_IDENTITY"
 	enableHTTPSEnvKey       = "ENABLE_HTTPS"
 	httpsCertFilePathEnv    = "HTTPS_CERT_FILE"
 	httpsKeyFilePathEnv     = "HTTPS_KEY_FILE"
@@ -53,18 +64,42 @@ const (
 
 	httpsPort = 30330
 
+	defaultShutdownTimeout    = 30 * time.Second
+	defaultConfigReloadPeriod = 10 * time.Second
+
 	minPort = 1000
 	maxPort = 65535
 )
 
 // ServerRunOptions of the kappital service
 type ServerRunOptions struct {
-	fs *flag.FlagSet
+	fs        *flag.FlagSet
+	component string
+	prefix    string
+	// mu protects the fields which can be reloaded
+	mu           sync.RWMutex
+	certReloader *certificate.Reloader
 	web.Config
 
+	// ConfigFile the YAML config file, the flags and env vars override it
+	ConfigFile string
+	// ConfigReloadPeriod the interval of checking the config file changes, 0 means only reloading by SIGHUP
+	ConfigReloadPeriod time.Duration
+	TLS                TLSConfig
+
 	FlowControllerConfig *flowcontroller.Config
 	DBConfig             *models.DatabaseConfig
 	DBWatcherConfig      *models.DatabaseWatcherConfig
+	LeaderElectionConfig *leaderelection.Config
+	ProcessTimeoutConfig *apis.ProcessTimeoutConfig
+	TracingConfig        *tracing.Config
+	AuthorizationConfig  *authorization.Config
+	AuthenticationConfig *authentication.Config
+	HealthConfig         *health.Config
+	GRPCConfig           *grpcserver.Config
+	AuditConfig          *audit.AuditLogConfig
+	// ShutdownTimeout the longest duration of waiting for the in-flight requests and processor steps when stopping
+	ShutdownTimeout time.Duration
 }
 
 // NewServerRunOptions creates a new ServerRunOptions object with default parameters
@@ -85,34 +120,151 @@ func NewServerRunOptions(component string) (*ServerRunOptions, error) {
 		return nil, fmt.Errorf("get local ip failed, error: %v", err)
 	}
 
+	s := newServerRunOptions(component, prefix, ip)
+	if err = s.getFlagSetValue(prefix); err != nil {
+		return nil, fmt.Errorf("resolve config error: %w ", err)
+	}
+	if err = s.generateConfig(); err != nil {
+		return nil, fmt.Errorf("cannot get the correct config, err: %v", err)
+	}
+	if err = s.secureServer(); err != nil {
+		return nil, fmt.Errorf("cannot start the secure server, err: %v", err)
+	}
+	return s, nil
+}
+
+// newServerRunOptions creates a ServerRunOptions object with default parameters and the registered flags
+func newServerRunOptions(component, prefix, ip string) *ServerRunOptions {
+	auditConfig := audit.DefaultAuditLogConfig()
 	s := &ServerRunOptions{
-		fs: flag.NewFlagSet(component, flag.ContinueOnError),
+		fs:        flag.NewFlagSet(component, flag.ContinueOnError),
+		component: component,
+		prefix:    prefix,
 		Config: web.Config{
 			Listen: web.Listen{HTTPSAddr: ip, HTTPSPort: httpsPort},
 		},
+		ConfigReloadPeriod:   defaultConfigReloadPeriod,
+		TLS:                  DefaultTLSConfig(),
 		FlowControllerConfig: flowcontroller.DefaultFlowControllerConfig(),
 		DBConfig:             models.DefaultDatabaseConfiguration(),
 		DBWatcherConfig:      models.DefaultDatabaseWatcherConfig(),
+		LeaderElectionConfig: leaderelection.DefaultLeaderElectionConfig(),
+		ProcessTimeoutConfig: apis.DefaultProcessTimeoutConfig(),
+		TracingConfig:        tracing.DefaultTracingConfig(),
+		AuthorizationConfig:  authorization.DefaultAuthorizationConfig(),
+		AuthenticationConfig: authentication.DefaultAuthenticationConfig(),
+		HealthConfig:         health.DefaultHealthConfig(),
+		GRPCConfig:           grpcserver.DefaultGRPCConfig(),
+		AuditConfig:          &auditConfig,
+		ShutdownTimeout:      defaultShutdownTimeout,
 	}
 	s.initFlagSet()
 	klog.InitFlags(s.fs)
+	return s
+}
 
-	if err = s.getFlagSetValue(prefix); err != nil {
-		return nil, fmt.Errorf("resolve config error: %w ", err)
-	}
-	if err = s.generateConfig(); err != nil {
-		return nil, fmt.Errorf("cannot get the correct config, err: %v", err)
-	}
-	if err = s.secureServer(); err != nil {
-		return nil, fmt.Errorf("cannot start the secure server, err: %v", err)
-	}
-	return s, nil
+// GetShutdownTimeout get the shutdown timeout, it can be changed by reloading the config file
+func (s *ServerRunOptions) GetShutdownTimeout() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ShutdownTimeout
 }
 
 func (s *ServerRunOptions) initFlagSet() {
+	// Config file flags
+	s.fs.StringVar(&s.ConfigFile, configFileFlag, s.ConfigFile,
+		"The YAML config file of the server, the flags and environment variables override it.")
+	s.fs.DurationVar(&s.ConfigReloadPeriod, "config-reload-period", s.ConfigReloadPeriod,
+		"The interval of checking the config file changes, 0 means the config file is only reloaded by SIGHUP.")
+
 	// Server flags of http and https
 	s.fs.IntVar(&s.Listen.HTTPSPort, "https-port", s.Listen.HTTPSPort,
 		"The port on which to serve HTTPS with authentication and authorization")
+	s.fs.DurationVar(&s.ShutdownTimeout, "shutdown-timeout", s.ShutdownTimeout,
+		"The longest duration of waiting for the in-flight requests and processor steps when stopping the server.")
+
+	// Health probe flags
+	s.fs.StringVar(&s.HealthConfig.BindAddress, "health-probe-bind-address", s.HealthConfig.BindAddress,
+		"The address the liveness and readiness probes bind to, they are served without the client certificate. "+
+			"Empty means disable the probes.")
+	s.fs.DurationVar(&s.HealthConfig.CheckTimeout, "health-check-timeout", s.HealthConfig.CheckTimeout,
+		"The longest duration of each liveness or readiness check.")
+	s.fs.Float64Var(&s.HealthConfig.WatcherSaturation, "health-watcher-saturation", s.HealthConfig.WatcherSaturation,
+		"The ratio of the used watcher event buffer in (0, 1], the replica is not ready when it is reached.")
+
+	// gRPC flags
+	s.fs.StringVar(&s.GRPCConfig.BindAddress, "grpc-bind-address", s.GRPCConfig.BindAddress,
+		"The address the gRPC API binds to, it is served with the TLS config and the identity check of the HTTPS "+
+			"server. Empty means disable the gRPC API.")
+	s.fs.DurationVar(&s.GRPCConfig.WatchInterval, "grpc-watch-interval", s.GRPCConfig.WatchInterval,
+		"The interval of polling the service bindings and instances watched by the gRPC API.")
+
+	// Flow control flags
+	s.fs.BoolVar(&s.FlowControllerConfig.Enable, "flow-control-enable", s.FlowControllerConfig.Enable,
+		"Enable the flow controller which limits the requests by the token buckets.")
+	s.fs.Float64Var(&s.FlowControllerConfig.QPS, "flow-control-qps", s.FlowControllerConfig.QPS,
+		"The QPS of the read bucket shared by all clients.")
+	s.fs.IntVar(&s.FlowControllerConfig.Burst, "flow-control-burst", s.FlowControllerConfig.Burst,
+		"The burst of the read bucket shared by all clients.")
+	s.fs.Float64Var(&s.FlowControllerConfig.MutationQPS, "flow-control-mutation-qps",
+		s.FlowControllerConfig.MutationQPS, "The QPS of the mutation bucket shared by all clients, thus the reads "+
+			"cannot use up the capacity of the mutations. 0 means the same as the read bucket.")
+	s.fs.IntVar(&s.FlowControllerConfig.MutationBurst, "flow-control-mutation-burst",
+		s.FlowControllerConfig.MutationBurst, "The burst of the mutation bucket shared by all clients.")
+	s.fs.Float64Var(&s.FlowControllerConfig.ClientReadQPS, "flow-control-client-read-qps",
+		s.FlowControllerConfig.ClientReadQPS, "The QPS of the read requests of each client, the client is the "+
+			"authenticated principal or the source ip. 0 means the client reads are only limited by the shared bucket.")
+	s.fs.IntVar(&s.FlowControllerConfig.ClientReadBurst, "flow-control-client-read-burst",
+		s.FlowControllerConfig.ClientReadBurst, "The burst of the read requests of each client.")
+	s.fs.Float64Var(&s.FlowControllerConfig.ClientMutationQPS, "flow-control-client-mutation-qps",
+		s.FlowControllerConfig.ClientMutationQPS, "The QPS of the mutation requests of each client. "+
+			"0 means the client mutations are only limited by the shared bucket.")
+	s.fs.IntVar(&s.FlowControllerConfig.ClientMutationBurst, "flow-control-client-mutation-burst",
+		s.FlowControllerConfig.ClientMutationBurst, "The burst of the mutation requests of each client.")
+
+	// Authentication flags
+	s.fs.StringVar(&s.AuthenticationConfig.OIDCConfigFile, "oidc-config-file",
+		s.AuthenticationConfig.OIDCConfigFile, "The config file of the trusted OIDC issuers whose ID tokens are "+
+			"accepted as the bearer tokens, it requires the authorization policy file. Empty means the OIDC "+
+			"authentication is disabled.")
+
+	// Audit flags
+	s.fs.StringVar(&s.AuditConfig.Filename, "audit-log-file", s.AuditConfig.Filename,
+		"The file of the audit log, empty means writing the audit log into the stdout.")
+	s.fs.Int64Var(&s.AuditConfig.MaxSize, "audit-log-max-size", s.AuditConfig.MaxSize,
+		"The size of the audit log file in bytes which triggers the rotation, 0 means not rotating by the size.")
+	s.fs.DurationVar(&s.AuditConfig.RotationPeriod, "audit-log-rotation-period", s.AuditConfig.RotationPeriod,
+		"The duration after which the audit log file is rotated, 0 means not rotating by the time.")
+	s.fs.IntVar(&s.AuditConfig.MaxBackups, "audit-log-max-backups", s.AuditConfig.MaxBackups,
+		"The number of the rotated audit log files which are kept, 0 means keeping all.")
+	s.fs.DurationVar(&s.AuditConfig.MaxBackupAge, "audit-log-max-backup-age", s.AuditConfig.MaxBackupAge,
+		"The duration which the rotated audit log files are kept, 0 means keeping them forever.")
+	s.fs.Var((*stringList)(&s.AuditConfig.Sinks), "audit-log-sinks", fmt.Sprintf("The comma separated sinks "+
+		"which the audit log is written to, the sinks are %s, %s, %s and %s.", audit.FileSink, audit.StdoutSink,
+		audit.SyslogSink, audit.WebhookSink))
+	s.fs.StringVar(&s.AuditConfig.SyslogNetwork, "audit-log-syslog-network", s.AuditConfig.SyslogNetwork,
+		"The network of the syslog server such as udp, empty means the local syslog.")
+	s.fs.StringVar(&s.AuditConfig.SyslogAddress, "audit-log-syslog-address", s.AuditConfig.SyslogAddress,
+		"The address of the syslog server, empty means the local syslog.")
+	s.fs.StringVar(&s.AuditConfig.SyslogTag, "audit-log-syslog-tag", s.AuditConfig.SyslogTag,
+		"The tag of the syslog messages, empty means the app name.")
+	s.fs.StringVar(&s.AuditConfig.WebhookURL, "audit-log-webhook-url", s.AuditConfig.WebhookURL,
+		"The url which the audit log entries are posted to by the webhook sink.")
+	s.fs.DurationVar(&s.AuditConfig.WebhookTimeout, "audit-log-webhook-timeout", s.AuditConfig.WebhookTimeout,
+		"The timeout of posting each audit log entry to the webhook.")
+	s.fs.BoolVar(&s.AuditConfig.HashChain, "audit-log-hash-chain", s.AuditConfig.HashChain,
+		"Add the hash of the previous record into each audit log record, thus the edited records can be found by "+
+			"the audit verify command.")
+	s.fs.StringVar(&s.AuditConfig.SigningKeyFile, "audit-log-signing-key-file", s.AuditConfig.SigningKeyFile,
+		"The ed25519 private key in PKCS#8 PEM which signs the hash chain of the audit log, empty means not signing.")
+	s.fs.DurationVar(&s.AuditConfig.SignInterval, "audit-log-sign-interval", s.AuditConfig.SignInterval,
+		"The interval of signing the hash chain of the audit log.")
+
+	// Authorization flags
+	s.fs.StringVar(&s.AuthorizationConfig.PolicyFile, "authorization-policy-file",
+		s.AuthorizationConfig.PolicyFile, "The policy file which grants the roles to the clients. "+
+			"Empty means only the accepted client certificate can do everything, and the API tokens and the OIDC ID "+
+			"tokens are refused.")
 
 	// Database flags
 	s.fs.StringVar(&s.DBConfig.SQLDriver, "sql-driver", s.DBConfig.SQLDriver,
@@ -131,17 +283,67 @@ func (s *ServerRunOptions) initFlagSet() {
 	s.fs.DurationVar(&s.DBWatcherConfig.ListenerMinReconnectInterval, "min-database-reconnect-interval",
 		s.DBWatcherConfig.ListenerMinReconnectInterval,
 		"min database reconnect interval in seconds for watching table.")
+	s.fs.DurationVar(&s.DBWatcherConfig.ResyncPeriod, "database-resync-period", s.DBWatcherConfig.ResyncPeriod,
+		"interval for listing the processing records again, 0 means never resync.")
+
+	// Leader election flags
+	s.fs.BoolVar(&s.LeaderElectionConfig.Enable, "leader-elect", s.LeaderElectionConfig.Enable,
+		"Enable leader election for the processors, only the leader replica will process the records. "+
+			"Enabling this will ensure there is only one active processor when running multiple replicas.")
+	s.fs.DurationVar(&s.LeaderElectionConfig.LeaseDuration, "leader-elect-lease-duration",
+		s.LeaderElectionConfig.LeaseDuration,
+		"The duration that non-leader candidates will wait to force acquire leadership.")
+	s.fs.DurationVar(&s.LeaderElectionConfig.RenewDeadline, "leader-elect-renew-deadline",
+		s.LeaderElectionConfig.RenewDeadline,
+		"The duration that the acting leader will retry refreshing leadership before giving up.")
+	s.fs.DurationVar(&s.LeaderElectionConfig.RetryPeriod, "leader-elect-retry-period",
+		s.LeaderElectionConfig.RetryPeriod,
+		"The duration the candidates should wait between tries of actions.")
+	s.fs.StringVar(&s.LeaderElectionConfig.LockName, "leader-elect-resource-name",
+		s.LeaderElectionConfig.LockName, "The name of the Lease object which used for leader election.")
+	s.fs.StringVar(&s.LeaderElectionConfig.LockNamespace, "leader-elect-resource-namespace",
+		s.LeaderElectionConfig.LockNamespace, "The namespace of the Lease object which used for leader election.")
+
+	// Processor timeout flags, the services and requests can override the install, upgrade and delete timeouts
+	s.fs.DurationVar(&s.ProcessTimeoutConfig.InstallTimeout, "install-timeout",
+		s.ProcessTimeoutConfig.InstallTimeout, "The default longest duration of installing the service or instance.")
+	s.fs.DurationVar(&s.ProcessTimeoutConfig.UpgradeTimeout, "upgrade-timeout",
+		s.ProcessTimeoutConfig.UpgradeTimeout, "The default longest duration of upgrading the service or instance.")
+	s.fs.DurationVar(&s.ProcessTimeoutConfig.DeleteTimeout, "delete-timeout",
+		s.ProcessTimeoutConfig.DeleteTimeout, "The default longest duration of deleting the service or instance.")
+	s.fs.DurationVar(&s.ProcessTimeoutConfig.BindingReadyTimeout, "binding-ready-timeout",
+		s.ProcessTimeoutConfig.BindingReadyTimeout,
+		"The longest duration of waiting for the service binding ready before installing the instances.")
+	s.fs.DurationVar(&s.ProcessTimeoutConfig.LongestDurationForProcess, "longest-process-duration",
+		s.ProcessTimeoutConfig.LongestDurationForProcess,
+		"The longest duration of processing a record since its last update, it will be extended to the "+
+			"operation timeout if the operation timeout is longer.")
+
+	// Tracing flags
+	s.fs.StringVar(&s.TracingConfig.Exporter, "tracing-exporter", s.TracingConfig.Exporter,
+		"The exporter of the tracing spans, one of none, otlp, stdout and file. none means disable the tracing.")
+	s.fs.StringVar(&s.TracingConfig.Endpoint, "tracing-endpoint", s.TracingConfig.Endpoint,
+		"The address of the OpenTelemetry collector which receives the spans with the OTLP gRPC protocol.")
+	s.fs.BoolVar(&s.TracingConfig.Insecure, "tracing-insecure", s.TracingConfig.Insecure,
+		"Connect the OpenTelemetry collector without TLS.")
+	s.fs.StringVar(&s.TracingConfig.FilePath, "tracing-file", s.TracingConfig.FilePath,
+		"The file which the spans will be written into when the tracing exporter is file.")
+	s.fs.Float64Var(&s.TracingConfig.SampleRatio, "tracing-sample-ratio", s.TracingConfig.SampleRatio,
+		"The ratio of the sampled traces in [0, 1], the traces started by the callers follow their decisions.")
 }
 
+// getFlagSetValue resolve the values in order of the flags, the environment variables, the config file and the
+// default values
 func (s *ServerRunOptions) getFlagSetValue(prefix string) error {
 	if err := s.fs.Parse(os.Args[1:]); err != nil {
 		return err
 	}
-	s.getFlagsValueFromEnv(prefix)
-	return nil
+	return s.loadConfigFile(s.getFlagsValueFromEnv(prefix))
 }
 
-func (s *ServerRunOptions) getFlagsValueFromEnv(prefix string) {
+// getFlagsValueFromEnv set the flags by the environment variables, and return the flags which are set explicitly or
+// by the environment variables
+func (s *ServerRunOptions) getFlagsValueFromEnv(prefix string) map[string]bool {
 	flagsAlreadySet := make(map[string]bool)
 	s.fs.Visit(func(f *flag.Flag) {
 		flagsAlreadySet[f.Name] = true
@@ -157,29 +359,23 @@ func (s *ServerRunOptions) getFlagsValueFromEnv(prefix string) {
 					fmt.Printf("invalid value %v for %s\n", v, k)
 				}
 				fmt.Printf("recongnized environment variable %s=%s\n", k, v)
+				flagsAlreadySet[f.Name] = true
 			}
 		}
 	})
+	return flagsAlreadySet
 }
 
 func (s *ServerRunOptions) generateConfig() error {
+	s.TLS.getValueFromEnv()
 	s.Listen.EnableHTTP = false
-	s.Listen.EnableHTTPS = parseBool(os.Getenv(enableHTTPSEnvKey))
-	s.Listen.HTTPSCertFile = os.Getenv(httpsCertFilePathEnv)
-	s.Listen.HTTPSKeyFile = os.Getenv(httpsKeyFilePathEnv)
-	s.Listen.TrustCaFile = os.Getenv(httpsTrustCaFilePathEnv)
-	if len(s.Listen.TrustCaFile) == 0 {
-		s.Listen.EnableMutualHTTPS = false
-	} else {
-		enable, err := strconv.ParseBool(os.Getenv(enableMutualHTTPSEnv))
-		if err != nil {
-			klog.Warningf("cannot trans the env [%s] to the bool type, will set the value with true", enableMutualHTTPSEnv)
-			enable = true
-		}
-		s.Listen.EnableMutualHTTPS = enable
-	}
+	s.Listen.EnableHTTPS = s.TLS.Enable
+	s.Listen.HTTPSCertFile = s.TLS.CertFile
+	s.Listen.HTTPSKeyFile = s.TLS.KeyFile
+	s.Listen.TrustCaFile = s.TLS.TrustCAFile
+	s.Listen.EnableMutualHTTPS = len(s.Listen.TrustCaFile) > 0 && s.TLS.EnableMutual
 	if s.Listen.EnableMutualHTTPS {
-		_, s.Listen.ClientAuth = getClientAuth()
+		_, s.Listen.ClientAuth = getClientAuth(s.TLS.ClientAuth)
 	}
 	if !s.Listen.EnableHTTPS {
 		return fmt.Errorf("https is disable, invalid start method")
@@ -194,7 +390,35 @@ func (s *ServerRunOptions) generateConfig() error {
 	if len(s.Listen.TrustCaFile) > 0 && !file.IsFileExist(s.Listen.TrustCaFile) {
 		return fmt.Errorf("enable the mutual https, but cannot find the trust ca file")
 	}
+	return s.validate()
+}
 
+// validate the configs which do not depend on the environment
+func (s *ServerRunOptions) validate() error {
+	if err := s.TLS.Validate(); err != nil {
+		return err
+	}
+	if err := s.TracingConfig.Validate(); err != nil {
+		return err
+	}
+	if err := s.FlowControllerConfig.Validate(); err != nil {
+		return err
+	}
+	if err := s.HealthConfig.Validate(); err != nil {
+		return err
+	}
+	if err := s.GRPCConfig.Validate(); err != nil {
+		return err
+	}
+	if err := s.AuditConfig.Validate(); err != nil {
+		return err
+	}
+	if len(s.AuthenticationConfig.OIDCConfigFile) > 0 && len(s.AuthorizationConfig.PolicyFile) == 0 {
+		return fmt.Errorf("the OIDC authentication requires the authorization policy file")
+	}
+	if s.ConfigReloadPeriod < 0 {
+		return fmt.Errorf("the config reload period cannot be negative")
+	}
 	return nil
 }
 
@@ -209,29 +433,38 @@ func (s *ServerRunOptions) secureServer() error {
 	if !s.Listen.EnableHTTPS {
 		return nil
 	}
-	certPEMBlock, err := ioutil.ReadFile(s.Listen.HTTPSCertFile)
-	if err != nil {
-		return err
+	caFile := ""
+	if s.Listen.EnableMutualHTTPS {
+		caFile = s.Listen.TrustCaFile
 	}
-	keyPEMBlock, err := ioutil.ReadFile(s.Listen.HTTPSKeyFile)
+	reloader, err := certificate.NewReloader(s.Listen.HTTPSCertFile, s.Listen.HTTPSKeyFile, caFile)
 	if err != nil {
 		return err
 	}
-	certificate, err := tls.X509KeyPair(certPEMBlock, keyPEMBlock)
-	if err != nil {
-		return err
+	s.certReloader = reloader
+	tlsConfig := reloader.TLSConfig(web.BeeApp.Server.TLSConfig)
+	tlsConfig.ClientAuth, web.BeeApp.Cfg.Listen.ClientAuth = getClientAuth(s.TLS.ClientAuth)
+	tlsConfig.InsecureSkipVerify = false
+	web.BeeApp.Server.TLSConfig = tlsConfig
+	// beego replaces the TLS config with the ca bundle read once when the mutual https is enabled, thus the client
+	// certificates are verified by the TLS config of the reloader instead
+	web.BConfig.Listen.EnableMutualHTTPS = false
+	return nil
+}
+
+// WatchCertificates reload the server certificate and the client ca bundle when they are changed until the stopCh is
+// closed
+func (s *ServerRunOptions) WatchCertificates(stopCh <-chan struct{}) {
+	if s.certReloader == nil {
+		return
 	}
-	web.BeeApp.Server.TLSConfig.Certificates = append(web.BeeApp.Server.TLSConfig.Certificates, certificate)
-	web.BeeApp.Server.TLSConfig.GetCertificate = func(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
-		return &web.BeeApp.Server.TLSConfig.Certificates[0], nil
+	if err := s.certReloader.Watch(stopCh); err != nil {
+		klog.Errorf("cannot watch the certificates, they will not be reloaded, err: %v", err)
 	}
-	web.BeeApp.Server.TLSConfig.ClientAuth, web.BeeApp.Cfg.Listen.ClientAuth = getClientAuth()
-	web.BeeApp.Server.TLSConfig.InsecureSkipVerify = false
-	return nil
 }
 
-func getClientAuth() (tls.ClientAuthType, int) {
-	switch os.Getenv(tlsConfigEnv) {
+func getClientAuth(clientAuth string) (tls.ClientAuthType, int) {
+	switch clientAuth {
 	case noClientCert:
 		return tls.NoClientCert, 0
 	case requestClientCert:
@@ -258,3 +491,21 @@ func parseBool(str string) bool {
 	}
 	return res
 }
+
+// stringList the flag value of the comma separated list, the empty items are ignored
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(str string) error {
+	var items []string
+	for _, item := range strings.Split(str, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	*l = items
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var tokenNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,62}[a-z0-9])?$`)
+
+// APITokenCreation the request body to create the static API token
+type APITokenCreation struct {
+	Name      string   `json:"name"`                // the unique name of the token, it is used to delete the token
+	User      string   `json:"user"`                // the principal name of the token
+	Groups    []string `json:"groups,omitempty"`    // the principal groups of the token
+	ExpiresIn string   `json:"expiresIn,omitempty"` // the lifetime of the token, such as "720h", never expires if empty
+}
+
+// Validate validate the struct variables
+func (a *APITokenCreation) Validate() error {
+	if !tokenNameRegexp.MatchString(a.Name) {
+		return fmt.Errorf("the name %q of the API token is invalid, it must be a DNS-1123 label", a.Name)
+	}
+	if len(a.User) == 0 {
+		return fmt.Errorf("the user of the API token cannot be empty")
+	}
+	for _, group := range a.Groups {
+		if len(group) == 0 || strings.Contains(group, ",") {
+			return fmt.Errorf("the group %q of the API token cannot be empty or contain the comma", group)
+		}
+	}
+	if len(a.ExpiresIn) == 0 {
+		return nil
+	}
+	d, err := time.ParseDuration(a.ExpiresIn)
+	if err != nil {
+		return fmt.Errorf("cannot parse the expiresIn %q of the API token, err: %v", a.ExpiresIn, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("the expiresIn %q of the API token must be positive", a.ExpiresIn)
+	}
+	return nil
+}
+
+// APIToken the static API token in the response, the token is only returned when it is created
+type APIToken struct {
+	Name       string     `json:"name"`
+	User       string     `json:"user"`
+	Groups     []string   `json:"groups,omitempty"`
+	CreateTime time.Time  `json:"createTime"`
+	ExpireTime *time.Time `json:"expireTime,omitempty"`
+	Token      string     `json:"token,omitempty"`
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import "testing"
+
+func TestAPITokenCreation_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		creation APITokenCreation
+		wantErr  bool
+	}{
+		{name: "valid", creation: APITokenCreation{Name: "ci", User: "ci-bot", Groups: []string{"ci"}, ExpiresIn: "720h"}},
+		{name: "never expires", creation: APITokenCreation{Name: "ci", User: "ci-bot"}},
+		{name: "invalid name", creation: APITokenCreation{Name: "CI_Token", User: "ci-bot"}, wantErr: true},
+		{name: "empty user", creation: APITokenCreation{Name: "ci"}, wantErr: true},
+		{name: "group with comma", creation: APITokenCreation{Name: "ci", User: "ci-bot", Groups: []string{"a,b"}},
+			wantErr: true},
+		{name: "invalid expiresIn", creation: APITokenCreation{Name: "ci", User: "ci-bot", ExpiresIn: "1month"},
+			wantErr: true},
+		{name: "negative expiresIn", creation: APITokenCreation{Name: "ci", User: "ci-bot", ExpiresIn: "-1h"},
+			wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.creation.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -17,6 +17,8 @@
 package apis
 
 import (
+	"sort"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -37,6 +39,16 @@ var serviceTypeSet = map[ServiceType]struct{}{
 	helmServiceType:     {},
 }
 
+// SupportedServiceTypes the service types of the packages which can be deployed
+func SupportedServiceTypes() []string {
+	types := make([]string, 0, len(serviceTypeSet))
+	for t := range serviceTypeSet {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	return types
+}
+
 // AbstractResource defines a generic format of a resource to facilitate unmarshalling of resource files
 type AbstractResource struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,28 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apis
+
+// Initiator of the request which causes the background system actions of the processors, such as the deploying or
+// deleting the custom resources in the cluster.
+type Initiator struct {
+	// Principal the name of the user or the service account which sends the request
+	Principal string `json:"principal,omitempty"`
+	// AuthMethod the method which authenticates the principal, such as "token" or "certificate"
+	AuthMethod string `json:"authMethod,omitempty"`
+	// RequestID the correlation ID of the request
+	RequestID string `json:"requestID,omitempty"`
+}
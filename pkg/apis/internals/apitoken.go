@@ -0,0 +1,35 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internals
+
+import "time"
+
+// APIToken the static API token struct which using in the program internal, only the digest of the token is kept
+type APIToken struct {
+	ID         string
+	Name       string
+	User       string
+	Groups     []string
+	TokenHash  string
+	CreateTime time.Time
+	ExpireTime time.Time
+}
+
+// Expired does the API token has expired, the token without the expire time never expires
+func (a APIToken) Expired(now time.Time) bool {
+	return !a.ExpireTime.IsZero() && now.After(a.ExpireTime)
+}
@@ -19,25 +19,32 @@ package internals
 import (
 	"time"
 
+	"github.com/kappital/kappital/pkg/apis"
 	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
 )
 
 // ServiceBinding the service binding struct which using in the program internal
 type ServiceBinding struct {
-	ID               string
-	Name             string
-	Version          string
-	Namespace        string
-	ServiceName      string
-	ServiceID        string
-	ClusterID        string
-	ClusterName      string
-	Status           string
-	Message          string
-	ProcessTime      time.Time
-	UpdateTime       time.Time
-	CRD              []string
-	Permissions      []enginev1alpha1.Permission
-	Workload         enginev1alpha1.Workload
-	CapabilityPlugin enginev1alpha1.CapabilityPlugin
+	ID                 string
+	Name               string
+	Version            string
+	Namespace          string
+	ServiceName        string
+	ServiceID          string
+	ClusterID          string
+	ClusterName        string
+	Status             string
+	Message            string
+	ProcessTime        time.Time
+	UpdateTime         time.Time
+	CRD                []string
+	Permissions        []enginev1alpha1.Permission
+	Workload           enginev1alpha1.Workload
+	CapabilityPlugin   enginev1alpha1.CapabilityPlugin
+	Timeouts           apis.Timeouts
+	Initiator          apis.Initiator
+	ResourceVersion    int64
+	DeletionProtection bool
+	ForceDelete        bool
+	RemoveFinalizers   bool
 }
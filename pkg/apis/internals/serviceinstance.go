@@ -21,6 +21,8 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kappital/kappital/pkg/apis"
 )
 
 // ServiceInstance the service instance struct which using in the program internal
@@ -46,6 +48,12 @@ type ServiceInstance struct {
 	UpdateTime         time.Time
 	InstallState       InstallState
 	RuntimeState       RuntimeState
+	Timeouts           apis.Timeouts
+	Initiator          apis.Initiator
+	ResourceVersion    int64
+	DeletionProtection bool
+	ForceDelete        bool
+	RemoveFinalizers   bool
 }
 
 // InstallState of the cloud native service instance
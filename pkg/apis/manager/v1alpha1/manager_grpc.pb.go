@@ -0,0 +1,631 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ServiceBindingServiceClient is the client API for ServiceBindingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ServiceBindingServiceClient interface {
+	// CreateServiceBinding deploys the service package into the cluster, the existing service binding is returned if
+	// it has been deployed
+	CreateServiceBinding(ctx context.Context, in *CreateServiceBindingRequest, opts ...grpc.CallOption) (*CreateServiceBindingResponse, error)
+	// ListServiceBindings gets the service bindings of the cluster
+	ListServiceBindings(ctx context.Context, in *ListServiceBindingsRequest, opts ...grpc.CallOption) (*ListServiceBindingsResponse, error)
+	// GetServiceBinding gets the service binding
+	GetServiceBinding(ctx context.Context, in *GetServiceBindingRequest, opts ...grpc.CallOption) (*ServiceBinding, error)
+	// UpdateServiceBindingSettings replaces or patches the settings of the service binding
+	UpdateServiceBindingSettings(ctx context.Context, in *UpdateServiceBindingSettingsRequest, opts ...grpc.CallOption) (*UpdateSettingsResponse, error)
+	// DeleteServiceBinding uninstalls the service binding and its instances from the cluster
+	DeleteServiceBinding(ctx context.Context, in *DeleteServiceBindingRequest, opts ...grpc.CallOption) (*DeleteServiceBindingResponse, error)
+	// WatchServiceBinding streams the changes of the service binding until it is deleted
+	WatchServiceBinding(ctx context.Context, in *WatchServiceBindingRequest, opts ...grpc.CallOption) (ServiceBindingService_WatchServiceBindingClient, error)
+}
+
+type serviceBindingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServiceBindingServiceClient(cc grpc.ClientConnInterface) ServiceBindingServiceClient {
+	return &serviceBindingServiceClient{cc}
+}
+
+func (c *serviceBindingServiceClient) CreateServiceBinding(ctx context.Context, in *CreateServiceBindingRequest, opts ...grpc.CallOption) (*CreateServiceBindingResponse, error) {
+	out := new(CreateServiceBindingResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.ServiceBindingService/CreateServiceBinding", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceBindingServiceClient) ListServiceBindings(ctx context.Context, in *ListServiceBindingsRequest, opts ...grpc.CallOption) (*ListServiceBindingsResponse, error) {
+	out := new(ListServiceBindingsResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.ServiceBindingService/ListServiceBindings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceBindingServiceClient) GetServiceBinding(ctx context.Context, in *GetServiceBindingRequest, opts ...grpc.CallOption) (*ServiceBinding, error) {
+	out := new(ServiceBinding)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.ServiceBindingService/GetServiceBinding", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceBindingServiceClient) UpdateServiceBindingSettings(ctx context.Context, in *UpdateServiceBindingSettingsRequest, opts ...grpc.CallOption) (*UpdateSettingsResponse, error) {
+	out := new(UpdateSettingsResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.ServiceBindingService/UpdateServiceBindingSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceBindingServiceClient) DeleteServiceBinding(ctx context.Context, in *DeleteServiceBindingRequest, opts ...grpc.CallOption) (*DeleteServiceBindingResponse, error) {
+	out := new(DeleteServiceBindingResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.ServiceBindingService/DeleteServiceBinding", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceBindingServiceClient) WatchServiceBinding(ctx context.Context, in *WatchServiceBindingRequest, opts ...grpc.CallOption) (ServiceBindingService_WatchServiceBindingClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceBindingService_ServiceDesc.Streams[0], "/kappital.manager.v1alpha1.ServiceBindingService/WatchServiceBinding", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceBindingServiceWatchServiceBindingClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServiceBindingService_WatchServiceBindingClient interface {
+	Recv() (*ServiceBindingEvent, error)
+	grpc.ClientStream
+}
+
+type serviceBindingServiceWatchServiceBindingClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceBindingServiceWatchServiceBindingClient) Recv() (*ServiceBindingEvent, error) {
+	m := new(ServiceBindingEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ServiceBindingServiceServer is the server API for ServiceBindingService service.
+// All implementations must embed UnimplementedServiceBindingServiceServer
+// for forward compatibility
+type ServiceBindingServiceServer interface {
+	// CreateServiceBinding deploys the service package into the cluster, the existing service binding is returned if
+	// it has been deployed
+	CreateServiceBinding(context.Context, *CreateServiceBindingRequest) (*CreateServiceBindingResponse, error)
+	// ListServiceBindings gets the service bindings of the cluster
+	ListServiceBindings(context.Context, *ListServiceBindingsRequest) (*ListServiceBindingsResponse, error)
+	// GetServiceBinding gets the service binding
+	GetServiceBinding(context.Context, *GetServiceBindingRequest) (*ServiceBinding, error)
+	// UpdateServiceBindingSettings replaces or patches the settings of the service binding
+	UpdateServiceBindingSettings(context.Context, *UpdateServiceBindingSettingsRequest) (*UpdateSettingsResponse, error)
+	// DeleteServiceBinding uninstalls the service binding and its instances from the cluster
+	DeleteServiceBinding(context.Context, *DeleteServiceBindingRequest) (*DeleteServiceBindingResponse, error)
+	// WatchServiceBinding streams the changes of the service binding until it is deleted
+	WatchServiceBinding(*WatchServiceBindingRequest, ServiceBindingService_WatchServiceBindingServer) error
+	mustEmbedUnimplementedServiceBindingServiceServer()
+}
+
+// UnimplementedServiceBindingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedServiceBindingServiceServer struct {
+}
+
+func (UnimplementedServiceBindingServiceServer) CreateServiceBinding(context.Context, *CreateServiceBindingRequest) (*CreateServiceBindingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateServiceBinding not implemented")
+}
+func (UnimplementedServiceBindingServiceServer) ListServiceBindings(context.Context, *ListServiceBindingsRequest) (*ListServiceBindingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListServiceBindings not implemented")
+}
+func (UnimplementedServiceBindingServiceServer) GetServiceBinding(context.Context, *GetServiceBindingRequest) (*ServiceBinding, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServiceBinding not implemented")
+}
+func (UnimplementedServiceBindingServiceServer) UpdateServiceBindingSettings(context.Context, *UpdateServiceBindingSettingsRequest) (*UpdateSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateServiceBindingSettings not implemented")
+}
+func (UnimplementedServiceBindingServiceServer) DeleteServiceBinding(context.Context, *DeleteServiceBindingRequest) (*DeleteServiceBindingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteServiceBinding not implemented")
+}
+func (UnimplementedServiceBindingServiceServer) WatchServiceBinding(*WatchServiceBindingRequest, ServiceBindingService_WatchServiceBindingServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchServiceBinding not implemented")
+}
+func (UnimplementedServiceBindingServiceServer) mustEmbedUnimplementedServiceBindingServiceServer() {}
+
+// UnsafeServiceBindingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ServiceBindingServiceServer will
+// result in compilation errors.
+type UnsafeServiceBindingServiceServer interface {
+	mustEmbedUnimplementedServiceBindingServiceServer()
+}
+
+func RegisterServiceBindingServiceServer(s grpc.ServiceRegistrar, srv ServiceBindingServiceServer) {
+	s.RegisterService(&ServiceBindingService_ServiceDesc, srv)
+}
+
+func _ServiceBindingService_CreateServiceBinding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateServiceBindingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceBindingServiceServer).CreateServiceBinding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.ServiceBindingService/CreateServiceBinding",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceBindingServiceServer).CreateServiceBinding(ctx, req.(*CreateServiceBindingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceBindingService_ListServiceBindings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListServiceBindingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceBindingServiceServer).ListServiceBindings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.ServiceBindingService/ListServiceBindings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceBindingServiceServer).ListServiceBindings(ctx, req.(*ListServiceBindingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceBindingService_GetServiceBinding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServiceBindingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceBindingServiceServer).GetServiceBinding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.ServiceBindingService/GetServiceBinding",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceBindingServiceServer).GetServiceBinding(ctx, req.(*GetServiceBindingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceBindingService_UpdateServiceBindingSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateServiceBindingSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceBindingServiceServer).UpdateServiceBindingSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.ServiceBindingService/UpdateServiceBindingSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceBindingServiceServer).UpdateServiceBindingSettings(ctx, req.(*UpdateServiceBindingSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceBindingService_DeleteServiceBinding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteServiceBindingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceBindingServiceServer).DeleteServiceBinding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.ServiceBindingService/DeleteServiceBinding",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceBindingServiceServer).DeleteServiceBinding(ctx, req.(*DeleteServiceBindingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceBindingService_WatchServiceBinding_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchServiceBindingRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceBindingServiceServer).WatchServiceBinding(m, &serviceBindingServiceWatchServiceBindingServer{stream})
+}
+
+type ServiceBindingService_WatchServiceBindingServer interface {
+	Send(*ServiceBindingEvent) error
+	grpc.ServerStream
+}
+
+type serviceBindingServiceWatchServiceBindingServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceBindingServiceWatchServiceBindingServer) Send(m *ServiceBindingEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ServiceBindingService_ServiceDesc is the grpc.ServiceDesc for ServiceBindingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ServiceBindingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kappital.manager.v1alpha1.ServiceBindingService",
+	HandlerType: (*ServiceBindingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateServiceBinding",
+			Handler:    _ServiceBindingService_CreateServiceBinding_Handler,
+		},
+		{
+			MethodName: "ListServiceBindings",
+			Handler:    _ServiceBindingService_ListServiceBindings_Handler,
+		},
+		{
+			MethodName: "GetServiceBinding",
+			Handler:    _ServiceBindingService_GetServiceBinding_Handler,
+		},
+		{
+			MethodName: "UpdateServiceBindingSettings",
+			Handler:    _ServiceBindingService_UpdateServiceBindingSettings_Handler,
+		},
+		{
+			MethodName: "DeleteServiceBinding",
+			Handler:    _ServiceBindingService_DeleteServiceBinding_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchServiceBinding",
+			Handler:       _ServiceBindingService_WatchServiceBinding_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "manager.proto",
+}
+
+// InstanceServiceClient is the client API for InstanceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InstanceServiceClient interface {
+	// CreateInstances deploys the custom resources of the service binding into the cluster, and the service binding is
+	// deployed first if it has not been deployed
+	CreateInstances(ctx context.Context, in *CreateInstancesRequest, opts ...grpc.CallOption) (*CreateInstancesResponse, error)
+	// ListInstances gets the instances of the service binding in the namespace
+	ListInstances(ctx context.Context, in *ListInstancesRequest, opts ...grpc.CallOption) (*ListInstancesResponse, error)
+	// GetInstance gets the instance
+	GetInstance(ctx context.Context, in *GetInstanceRequest, opts ...grpc.CallOption) (*Instance, error)
+	// UpdateInstanceSettings replaces or patches the settings of the instance
+	UpdateInstanceSettings(ctx context.Context, in *UpdateInstanceSettingsRequest, opts ...grpc.CallOption) (*UpdateSettingsResponse, error)
+	// DeleteInstance uninstalls the instance from the cluster
+	DeleteInstance(ctx context.Context, in *DeleteInstanceRequest, opts ...grpc.CallOption) (*DeleteInstanceResponse, error)
+	// WatchInstance streams the changes of the instance until it is deleted
+	WatchInstance(ctx context.Context, in *WatchInstanceRequest, opts ...grpc.CallOption) (InstanceService_WatchInstanceClient, error)
+}
+
+type instanceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInstanceServiceClient(cc grpc.ClientConnInterface) InstanceServiceClient {
+	return &instanceServiceClient{cc}
+}
+
+func (c *instanceServiceClient) CreateInstances(ctx context.Context, in *CreateInstancesRequest, opts ...grpc.CallOption) (*CreateInstancesResponse, error) {
+	out := new(CreateInstancesResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.InstanceService/CreateInstances", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *instanceServiceClient) ListInstances(ctx context.Context, in *ListInstancesRequest, opts ...grpc.CallOption) (*ListInstancesResponse, error) {
+	out := new(ListInstancesResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.InstanceService/ListInstances", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *instanceServiceClient) GetInstance(ctx context.Context, in *GetInstanceRequest, opts ...grpc.CallOption) (*Instance, error) {
+	out := new(Instance)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.InstanceService/GetInstance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *instanceServiceClient) UpdateInstanceSettings(ctx context.Context, in *UpdateInstanceSettingsRequest, opts ...grpc.CallOption) (*UpdateSettingsResponse, error) {
+	out := new(UpdateSettingsResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.InstanceService/UpdateInstanceSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *instanceServiceClient) DeleteInstance(ctx context.Context, in *DeleteInstanceRequest, opts ...grpc.CallOption) (*DeleteInstanceResponse, error) {
+	out := new(DeleteInstanceResponse)
+	err := c.cc.Invoke(ctx, "/kappital.manager.v1alpha1.InstanceService/DeleteInstance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *instanceServiceClient) WatchInstance(ctx context.Context, in *WatchInstanceRequest, opts ...grpc.CallOption) (InstanceService_WatchInstanceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &InstanceService_ServiceDesc.Streams[0], "/kappital.manager.v1alpha1.InstanceService/WatchInstance", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &instanceServiceWatchInstanceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type InstanceService_WatchInstanceClient interface {
+	Recv() (*InstanceEvent, error)
+	grpc.ClientStream
+}
+
+type instanceServiceWatchInstanceClient struct {
+	grpc.ClientStream
+}
+
+func (x *instanceServiceWatchInstanceClient) Recv() (*InstanceEvent, error) {
+	m := new(InstanceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// InstanceServiceServer is the server API for InstanceService service.
+// All implementations must embed UnimplementedInstanceServiceServer
+// for forward compatibility
+type InstanceServiceServer interface {
+	// CreateInstances deploys the custom resources of the service binding into the cluster, and the service binding is
+	// deployed first if it has not been deployed
+	CreateInstances(context.Context, *CreateInstancesRequest) (*CreateInstancesResponse, error)
+	// ListInstances gets the instances of the service binding in the namespace
+	ListInstances(context.Context, *ListInstancesRequest) (*ListInstancesResponse, error)
+	// GetInstance gets the instance
+	GetInstance(context.Context, *GetInstanceRequest) (*Instance, error)
+	// UpdateInstanceSettings replaces or patches the settings of the instance
+	UpdateInstanceSettings(context.Context, *UpdateInstanceSettingsRequest) (*UpdateSettingsResponse, error)
+	// DeleteInstance uninstalls the instance from the cluster
+	DeleteInstance(context.Context, *DeleteInstanceRequest) (*DeleteInstanceResponse, error)
+	// WatchInstance streams the changes of the instance until it is deleted
+	WatchInstance(*WatchInstanceRequest, InstanceService_WatchInstanceServer) error
+	mustEmbedUnimplementedInstanceServiceServer()
+}
+
+// UnimplementedInstanceServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedInstanceServiceServer struct {
+}
+
+func (UnimplementedInstanceServiceServer) CreateInstances(context.Context, *CreateInstancesRequest) (*CreateInstancesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateInstances not implemented")
+}
+func (UnimplementedInstanceServiceServer) ListInstances(context.Context, *ListInstancesRequest) (*ListInstancesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInstances not implemented")
+}
+func (UnimplementedInstanceServiceServer) GetInstance(context.Context, *GetInstanceRequest) (*Instance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInstance not implemented")
+}
+func (UnimplementedInstanceServiceServer) UpdateInstanceSettings(context.Context, *UpdateInstanceSettingsRequest) (*UpdateSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateInstanceSettings not implemented")
+}
+func (UnimplementedInstanceServiceServer) DeleteInstance(context.Context, *DeleteInstanceRequest) (*DeleteInstanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteInstance not implemented")
+}
+func (UnimplementedInstanceServiceServer) WatchInstance(*WatchInstanceRequest, InstanceService_WatchInstanceServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchInstance not implemented")
+}
+func (UnimplementedInstanceServiceServer) mustEmbedUnimplementedInstanceServiceServer() {}
+
+// UnsafeInstanceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InstanceServiceServer will
+// result in compilation errors.
+type UnsafeInstanceServiceServer interface {
+	mustEmbedUnimplementedInstanceServiceServer()
+}
+
+func RegisterInstanceServiceServer(s grpc.ServiceRegistrar, srv InstanceServiceServer) {
+	s.RegisterService(&InstanceService_ServiceDesc, srv)
+}
+
+func _InstanceService_CreateInstances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInstancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceServiceServer).CreateInstances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.InstanceService/CreateInstances",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceServiceServer).CreateInstances(ctx, req.(*CreateInstancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InstanceService_ListInstances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInstancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceServiceServer).ListInstances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.InstanceService/ListInstances",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceServiceServer).ListInstances(ctx, req.(*ListInstancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InstanceService_GetInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInstanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceServiceServer).GetInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.InstanceService/GetInstance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceServiceServer).GetInstance(ctx, req.(*GetInstanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InstanceService_UpdateInstanceSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateInstanceSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceServiceServer).UpdateInstanceSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.InstanceService/UpdateInstanceSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceServiceServer).UpdateInstanceSettings(ctx, req.(*UpdateInstanceSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InstanceService_DeleteInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteInstanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InstanceServiceServer).DeleteInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kappital.manager.v1alpha1.InstanceService/DeleteInstance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InstanceServiceServer).DeleteInstance(ctx, req.(*DeleteInstanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InstanceService_WatchInstance_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchInstanceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InstanceServiceServer).WatchInstance(m, &instanceServiceWatchInstanceServer{stream})
+}
+
+type InstanceService_WatchInstanceServer interface {
+	Send(*InstanceEvent) error
+	grpc.ServerStream
+}
+
+type instanceServiceWatchInstanceServer struct {
+	grpc.ServerStream
+}
+
+func (x *instanceServiceWatchInstanceServer) Send(m *InstanceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// InstanceService_ServiceDesc is the grpc.ServiceDesc for InstanceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InstanceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kappital.manager.v1alpha1.InstanceService",
+	HandlerType: (*InstanceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateInstances",
+			Handler:    _InstanceService_CreateInstances_Handler,
+		},
+		{
+			MethodName: "ListInstances",
+			Handler:    _InstanceService_ListInstances_Handler,
+		},
+		{
+			MethodName: "GetInstance",
+			Handler:    _InstanceService_GetInstance_Handler,
+		},
+		{
+			MethodName: "UpdateInstanceSettings",
+			Handler:    _InstanceService_UpdateInstanceSettings_Handler,
+		},
+		{
+			MethodName: "DeleteInstance",
+			Handler:    _InstanceService_DeleteInstance_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchInstance",
+			Handler:       _InstanceService_WatchInstance_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "manager.proto",
+}
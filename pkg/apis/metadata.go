@@ -61,6 +61,9 @@ type Descriptor struct {
 	Devices []string `json:"devices,omitempty"`
 	// Scenes indicates in which scenes its instances can survive.
 	Scenes []string `json:"scenes,omitempty"`
+
+	// Timeouts of installing, upgrading, and deleting the service, the manager default ones will be used if empty.
+	Timeouts `json:",inline"`
 }
 
 // AppLink contains name and URL to connect to certain app.
@@ -108,6 +111,11 @@ func (d *Descriptor) Validation() bool {
 		klog.Errorf("the service type is invalid")
 		return false
 	}
+	// 4. check the lifecycle timeouts are valid durations
+	if err := d.Timeouts.Validate(); err != nil {
+		klog.Errorf("the service timeouts are invalid, err: %v", err)
+		return false
+	}
 	d.GenerateSlice()
 	return true
 }
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"github.com/kappital/kappital/pkg/utils/version"
+)
+
+// ServerInfo the version and the capabilities of the manager, the clients use it to detect what the manager supports
+type ServerInfo struct {
+	Version        version.Info  `json:"version"`
+	Features       Features      `json:"features"`
+	Clusters       []ClusterInfo `json:"clusters"`
+	PackageFormats []string      `json:"packageFormats"` // the service types of the packages which can be deployed
+}
+
+// Features the optional features of the manager which are decided by its options
+type Features struct {
+	AuthenticationMethods []string `json:"authenticationMethods"` // certificate, token and oidc
+	DatabaseDriver        string   `json:"databaseDriver"`
+	MultiCluster          bool     `json:"multiCluster"` // the clusters except the one which it is running in
+	LeaderElection        bool     `json:"leaderElection"`
+	GRPC                  bool     `json:"grpc"`
+}
+
+// ClusterInfo the registered cluster and the version of its API server, the version is empty if it is unreachable
+type ClusterInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
@@ -31,6 +31,8 @@ type ServiceInstanceCreation struct {
 	ClusterID               string                         `json:"clusterID,omitempty"`               // default if clusterID is null
 	Service                 svcv1alpha1.CloudNativeService `json:"service,omitempty"`                 // service CloudNativeService
 	InstanceCustomResources []InstanceCustomResource       `json:"instanceCustomResources,omitempty"` // cr list
+	Timeouts                apis.Timeouts                  `json:"timeouts,omitempty"`                // override the service timeouts
+	DeletionProtection      bool                           `json:"deletionProtection,omitempty"`      // reject the deletion of the created records
 }
 
 // InstanceCustomResource user's custom resource of the instance
@@ -51,5 +53,5 @@ func (s *ServiceInstanceCreation) Validate() error {
 			s.InstanceCustomResources[i].Namespace = apis.DefaultNamespace
 		}
 	}
-	return nil
+	return s.Timeouts.Validate()
 }
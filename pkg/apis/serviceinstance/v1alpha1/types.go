@@ -57,16 +57,18 @@ type CloudNativeServiceInstanceSpec struct {
 	CapabilityResources []Resource       `json:"capabilityResources,omitempty"`
 	ServiceReference    ServiceReference `json:"serviceReference"`
 	DependentResources  []Resource       `json:"dependentResources,omitempty"`
+	DeletionProtection  bool             `json:"deletionProtection,omitempty"`
 }
 
 // Resource the user's instance
 type Resource struct {
-	metav1.TypeMeta `json:",inline"`
-	Name            string `json:"name"`
-	Namespace       string `json:"namespace,omitempty"`
-	UID             string `json:"uid,omitempty"`
-	Status          string `json:"status,omitempty"`
-	RawMessage      string `json:"rawMessage,omitempty"`
+	metav1.TypeMeta    `json:",inline"`
+	Name               string `json:"name"`
+	Namespace          string `json:"namespace,omitempty"`
+	UID                string `json:"uid,omitempty"`
+	Status             string `json:"status,omitempty"`
+	RawMessage         string `json:"rawMessage,omitempty"`
+	DeletionProtection bool   `json:"deletionProtection,omitempty"`
 }
 
 // ServiceReference the service package information
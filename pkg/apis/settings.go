@@ -0,0 +1,31 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apis
+
+// Settings of the service binding or the service instance which can be replaced by the PUT requests and edited by the
+// PATCH requests
+type Settings struct {
+	// Timeouts of the lifecycle, the empty values mean using the default ones of the manager
+	Timeouts Timeouts `json:"timeouts"`
+	// DeletionProtection rejects the deletion of the service binding or the instance while it is true
+	DeletionProtection bool `json:"deletionProtection"`
+}
+
+// Validate the settings
+func (s Settings) Validate() error {
+	return s.Timeouts.Validate()
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apis
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	processTimeoutConfig = DefaultProcessTimeoutConfig()
+	processTimeoutMutex  sync.RWMutex
+)
+
+// Timeouts of the cloud native service lifecycle, the values are the duration strings, such as "10m" or "1h30m".
+// The empty value means using the default one of the manager.
+type Timeouts struct {
+	// InstallTimeout the longest duration of installing the service or the instance
+	InstallTimeout string `json:"installTimeout,omitempty"`
+	// UpgradeTimeout the longest duration of upgrading the service or the instance
+	UpgradeTimeout string `json:"upgradeTimeout,omitempty"`
+	// DeleteTimeout the longest duration of deleting the service or the instance
+	DeleteTimeout string `json:"deleteTimeout,omitempty"`
+}
+
+// Validate does all timeouts are empty or the positive durations
+func (t Timeouts) Validate() error {
+	for name, value := range map[string]string{
+		"installTimeout": t.InstallTimeout,
+		"upgradeTimeout": t.UpgradeTimeout,
+		"deleteTimeout":  t.DeleteTimeout,
+	} {
+		if len(value) == 0 {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("the %s %s is not a valid duration, err: %v", name, value, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("the %s %s must be positive", name, value)
+		}
+	}
+	return nil
+}
+
+// Override the timeouts with the not empty values of the higher priority timeouts
+func (t Timeouts) Override(higher Timeouts) Timeouts {
+	if len(higher.InstallTimeout) > 0 {
+		t.InstallTimeout = higher.InstallTimeout
+	}
+	if len(higher.UpgradeTimeout) > 0 {
+		t.UpgradeTimeout = higher.UpgradeTimeout
+	}
+	if len(higher.DeleteTimeout) > 0 {
+		t.DeleteTimeout = higher.DeleteTimeout
+	}
+	return t
+}
+
+// InstallDuration get the install timeout, the default one will be used if it is empty or invalid
+func (t Timeouts) InstallDuration() time.Duration {
+	return parseDurationOrDefault(t.InstallTimeout, GetProcessTimeoutConfig().InstallTimeout)
+}
+
+// UpgradeDuration get the upgrade timeout, the default one will be used if it is empty or invalid
+func (t Timeouts) UpgradeDuration() time.Duration {
+	return parseDurationOrDefault(t.UpgradeTimeout, GetProcessTimeoutConfig().UpgradeTimeout)
+}
+
+// DeleteDuration get the delete timeout, the default one will be used if it is empty or invalid
+func (t Timeouts) DeleteDuration() time.Duration {
+	return parseDurationOrDefault(t.DeleteTimeout, GetProcessTimeoutConfig().DeleteTimeout)
+}
+
+func parseDurationOrDefault(value string, defaultValue time.Duration) time.Duration {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return defaultValue
+	}
+	return d
+}
+
+// ProcessTimeoutConfig the default timeouts of the manager processors
+type ProcessTimeoutConfig struct {
+	// InstallTimeout the default longest duration of installing the service or the instance
+	InstallTimeout time.Duration
+	// UpgradeTimeout the default longest duration of upgrading the service or the instance
+	UpgradeTimeout time.Duration
+	// DeleteTimeout the default longest duration of deleting the service or the instance
+	DeleteTimeout time.Duration
+	// BindingReadyTimeout the longest duration of waiting for the service binding ready before installing instances
+	BindingReadyTimeout time.Duration
+	// LongestDurationForProcess the longest duration of processing a record since its last update, it will be
+	// extended to the operation timeout if the operation timeout is longer
+	LongestDurationForProcess time.Duration
+}
+
+// DefaultProcessTimeoutConfig get the default timeouts of the manager processors
+func DefaultProcessTimeoutConfig() *ProcessTimeoutConfig {
+	return &ProcessTimeoutConfig{
+		InstallTimeout:            3 * time.Minute,
+		UpgradeTimeout:            3 * time.Minute,
+		DeleteTimeout:             3 * time.Minute,
+		BindingReadyTimeout:       2 * time.Minute,
+		LongestDurationForProcess: 20 * time.Minute,
+	}
+}
+
+// SetProcessTimeoutConfig set the default timeouts of the manager processors
+func SetProcessTimeoutConfig(cfg *ProcessTimeoutConfig) {
+	if cfg == nil {
+		return
+	}
+	processTimeoutMutex.Lock()
+	defer processTimeoutMutex.Unlock()
+	processTimeoutConfig = cfg
+}
+
+// GetProcessTimeoutConfig get the default timeouts of the manager processors
+func GetProcessTimeoutConfig() ProcessTimeoutConfig {
+	processTimeoutMutex.RLock()
+	defer processTimeoutMutex.RUnlock()
+	return *processTimeoutConfig
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeouts_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts Timeouts
+		wantErr  bool
+	}{
+		{
+			name: "Test Timeouts Validate (empty)",
+		},
+		{
+			name:     "Test Timeouts Validate (invalid duration)",
+			timeouts: Timeouts{InstallTimeout: "ten minutes"},
+			wantErr:  true,
+		},
+		{
+			name:     "Test Timeouts Validate (negative duration)",
+			timeouts: Timeouts{DeleteTimeout: "-1m"},
+			wantErr:  true,
+		},
+		{
+			name:     "Test Timeouts Validate",
+			timeouts: Timeouts{InstallTimeout: "10m", UpgradeTimeout: "1h", DeleteTimeout: "30s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.timeouts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTimeouts_Override(t *testing.T) {
+	service := Timeouts{InstallTimeout: "10m", DeleteTimeout: "5m"}
+	got := service.Override(Timeouts{InstallTimeout: "20m", UpgradeTimeout: "15m"})
+	want := Timeouts{InstallTimeout: "20m", UpgradeTimeout: "15m", DeleteTimeout: "5m"}
+	if got != want {
+		t.Errorf("Override() = %v, want %v", got, want)
+	}
+}
+
+func TestTimeouts_Duration(t *testing.T) {
+	defer SetProcessTimeoutConfig(DefaultProcessTimeoutConfig())
+	SetProcessTimeoutConfig(&ProcessTimeoutConfig{
+		InstallTimeout: time.Minute,
+		UpgradeTimeout: 2 * time.Minute,
+		DeleteTimeout:  3 * time.Minute,
+	})
+	timeouts := Timeouts{InstallTimeout: "10m", UpgradeTimeout: "invalid"}
+	if got := timeouts.InstallDuration(); got != 10*time.Minute {
+		t.Errorf("InstallDuration() = %v, want %v", got, 10*time.Minute)
+	}
+	if got := timeouts.UpgradeDuration(); got != 2*time.Minute {
+		t.Errorf("UpgradeDuration() = %v, want %v", got, 2*time.Minute)
+	}
+	if got := timeouts.DeleteDuration(); got != 3*time.Minute {
+		t.Errorf("DeleteDuration() = %v, want %v", got, 3*time.Minute)
+	}
+}
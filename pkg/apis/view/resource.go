@@ -31,6 +31,7 @@ type Service struct {
 	Namespace string
 	Phase     string
 	Message   string
+	Protected string
 	Created   string
 }
 
@@ -41,6 +42,7 @@ type Instance struct {
 	ServiceName  string
 	ClusterName  string
 	Status       string
+	Protected    string
 	Created      string
 }
 
@@ -0,0 +1,366 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+)
+
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+	defaultPollInterval   = 2 * time.Second
+
+	apiPrefix = "/api/v1alpha1"
+)
+
+// Config of the client which connects the kappital manager
+type Config struct {
+	// Server the address of the manager, such as https://127.0.0.1:8443
+	Server string
+	// CAData the PEM encoded CA certificates which verify the manager, the system ones are used if it is empty
+	CAData []byte
+	// CertData the PEM encoded client certificate, it is optional if the BearerToken is set
+	CertData []byte
+	// KeyData the PEM encoded private key of the client certificate
+	KeyData []byte
+	// InsecureSkipVerify does not verify the certificate of the manager, only for testing
+	InsecureSkipVerify bool
+	// BearerToken the API token or the ID token which is sent in the Authorization header
+	BearerToken string
+	// Timeout of each http request, default is 30s
+	Timeout time.Duration
+	// Retry the policy of retrying the failed requests
+	Retry RetryPolicy
+	// PollInterval the interval of the wait and watch helpers, default is 2s
+	PollInterval time.Duration
+}
+
+// RetryPolicy retries the requests which are failed by the network errors or the temporary http codes, the wait
+// between two attempts is doubled after each retry, and the Retry-After of the manager is respected. The requests
+// which are not idempotent are only retried when the manager has rejected them before processing.
+type RetryPolicy struct {
+	// MaxAttempts the max number of attempts of each request including the first one, default is 3, 1 disables
+	// the retry
+	MaxAttempts int
+	// InitialBackoff the wait before the first retry, default is 200ms
+	InitialBackoff time.Duration
+	// MaxBackoff the max wait between two attempts, default is 5s
+	MaxBackoff time.Duration
+}
+
+// Client the typed client of the manager REST API, it is safe for the concurrent use
+type Client struct {
+	server       string
+	token        string
+	httpClient   *http.Client
+	retry        RetryPolicy
+	pollInterval time.Duration
+}
+
+// New create the client of the manager by the config
+func New(cfg Config) (*Client, error) {
+	server := strings.TrimSuffix(cfg.Server, "/")
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return nil, fmt.Errorf("the server %q must be the http or https url of the manager", cfg.Server)
+	}
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("cannot get the default http transport")
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	c := &Client{
+		server:       server,
+		token:        cfg.BearerToken,
+		httpClient:   &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		retry:        cfg.Retry,
+		pollInterval: cfg.PollInterval,
+	}
+	if c.httpClient.Timeout <= 0 {
+		c.httpClient.Timeout = defaultTimeout
+	}
+	if c.retry.MaxAttempts <= 0 {
+		c.retry.MaxAttempts = defaultMaxAttempts
+	}
+	if c.retry.InitialBackoff <= 0 {
+		c.retry.InitialBackoff = defaultInitialBackoff
+	}
+	if c.retry.MaxBackoff <= 0 {
+		c.retry.MaxBackoff = defaultMaxBackoff
+	}
+	if c.pollInterval <= 0 {
+		c.pollInterval = defaultPollInterval
+	}
+	return c, nil
+}
+
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec
+	}
+	if len(cfg.CAData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(cfg.CAData) {
+			return nil, fmt.Errorf("cannot parse the CA certificates of the manager")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(cfg.CertData) > 0 || len(cfg.KeyData) > 0 {
+		cert, err := tls.X509KeyPair(cfg.CertData, cfg.KeyData)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the client certificate, err: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// ServerInfo get the version, the features, the registered clusters and the supported package formats of the manager
+func (c *Client) ServerInfo(ctx context.Context) (*infov1alpha1.ServerInfo, error) {
+	info := &infov1alpha1.ServerInfo{}
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: apiPrefix + "/info"}, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Cluster get the client of the resources in the cluster, the default cluster is used if the name is empty
+func (c *Client) Cluster(name string) *ClusterClient {
+	return &ClusterClient{client: c, name: name}
+}
+
+// ServiceBindings get the client of the service bindings in the default cluster
+func (c *Client) ServiceBindings() *ServiceBindingClient {
+	return c.Cluster("").ServiceBindings()
+}
+
+// Instances get the client of the instances of the service binding in the namespace of the default cluster
+func (c *Client) Instances(serviceBinding, namespace string) *InstanceClient {
+	return c.Cluster("").Instances(serviceBinding, namespace)
+}
+
+// ClusterClient the client of the service bindings and the instances in a cluster
+type ClusterClient struct {
+	client *Client
+	name   string
+}
+
+// Name get the name of the cluster, it is empty for the default cluster
+func (c *ClusterClient) Name() string {
+	return c.name
+}
+
+// ServiceBindings get the client of the service bindings in the cluster
+func (c *ClusterClient) ServiceBindings() *ServiceBindingClient {
+	return &ServiceBindingClient{cluster: c}
+}
+
+// Instances get the client of the instances of the service binding in the namespace of the cluster, the default
+// namespace is used if it is empty
+func (c *ClusterClient) Instances(serviceBinding, namespace string) *InstanceClient {
+	return &InstanceClient{cluster: c, serviceBinding: serviceBinding, namespace: namespace}
+}
+
+// query get the query parameters of the cluster
+func (c *ClusterClient) query() url.Values {
+	query := url.Values{}
+	if len(c.name) > 0 {
+		query.Set("cluster_name", c.name)
+	}
+	return query
+}
+
+// request of the manager REST API
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        interface{}
+	contentType string
+	ifMatch     *int64
+}
+
+// do send the request with the retries, and decode the success response body into the out if it is not nil. All
+// attempts share the same X-Request-ID, thus they can be correlated in the manager logs.
+func (c *Client) do(ctx context.Context, req request, out interface{}) (http.Header, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if raw, ok := req.body.([]byte); ok {
+			body = raw
+		} else if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("cannot marshal the request body, err: %v", err)
+		}
+	}
+	id := requestid.New()
+	backoff := c.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		header, err := c.doOnce(ctx, req, body, id, out)
+		if err == nil {
+			return header, nil
+		}
+		// the canceled or expired request is not retried
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		wait, retryable := c.retryAfter(req.method, err)
+		if !retryable || attempt >= c.retry.MaxAttempts {
+			return nil, err
+		}
+		if wait < backoff {
+			// add the jitter, thus the clients rejected at the same time do not retry at the same time
+			wait = backoff + time.Duration(rand.Int63n(int64(backoff)/5+1)) //nolint:gosec
+		}
+		if err = sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if backoff *= 2; backoff > c.retry.MaxBackoff {
+			backoff = c.retry.MaxBackoff
+		}
+	}
+}
+
+func (c *Client) doOnce(ctx context.Context, req request, body []byte, id string, out interface{}) (http.Header,
+	error) {
+	u := c.server + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the request, err: %v", err)
+	}
+	if body != nil {
+		contentType := req.contentType
+		if len(contentType) == 0 {
+			contentType = "application/json"
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set(requestid.Header, id)
+	if len(c.token) > 0 {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if req.ifMatch != nil {
+		httpReq.Header.Set("If-Match", strconv.Quote(strconv.FormatInt(*req.ifMatch, 10)))
+	}
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &networkError{err: fmt.Errorf("perform request failed, requestID: %s, err: %w", id, err)}
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	buf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &networkError{err: fmt.Errorf("read response body failed, requestID: %s, err: %w", id, err)}
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, newError(resp, buf, id)
+	}
+	if out != nil && len(buf) > 0 {
+		if err = json.Unmarshal(buf, out); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal the response, requestID: %s, err: %v", id, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// retryAfter get the wait before retrying the failed request, and whether it can be retried. The idempotent
+// requests are retried for the network errors and the temporary http codes, the others are only retried when the
+// manager has rejected them before processing.
+func (c *Client) retryAfter(method string, err error) (time.Duration, bool) {
+	idempotent := method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+	if _, ok := err.(*networkError); ok {
+		return 0, idempotent
+	}
+	e, ok := err.(*Error)
+	if !ok {
+		return 0, false
+	}
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return e.retryAfter, true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return e.retryAfter, idempotent
+	default:
+		return 0, false
+	}
+}
+
+// networkError the request cannot be sent or the response cannot be read
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *networkError) Unwrap() error {
+	return e.err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// resourceVersion get the version of the record from the ETag header
+func resourceVersion(header http.Header) int64 {
+	etag := strings.Trim(strings.TrimPrefix(header.Get("ETag"), "W/"), "\"")
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// escape the path segment
+func escape(segment string) string {
+	return url.PathEscape(segment)
+}
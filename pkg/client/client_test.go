@@ -0,0 +1,377 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kappital/kappital/pkg/apis"
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+	"github.com/kappital/kappital/pkg/utils/version"
+)
+
+// fakeManager count the requests and reply them by the handler with the attempt number starting from 1
+type fakeManager struct {
+	mu       sync.Mutex
+	attempts int
+	ids      []string
+	handler  func(w http.ResponseWriter, r *http.Request, attempt int)
+}
+
+func (f *fakeManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.attempts++
+	f.ids = append(f.ids, r.Header.Get(requestid.Header))
+	attempt := f.attempts
+	f.mu.Unlock()
+	f.handler(w, r, attempt)
+}
+
+func newTestClient(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, attempt int)) (*Client,
+	*fakeManager) {
+	manager := &fakeManager{handler: handler}
+	srv := httptest.NewServer(manager)
+	t.Cleanup(srv.Close)
+	c, err := New(Config{
+		Server:       srv.URL,
+		Retry:        RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c, manager
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "TestNew (empty server)", cfg: Config{}, wantErr: true},
+		{name: "TestNew (not http server)", cfg: Config{Server: "ftp://127.0.0.1"}, wantErr: true},
+		{name: "TestNew (no host)", cfg: Config{Server: "https://"}, wantErr: true},
+		{name: "TestNew (invalid CA)", cfg: Config{Server: "https://x", CAData: []byte("x")}, wantErr: true},
+		{name: "TestNew (invalid client certificate)", cfg: Config{Server: "https://x", CertData: []byte("x")},
+			wantErr: true},
+		{name: "TestNew (valid)", cfg: Config{Server: "https://x/", BearerToken: "token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.server != "https://x" {
+				t.Errorf("New() server = %v, want https://x", got.server)
+			}
+		})
+	}
+}
+
+func TestClient_ServerInfo(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request, _ int) {
+		if r.URL.Path != "/api/v1alpha1/info" {
+			writeJSON(w, http.StatusNotFound, errors.ErrNotFound.GetResp())
+			return
+		}
+		writeJSON(w, http.StatusOK, infov1alpha1.ServerInfo{Version: version.Info{GitVersion: "v0.2.0"},
+			Clusters: []infov1alpha1.ClusterInfo{{Name: "default", Version: "v1.23.0"}}})
+	})
+	info, err := c.ServerInfo(context.Background())
+	if err != nil {
+		t.Fatalf("ServerInfo() error = %v", err)
+	}
+	if info.Version.GitVersion != "v0.2.0" || len(info.Clusters) != 1 || info.Clusters[0].Version != "v1.23.0" {
+		t.Errorf("ServerInfo() = %+v", info)
+	}
+}
+
+func TestClient_retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         int
+		call         func(c *Client) error
+		wantAttempts int
+	}{
+		{
+			name: "TestClient retry (GET is retried for 503)",
+			code: http.StatusServiceUnavailable,
+			call: func(c *Client) error {
+				_, err := c.ServiceBindings().List(context.Background())
+				return err
+			},
+			wantAttempts: defaultMaxAttempts,
+		},
+		{
+			name: "TestClient retry (POST is retried for 429)",
+			code: http.StatusTooManyRequests,
+			call: func(c *Client) error {
+				_, err := c.ServiceBindings().Create(context.Background(), instancev1alpha1.ServiceInstanceCreation{})
+				return err
+			},
+			wantAttempts: defaultMaxAttempts,
+		},
+		{
+			name: "TestClient retry (POST is not retried for 502)",
+			code: http.StatusBadGateway,
+			call: func(c *Client) error {
+				_, err := c.ServiceBindings().Create(context.Background(), instancev1alpha1.ServiceInstanceCreation{})
+				return err
+			},
+			wantAttempts: 1,
+		},
+		{
+			name: "TestClient retry (DELETE is not retried for 500)",
+			code: http.StatusInternalServerError,
+			call: func(c *Client) error {
+				return c.Instances("svc", "").Delete(context.Background(), "ins", DeleteOptions{})
+			},
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, manager := newTestClient(t, func(w http.ResponseWriter, _ *http.Request, _ int) {
+				w.WriteHeader(tt.code)
+			})
+			err := tt.call(c)
+			var e *Error
+			if !stderrors.As(err, &e) || e.StatusCode != tt.code {
+				t.Errorf("call() error = %v, want the status code %d", err, tt.code)
+			}
+			if manager.attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", manager.attempts, tt.wantAttempts)
+			}
+			for _, id := range manager.ids {
+				if id != manager.ids[0] || len(id) == 0 {
+					t.Errorf("the request ids %v are not the same one", manager.ids)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_retrySucceeded(t *testing.T) {
+	c, manager := newTestClient(t, func(w http.ResponseWriter, _ *http.Request, attempt int) {
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, http.StatusOK, []instancev1alpha1.CloudNativeServiceInstance{{}})
+	})
+	got, err := c.ServiceBindings().List(context.Background())
+	if err != nil || len(got) != 1 {
+		t.Errorf("List() = %v, %v, want one service binding", got, err)
+	}
+	if manager.attempts != 2 {
+		t.Errorf("attempts = %d, want 2", manager.attempts)
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         int
+		body         interface{}
+		wantNotFound bool
+		wantConflict bool
+		wantErrCode  string
+	}{
+		{
+			name:         "TestError (not found)",
+			code:         http.StatusNotFound,
+			body:         errors.ErrNotFound.WrapErrorReasonWith("no service binding").GetResp(),
+			wantNotFound: true,
+			wantErrCode:  errors.ErrNotFound.GetErrorCode(),
+		},
+		{
+			name:         "TestError (conflict)",
+			code:         http.StatusPreconditionFailed,
+			body:         errors.ErrResourceConflict.GetResp(),
+			wantConflict: true,
+			wantErrCode:  errors.ErrResourceConflict.GetErrorCode(),
+		},
+		{
+			name: "TestError (not ErrorResp)",
+			code: http.StatusForbidden,
+			body: "forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, func(w http.ResponseWriter, _ *http.Request, _ int) {
+				writeJSON(w, tt.code, tt.body)
+			})
+			_, err := c.ServiceBindings().Get(context.Background(), "svc", GetOptions{})
+			if got := IsNotFound(err); got != tt.wantNotFound {
+				t.Errorf("IsNotFound(%v) = %v, want %v", err, got, tt.wantNotFound)
+			}
+			if got := IsConflict(err); got != tt.wantConflict {
+				t.Errorf("IsConflict(%v) = %v, want %v", err, got, tt.wantConflict)
+			}
+			var e *Error
+			if !stderrors.As(err, &e) || e.ErrorCode != tt.wantErrCode || len(e.RequestID) == 0 {
+				t.Errorf("Get() error = %#v, want the error code %q", err, tt.wantErrCode)
+			}
+			if stderrors.Is(err, errors.ErrInternal) {
+				t.Errorf("Get() error = %v, do not want to match ErrInternal", err)
+			}
+		})
+	}
+}
+
+func TestClient_TLS(t *testing.T) {
+	var gotToken, gotIfMatch, gotQuery string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken, gotIfMatch, gotQuery = r.Header.Get("Authorization"), r.Header.Get("If-Match"), r.URL.RawQuery
+		w.Header().Set("ETag", `"8"`)
+		writeJSON(w, http.StatusOK, apis.Settings{})
+	}))
+	defer srv.Close()
+
+	if _, err := New(Config{Server: srv.URL, CAData: []byte("invalid")}); err == nil {
+		t.Errorf("New() with invalid CA error = nil, want error")
+	}
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	c, err := New(Config{Server: srv.URL, CAData: caData, BearerToken: "token"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	version := int64(7)
+	_, got, err := c.Cluster("c1").ServiceBindings().PatchSettings(context.Background(), "svc", MergePatchType,
+		[]byte(`{}`), UpdateOptions{ResourceVersion: &version})
+	if err != nil || got != 8 {
+		t.Errorf("PatchSettings() = %v, %v, want the version 8", got, err)
+	}
+	if gotToken != "Bearer token" || gotIfMatch != `"7"` || gotQuery != "cluster_name=c1" {
+		t.Errorf("the manager got the token %q, If-Match %q, query %q", gotToken, gotIfMatch, gotQuery)
+	}
+}
+
+func TestServiceBindingClient_WaitForPhase(t *testing.T) {
+	c, manager := newTestClient(t, func(w http.ResponseWriter, _ *http.Request, attempt int) {
+		binding := instancev1alpha1.CloudNativeServiceInstance{}
+		binding.Status.Phase = instancev1alpha1.PendingPhase
+		if attempt >= 3 {
+			binding.Status.Phase = instancev1alpha1.SucceededPhase
+		}
+		writeJSON(w, http.StatusOK, binding)
+	})
+	got, err := c.ServiceBindings().WaitForPhase(context.Background(), "svc", instancev1alpha1.SucceededPhase,
+		instancev1alpha1.FailedPhase)
+	if err != nil || got.Status.Phase != instancev1alpha1.SucceededPhase || manager.attempts != 3 {
+		t.Errorf("WaitForPhase() = %v, %v after %d polls", got, err, manager.attempts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = c.ServiceBindings().WaitForPhase(ctx, "svc", instancev1alpha1.FailedPhase); err == nil {
+		t.Errorf("WaitForPhase() error = nil, want the timeout")
+	}
+}
+
+func TestInstanceClient_WaitForDeletion(t *testing.T) {
+	c, manager := newTestClient(t, func(w http.ResponseWriter, _ *http.Request, attempt int) {
+		if attempt < 3 {
+			writeJSON(w, http.StatusOK, models.InstanceModel{Status: models.StatusDeleting})
+			return
+		}
+		writeJSON(w, http.StatusNotFound, errors.ErrNotFound.GetResp())
+	})
+	if err := c.Instances("svc", "ns").WaitForDeletion(context.Background(), "ins"); err != nil ||
+		manager.attempts != 3 {
+		t.Errorf("WaitForDeletion() error = %v after %d polls", err, manager.attempts)
+	}
+}
+
+func TestServiceBindingClient_Watch(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, _ *http.Request, attempt int) {
+		binding := instancev1alpha1.CloudNativeServiceInstance{ObjectMeta: metav1.ObjectMeta{Name: "svc"}}
+		switch {
+		case attempt <= 2:
+			binding.Status.Phase = instancev1alpha1.PendingPhase
+		case attempt <= 4:
+			binding.Status.Phase = instancev1alpha1.SucceededPhase
+		default:
+			writeJSON(w, http.StatusNotFound, errors.ErrNotFound.GetResp())
+			return
+		}
+		writeJSON(w, http.StatusOK, binding)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []EventType
+	for e := range c.ServiceBindings().Watch(ctx, "svc") {
+		if e.Object == nil || e.Object.Name != "svc" {
+			t.Errorf("Watch() event %v has no service binding", e.Type)
+		}
+		got = append(got, e.Type)
+	}
+	want := []EventType{EventAdded, EventModified, EventDeleted}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("Watch() events = %v, want %v", got, want)
+	}
+}
+
+func TestServiceBindingClient_Delete(t *testing.T) {
+	var query string
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request, _ int) {
+		query = r.URL.RawQuery
+		if r.URL.Query().Get("cascade") != "true" {
+			writeJSON(w, http.StatusConflict, errors.ErrServiceHasInstances.GetResp())
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	err := c.Cluster("prod").ServiceBindings().Delete(context.Background(), "svc", DeleteOptions{})
+	if !stderrors.Is(err, errors.ErrServiceHasInstances) {
+		t.Errorf("Delete() error = %v, want ErrServiceHasInstances", err)
+	}
+	err = c.Cluster("prod").ServiceBindings().Delete(context.Background(), "svc",
+		DeleteOptions{Timeout: "5m", Cascade: true})
+	if err != nil || query != "cascade=true&cluster_name=prod&timeout=5m" {
+		t.Errorf("Delete() error = %v and query %s", err, query)
+	}
+	err = c.Cluster("prod").ServiceBindings().Delete(context.Background(), "svc",
+		DeleteOptions{Cascade: true, Force: true, RemoveFinalizers: true})
+	if err != nil || query != "cascade=true&cluster_name=prod&force=true&remove_finalizers=true" {
+		t.Errorf("Delete() error = %v and query %s", err, query)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+// Error the failed response of the manager. The ErrorCode is the code of the KappError, thus the error matches the
+// KappError of the same code by errors.Is, such as errors.Is(err, errors.ErrNotFound).
+type Error struct {
+	// StatusCode the http code of the response
+	StatusCode int
+	// ErrorCode the code of the KappError, such as KAPPITAL.01000006, it is empty if the response is not an ErrorResp
+	ErrorCode string
+	// Message the message of the KappError, or the response body if it is not an ErrorResp
+	Message string
+	// Reason the detail of the failure
+	Reason string
+	// RequestID the X-Request-ID of the request, which correlates the logs and the audit entries of the manager
+	RequestID string
+
+	retryAfter time.Duration
+}
+
+func newError(resp *http.Response, body []byte, id string) *Error {
+	e := &Error{StatusCode: resp.StatusCode, RequestID: id}
+	var errResp errors.ErrorResp
+	if err := json.Unmarshal(body, &errResp); err == nil && len(errResp.ErrorCode) > 0 {
+		e.ErrorCode, e.Message, e.Reason = errResp.ErrorCode, errResp.Message, errResp.Reason
+		if len(errResp.RequestID) > 0 {
+			e.RequestID = errResp.RequestID
+		}
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		e.retryAfter = time.Duration(seconds) * time.Second
+	}
+	return e
+}
+
+// Error output the http code, the error code, the message, the reason and the request id
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "the manager replied %d", e.StatusCode)
+	if len(e.ErrorCode) > 0 {
+		fmt.Fprintf(&b, " %s", e.ErrorCode)
+	}
+	if len(e.Message) > 0 {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if len(e.Reason) > 0 {
+		fmt.Fprintf(&b, " reason: %s", e.Reason)
+	}
+	fmt.Fprintf(&b, ", requestID: %s", e.RequestID)
+	return b.String()
+}
+
+// Is the error has the same code as the target KappError
+func (e *Error) Is(target error) bool {
+	var kappErr errors.KappError
+	if !stderrors.As(target, &kappErr) {
+		return false
+	}
+	return len(e.ErrorCode) > 0 && e.ErrorCode == kappErr.GetErrorCode()
+}
+
+// IsNotFound does the resource not exist
+func IsNotFound(err error) bool {
+	var e *Error
+	return stderrors.As(err, &e) && (e.StatusCode == http.StatusNotFound || stderrors.Is(e, errors.ErrNotFound))
+}
+
+// IsConflict does the resource has been changed by others, or the If-Match precondition is not satisfied
+func IsConflict(err error) bool {
+	return stderrors.Is(err, errors.ErrResourceConflict)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/kappital/kappital/pkg/apis"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/utils/patch"
+)
+
+// InstanceEvent the change of the instance observed by the watch
+type InstanceEvent struct {
+	Type   EventType
+	Object *models.InstanceModel
+	Err    error
+}
+
+// InstanceClient the client of the instances of a service binding in a namespace of the cluster
+type InstanceClient struct {
+	cluster        *ClusterClient
+	serviceBinding string
+	namespace      string
+}
+
+func (i *InstanceClient) path(name string) string {
+	path := apiPrefix + "/servicebinding/" + escape(i.serviceBinding) + "/instance"
+	if len(name) > 0 {
+		path += "/" + escape(name)
+	}
+	return path
+}
+
+func (i *InstanceClient) query() url.Values {
+	query := i.cluster.query()
+	if len(i.namespace) > 0 {
+		query.Set("namespace", i.namespace)
+	}
+	return query
+}
+
+// Create deploy the instance custom resources of the creation into the cluster, the namespaces of the resources are
+// decided by themselves
+func (i *InstanceClient) Create(ctx context.Context, creation instancev1alpha1.ServiceInstanceCreation) error {
+	if len(creation.ClusterID) == 0 {
+		creation.ClusterID = i.cluster.name
+	}
+	_, err := i.cluster.client.do(ctx, request{method: http.MethodPost, path: i.path(""), query: i.cluster.query(),
+		body: creation}, nil)
+	return err
+}
+
+// List the instances of the service binding in the namespace
+func (i *InstanceClient) List(ctx context.Context) ([]models.InstanceModel, error) {
+	var instances []models.InstanceModel
+	_, err := i.cluster.client.do(ctx, request{method: http.MethodGet, path: i.path(""), query: i.query()},
+		&instances)
+	return instances, err
+}
+
+// Get the instance by its name, the error matches IsNotFound if it does not exist
+func (i *InstanceClient) Get(ctx context.Context, name string) (*models.InstanceModel, error) {
+	instance := &models.InstanceModel{}
+	if _, err := i.cluster.client.do(ctx, request{method: http.MethodGet, path: i.path(name), query: i.query()},
+		instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// UpdateSettings replace the settings of the instance, and get the updated settings with the new version
+func (i *InstanceClient) UpdateSettings(ctx context.Context, name string, settings apis.Settings,
+	opts UpdateOptions) (apis.Settings, int64, error) {
+	return i.cluster.client.updateSettings(ctx, i.path(name), i.query(), patch.JSONType, settings, opts)
+}
+
+// PatchSettings edit the settings of the instance by the MergePatchType or the JSONPatchType patch, and get the
+// updated settings with the new version
+func (i *InstanceClient) PatchSettings(ctx context.Context, name, patchType string, data []byte,
+	opts UpdateOptions) (apis.Settings, int64, error) {
+	return i.cluster.client.updateSettings(ctx, i.path(name), i.query(), patchType, data, opts)
+}
+
+// Delete uninstall the instance from the cluster, the custom resource is deleted in the background, thus use
+// WaitForDeletion to wait until it is gone
+func (i *InstanceClient) Delete(ctx context.Context, name string, opts DeleteOptions) error {
+	_, err := i.cluster.client.do(ctx, request{method: http.MethodDelete, path: i.path(name),
+		query: opts.query(i.query()), ifMatch: opts.ResourceVersion}, nil)
+	return err
+}
+
+// Wait poll the instance until the condition is satisfied or failed, or the ctx is done
+func (i *InstanceClient) Wait(ctx context.Context, name string,
+	condition func(*models.InstanceModel) (bool, error)) (*models.InstanceModel, error) {
+	var instance *models.InstanceModel
+	err := i.cluster.client.poll(ctx, func() (bool, error) {
+		var err error
+		if instance, err = i.Get(ctx, name); err != nil {
+			return false, err
+		}
+		return condition(instance)
+	})
+	return instance, err
+}
+
+// WaitForStatus poll the instance until it is in one of the status, such as models.StatusSuccess
+func (i *InstanceClient) WaitForStatus(ctx context.Context, name string, status ...string) (*models.InstanceModel,
+	error) {
+	return i.Wait(ctx, name, func(instance *models.InstanceModel) (bool, error) {
+		for _, s := range status {
+			if instance.Status == s {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// WaitForDeletion poll the instance until it does not exist
+func (i *InstanceClient) WaitForDeletion(ctx context.Context, name string) error {
+	return i.cluster.client.waitForDeletion(ctx, func(ctx context.Context) (interface{}, error) {
+		return i.Get(ctx, name)
+	})
+}
+
+// Watch poll the instance and send its changes, the channel is closed after the deleted event or when the ctx is
+// done
+func (i *InstanceClient) Watch(ctx context.Context, name string) <-chan InstanceEvent {
+	events := i.cluster.client.watch(ctx, func(ctx context.Context) (interface{}, error) {
+		return i.Get(ctx, name)
+	})
+	ch := make(chan InstanceEvent)
+	go func() {
+		defer close(ch)
+		for e := range events {
+			instance, _ := e.object.(*models.InstanceModel)
+			select {
+			case ch <- InstanceEvent{Type: e.eventType, Object: instance, Err: e.err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
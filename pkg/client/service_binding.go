@@ -0,0 +1,164 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kappital/kappital/pkg/apis"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/utils/patch"
+)
+
+// CreateResult the name and the id of the created service binding
+type CreateResult struct {
+	Name string `json:"Name"`
+	ID   string `json:"ID"`
+}
+
+// ServiceBindingEvent the change of the service binding observed by the watch
+type ServiceBindingEvent struct {
+	Type   EventType
+	Object *instancev1alpha1.CloudNativeServiceInstance
+	Err    error
+}
+
+// ServiceBindingClient the client of the service bindings in a cluster
+type ServiceBindingClient struct {
+	cluster *ClusterClient
+}
+
+func (s *ServiceBindingClient) path(name string) string {
+	path := apiPrefix + "/servicebinding"
+	if len(name) > 0 {
+		path += "/" + escape(name)
+	}
+	return path
+}
+
+// Create deploy the service package into the cluster, the existing service binding is returned if the service has
+// been deployed
+func (s *ServiceBindingClient) Create(ctx context.Context, creation instancev1alpha1.ServiceInstanceCreation) (
+	CreateResult, error) {
+	if len(creation.ClusterID) == 0 {
+		creation.ClusterID = s.cluster.name
+	}
+	var result CreateResult
+	_, err := s.cluster.client.do(ctx, request{method: http.MethodPost, path: s.path(""), body: creation}, &result)
+	return result, err
+}
+
+// List the service bindings in the cluster
+func (s *ServiceBindingClient) List(ctx context.Context) ([]instancev1alpha1.CloudNativeServiceInstance, error) {
+	var bindings []instancev1alpha1.CloudNativeServiceInstance
+	_, err := s.cluster.client.do(ctx, request{method: http.MethodGet, path: s.path(""), query: s.cluster.query()},
+		&bindings)
+	return bindings, err
+}
+
+// Get the service binding by its name, the error matches IsNotFound if it does not exist
+func (s *ServiceBindingClient) Get(ctx context.Context, name string, opts GetOptions) (
+	*instancev1alpha1.CloudNativeServiceInstance, error) {
+	query := s.cluster.query()
+	if opts.Detail {
+		query.Set("detail", "true")
+	}
+	binding := &instancev1alpha1.CloudNativeServiceInstance{}
+	if _, err := s.cluster.client.do(ctx, request{method: http.MethodGet, path: s.path(name), query: query},
+		binding); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
+
+// UpdateSettings replace the settings of the service binding, and get the updated settings with the new version
+func (s *ServiceBindingClient) UpdateSettings(ctx context.Context, name string, settings apis.Settings,
+	opts UpdateOptions) (apis.Settings, int64, error) {
+	return s.cluster.client.updateSettings(ctx, s.path(name), s.cluster.query(), patch.JSONType, settings, opts)
+}
+
+// PatchSettings edit the settings of the service binding by the MergePatchType or the JSONPatchType patch, and get
+// the updated settings with the new version
+func (s *ServiceBindingClient) PatchSettings(ctx context.Context, name, patchType string, data []byte,
+	opts UpdateOptions) (apis.Settings, int64, error) {
+	return s.cluster.client.updateSettings(ctx, s.path(name), s.cluster.query(), patchType, data, opts)
+}
+
+// Delete uninstall the service binding from the cluster, the resources are deleted in the background, thus use
+// WaitForDeletion to wait until it is gone
+func (s *ServiceBindingClient) Delete(ctx context.Context, name string, opts DeleteOptions) error {
+	_, err := s.cluster.client.do(ctx, request{method: http.MethodDelete, path: s.path(name),
+		query: opts.query(s.cluster.query()), ifMatch: opts.ResourceVersion}, nil)
+	return err
+}
+
+// Wait poll the service binding until the condition is satisfied or failed, or the ctx is done
+func (s *ServiceBindingClient) Wait(ctx context.Context, name string,
+	condition func(*instancev1alpha1.CloudNativeServiceInstance) (bool, error)) (
+	*instancev1alpha1.CloudNativeServiceInstance, error) {
+	var binding *instancev1alpha1.CloudNativeServiceInstance
+	err := s.cluster.client.poll(ctx, func() (bool, error) {
+		var err error
+		if binding, err = s.Get(ctx, name, GetOptions{Detail: true}); err != nil {
+			return false, err
+		}
+		return condition(binding)
+	})
+	return binding, err
+}
+
+// WaitForPhase poll the service binding until it is in one of the phases
+func (s *ServiceBindingClient) WaitForPhase(ctx context.Context, name string,
+	phases ...instancev1alpha1.Phase) (*instancev1alpha1.CloudNativeServiceInstance, error) {
+	return s.Wait(ctx, name, func(binding *instancev1alpha1.CloudNativeServiceInstance) (bool, error) {
+		for _, phase := range phases {
+			if binding.Status.Phase == phase {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// WaitForDeletion poll the service binding until it does not exist
+func (s *ServiceBindingClient) WaitForDeletion(ctx context.Context, name string) error {
+	return s.cluster.client.waitForDeletion(ctx, func(ctx context.Context) (interface{}, error) {
+		return s.Get(ctx, name, GetOptions{})
+	})
+}
+
+// Watch poll the service binding and send its changes, the channel is closed after the deleted event or when the
+// ctx is done
+func (s *ServiceBindingClient) Watch(ctx context.Context, name string) <-chan ServiceBindingEvent {
+	events := s.cluster.client.watch(ctx, func(ctx context.Context) (interface{}, error) {
+		return s.Get(ctx, name, GetOptions{Detail: true})
+	})
+	ch := make(chan ServiceBindingEvent)
+	go func() {
+		defer close(ch)
+		for e := range events {
+			binding, _ := e.object.(*instancev1alpha1.CloudNativeServiceInstance)
+			select {
+			case ch <- ServiceBindingEvent{Type: e.eventType, Object: binding, Err: e.err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/utils/patch"
+)
+
+const (
+	// MergePatchType the content type of the JSON merge patch, see RFC 7386
+	MergePatchType = patch.MergePatchType
+	// JSONPatchType the content type of the JSON patch, see RFC 6902
+	JSONPatchType = patch.JSONPatchType
+)
+
+// GetOptions of getting the service binding
+type GetOptions struct {
+	// Detail get the status of the resources in the cluster
+	Detail bool
+}
+
+// UpdateOptions of replacing or editing the settings
+type UpdateOptions struct {
+	// ResourceVersion the expected version of the record, the update fails with the conflict if it is changed
+	ResourceVersion *int64
+}
+
+// DeleteOptions of deleting the service binding or the instance
+type DeleteOptions struct {
+	// ResourceVersion the expected version of the record, the delete fails with the conflict if it is changed
+	ResourceVersion *int64
+	// Timeout override the delete timeout, such as "10m"
+	Timeout string
+	// Cascade delete the instances with the service binding, otherwise the service binding which has instances
+	// cannot be deleted, it is ignored by the instances
+	Cascade bool
+	// Force remove the records without waiting for the cluster to clean them up, only the admin can use it
+	Force bool
+	// RemoveFinalizers clear the finalizers of the custom resources which are deleted by force
+	RemoveFinalizers bool
+}
+
+// query add the delete timeout, the cascade and the force options into the query parameters
+func (o DeleteOptions) query(query url.Values) url.Values {
+	if len(o.Timeout) > 0 {
+		query.Set("timeout", o.Timeout)
+	}
+	if o.Cascade {
+		query.Set("cascade", "true")
+	}
+	if o.Force {
+		query.Set("force", "true")
+	}
+	if o.RemoveFinalizers {
+		query.Set("remove_finalizers", "true")
+	}
+	return query
+}
+
+// updateSettings replace the settings by PUT, or edit them by PATCH with the content type, and get the updated
+// settings with the new version of the record
+func (c *Client) updateSettings(ctx context.Context, path string, query url.Values, contentType string, body interface{},
+	opts UpdateOptions) (apis.Settings, int64, error) {
+	method := http.MethodPut
+	if contentType != patch.JSONType {
+		method = http.MethodPatch
+	}
+	var settings apis.Settings
+	header, err := c.do(ctx, request{method: method, path: path, query: query, body: body, contentType: contentType,
+		ifMatch: opts.ResourceVersion}, &settings)
+	if err != nil {
+		return apis.Settings{}, 0, err
+	}
+	return settings, resourceVersion(header), nil
+}
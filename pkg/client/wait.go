@@ -0,0 +1,115 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// EventType the type of the change which is observed by the watch
+type EventType string
+
+const (
+	// EventAdded the resource is observed the first time
+	EventAdded EventType = "Added"
+	// EventModified the resource is changed since the last observation
+	EventModified EventType = "Modified"
+	// EventDeleted the resource does not exist anymore, it is the last event of the watch
+	EventDeleted EventType = "Deleted"
+	// EventError the resource cannot be got, the watch keeps polling
+	EventError EventType = "Error"
+)
+
+// event the untyped change of the resource
+type event struct {
+	eventType EventType
+	object    interface{}
+	err       error
+}
+
+// poll call the fn at the interval until it is done, failed or the ctx is done
+func (c *Client) poll(ctx context.Context, fn func() (bool, error)) error {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		done, err := fn()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForDeletion poll the resource until it is not found
+func (c *Client) waitForDeletion(ctx context.Context, get func(context.Context) (interface{}, error)) error {
+	return c.poll(ctx, func() (bool, error) {
+		_, err := get(ctx)
+		if IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// watch poll the resource and send the changes until it is deleted or the ctx is done, the manager has no watch
+// api, thus the changes between two polls are merged
+func (c *Client) watch(ctx context.Context, get func(context.Context) (interface{}, error)) <-chan event {
+	ch := make(chan event)
+	go func() {
+		defer close(ch)
+		var last interface{}
+		send := func(e event) bool {
+			select {
+			case ch <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		_ = c.poll(ctx, func() (bool, error) { //nolint:errcheck
+			obj, err := get(ctx)
+			switch {
+			case IsNotFound(err):
+				if last != nil {
+					send(event{eventType: EventDeleted, object: last})
+					return true, nil
+				}
+				return false, nil
+			case err != nil:
+				if ctx.Err() != nil {
+					return true, nil
+				}
+				return !send(event{eventType: EventError, err: err}), nil
+			case last == nil:
+				last = obj
+				return !send(event{eventType: EventAdded, object: obj}), nil
+			case !reflect.DeepEqual(last, obj):
+				last = obj
+				return !send(event{eventType: EventModified, object: obj}), nil
+			default:
+				return false, nil
+			}
+		})
+	}()
+	return ch
+}
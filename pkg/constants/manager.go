@@ -19,6 +19,8 @@ package constants
 const (
 	// ServiceBindingPathParam url path parameter
 	ServiceBindingPathParam = ":service_binding"
+	// APITokenPathParam url path parameter
+	APITokenPathParam = ":token"
 	// InstancePathParam url path parameter
 	InstancePathParam = ":instance"
 	// ClusterNameQueryParam URL query parameters
@@ -27,4 +29,28 @@ const (
 	NamespaceQueryParam = "namespace"
 	// Detail URL query parameters
 	Detail = "detail"
+	// TimeoutQueryParam URL query parameters which overrides the delete timeout, such as "10m"
+	TimeoutQueryParam = "timeout"
+	// CascadeQueryParam URL query parameters which deletes the service binding with its instances
+	CascadeQueryParam = "cascade"
+	// ForceQueryParam URL query parameters which removes the records without waiting for the cluster to clean them
+	// up, only the admin can use it
+	ForceQueryParam = "force"
+	// RemoveFinalizersQueryParam URL query parameters which clears the finalizers of the custom resources which are
+	// deleted by force
+	RemoveFinalizersQueryParam = "remove_finalizers"
+	// SinceQueryParam and UntilQueryParam URL query parameters of the time range in RFC3339
+	SinceQueryParam = "since"
+	UntilQueryParam = "until"
+	// PrincipalQueryParam URL query parameters of the principal name
+	PrincipalQueryParam = "principal"
+	// RequestIDQueryParam URL query parameters of the request id
+	RequestIDQueryParam = "request_id"
+	// ResourceTypeQueryParam and ResourceNameQueryParam URL query parameters of the audited resource
+	ResourceTypeQueryParam = "resource_type"
+	ResourceNameQueryParam = "resource_name"
+	// TraceTypeQueryParam URL query parameters of the audit trace type
+	TraceTypeQueryParam = "trace_type"
+	// LimitQueryParam URL query parameters of the max number of the returned items
+	LimitQueryParam = "limit"
 )
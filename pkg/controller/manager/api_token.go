@@ -0,0 +1,107 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+
+	tokenv1alpha1 "github.com/kappital/kappital/pkg/apis/apitoken/v1alpha1"
+	"github.com/kappital/kappital/pkg/constants"
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+// APITokenController the controller of the static API tokens which create, list and revoke the tokens, only the
+// admin can manage the tokens
+type APITokenController struct {
+	web.Controller
+	resource resource.APITokenResource
+}
+
+// CreateAPIToken generate the API token, the token is only returned in the response
+func (a *APITokenController) CreateAPIToken() {
+	var err error
+	var resourceName string
+	defer utils.AuditLog(a.Ctx, "CreateAPIToken", utils.DeployAction, &resourceName, &err)
+	creation := tokenv1alpha1.APITokenCreation{}
+	if err = json.Unmarshal(a.Ctx.Input.RequestBody, &creation); err == nil {
+		err = creation.Validate()
+	}
+	if err != nil {
+		utils.ReplyError(a.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	resourceName = fmt.Sprintf("Create API Token [%s] for User [%s]", creation.Name, creation.User)
+	if err = utils.Authorize(a.Ctx, authorization.Attributes{Verb: authorization.VerbAdmin}); err != nil {
+		return
+	}
+	token, err := a.resource.CreateAPIToken(a.Ctx.Request.Context(), creation)
+	if err != nil {
+		if stderrors.Is(err, resource.ErrAPITokenExists) {
+			utils.ReplyError(a.Ctx, errors.ErrResourceConflict.WrapErrorReasonWith(err.Error()))
+			return
+		}
+		utils.ReplyError(a.Ctx, err)
+		return
+	}
+	utils.ReplyJSON(a.Ctx, http.StatusCreated, token)
+}
+
+// GetAPITokens list the API tokens without the tokens themselves
+func (a *APITokenController) GetAPITokens() {
+	var err error
+	resourceName := "Get API Token List"
+	defer utils.AuditLog(a.Ctx, "GetAPITokens", utils.QueryAction, &resourceName, &err)
+	if err = utils.Authorize(a.Ctx, authorization.Attributes{Verb: authorization.VerbAdmin}); err != nil {
+		return
+	}
+	tokens, err := a.resource.GetAPITokens(a.Ctx.Request.Context())
+	if err != nil {
+		utils.ReplyError(a.Ctx, err)
+		return
+	}
+	utils.ReplyJSON(a.Ctx, http.StatusOK, tokens)
+}
+
+// DeleteAPIToken revoke the API token by its name
+func (a *APITokenController) DeleteAPIToken() {
+	name := a.GetString(constants.APITokenPathParam)
+	var err error
+	var resourceName string
+	defer utils.AuditLog(a.Ctx, "DeleteAPIToken", utils.UninstallAction, &resourceName, &err)
+	if !utils.ValidString(name) {
+		err = utils.ErrIllegalParameters
+		utils.ReplyError(a.Ctx, utils.ErrIllegalParameters)
+		return
+	}
+	resourceName = fmt.Sprintf("Revoke API Token [%s]", name)
+	if err = utils.Authorize(a.Ctx, authorization.Attributes{Verb: authorization.VerbAdmin}); err != nil {
+		return
+	}
+	if err = a.resource.DeleteAPIToken(a.Ctx.Request.Context(), name); err != nil {
+		utils.ReplyError(a.Ctx, err)
+		return
+	}
+	utils.ReplyJSON(a.Ctx, http.StatusOK, nil)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+
+	"github.com/kappital/kappital/pkg/constants"
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/utils/audit"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+// AuditController the controller which queries the audit log, only the admin can query it
+type AuditController struct {
+	web.Controller
+}
+
+// GetAuditLogs query the audit log entries by the time range, principal, resource and trace type, the newest
+// entries are returned first
+func (a *AuditController) GetAuditLogs() {
+	var err error
+	resourceName := "Get Audit Log List"
+	defer utils.AuditLog(a.Ctx, "GetAuditLogs", utils.QueryAction, &resourceName, &err)
+	if err = utils.Authorize(a.Ctx, authorization.Attributes{Verb: authorization.VerbAdmin}); err != nil {
+		return
+	}
+	filter, err := parseAuditQuery(a.Ctx)
+	if err != nil {
+		utils.ReplyError(a.Ctx, errors.ErrIllegalParameters.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	entries, err := audit.Query(filter)
+	if err != nil {
+		if stderrors.Is(err, audit.ErrQueryUnsupported) {
+			utils.ReplyError(a.Ctx, errors.ErrNotFound.WrapErrorReasonWith(err.Error()))
+			return
+		}
+		utils.ReplyError(a.Ctx, errors.ErrInternal.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	utils.ReplyJSON(a.Ctx, http.StatusOK, entries)
+}
+
+// parseAuditQuery parse the filter of the audit log from the query parameters
+func parseAuditQuery(ctx *context.Context) (audit.QueryFilter, error) {
+	filter := audit.QueryFilter{
+		Principal:    ctx.Input.Query(constants.PrincipalQueryParam),
+		RequestID:    ctx.Input.Query(constants.RequestIDQueryParam),
+		ResourceType: ctx.Input.Query(constants.ResourceTypeQueryParam),
+		ResourceName: ctx.Input.Query(constants.ResourceNameQueryParam),
+		TraceType:    audit.TraceType(ctx.Input.Query(constants.TraceTypeQueryParam)),
+		Limit:        audit.DefaultQueryLimit,
+	}
+	var err error
+	if filter.Since, err = parseQueryTime(ctx, constants.SinceQueryParam); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseQueryTime(ctx, constants.UntilQueryParam); err != nil {
+		return filter, err
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		return filter, fmt.Errorf("the %s cannot be before the %s", constants.UntilQueryParam,
+			constants.SinceQueryParam)
+	}
+	if v := ctx.Input.Query(constants.LimitQueryParam); len(v) > 0 {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 || filter.Limit > audit.MaxQueryLimit {
+			return filter, fmt.Errorf("the %s must be an integer in [1, %d]", constants.LimitQueryParam,
+				audit.MaxQueryLimit)
+		}
+	}
+	return filter, nil
+}
+
+func parseQueryTime(ctx *context.Context, key string) (time.Time, error) {
+	v := ctx.Input.Query(key)
+	if len(v) == 0 {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("the %s should be in RFC3339 such as 2022-01-02T15:04:05Z", key)
+	}
+	return t, nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/beego/beego/v2/server/web/mock"
+)
+
+func Test_parseAuditQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantLimit int
+		wantSince time.Time
+		wantErr   bool
+	}{
+		{name: "Test parseAuditQuery default", query: "", wantLimit: 100},
+		{name: "Test parseAuditQuery all", query: "since=2022-01-02T15:04:05Z&until=2022-01-03T15:04:05Z" +
+			"&principal=alice&resource_type=Deploy&trace_type=ApiCall&limit=10", wantLimit: 10,
+			wantSince: time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{name: "Test parseAuditQuery invalid time", query: "since=yesterday", wantErr: true},
+		{name: "Test parseAuditQuery reversed range", query: "since=2022-01-03T15:04:05Z&until=2022-01-02T15:04:05Z",
+			wantErr: true},
+		{name: "Test parseAuditQuery invalid limit", query: "limit=0", wantErr: true},
+		{name: "Test parseAuditQuery too large limit", query: "limit=1001", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := mock.NewMockContext(&http.Request{Method: http.MethodGet,
+				URL: &url.URL{Path: "/api/v1alpha1/audit", RawQuery: tt.query}})
+			got, err := parseAuditQuery(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAuditQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Limit != tt.wantLimit || !got.Since.Equal(tt.wantSince) {
+				t.Errorf("parseAuditQuery() = %+v, want limit %d since %v", got, tt.wantLimit, tt.wantSince)
+			}
+		})
+	}
+}
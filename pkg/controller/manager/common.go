@@ -19,19 +19,17 @@ package manager
 import (
 	"encoding/json"
 	"fmt"
-	"time"
+	"net/http"
+	"strconv"
 
 	"github.com/beego/beego/v2/server/web/context"
-	appsv1 "k8s.io/api/apps/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/apis"
-	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
-	"github.com/kappital/kappital/pkg/apis/internals"
-	svcv1alpha1 "github.com/kappital/kappital/pkg/apis/service/v1alpha1"
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
-	"github.com/kappital/kappital/pkg/utils/uuid"
+	"github.com/kappital/kappital/pkg/constants"
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
 )
 
 func getAndResolveServiceParam(ctx *context.Context) (*instancev1alpha1.ServiceInstanceCreation, error) {
@@ -48,85 +46,51 @@ func getAndResolveServiceParam(ctx *context.Context) (*instancev1alpha1.ServiceI
 	return creation, nil
 }
 
-func transCreationToServiceBinding(sbReq *instancev1alpha1.ServiceInstanceCreation) (*internals.ServiceBinding, error) {
-	if sbReq == nil {
-		return nil, fmt.Errorf("the pass in variable is empty, cannot translate to the Service Binding")
+// getDeleteOptions get the If-Match precondition, the delete timeout and the force options from the request
+func getDeleteOptions(ctx *context.Context) (resource.DeleteOptions, error) {
+	ifMatch, err := utils.GetIfMatchVersion(ctx)
+	if err != nil {
+		return resource.DeleteOptions{}, err
 	}
-	var err error
-	now := time.Now()
-	serviceBinding := &internals.ServiceBinding{
-		ID:          uuid.NewUUID(),
-		Name:        sbReq.Service.Spec.Description.Name,
-		Version:     sbReq.Service.Spec.Version,
-		Namespace:   apis.KappitalSystemNamespace,
-		ServiceName: sbReq.Service.Spec.Description.Name,
-		ServiceID:   string(sbReq.Service.UID),
-		ClusterID:   sbReq.ClusterID,
-		ClusterName: sbReq.ClusterID,
-		ProcessTime: now.UTC(),
-		UpdateTime:  now.UTC(),
-		Permissions: servicePermissionBuilder(sbReq.Service.Spec.Operator.ClusterRoles,
-			sbReq.Service.Spec.Operator.ClusterRoleBindings),
-		CapabilityPlugin: serviceCapabilityPluginBuilder(sbReq.Service.Spec.Manifests),
+	timeout := ctx.Input.Query(constants.TimeoutQueryParam)
+	if err = (apis.Timeouts{DeleteTimeout: timeout}).Validate(); err != nil {
+		return resource.DeleteOptions{}, err
 	}
-	serviceBinding.CRD, err = serviceCRDBuilder(sbReq.Service.Spec.Manifests)
-	if err != nil {
-		return nil, err
+	opts := resource.DeleteOptions{ResourceVersion: ifMatch, Timeout: timeout}
+	if opts.Force, err = getBoolQuery(ctx, constants.ForceQueryParam); err != nil {
+		return resource.DeleteOptions{}, err
 	}
-	serviceBinding.Workload.Deployments = deploymentWorkloadBuilder(sbReq.Service.Spec.Operator.Deployments)
-
-	return serviceBinding, nil
-}
-
-func serviceCRDBuilder(csds []svcv1alpha1.CustomServiceDefinition) ([]string, error) {
-	var customResource []string
-	for _, csd := range csds {
-		crdString, err := json.Marshal(csd.Spec.CRD)
-		if err != nil {
-			klog.Errorf("json Marshal service %s crd to string failed", csd.Name)
-			return nil, err
-		}
-		customResource = append(customResource, string(crdString))
+	if opts.RemoveFinalizers, err = getBoolQuery(ctx, constants.RemoveFinalizersQueryParam); err != nil {
+		return resource.DeleteOptions{}, err
 	}
-	return customResource, nil
+	return opts, opts.Validate()
 }
 
-func servicePermissionBuilder(crs []rbacv1.ClusterRole, crbs []rbacv1.ClusterRoleBinding) []enginev1alpha1.Permission {
-	crMap := getClusterRoleMap(crs)
-	permissions := make([]enginev1alpha1.Permission, 0, len(crs))
-	for _, binding := range crbs {
-		cr, find := crMap[binding.RoleRef.Name]
-		if !find {
-			continue
-		}
-		permissions = append(permissions, enginev1alpha1.Permission{
-			ServiceAccountName: binding.Subjects[0].Name,
-			Rules:              cr.Rules,
-		})
+// getBoolQuery get the boolean query parameter, which is false if it is absent
+func getBoolQuery(ctx *context.Context, key string) (bool, error) {
+	value := ctx.Input.Query(key)
+	if len(value) == 0 {
+		return false, nil
 	}
-	return permissions
-}
-
-func serviceCapabilityPluginBuilder(_ []svcv1alpha1.CustomServiceDefinition) enginev1alpha1.CapabilityPlugin {
-	return enginev1alpha1.CapabilityPlugin{}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("the query parameter %s must be a boolean, err: %v", key, err)
+	}
+	return b, nil
 }
 
-func deploymentWorkloadBuilder(deployments []appsv1.Deployment) []enginev1alpha1.ServiceDeploymentSpec {
-	var deploymentSpecs []enginev1alpha1.ServiceDeploymentSpec
-	for _, deployment := range deployments {
-		deploymentSpec := enginev1alpha1.ServiceDeploymentSpec{
-			Name: deployment.Name,
-			Spec: deployment.Spec,
-		}
-		deploymentSpecs = append(deploymentSpecs, deploymentSpec)
+// authorizeForce check the principal is the admin if the records are deleted by force, and the rejection has been
+// replied if the error is not nil
+func authorizeForce(ctx *context.Context, opts resource.DeleteOptions) error {
+	if !opts.Force {
+		return nil
 	}
-	return deploymentSpecs
+	return utils.Authorize(ctx, authorization.Attributes{Verb: authorization.VerbAdmin})
 }
 
-func getClusterRoleMap(crs []rbacv1.ClusterRole) map[string]rbacv1.ClusterRole {
-	crMap := make(map[string]rbacv1.ClusterRole, len(crs))
-	for _, cr := range crs {
-		crMap[cr.Name] = cr
-	}
-	return crMap
+// getSettingsUpdate get the update which replaces the settings with the PUT body, or applies the PATCH body
+// with its content type to the current settings
+func getSettingsUpdate(ctx *context.Context) resource.UpdateSettings {
+	return resource.NewSettingsUpdate(ctx.Input.Method() == http.MethodPut, ctx.Input.Header("Content-Type"),
+		ctx.Input.RequestBody)
 }
@@ -18,18 +18,13 @@ package manager
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
 	"github.com/beego/beego/v2/server/web"
 	"github.com/beego/beego/v2/server/web/mock"
-	appsv1 "k8s.io/api/apps/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/kappital/kappital/pkg/apis"
-	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
-	svcv1alpha1 "github.com/kappital/kappital/pkg/apis/service/v1alpha1"
 	"github.com/kappital/kappital/pkg/resource"
 	"github.com/kappital/kappital/pkg/utils/audit"
 )
@@ -54,125 +49,29 @@ func TestMain(m *testing.M) {
 	m.Run()
 }
 
-func Test_deploymentWorkloadBuilder(t *testing.T) {
-	tests := []struct {
-		name        string
-		deployments []appsv1.Deployment
-		want        []enginev1alpha1.ServiceDeploymentSpec
-	}{
-		{
-			name:        "test deploymentWorkloadBuilder",
-			deployments: []appsv1.Deployment{{ObjectMeta: metav1.ObjectMeta{Name: "test-deployment"}}},
-			want:        []enginev1alpha1.ServiceDeploymentSpec{{Name: "test-deployment"}},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := deploymentWorkloadBuilder(tt.deployments); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("deploymentWorkloadBuilder() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_serviceCRDBuilder(t *testing.T) {
+func Test_getDeleteOptions(t *testing.T) {
 	tests := []struct {
 		name    string
-		csds    []svcv1alpha1.CustomServiceDefinition
-		want    []string
+		query   string
+		want    resource.DeleteOptions
 		wantErr bool
 	}{
-		{
-			name: "Test serviceCRDBuilder (without error)",
-			csds: []svcv1alpha1.CustomServiceDefinition{
-				{Spec: svcv1alpha1.CustomServiceDefinitionSpec{CRD: &apis.AbstractResource{}}},
-			},
-			want:    []string{"{\"metadata\":{\"creationTimestamp\":null}}"},
-			wantErr: false,
-		},
+		{name: "Test getDeleteOptions (no options)", query: ""},
+		{name: "Test getDeleteOptions (force)", query: "timeout=5m&force=true&remove_finalizers=true",
+			want: resource.DeleteOptions{Timeout: "5m", Force: true, RemoveFinalizers: true}},
+		{name: "Test getDeleteOptions (invalid force)", query: "force=yes", wantErr: true},
+		{name: "Test getDeleteOptions (remove finalizers without force)", query: "remove_finalizers=true",
+			wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := serviceCRDBuilder(tt.csds)
+			ctx, _ := mock.NewMockContext(httptest.NewRequest(http.MethodDelete, "/?"+tt.query, nil))
+			got, err := getDeleteOptions(ctx)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("serviceCRDBuilder() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("serviceCRDBuilder() got = %v, want %v", got, tt.want)
+				t.Fatalf("getDeleteOptions() error = %v, wantErr %v", err, tt.wantErr)
 			}
-		})
-	}
-}
-
-func Test_servicePermissionBuilder(t *testing.T) {
-	type args struct {
-		crs  []rbacv1.ClusterRole
-		crbs []rbacv1.ClusterRoleBinding
-	}
-	tests := []struct {
-		name string
-		args args
-		want []enginev1alpha1.Permission
-	}{
-		{
-			name: "Test servicePermissionBuilder",
-			args: args{
-				crs: []rbacv1.ClusterRole{{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-role"}}},
-				crbs: []rbacv1.ClusterRoleBinding{
-					{
-						Subjects: []rbacv1.Subject{{Name: "test-service-account-name"}},
-						RoleRef:  rbacv1.RoleRef{Name: "test-cluster-role"},
-					},
-					{RoleRef: rbacv1.RoleRef{Name: "not exist name"}},
-				},
-			},
-			want: []enginev1alpha1.Permission{{ServiceAccountName: "test-service-account-name"}},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := servicePermissionBuilder(tt.args.crs, tt.args.crbs); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("servicePermissionBuilder() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_getClusterRoleMap(t *testing.T) {
-	tests := []struct {
-		name string
-		crs  []rbacv1.ClusterRole
-		want map[string]rbacv1.ClusterRole
-	}{
-		{
-			name: "Test getClusterRoleMap",
-			crs:  []rbacv1.ClusterRole{{ObjectMeta: metav1.ObjectMeta{Name: "test cluster role"}}},
-			want: map[string]rbacv1.ClusterRole{
-				"test cluster role": {ObjectMeta: metav1.ObjectMeta{Name: "test cluster role"}},
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := getClusterRoleMap(tt.crs); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("getClusterRoleMap() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_serviceCapabilityPluginBuilder(t *testing.T) {
-	tests := []struct {
-		name string
-		want enginev1alpha1.CapabilityPlugin
-	}{
-		{name: "Test serviceCapabilityPluginBuilder"},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := serviceCapabilityPluginBuilder(nil); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("serviceCapabilityPluginBuilder() = %v, want %v", got, tt.want)
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getDeleteOptions() = %+v, want %+v", got, tt.want)
 			}
 		})
 	}
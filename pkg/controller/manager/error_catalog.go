@@ -0,0 +1,37 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+// ErrorCatalogController the controller which lists the registered errors, thus the clients can branch on the
+// error codes of the ErrorResp
+type ErrorCatalogController struct {
+	web.Controller
+}
+
+// GetErrors list the error codes, messages and http codes of all registered errors
+func (e *ErrorCatalogController) GetErrors() {
+	utils.ReplyJSON(e.Ctx, http.StatusOK, errors.Catalog())
+}
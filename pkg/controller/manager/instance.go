@@ -17,24 +17,17 @@
 package manager
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/beego/beego/v2/server/web"
-	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/apis"
-	"github.com/kappital/kappital/pkg/apis/internals"
-	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	"github.com/kappital/kappital/pkg/constants"
 	"github.com/kappital/kappital/pkg/controller/utils"
-	"github.com/kappital/kappital/pkg/models"
 	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
 	"github.com/kappital/kappital/pkg/utils/errors"
-	"github.com/kappital/kappital/pkg/utils/uuid"
-	"github.com/kappital/kappital/pkg/utils/version"
 )
 
 // InstanceController the controller of the instance which deploy, search, and delete the instance
@@ -54,52 +47,30 @@ func (i *InstanceController) CreateInstance() {
 	defer utils.AuditLog(i.Ctx, "CreateInstance", utils.DeployAction, &resourceName, &err)
 	if !utils.ValidString(serviceBinding) || !utils.ValidString(clusterName) {
 		err = utils.ErrIllegalParameters
-		utils.ReplyJSON(i.Ctx, http.StatusBadRequest, utils.ErrIllegalParameters)
+		utils.ReplyError(i.Ctx, utils.ErrIllegalParameters)
 		return
 	}
 	instanceCreation, err := getAndResolveServiceParam(i.Ctx)
 	if err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusBadRequest, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+		utils.ReplyError(i.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
 		return
 	}
 	resourceName = fmt.Sprintf("Deploy Instance for Service Package [%s] to Cluster [%s]",
 		serviceBinding, clusterName)
-	if err = resource.ValidationInstance(instanceCreation); err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusBadRequest, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+	if err = resource.ValidationInstance(i.Ctx.Request.Context(), instanceCreation); err != nil {
+		utils.ReplyError(i.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
 		return
 	}
-	if !i.binding.IsServiceBindingDeployed(serviceBinding, clusterName) {
-		subRes, err := transCreationToServiceBinding(instanceCreation)
-		if err != nil {
-			utils.ReplyJSON(i.Ctx, http.StatusBadRequest, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+	for _, attrs := range i.binding.DeployInstanceAttributes(serviceBinding, clusterName, instanceCreation) {
+		if err = utils.Authorize(i.Ctx, attrs); err != nil {
 			return
 		}
-		if err = i.binding.CreateServiceBinding(*subRes); err != nil {
-			utils.ReplyJSON(i.Ctx, http.StatusInternalServerError,
-				errors.ErrServiceInstall.WrapErrorReasonWith(err.Error()))
-			return
-		}
-	}
-	binding, err := i.binding.GetInternalServiceBinding(serviceBinding, clusterName)
-	if err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusInternalServerError,
-			errors.ErrDataUnmarshal.WrapErrorReasonWith(err.Error()))
-		return
-	}
-	instances, err := transCreationToServiceInstance(*binding, instanceCreation)
-	if err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusInternalServerError,
-			errors.ErrDataUnmarshal.WrapErrorReasonWith(err.Error()))
-		return
 	}
-	if err = i.instance.CreateInstance(instances, map[string]string{"name": serviceBinding, "cluster_name": clusterName}); err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusInternalServerError,
-			errors.ErrServiceInstanceCreate.WrapErrorReasonWith(err.Error()))
+	if _, err = i.instance.DeployInstances(i.Ctx.Request.Context(), &i.binding, serviceBinding, clusterName,
+		instanceCreation); err != nil {
+		utils.ReplyError(i.Ctx, err)
 		return
 	}
-	for _, instance := range instances {
-		klog.Infof("create service instance %s success", instance.Name)
-	}
 	utils.ReplyJSON(i.Ctx, http.StatusOK, "success")
 }
 
@@ -113,14 +84,18 @@ func (i *InstanceController) GetInstances() {
 	defer utils.AuditLog(i.Ctx, "GetInstances", utils.QueryAction, &resourceName, &err)
 	if (!utils.ValidString(serviceBinding) && len(serviceBinding) > 0) || !utils.ValidString(clusterName) || !utils.ValidString(namespace) {
 		err = utils.ErrIllegalParameters
-		utils.ReplyJSON(i.Ctx, http.StatusBadRequest, utils.ErrIllegalParameters)
+		utils.ReplyError(i.Ctx, utils.ErrIllegalParameters)
 		return
 	}
 	resourceName = fmt.Sprintf("Get Instance List of Service Binding [%s] from Namespace [%s] in Cluster [%s]",
 		serviceBinding, namespace, clusterName)
-	resp, err := i.instance.GetInstances(serviceBinding, clusterName, namespace)
+	if err = utils.Authorize(i.Ctx, authorization.Attributes{Verb: authorization.VerbGet, Cluster: clusterName,
+		Namespace: namespace, Service: serviceBinding}); err != nil {
+		return
+	}
+	resp, err := i.instance.GetInstances(i.Ctx.Request.Context(), serviceBinding, clusterName, namespace)
 	if err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusInternalServerError, err)
+		utils.ReplyError(i.Ctx, err)
 		return
 	}
 	utils.ReplyJSON(i.Ctx, http.StatusOK, resp)
@@ -137,16 +112,21 @@ func (i *InstanceController) GetInstanceDetail() {
 	defer utils.AuditLog(i.Ctx, "GetInstanceDetail", utils.QueryAction, &resourceName, &err)
 	if !utils.ValidString(serviceBinding) || !utils.ValidString(instanceName) || !utils.ValidString(clusterName) || !utils.ValidString(namespace) {
 		err = utils.ErrIllegalParameters
-		utils.ReplyJSON(i.Ctx, http.StatusBadRequest, utils.ErrIllegalParameters)
+		utils.ReplyError(i.Ctx, utils.ErrIllegalParameters)
 		return
 	}
 	resourceName = fmt.Sprintf("Get Instance [%s] if Service Binding [%s] from Namespace [%s] in Cluster [%s]",
 		instanceName, serviceBinding, namespace, clusterName)
-	resp, err := i.instance.GetInstance(serviceBinding, clusterName, namespace, instanceName)
+	if err = utils.Authorize(i.Ctx, authorization.Attributes{Verb: authorization.VerbGet, Cluster: clusterName,
+		Namespace: namespace, Service: serviceBinding}); err != nil {
+		return
+	}
+	resp, err := i.instance.GetInstance(i.Ctx.Request.Context(), serviceBinding, clusterName, namespace, instanceName)
 	if err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusInternalServerError, err)
+		utils.ReplyError(i.Ctx, err)
 		return
 	}
+	utils.SetETag(i.Ctx, resp.ResourceVersion)
 	utils.ReplyJSON(i.Ctx, http.StatusOK, resp)
 }
 
@@ -161,67 +141,79 @@ func (i *InstanceController) DeleteInstance() {
 	defer utils.AuditLog(i.Ctx, "DeleteInstance", utils.UninstallAction, &resourceName, &err)
 	if !utils.ValidString(instanceName) || !utils.ValidString(clusterName) || !utils.ValidString(namespace) {
 		err = utils.ErrIllegalParameters
-		utils.ReplyJSON(i.Ctx, http.StatusBadRequest, utils.ErrIllegalParameters)
+		utils.ReplyError(i.Ctx, utils.ErrIllegalParameters)
 		return
 	}
 	resourceName = fmt.Sprintf("Uninstall Service Instance [%s] of Service Binding [%s] from Namespace [%s] in Cluster [%s]",
 		instanceName, serviceBinding, namespace, clusterName)
-	if err = i.instance.DeleteInstance(clusterName, instanceName, namespace); err != nil {
-		utils.ReplyJSON(i.Ctx, http.StatusBadRequest, err)
+	if err = utils.Authorize(i.Ctx, authorization.Attributes{Verb: authorization.VerbDelete, Cluster: clusterName,
+		Namespace: namespace, Service: serviceBinding}); err != nil {
+		return
+	}
+	opts, err := getDeleteOptions(i.Ctx)
+	if err != nil {
+		utils.ReplyError(i.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	if err = authorizeForce(i.Ctx, opts); err != nil {
+		return
+	}
+	if err = i.instance.DeleteInstance(i.Ctx.Request.Context(), serviceBinding, clusterName, instanceName, namespace,
+		opts); err != nil {
+		if utils.IsResourceVersionConflict(err) {
+			utils.ReplyConflict(i.Ctx, opts.ResourceVersion, err)
+			return
+		}
+		utils.ReplyError(i.Ctx, err)
 		return
 	}
 	utils.ReplyJSON(i.Ctx, http.StatusOK, nil)
 }
 
-func transCreationToServiceInstance(binding internals.ServiceBinding,
-	serviceBindingReq *instancev1alpha1.ServiceInstanceCreation) ([]internals.ServiceInstance, error) {
-	now := time.Now()
-	var instances []internals.ServiceInstance
-	for _, cr := range serviceBindingReq.InstanceCustomResources {
-		crByte, err := json.Marshal(cr)
-		if err != nil {
-			return nil, err
-		}
-		instance := internals.ServiceInstance{
-			ID:                 uuid.NewUUID(),
-			Name:               cr.Name,
-			Namespace:          cr.Namespace,
-			RawResource:        string(crByte),
-			ClusterName:        binding.ClusterName,
-			CreateTime:         now,
-			Status:             models.StatusInitializing,
-			Kind:               cr.Kind,
-			APIVersion:         cr.APIVersion,
-			ServiceBindingName: binding.Name,
-			ServiceBindingID:   binding.ID,
-			ServiceName:        binding.ServiceName,
-			ServiceID:          binding.ServiceID,
-			UpdateTime:         now,
-		}
-		plural, err := getResourceFromCRD(binding.CRD, instance.Kind, cr.GroupVersionKind().Group)
-		if err != nil {
-			return nil, err
-		}
-		instance.Resource = plural
-		instances = append(instances, instance)
-	}
+// UpdateInstance replace the settings of the service instance with the request body
+func (i *InstanceController) UpdateInstance() {
+	i.updateSettings("UpdateInstance")
+}
 
-	return instances, nil
+// PatchInstance edit the settings of the service instance with the JSON merge patch or the JSON patch
+func (i *InstanceController) PatchInstance() {
+	i.updateSettings("PatchInstance")
 }
 
-func getResourceFromCRD(crds []string, kind, group string) (string, error) {
-	v1CRDs, v1beta1CRDs := version.GetCrdV1AndBeta1Slice(crds)
-	for _, v1CRD := range v1CRDs {
-		if v1CRD.Spec.Names.Kind == kind && v1CRD.Spec.Group == group {
-			return v1CRD.Spec.Names.Plural, nil
-		}
+func (i *InstanceController) updateSettings(traceName string) {
+	serviceBinding := i.GetString(constants.ServiceBindingPathParam)
+	instanceName := i.GetString(constants.InstancePathParam)
+	clusterName := i.GetString(constants.ClusterNameQueryParam, apis.DefaultCluster)
+	namespace := i.GetString(constants.NamespaceQueryParam, apis.DefaultNamespace)
+	var err error
+	var resourceName string
+	defer utils.AuditLog(i.Ctx, traceName, utils.UpdateAction, &resourceName, &err)
+	if !utils.ValidString(instanceName) || !utils.ValidString(clusterName) || !utils.ValidString(namespace) {
+		err = utils.ErrIllegalParameters
+		utils.ReplyError(i.Ctx, utils.ErrIllegalParameters)
+		return
 	}
-
-	for _, v1beta1CRD := range v1beta1CRDs {
-		if v1beta1CRD.Spec.Names.Kind == kind && v1beta1CRD.Spec.Group == group {
-			return v1beta1CRD.Spec.Names.Plural, nil
+	resourceName = fmt.Sprintf("Update Service Instance [%s] of Service Binding [%s] from Namespace [%s] in Cluster [%s]",
+		instanceName, serviceBinding, namespace, clusterName)
+	if err = utils.Authorize(i.Ctx, authorization.Attributes{Verb: authorization.VerbUpdate, Cluster: clusterName,
+		Namespace: namespace, Service: serviceBinding}); err != nil {
+		return
+	}
+	ifMatch, err := utils.GetIfMatchVersion(i.Ctx)
+	if err != nil {
+		utils.ReplyError(i.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	settings, version, err := i.instance.UpdateInstanceSettings(i.Ctx.Request.Context(), serviceBinding, clusterName,
+		instanceName, namespace, ifMatch, getSettingsUpdate(i.Ctx))
+	if err != nil {
+		if utils.IsResourceVersionConflict(err) {
+			utils.ReplyConflict(i.Ctx, ifMatch, err)
+			return
 		}
+		utils.ReplyError(i.Ctx, err)
+		return
 	}
-
-	return "", fmt.Errorf("can not find cr plural because the cr is not fit CRD")
+	utils.SetETag(i.Ctx, version)
+	utils.ReplyJSON(i.Ctx, http.StatusOK, settings)
 }
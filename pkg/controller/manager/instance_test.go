@@ -17,11 +17,26 @@
 package manager
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/mock"
 	"github.com/smartystreets/goconvey/convey"
 
+	"github.com/kappital/kappital/pkg/apis/internals"
 	"github.com/kappital/kappital/pkg/constants"
+	"github.com/kappital/kappital/pkg/dao/instance"
+	"github.com/kappital/kappital/pkg/models"
+	mo "github.com/kappital/kappital/pkg/models/operation"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
 )
 
 var testInstanceController *InstanceController
@@ -56,6 +71,16 @@ func TestInstanceController_DeleteInstance(t *testing.T) {
 	})
 }
 
+func TestInstanceController_UpdateInstance(t *testing.T) {
+	convey.Convey("Test InstanceController UpdateInstance and PatchInstance", t, func() {
+		testInstanceController.Ctx.Input.SetParam(constants.InstancePathParam, "_")
+		testInstanceController.UpdateInstance()
+		testInstanceController.Ctx.Input.SetParam(constants.InstancePathParam, "xx")
+		testInstanceController.Ctx.Input.SetParam(constants.ClusterNameQueryParam, "_")
+		testInstanceController.PatchInstance()
+	})
+}
+
 func TestInstanceController_CreateInstance(t *testing.T) {
 	convey.Convey("Test InstanceController DeleteInstance", t, func() {
 		testInstanceController.Ctx.Input.SetParam(constants.ServiceBindingPathParam, "_")
@@ -65,3 +90,65 @@ func TestInstanceController_CreateInstance(t *testing.T) {
 		testInstanceController.CreateInstance()
 	})
 }
+
+func TestInstanceController_otherServiceInstance(t *testing.T) {
+	// alice operates the service orders only, the instance cache belongs to the service payments
+	authorization.SetPolicy(&authorization.Policy{Bindings: []authorization.RoleBinding{{
+		Role:     authorization.RoleOperator,
+		Subjects: []authorization.Subject{{Kind: authorization.SubjectUser, Name: "alice"}},
+		Scopes:   []authorization.Scope{{Services: []string{"orders"}}},
+	}}})
+	defer authorization.SetPolicy(nil)
+	p := gomonkey.ApplyMethod(reflect.TypeOf(mo.ServiceBindingOperation{}), "Get",
+		func(_ mo.ServiceBindingOperation, cols map[string]string) (interface{}, error) {
+			return models.ServiceBindingModel{ID: "sb-" + cols["name"], Name: cols["name"]}, nil
+		})
+	defer p.Reset()
+	p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Get",
+		func(_ instance.Instance, cols map[string]string) (interface{}, error) {
+			if cols["service_binding_id"] != "sb-payments" {
+				return nil, orm.ErrNoRows
+			}
+			return internals.ServiceInstance{ID: "i1", Name: "cache", Namespace: "prod",
+				ServiceBindingID: "sb-payments"}, nil
+		})
+	p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Update",
+		func(instance.Instance, interface{}, ...string) error {
+			t.Errorf("the instance of the other service is updated")
+			return nil
+		})
+
+	tests := []struct {
+		name     string
+		method   string
+		service  string
+		wantCode int
+	}{
+		{name: "delete through the own service", method: http.MethodDelete, service: "orders",
+			wantCode: http.StatusNotFound},
+		{name: "delete through the other service", method: http.MethodDelete, service: "payments",
+			wantCode: http.StatusForbidden},
+		{name: "update through the own service", method: http.MethodPut, service: "orders",
+			wantCode: http.StatusNotFound},
+		{name: "update through the other service", method: http.MethodPut, service: "payments",
+			wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/?namespace=prod", strings.NewReader(`{"deletionProtection":false}`))
+			req = req.WithContext(authentication.WithPrincipal(req.Context(), authentication.Principal{Name: "alice"}))
+			ctx, resp := mock.NewMockContext(req)
+			ctx.Input.SetParam(constants.ServiceBindingPathParam, tt.service)
+			ctx.Input.SetParam(constants.InstancePathParam, "cache")
+			c := &InstanceController{Controller: web.Controller{Ctx: ctx}, instance: resource.InstanceResource{}}
+			if tt.method == http.MethodDelete {
+				c.DeleteInstance()
+			} else {
+				c.UpdateInstance()
+			}
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("%s code = %d, want %d", tt.method, resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/openapi"
+)
+
+var (
+	openAPIDoc  *openapi.Document
+	openAPIOnce sync.Once
+)
+
+// OpenAPIController the controller which serves the OpenAPI document of the manager REST API, thus the clients can
+// be generated from it instead of hand-coding the URLs
+type OpenAPIController struct {
+	web.Controller
+}
+
+// GetOpenAPI get the OpenAPI document of the registered routes, it is built once because the routes are registered
+// before the manager serves
+func (o *OpenAPIController) GetOpenAPI() {
+	openAPIOnce.Do(func() {
+		openAPIDoc = openapi.Spec(openapi.Info{
+			Title:       "Kappital Manager API",
+			Description: "The REST API of the Kappital Manager which deploys and manages the cloud native services.",
+			Version:     "v1alpha1",
+		}, errors.ErrorResp{})
+	})
+	utils.ReplyJSON(o.Ctx, http.StatusOK, openAPIDoc)
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+// ServerInfoController the controller which describes the version and the capabilities of the manager, thus the
+// clients can detect what it supports
+type ServerInfoController struct {
+	web.Controller
+	resource resource.ServerInfoResource
+}
+
+// GetServerInfo get the version, the features, the registered clusters and the supported package formats, it is
+// authorized as getting all resources because the clusters are listed
+func (s *ServerInfoController) GetServerInfo() {
+	if err := utils.Authorize(s.Ctx, authorization.Attributes{Verb: authorization.VerbGet}); err != nil {
+		return
+	}
+	info, err := s.resource.GetServerInfo(s.Ctx.Request.Context())
+	if err != nil {
+		utils.ReplyError(s.Ctx, errors.ErrInternal.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	utils.ReplyJSON(s.Ctx, http.StatusOK, info)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/mock"
+
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+)
+
+func TestServerInfoController_GetServerInfo(t *testing.T) {
+	// alice views the service orders only, thus she cannot list all clusters
+	authorization.SetPolicy(&authorization.Policy{Bindings: []authorization.RoleBinding{
+		{Role: authorization.RoleViewer, Subjects: []authorization.Subject{{Kind: authorization.SubjectUser,
+			Name: "alice"}}, Scopes: []authorization.Scope{{Services: []string{"orders"}}}},
+		{Role: authorization.RoleViewer, Subjects: []authorization.Subject{{Kind: authorization.SubjectUser,
+			Name: "bob"}}},
+	}})
+	defer authorization.SetPolicy(nil)
+	p := gomonkey.ApplyMethod(reflect.TypeOf(&resource.ServerInfoResource{}), "GetServerInfo",
+		func(*resource.ServerInfoResource, context.Context) (infov1alpha1.ServerInfo, error) {
+			return infov1alpha1.ServerInfo{Clusters: []infov1alpha1.ClusterInfo{{Name: "default"}}}, nil
+		})
+	defer p.Reset()
+
+	tests := []struct {
+		name     string
+		user     string
+		wantCode int
+	}{
+		{name: "viewer of all services", user: "bob", wantCode: http.StatusOK},
+		{name: "viewer of one service", user: "alice", wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1alpha1/info", nil)
+			req = req.WithContext(authentication.WithPrincipal(req.Context(), authentication.Principal{Name: tt.user}))
+			ctx, resp := mock.NewMockContext(req)
+			c := &ServerInfoController{Controller: web.Controller{Ctx: ctx}}
+			c.GetServerInfo()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("GetServerInfo() code = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+}
@@ -19,15 +19,17 @@ package manager
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/beego/beego/v2/server/web"
-	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/apis"
 	"github.com/kappital/kappital/pkg/constants"
 	"github.com/kappital/kappital/pkg/controller/utils"
 	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
 	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 	"github.com/kappital/kappital/pkg/utils/validation"
 )
 
@@ -44,35 +46,65 @@ func (s *ServiceBindingController) CreateServiceBinding() {
 	var resourceName string
 	defer utils.AuditLog(s.Ctx, "CreateServiceBinding", utils.DeployAction, &resourceName, &err)
 	if err != nil {
-		utils.ReplyJSON(s.Ctx, http.StatusBadRequest, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+		utils.ReplyError(s.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
 		return
 	}
 	resourceName = fmt.Sprintf("Deploy Service [%s] into Cluster [%s]", serviceBody.Service.Name, serviceBody.ClusterID)
-	sb, err := s.resource.GetInternalServiceBinding(serviceBody.Service.Spec.Description.Name, serviceBody.ClusterID)
-	if err != nil {
-		utils.ReplyJSON(s.Ctx, http.StatusInternalServerError,
-			errors.ErrServiceInstall.WrapErrorReasonWith(err.Error()))
+	if err = utils.Authorize(s.Ctx, authorization.Attributes{Verb: authorization.VerbCreate,
+		Cluster: serviceBody.ClusterID, Service: serviceBody.Service.Spec.Description.Name}); err != nil {
 		return
 	}
-	if sb != nil {
-		utils.ReplyJSON(s.Ctx, http.StatusOK, map[string]string{"Name": sb.Name, "ID": sb.ID})
+	sb, err := s.resource.DeployServiceBinding(s.Ctx.Request.Context(), serviceBody)
+	if err != nil {
+		utils.ReplyError(s.Ctx, err)
 		return
 	}
+	utils.ReplyJSON(s.Ctx, http.StatusOK, map[string]string{"Name": sb.Name, "ID": sb.ID})
+}
+
+// UpdateServiceBinding replace the settings of the service binding with the request body
+func (s *ServiceBindingController) UpdateServiceBinding() {
+	s.updateSettings("UpdateServiceBinding")
+}
 
-	subRes, err := transCreationToServiceBinding(serviceBody)
+// PatchServiceBinding edit the settings of the service binding with the JSON merge patch or the JSON patch
+func (s *ServiceBindingController) PatchServiceBinding() {
+	s.updateSettings("PatchServiceBinding")
+}
+
+func (s *ServiceBindingController) updateSettings(traceName string) {
+	serviceBinding := s.GetString(constants.ServiceBindingPathParam)
+	clusterName := s.GetString(constants.ClusterNameQueryParam, apis.DefaultCluster)
+	var err error
+	var resourceName string
+	defer utils.AuditLog(s.Ctx, traceName, utils.UpdateAction, &resourceName, &err)
+	if !utils.ValidString(serviceBinding) || !utils.ValidString(clusterName) {
+		err = utils.ErrIllegalParameters
+		utils.ReplyError(s.Ctx, utils.ErrIllegalParameters)
+		return
+	}
+	resourceName = fmt.Sprintf("Update Service Binding [%s] in Cluster [%s]", serviceBinding, clusterName)
+	if err = utils.Authorize(s.Ctx, authorization.Attributes{Verb: authorization.VerbUpdate, Cluster: clusterName,
+		Service: serviceBinding}); err != nil {
+		return
+	}
+	ifMatch, err := utils.GetIfMatchVersion(s.Ctx)
 	if err != nil {
-		utils.ReplyJSON(s.Ctx, http.StatusBadRequest, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+		utils.ReplyError(s.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
 		return
 	}
-
-	if err = s.resource.CreateServiceBinding(*subRes); err != nil {
-		utils.ReplyJSON(s.Ctx, http.StatusInternalServerError,
-			errors.ErrServiceInstall.WrapErrorReasonWith(err.Error()))
+	settings, version, err := s.resource.UpdateServiceBindingSettings(s.Ctx.Request.Context(), serviceBinding,
+		clusterName, ifMatch, getSettingsUpdate(s.Ctx))
+	if err != nil {
+		if utils.IsResourceVersionConflict(err) {
+			utils.ReplyConflict(s.Ctx, ifMatch, err)
+			return
+		}
+		utils.ReplyError(s.Ctx, err)
 		return
 	}
-
-	klog.Infof("service %s serviceBinding create in cluster %s success.", subRes.Name, subRes.ClusterID)
-	utils.ReplyJSON(s.Ctx, http.StatusOK, map[string]string{"Name": subRes.Name, "ID": subRes.ID})
+	utils.SetETag(s.Ctx, version)
+	utils.ReplyJSON(s.Ctx, http.StatusOK, settings)
 }
 
 // DeleteServiceBinding destroy the service binding from cluster
@@ -84,12 +116,35 @@ func (s *ServiceBindingController) DeleteServiceBinding() {
 	defer utils.AuditLog(s.Ctx, "DeleteServiceBinding", utils.UninstallAction, &resourceName, &err)
 	if !utils.ValidString(serviceBinding) || !utils.ValidString(clusterName) {
 		err = utils.ErrIllegalParameters
-		utils.ReplyJSON(s.Ctx, http.StatusBadRequest, utils.ErrIllegalParameters)
+		utils.ReplyError(s.Ctx, utils.ErrIllegalParameters)
 		return
 	}
 	resourceName = fmt.Sprintf("Uninstall Service Binding [%s] in Cluster [%s]", serviceBinding, clusterName)
-	if err = s.resource.DeleteServiceBinding(serviceBinding, clusterName); err != nil {
-		utils.ReplyJSON(s.Ctx, http.StatusInternalServerError, errors.ErrServiceDelete.WrapErrorReasonWith(err.Error()))
+	if err = utils.Authorize(s.Ctx, authorization.Attributes{Verb: authorization.VerbDelete, Cluster: clusterName,
+		Service: serviceBinding}); err != nil {
+		return
+	}
+	opts, err := getDeleteOptions(s.Ctx)
+	if err == nil {
+		opts.Cascade, err = s.GetBool(constants.CascadeQueryParam, false)
+	}
+	if err != nil {
+		utils.ReplyError(s.Ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	if err = authorizeForce(s.Ctx, opts); err != nil {
+		return
+	}
+	if err = s.resource.DeleteServiceBinding(s.Ctx.Request.Context(), serviceBinding, clusterName, opts); err != nil {
+		if utils.IsResourceVersionConflict(err) {
+			utils.ReplyConflict(s.Ctx, opts.ResourceVersion, err)
+			return
+		}
+		if utils.IsKappError(err) {
+			utils.ReplyError(s.Ctx, err)
+			return
+		}
+		utils.ReplyError(s.Ctx, errors.ErrServiceDelete.WrapErrorReasonWith(err.Error()))
 		return
 	}
 	utils.ReplyJSON(s.Ctx, http.StatusOK, nil)
@@ -103,13 +158,17 @@ func (s *ServiceBindingController) GetServiceBindings() {
 	defer utils.AuditLog(s.Ctx, "GetServiceBindings", utils.QueryAction, &resourceName, &err)
 	if !utils.ValidString(clusterName) {
 		err = utils.ErrIllegalParameters
-		utils.ReplyJSON(s.Ctx, http.StatusBadRequest, utils.ErrIllegalParameters)
+		utils.ReplyError(s.Ctx, utils.ErrIllegalParameters)
 		return
 	}
 	resourceName = fmt.Sprintf("Get Service Binding List from Cluster [%s]", clusterName)
+	if err = utils.Authorize(s.Ctx, authorization.Attributes{Verb: authorization.VerbGet,
+		Cluster: clusterName}); err != nil {
+		return
+	}
 	sis, err := s.resource.GetServiceBindings(clusterName)
 	if err != nil {
-		utils.ReplyJSON(s.Ctx, http.StatusInternalServerError, err)
+		utils.ReplyError(s.Ctx, err)
 		return
 	}
 	utils.ReplyJSON(s.Ctx, http.StatusOK, sis)
@@ -124,19 +183,31 @@ func (s *ServiceBindingController) GetServiceBindingDetail() {
 	defer utils.AuditLog(s.Ctx, "GetServiceBindingDetail", utils.QueryAction, &resourceName, &err)
 	if !utils.ValidString(serviceBinding) || !utils.ValidString(clusterName) {
 		err = utils.ErrIllegalParameters
-		utils.ReplyJSON(s.Ctx, http.StatusBadRequest, utils.ErrIllegalParameters)
+		utils.ReplyError(s.Ctx, utils.ErrIllegalParameters)
 		return
 	}
 	resourceName = fmt.Sprintf("Get Service Binding [%s] from Cluster [%s]", serviceBinding, clusterName)
+	if err = utils.Authorize(s.Ctx, authorization.Attributes{Verb: authorization.VerbGet, Cluster: clusterName,
+		Service: serviceBinding}); err != nil {
+		return
+	}
 	detail, err := validation.ValidBool(s.Ctx.Input.Query(constants.Detail))
 	if err != nil {
-		klog.Warningf("cannot get parameter value of detail, will set it to the false")
+		requestid.Warningf(s.Ctx.Request.Context(), "cannot get parameter value of detail, will set it to the false")
 	}
-	si, err := s.resource.GetServiceBinding(serviceBinding, clusterName, detail)
-	if err != nil || si == nil {
-		err = fmt.Errorf("cannot get the service binding")
-		utils.ReplyJSON(s.Ctx, http.StatusBadRequest, err)
+	si, err := s.resource.GetServiceBinding(s.Ctx.Request.Context(), serviceBinding, clusterName, detail)
+	if err != nil {
+		utils.ReplyError(s.Ctx, err)
+		return
+	}
+	if si == nil {
+		err = errors.ErrNotFound.WrapErrorReasonWith("the service binding %s is not found in cluster %s",
+			serviceBinding, clusterName)
+		utils.ReplyError(s.Ctx, err)
 		return
 	}
+	if version, err := strconv.ParseInt(si.ResourceVersion, 10, 64); err == nil {
+		utils.SetETag(s.Ctx, version)
+	}
 	utils.ReplyJSON(s.Ctx, http.StatusOK, si)
 }
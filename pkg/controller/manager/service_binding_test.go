@@ -36,6 +36,16 @@ func TestServiceBindingController_DeleteServiceBinding(t *testing.T) {
 	})
 }
 
+func TestServiceBindingController_UpdateServiceBinding(t *testing.T) {
+	convey.Convey("Test ServiceBindingController UpdateServiceBinding and PatchServiceBinding", t, func() {
+		testServiceBindingController.Ctx.Input.SetParam(constants.ServiceBindingPathParam, "_")
+		testServiceBindingController.UpdateServiceBinding()
+		testServiceBindingController.Ctx.Input.SetParam(constants.ServiceBindingPathParam, "xx")
+		testServiceBindingController.Ctx.Input.SetParam(constants.ClusterNameQueryParam, "_")
+		testServiceBindingController.PatchServiceBinding()
+	})
+}
+
 func TestServiceBindingController_GetServiceBindings(t *testing.T) {
 	convey.Convey("Test ServiceBindingController GetServiceBindings", t, func() {
 		testServiceBindingController.Ctx.Input.SetParam(constants.ClusterNameQueryParam, "_")
@@ -17,71 +17,184 @@
 package utils
 
 import (
+	stdcontext "context"
+	stderrors "errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web/context"
-	"k8s.io/klog/v2"
 
+	"github.com/kappital/kappital/pkg/models"
 	"github.com/kappital/kappital/pkg/utils/audit"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
 	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 var (
 	// ErrIllegalParameters pass in from url or body struct
-	ErrIllegalParameters = fmt.Errorf("illegal parameters")
+	ErrIllegalParameters = errors.ErrIllegalParameters
 )
 
-type action string
+// Action the type of the audited operation
+type Action string
 
 const (
 	// QueryAction of the manager which search service packages, service instances or service bindings
-	QueryAction action = "Query"
+	QueryAction Action = "Query"
 	// DeployAction of manager which deploy service binding or service instance
-	DeployAction action = "Deploy"
+	DeployAction Action = "Deploy"
 	// UninstallAction of manager which uninstall service binding or service instance
-	UninstallAction action = "Uninstall"
+	UninstallAction Action = "Uninstall"
+	// UpdateAction of manager which update the settings of service binding or service instance
+	UpdateAction Action = "Update"
 )
 
 // AuditLog write the audit log from the defer method, and the detail dependents on the error.
 // Because only use the kappctl binary tool or CURL APIs to visit manager,
 // thus, it only offers APICallType of trace.
-func AuditLog(ctx *context.Context, traceName string, resourceType action, name *string, err *error) {
-	if (*err) != nil {
-		audit.Error(audit.AuditLogInfo{
-			SourceIP:     ctx.Request.RemoteAddr,
-			ResourceType: string(resourceType),
-			ResourceName: *name,
-			TraceName:    traceName,
-			TraceType:    audit.APICallType,
-			Message:      (*err).Error(),
-		})
-	} else {
-		audit.Info(audit.AuditLogInfo{
-			SourceIP:     ctx.Request.RemoteAddr,
-			ResourceType: string(resourceType),
-			ResourceName: *name,
-			TraceName:    traceName,
-			TraceType:    audit.APICallType,
-			Message:      "success",
-		})
+func AuditLog(ctx *context.Context, traceName string, resourceType Action, name *string, err *error) {
+	Audit(ctx.Request.Context(), ctx.Request.RemoteAddr, traceName, resourceType, *name, *err)
+}
+
+// Audit write the audit log of the call from the source ip with the principal and the request id in the ctx, it is
+// shared by the REST and gRPC APIs
+func Audit(ctx stdcontext.Context, sourceIP, traceName string, resourceType Action, name string, err error) {
+	principal, _ := authentication.PrincipalFrom(ctx)
+	info := audit.AuditLogInfo{
+		SourceIP:     sourceIP,
+		Principal:    principal.Name,
+		AuthMethod:   principal.Method,
+		RequestID:    requestid.FromContext(ctx),
+		ResourceType: string(resourceType),
+		ResourceName: name,
+		TraceName:    traceName,
+		TraceType:    audit.APICallType,
 	}
+	if err != nil {
+		info.Message = err.Error()
+		audit.Error(info)
+		return
+	}
+	info.Message = "success"
+	audit.Info(info)
 }
 
-// ReplyJSON sends json reply to http client
+// ReplyJSON sends json reply to http client, the error is replied as the ErrorResp
 func ReplyJSON(ctx *context.Context, stateCode int, resp interface{}) {
-	var msg interface{}
-	if resp != nil {
-		switch resp.(type) {
-		case error, errors.KappError:
-			msg = resp.(error).Error()
-		default:
-			msg = resp
-		}
+	if err, ok := resp.(error); ok {
+		resp = NewErrorResp(ctx, err)
 	}
-
 	ctx.Output.SetStatus(stateCode)
-	err := ctx.Output.JSON(msg, false, false)
+	if err := ctx.Output.JSON(resp, false, false); err != nil {
+		requestid.Errorf(ctx.Request.Context(), "failed to write json resp, err: %v", err)
+	}
+}
+
+// ReplyError sends the ErrorResp to http client, and the http code is decided by the type of the error
+func ReplyError(ctx *context.Context, err error) {
+	kappErr := ToKappError(err)
+	ReplyJSON(ctx, kappErr.GetHTTPCode(), kappErr)
+}
+
+// IsKappError does the error carry the KappError, which is replied as it is
+func IsKappError(err error) bool {
+	var kappErr errors.KappError
+	return stderrors.As(err, &kappErr)
+}
+
+// ToKappError get the KappError of the error, the raw errors are mapped by their types and the unknown ones are
+// the internal errors
+func ToKappError(err error) errors.KappError {
+	var kappErr errors.KappError
+	switch {
+	case stderrors.As(err, &kappErr):
+		return kappErr
+	case IsResourceVersionConflict(err):
+		return errors.ErrResourceConflict.WrapErrorReasonWith(err.Error())
+	case stderrors.Is(err, orm.ErrNoRows):
+		return errors.ErrNotFound.WrapErrorReasonWith(err.Error())
+	case authorization.IsForbidden(err):
+		return errors.ErrForbidden.WrapErrorReasonWith(err.Error())
+	case stderrors.Is(err, authentication.ErrUnauthenticated), stderrors.Is(err, authentication.ErrTokenNotFound):
+		return errors.ErrUnauthorized.WrapErrorReasonWith(err.Error())
+	default:
+		return errors.ErrInternal.WrapErrorReasonWith(err.Error())
+	}
+}
+
+// RequestID get the id of the request which is replied in the X-Request-ID header and the ErrorResp
+func RequestID(ctx *context.Context) string {
+	return requestid.FromContext(ctx.Request.Context())
+}
+
+// NewErrorResp get the ErrorResp of the error with the request id
+func NewErrorResp(ctx *context.Context, err error) errors.ErrorResp {
+	resp := ToKappError(err).GetResp()
+	resp.RequestID = RequestID(ctx)
+	return resp
+}
+
+// GetIfMatchVersion get the expected resource version from the If-Match header. The nil will be returned if the
+// header is not set or is "*".
+func GetIfMatchVersion(ctx *context.Context) (*int64, error) {
+	value := strings.TrimSpace(ctx.Input.Header("If-Match"))
+	if len(value) == 0 || value == "*" {
+		return nil, nil
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), "\"")
+	version, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		klog.Errorf("failed to write json resp, err: %v", err)
+		return nil, fmt.Errorf("the If-Match header %s is not a valid resource version", value)
+	}
+	return &version, nil
+}
+
+// SetETag set the resource version as the ETag header of the response
+func SetETag(ctx *context.Context, version int64) {
+	ctx.Output.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// Authorize check whether the caller is allowed to do the request by the authorization policy. The 401 or 403 is
+// replied and the error is returned if not, and the error should be recorded by the audit log of the action.
+func Authorize(ctx *context.Context, attrs authorization.Attributes) error {
+	if err := AuthorizeContext(ctx.Request.Context(), attrs); err != nil {
+		requestid.Warningf(ctx.Request.Context(), "reject the request %s %s, err: %v", ctx.Input.Method(),
+			ctx.Input.URI(), err)
+		ReplyError(ctx, err)
+		return err
+	}
+	return nil
+}
+
+// AuthorizeContext check whether the principal in the ctx is allowed to do the request by the authorization policy,
+// the ErrUnauthenticated is returned if the principal is unknown, it is shared by the REST and gRPC APIs
+func AuthorizeContext(ctx stdcontext.Context, attrs authorization.Attributes) error {
+	if !authorization.Enabled() {
+		return nil
+	}
+	principal, ok := authentication.PrincipalFrom(ctx)
+	if !ok {
+		return authentication.ErrUnauthenticated
+	}
+	return authorization.Authorize(principal, attrs)
+}
+
+// IsResourceVersionConflict does the error is caused by the resource version conflict
+func IsResourceVersionConflict(err error) bool {
+	return stderrors.Is(err, models.ErrResourceVersionConflict)
+}
+
+// ReplyConflict sends the resource version conflict to http client. The 412 will be used if the request has the
+// If-Match precondition, otherwise the 409 will be used.
+func ReplyConflict(ctx *context.Context, ifMatch *int64, err error) {
+	code := http.StatusConflict
+	if ifMatch != nil {
+		code = http.StatusPreconditionFailed
 	}
+	ReplyJSON(ctx, code, errors.ErrResourceConflict.WrapErrorReasonWith(err.Error()))
 }
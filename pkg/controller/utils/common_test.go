@@ -0,0 +1,52 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+func TestToKappError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "kapp error", err: errors.ErrServiceParam.WrapErrorReasonWith("x"), wantCode: http.StatusBadRequest},
+		{name: "resource version conflict", err: fmt.Errorf("update failed, err: %w",
+			models.ErrResourceVersionConflict), wantCode: http.StatusConflict},
+		{name: "not found", err: fmt.Errorf("the instance is not found, err: %w", orm.ErrNoRows),
+			wantCode: http.StatusNotFound},
+		{name: "forbidden", err: &authorization.ForbiddenError{}, wantCode: http.StatusForbidden},
+		{name: "unknown error", err: fmt.Errorf("database is locked"), wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToKappError(tt.err); got.GetHTTPCode() != tt.wantCode || len(got.GetResp().Reason) == 0 {
+				t.Errorf("ToKappError() = %v with http code %d, want %d", got, got.GetHTTPCode(), tt.wantCode)
+			}
+		})
+	}
+}
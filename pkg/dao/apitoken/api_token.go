@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apitoken
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kappital/kappital/pkg/apis/internals"
+	"github.com/kappital/kappital/pkg/models"
+	mo "github.com/kappital/kappital/pkg/models/operation"
+)
+
+// APIToken the dao layer of the static API token for database CRUD
+type APIToken struct {
+	db mo.APITokenOperation
+}
+
+// WithContext get a copy of the dao, the database calls of which are traced as the children of the span in ctx
+func (a APIToken) WithContext(ctx context.Context) APIToken {
+	a.db = a.db.WithContext(ctx)
+	return a
+}
+
+// Create insert the API token to the database
+func (a APIToken) Create(obj interface{}) error {
+	token, ok := obj.(internals.APIToken)
+	if !ok {
+		return fmt.Errorf("obj type is not APIToken")
+	}
+	return a.db.Insert(transAPIToken2Model(token))
+}
+
+// Get the API token from database and filter by cols
+func (a APIToken) Get(cols map[string]string) (interface{}, error) {
+	result, err := a.db.Get(cols)
+	if err != nil {
+		return nil, err
+	}
+	return transModel2APIToken(result.(models.APITokenModel)), nil
+}
+
+// GetList of the API tokens from database and filter by cols
+func (a APIToken) GetList(cols map[string]string) (interface{}, error) {
+	result, err := a.db.GetList(cols)
+	if err != nil {
+		return nil, err
+	}
+	items := result.([]models.APITokenModel)
+	tokens := make([]internals.APIToken, 0, len(items))
+	for _, item := range items {
+		tokens = append(tokens, transModel2APIToken(item))
+	}
+	return tokens, nil
+}
+
+// Delete the API token by its id or name
+func (a APIToken) Delete(obj interface{}) error {
+	token, ok := obj.(internals.APIToken)
+	if !ok {
+		return fmt.Errorf("obj type is not APIToken")
+	}
+	return a.db.Delete(transAPIToken2Model(token))
+}
+
+func transAPIToken2Model(token internals.APIToken) models.APITokenModel {
+	return models.APITokenModel{
+		ID:         token.ID,
+		Name:       token.Name,
+		User:       token.User,
+		Groups:     strings.Join(token.Groups, ","),
+		TokenHash:  token.TokenHash,
+		CreateTime: token.CreateTime,
+		ExpireTime: token.ExpireTime,
+	}
+}
+
+func transModel2APIToken(model models.APITokenModel) internals.APIToken {
+	token := internals.APIToken{
+		ID:         model.ID,
+		Name:       model.Name,
+		User:       model.User,
+		TokenHash:  model.TokenHash,
+		CreateTime: model.CreateTime,
+		ExpireTime: model.ExpireTime,
+	}
+	if len(model.Groups) > 0 {
+		token.Groups = strings.Split(model.Groups, ",")
+	}
+	return token
+}
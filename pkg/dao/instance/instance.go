@@ -17,13 +17,16 @@
 package instance
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/beego/beego/v2/client/orm"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/kappital/kappital/pkg/apis"
 	"github.com/kappital/kappital/pkg/apis/internals"
 	"github.com/kappital/kappital/pkg/models"
 	mo "github.com/kappital/kappital/pkg/models/operation"
@@ -57,6 +60,13 @@ type Instance struct {
 	instance mo.InstanceOperation
 }
 
+// WithContext get a copy of the dao, the database calls of which are traced as the children of the span in ctx
+func (i Instance) WithContext(ctx context.Context) Instance {
+	i.binding = i.binding.WithContext(ctx)
+	i.instance = i.instance.WithContext(ctx)
+	return i
+}
+
 // Create Insert the ServiceInstance into database
 // params is a map of the necessary values, such as ServiceBinding's name, and the ClusterId
 func (i Instance) Create(obj interface{}, params map[string]string) error {
@@ -174,21 +184,43 @@ func (i Instance) GetListByStatusSets(status sets.String) (interface{}, error) {
 }
 
 // Update the instance to the database with cols
+// If the obj is a pointer, the new resource version will be set back to it.
 func (i Instance) Update(obj interface{}, cols ...string) error {
-	internal, ok := obj.(internals.ServiceInstance)
+	internal, ok := toServiceInstancePtr(obj)
+	if !ok {
+		return fmt.Errorf("obj type is not ServiceInstance")
+	}
+	instance, err := transformInstanceToModel(*internal)
+	if err != nil {
+		return err
+	}
+	if err = i.instance.Update(&instance, cols...); err != nil {
+		return err
+	}
+	internal.ResourceVersion = instance.ResourceVersion
+	return nil
+}
+
+// UpdateTx update the instance with the transaction, the resource version will be checked and increased
+func (i Instance) UpdateTx(obj interface{}, tx orm.TxOrmer, cols ...string) error {
+	internal, ok := toServiceInstancePtr(obj)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceInstance")
 	}
-	instance, err := transformInstanceToModel(internal)
+	instance, err := transformInstanceToModel(*internal)
 	if err != nil {
 		return err
 	}
-	return i.instance.Update(instance, cols...)
+	if err = i.instance.UpdateTx(&instance, tx, cols...); err != nil {
+		return err
+	}
+	internal.ResourceVersion = instance.ResourceVersion
+	return nil
 }
 
 // UpdateStatusMsg update the status massage for instance
 func (i Instance) UpdateStatusMsg(obj interface{}, status, msg string) error {
-	instance, ok := obj.(internals.ServiceInstance)
+	instance, ok := toServiceInstancePtr(obj)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceInstance")
 	}
@@ -212,12 +244,16 @@ func (i Instance) UpdateStatusMsg(obj interface{}, status, msg string) error {
 		instance.Message += fmt.Sprintf(":%s", innerMsg)
 	}
 
-	ins, err := transformInstanceToModel(instance)
+	ins, err := transformInstanceToModel(*instance)
 	if err != nil {
 		return err
 	}
 
-	return i.instance.Update(ins, "install_state", "status", "process_time", "error_message")
+	if err = i.instance.Update(&ins, "install_state", "status", "process_time", "error_message"); err != nil {
+		return err
+	}
+	instance.ResourceVersion = ins.ResourceVersion
+	return nil
 }
 
 // Delete the instance
@@ -239,6 +275,14 @@ func transformInstanceToModel(ins internals.ServiceInstance) (models.InstanceMod
 	if err != nil {
 		return models.InstanceModel{}, err
 	}
+	timeouts, err := json.Marshal(ins.Timeouts)
+	if err != nil {
+		return models.InstanceModel{}, err
+	}
+	initiator, err := json.Marshal(ins.Initiator)
+	if err != nil {
+		return models.InstanceModel{}, err
+	}
 
 	return models.InstanceModel{
 		ID:                  ins.ID,
@@ -260,6 +304,12 @@ func transformInstanceToModel(ins internals.ServiceInstance) (models.InstanceMod
 		ProcessTime:         ins.ProcessTime,
 		UpdateTime:          ins.UpdateTime,
 		InstallState:        string(installPhase),
+		Timeouts:            string(timeouts),
+		Initiator:           string(initiator),
+		ResourceVersion:     ins.ResourceVersion,
+		DeletionProtection:  ins.DeletionProtection,
+		ForceDelete:         ins.ForceDelete,
+		RemoveFinalizers:    ins.RemoveFinalizers,
 	}, nil
 }
 
@@ -270,6 +320,20 @@ func transModel2Instance(instance models.InstanceModel) (internals.ServiceInstan
 			return internals.ServiceInstance{}, err
 		}
 	}
+	var timeouts apis.Timeouts
+	// the records which are created by the old version do not have the timeouts
+	if instance.Timeouts != "" {
+		if err := json.Unmarshal([]byte(instance.Timeouts), &timeouts); err != nil {
+			return internals.ServiceInstance{}, err
+		}
+	}
+	var initiator apis.Initiator
+	// the records which are created by the old version do not have the initiator
+	if instance.Initiator != "" {
+		if err := json.Unmarshal([]byte(instance.Initiator), &initiator); err != nil {
+			return internals.ServiceInstance{}, err
+		}
+	}
 
 	resourceOperation := mo.ResourceOperation{}
 	obj, err := resourceOperation.GetByPrimaryKey(instance.Resource.ID)
@@ -301,9 +365,26 @@ func transModel2Instance(instance models.InstanceModel) (internals.ServiceInstan
 		UpdateTime:         instance.UpdateTime,
 		ProcessTime:        instance.ProcessTime,
 		InstallState:       installPhase,
+		Timeouts:           timeouts,
+		Initiator:          initiator,
+		ResourceVersion:    instance.ResourceVersion,
+		DeletionProtection: instance.DeletionProtection,
+		ForceDelete:        instance.ForceDelete,
+		RemoveFinalizers:   instance.RemoveFinalizers,
 	}, nil
 }
 
+func toServiceInstancePtr(obj interface{}) (*internals.ServiceInstance, bool) {
+	switch instance := obj.(type) {
+	case internals.ServiceInstance:
+		return &instance, true
+	case *internals.ServiceInstance:
+		return instance, instance != nil
+	default:
+		return nil, false
+	}
+}
+
 func transModelSlice2InstanceSlice(instances []models.InstanceModel) ([]internals.ServiceInstance, error) {
 	result := make([]internals.ServiceInstance, 0, len(instances))
 	for _, instance := range instances {
@@ -17,6 +17,7 @@
 package resource
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/beego/beego/v2/client/orm"
@@ -33,6 +34,13 @@ type Resource struct {
 	binding  mo.ServiceBindingOperation
 }
 
+// WithContext get a copy of the dao, the database calls of which are traced as the children of the span in ctx
+func (r Resource) WithContext(ctx context.Context) Resource {
+	r.resource = r.resource.WithContext(ctx)
+	r.binding = r.binding.WithContext(ctx)
+	return r
+}
+
 // Create insert a data record to the database
 func (r Resource) Create(obj interface{}, params map[string]string) error {
 	// get the whole service binding object
@@ -17,11 +17,13 @@
 package servicebinding
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
 
+	"github.com/beego/beego/v2/client/orm"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
@@ -38,6 +40,12 @@ type ServiceBinding struct {
 	db mo.ServiceBindingOperation
 }
 
+// WithContext get a copy of the dao, the database calls of which are traced as the children of the span in ctx
+func (s ServiceBinding) WithContext(ctx context.Context) ServiceBinding {
+	s.db = s.db.WithContext(ctx)
+	return s
+}
+
 // Create insert a data record to the database
 func (s ServiceBinding) Create(obj interface{}, _ map[string]string) error {
 	serviceBinding, ok := obj.(internals.ServiceBinding)
@@ -147,34 +155,61 @@ func (s ServiceBinding) GetListByStatusSets(status sets.String) (interface{}, er
 }
 
 // Update the service binding to the database with cols
+// If the obj is a pointer, the new resource version will be set back to it.
 func (s ServiceBinding) Update(obj interface{}, cols ...string) error {
-	binding, ok := obj.(internals.ServiceBinding)
+	binding, ok := toServiceBindingPtr(obj)
 	if !ok {
 		klog.Errorf("obj type is not ServiceBindingModel, actual: %s", reflect.TypeOf(obj).Name())
 		return fmt.Errorf("obj type is not ServiceBindingModel")
 	}
-	bindingModel, err := transServiceBinding2Model(binding)
+	bindingModel, err := transServiceBinding2Model(*binding)
 	if err != nil {
 		return err
 	}
 
-	return s.db.Update(bindingModel, cols...)
+	if err = s.db.Update(&bindingModel, cols...); err != nil {
+		return err
+	}
+	binding.ResourceVersion = bindingModel.ResourceVersion
+	return nil
+}
+
+// UpdateTx update the service binding with the transaction, the resource version will be checked and increased
+func (s ServiceBinding) UpdateTx(obj interface{}, tx orm.TxOrmer, cols ...string) error {
+	binding, ok := toServiceBindingPtr(obj)
+	if !ok {
+		return fmt.Errorf("obj type is not ServiceBindingModel")
+	}
+	bindingModel, err := transServiceBinding2Model(*binding)
+	if err != nil {
+		return err
+	}
+
+	if err = s.db.UpdateTx(&bindingModel, tx, cols...); err != nil {
+		return err
+	}
+	binding.ResourceVersion = bindingModel.ResourceVersion
+	return nil
 }
 
 // UpdateStatusMsg update the status massage for service binding
 func (s ServiceBinding) UpdateStatusMsg(obj interface{}, status, msg string) error {
-	binding, ok := obj.(internals.ServiceBinding)
+	binding, ok := toServiceBindingPtr(obj)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceBindingModel")
 	}
 	binding.Status = status
 	binding.Message = msg
-	bindingModel, err := transServiceBinding2Model(binding)
+	bindingModel, err := transServiceBinding2Model(*binding)
 	if err != nil {
 		return err
 	}
 
-	return s.db.Update(bindingModel)
+	if err = s.db.Update(&bindingModel); err != nil {
+		return err
+	}
+	binding.ResourceVersion = bindingModel.ResourceVersion
+	return nil
 }
 
 // Delete the service binding
@@ -194,17 +229,22 @@ func (s ServiceBinding) Delete(obj interface{}) error {
 func transServiceBinding2Model(serviceBinding internals.ServiceBinding) (models.ServiceBindingModel, error) {
 	now := time.Now().UTC()
 	binding := models.ServiceBindingModel{
-		ID:           serviceBinding.ID,
-		Name:         serviceBinding.Name,
-		Namespace:    serviceBinding.Namespace,
-		ServiceName:  serviceBinding.ServiceName,
-		Version:      serviceBinding.Version,
-		ClusterName:  serviceBinding.ClusterName,
-		ServiceID:    serviceBinding.ServiceID,
-		Status:       serviceBinding.Status,
-		ErrorMessage: serviceBinding.Message,
-		CreateTime:   now,
-		UpdateTime:   now,
+		ID:                 serviceBinding.ID,
+		Name:               serviceBinding.Name,
+		Namespace:          serviceBinding.Namespace,
+		ServiceName:        serviceBinding.ServiceName,
+		Version:            serviceBinding.Version,
+		ClusterName:        serviceBinding.ClusterName,
+		ServiceID:          serviceBinding.ServiceID,
+		Status:             serviceBinding.Status,
+		ErrorMessage:       serviceBinding.Message,
+		ProcessTime:        serviceBinding.ProcessTime,
+		CreateTime:         now,
+		UpdateTime:         now,
+		ResourceVersion:    serviceBinding.ResourceVersion,
+		DeletionProtection: serviceBinding.DeletionProtection,
+		ForceDelete:        serviceBinding.ForceDelete,
+		RemoveFinalizers:   serviceBinding.RemoveFinalizers,
 	}
 
 	if len(serviceBinding.Status) == 0 {
@@ -231,27 +271,43 @@ func transServiceBinding2Model(serviceBinding internals.ServiceBinding) (models.
 		return models.ServiceBindingModel{}, err
 	}
 
+	timeoutsByte, err := json.Marshal(serviceBinding.Timeouts)
+	if err != nil {
+		return models.ServiceBindingModel{}, err
+	}
+
+	initiatorByte, err := json.Marshal(serviceBinding.Initiator)
+	if err != nil {
+		return models.ServiceBindingModel{}, err
+	}
+
 	binding.Workloads = string(workloadByte)
 	binding.Permissions = string(permissions)
 	binding.CapabilityPlugin = string(capabilityPluginByte)
 	binding.CustomResourceDefinition = string(crdsByte)
+	binding.Timeouts = string(timeoutsByte)
+	binding.Initiator = string(initiatorByte)
 
 	return binding, nil
 }
 
 func transModel2ServiceBinding(model models.ServiceBindingModel) (internals.ServiceBinding, error) {
 	serviceBinding := internals.ServiceBinding{
-		ID:          model.ID,
-		Name:        model.Name,
-		Version:     model.Version,
-		Namespace:   model.Namespace,
-		ServiceName: model.ServiceName,
-		ServiceID:   model.ServiceID,
-		ClusterName: model.ClusterName,
-		Status:      model.Status,
-		Message:     model.ErrorMessage,
-		ProcessTime: model.ProcessTime,
-		UpdateTime:  model.UpdateTime,
+		ID:                 model.ID,
+		Name:               model.Name,
+		Version:            model.Version,
+		Namespace:          model.Namespace,
+		ServiceName:        model.ServiceName,
+		ServiceID:          model.ServiceID,
+		ClusterName:        model.ClusterName,
+		Status:             model.Status,
+		Message:            model.ErrorMessage,
+		ProcessTime:        model.ProcessTime,
+		UpdateTime:         model.UpdateTime,
+		ResourceVersion:    model.ResourceVersion,
+		DeletionProtection: model.DeletionProtection,
+		ForceDelete:        model.ForceDelete,
+		RemoveFinalizers:   model.RemoveFinalizers,
 	}
 
 	var crd []string
@@ -282,9 +338,36 @@ func transModel2ServiceBinding(model models.ServiceBindingModel) (internals.Serv
 	}
 	serviceBinding.CapabilityPlugin = capabilityPlugin
 
+	// the records which are created by the old version do not have the timeouts
+	if len(model.Timeouts) > 0 {
+		if err := json.Unmarshal([]byte(model.Timeouts), &serviceBinding.Timeouts); err != nil {
+			klog.Errorf("json Unmarshal string to timeouts struct failed, err: %s", err)
+			return internals.ServiceBinding{}, err
+		}
+	}
+
+	// the records which are created by the old version do not have the initiator
+	if len(model.Initiator) > 0 {
+		if err := json.Unmarshal([]byte(model.Initiator), &serviceBinding.Initiator); err != nil {
+			klog.Errorf("json Unmarshal string to initiator struct failed, err: %s", err)
+			return internals.ServiceBinding{}, err
+		}
+	}
+
 	return serviceBinding, nil
 }
 
+func toServiceBindingPtr(obj interface{}) (*internals.ServiceBinding, bool) {
+	switch binding := obj.(type) {
+	case internals.ServiceBinding:
+		return &binding, true
+	case *internals.ServiceBinding:
+		return binding, binding != nil
+	default:
+		return nil, false
+	}
+}
+
 func transModelSlice2ServiceBindingSlice(bindings []models.ServiceBindingModel) ([]internals.ServiceBinding, error) {
 	result := make([]internals.ServiceBinding, 0, len(bindings))
 	for _, binding := range bindings {
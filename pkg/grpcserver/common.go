@@ -0,0 +1,142 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/patch"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+)
+
+// auditLog write the audit log from the defer method as the one of the REST API
+func auditLog(ctx context.Context, traceName string, action utils.Action, name *string, err *error) {
+	utils.Audit(ctx, sourceAddr(ctx), traceName, action, *name, *err)
+}
+
+// authorize check whether the principal of the call is allowed by the authorization policy
+func authorize(ctx context.Context, traceName string, attrs authorization.Attributes) error {
+	if err := utils.AuthorizeContext(ctx, attrs); err != nil {
+		requestid.Warningf(ctx, "reject the call %s, err: %v", traceName, err)
+		return err
+	}
+	return nil
+}
+
+// defaultString get the value, or the default value if it is empty, as the optional query parameters of the REST API
+func defaultString(value, defaultValue string) string {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// validStrings does all names are valid
+func validStrings(names ...string) bool {
+	for _, name := range names {
+		if !utils.ValidString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeCreation decode the ServiceInstanceCreation in JSON and validate it, as the body of the REST API
+func decodeCreation(data []byte) (*instancev1alpha1.ServiceInstanceCreation, error) {
+	creation := &instancev1alpha1.ServiceInstanceCreation{}
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, creation); err != nil {
+			return nil, err
+		}
+	}
+	if err := creation.Validate(); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// deleteOptions get the delete options with the expected resource version, the delete timeout and the force options
+func deleteOptions(resourceVersion *int64, timeout string, force, removeFinalizers bool) (resource.DeleteOptions,
+	error) {
+	if err := (apis.Timeouts{DeleteTimeout: timeout}).Validate(); err != nil {
+		return resource.DeleteOptions{}, errors.ErrServiceParam.WrapErrorReasonWith(err.Error())
+	}
+	opts := resource.DeleteOptions{ResourceVersion: resourceVersion, Timeout: timeout, Force: force,
+		RemoveFinalizers: removeFinalizers}
+	if err := opts.Validate(); err != nil {
+		return resource.DeleteOptions{}, errors.ErrServiceParam.WrapErrorReasonWith(err.Error())
+	}
+	return opts, nil
+}
+
+// authorizeForce check the principal is the admin if the records are deleted by force
+func authorizeForce(ctx context.Context, traceName string, opts resource.DeleteOptions) error {
+	if !opts.Force {
+		return nil
+	}
+	return authorize(ctx, traceName, authorization.Attributes{Verb: authorization.VerbAdmin})
+}
+
+// settingsUpdate get the update which replaces the settings with the whole settings, or applies the patch of the
+// content type. The body is validated against the settings before the update, as the PUT and PATCH of the REST API.
+func settingsUpdate(settings *v1alpha1.Settings, contentType string, body []byte) (resource.UpdateSettings, error) {
+	replace := settings != nil
+	var err error
+	switch {
+	case replace:
+		contentType = patch.JSONType
+		if body, err = json.Marshal(toSettings(settings)); err == nil {
+			err = patch.ValidateDocument(body, apis.Settings{})
+		}
+	case len(contentType) == 0:
+		return nil, errors.ErrInvalidBody.WrapErrorReasonWith("one of the settings and the patches must be set")
+	default:
+		err = patch.ValidatePatch(contentType, body, apis.Settings{})
+	}
+	if err != nil {
+		return nil, errors.ErrInvalidBody.WrapErrorReasonWith(err.Error())
+	}
+	return resource.NewSettingsUpdate(replace, contentType, body), nil
+}
+
+func toSettings(settings *v1alpha1.Settings) apis.Settings {
+	timeouts := settings.GetTimeouts()
+	return apis.Settings{Timeouts: apis.Timeouts{
+		InstallTimeout: timeouts.GetInstallTimeout(),
+		UpgradeTimeout: timeouts.GetUpgradeTimeout(),
+		DeleteTimeout:  timeouts.GetDeleteTimeout(),
+	}, DeletionProtection: settings.GetDeletionProtection()}
+}
+
+func fromSettings(settings apis.Settings, resourceVersion int64) *v1alpha1.UpdateSettingsResponse {
+	return &v1alpha1.UpdateSettingsResponse{
+		Settings: &v1alpha1.Settings{Timeouts: &v1alpha1.Timeouts{
+			InstallTimeout: settings.Timeouts.InstallTimeout,
+			UpgradeTimeout: settings.Timeouts.UpgradeTimeout,
+			DeleteTimeout:  settings.Timeouts.DeleteTimeout,
+		}, DeletionProtection: settings.DeletionProtection},
+		ResourceVersion: resourceVersion,
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"testing"
+
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	"github.com/kappital/kappital/pkg/utils/patch"
+)
+
+func Test_settingsUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    *v1alpha1.Settings
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{name: "whole settings", settings: &v1alpha1.Settings{Timeouts: &v1alpha1.Timeouts{InstallTimeout: "10m"}}},
+		{name: "invalid settings", settings: &v1alpha1.Settings{Timeouts: &v1alpha1.Timeouts{InstallTimeout: "abc"}},
+			wantErr: true},
+		{name: "merge patch", contentType: patch.MergePatchType, body: `{"timeouts":{"deleteTimeout":"5m"}}`},
+		{name: "json patch of the deletion protection", contentType: patch.JSONPatchType,
+			body: `[{"op":"replace","path":"/deletionProtection","value":true}]`},
+		{name: "json patch out of the settings", contentType: patch.JSONPatchType,
+			body: `[{"op":"add","path":"/unknown","value":"1"}]`, wantErr: true},
+		{name: "nothing is set", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := settingsUpdate(tt.settings, tt.contentType, []byte(tt.body))
+			if (err != nil) != tt.wantErr || (err == nil && got == nil) {
+				t.Errorf("settingsUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_deleteOptions(t *testing.T) {
+	rv := int64(3)
+	got, err := deleteOptions(&rv, "5m", true, true)
+	if err != nil || got.Timeout != "5m" || *got.ResourceVersion != rv || !got.Force || !got.RemoveFinalizers {
+		t.Errorf("deleteOptions() = %+v, %v", got, err)
+	}
+	if _, err = deleteOptions(nil, "abc", false, false); err == nil {
+		t.Errorf("deleteOptions() error = nil, want the invalid timeout")
+	}
+	if _, err = deleteOptions(nil, "", false, true); err == nil {
+		t.Errorf("deleteOptions() error = nil, want the finalizers are removed without force")
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+)
+
+// errorDomain the domain of the ErrorInfo details, the reasons of the domain are the error codes of the manager
+const errorDomain = "manager.kappital.io"
+
+// httpCodes the gRPC codes of the http codes of the KappErrors
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusMethodNotAllowed:      codes.Unimplemented,
+	http.StatusConflict:              codes.Aborted,
+	http.StatusPreconditionFailed:    codes.Aborted,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+// toStatus get the status error of the error. The code is mapped from the http code of its KappError, and the error
+// code, the reason and the request id are carried by the ErrorInfo detail, thus the clients can handle the errors as
+// the ones of the REST API. The details are appended after the ErrorInfo.
+func toStatus(ctx context.Context, err error, details ...proto.Message) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	kappErr := utils.ToKappError(err)
+	resp := kappErr.GetResp()
+	code, ok := httpCodes[kappErr.GetHTTPCode()]
+	if !ok {
+		code = codes.Unknown
+	}
+	msg := resp.Message
+	if len(resp.Reason) > 0 {
+		msg = fmt.Sprintf("%s: %s", resp.Message, resp.Reason)
+	}
+	info := &errdetails.ErrorInfo{Reason: resp.ErrorCode, Domain: errorDomain, Metadata: map[string]string{}}
+	if len(resp.Reason) > 0 {
+		info.Metadata["reason"] = resp.Reason
+	}
+	if id := requestid.FromContext(ctx); len(id) > 0 {
+		info.Metadata["requestId"] = id
+	}
+	st, detailErr := status.New(code, msg).WithDetails(append([]proto.Message{info}, details...)...)
+	if detailErr != nil {
+		requestid.Errorf(ctx, "cannot add the details to the status, err: %v", detailErr)
+		return status.Error(code, msg)
+	}
+	return st.Err()
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+)
+
+func Test_toStatus(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "test-id")
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{name: "not found", err: errors.ErrNotFound.WrapErrorReasonWith("the redis is not found"),
+			wantCode: codes.NotFound, wantReason: errors.ErrNotFound.GetResp().ErrorCode},
+		{name: "conflict", err: errors.ErrResourceConflict, wantCode: codes.Aborted,
+			wantReason: errors.ErrResourceConflict.GetResp().ErrorCode},
+		{name: "too many requests", err: errors.ErrTooManyRequests, wantCode: codes.ResourceExhausted,
+			wantReason: errors.ErrTooManyRequests.GetResp().ErrorCode},
+		{name: "unknown error is internal", err: fmt.Errorf("unknown"), wantCode: codes.Internal,
+			wantReason: errors.ErrInternal.GetResp().ErrorCode},
+		{name: "canceled", err: context.Canceled, wantCode: codes.Canceled},
+		{name: "status", err: status.Error(codes.Unavailable, "stopping"), wantCode: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(toStatus(ctx, tt.err))
+			if st.Code() != tt.wantCode {
+				t.Errorf("toStatus() code = %v, want %v", st.Code(), tt.wantCode)
+			}
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if i, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = i
+				}
+			}
+			if len(tt.wantReason) == 0 {
+				if info != nil {
+					t.Errorf("toStatus() error info = %v, want nil", info)
+				}
+				return
+			}
+			if info == nil || info.GetReason() != tt.wantReason || info.GetDomain() != errorDomain ||
+				info.GetMetadata()["requestId"] != "test-id" {
+				t.Errorf("toStatus() error info = %v, want reason %s", info, tt.wantReason)
+			}
+		})
+	}
+	if err := toStatus(ctx, nil); err != nil {
+		t.Errorf("toStatus() = %v, want nil", err)
+	}
+}
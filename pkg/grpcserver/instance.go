@@ -0,0 +1,260 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/patch"
+)
+
+// instanceServer the gRPC service of the instances, it does the same as the InstanceController
+type instanceServer struct {
+	v1alpha1.UnimplementedInstanceServiceServer
+	instance resource.InstanceResource
+	binding  resource.ServiceBindingResource
+	watcher  watcher
+}
+
+// CreateInstances deploy the custom resources into cluster
+func (i *instanceServer) CreateInstances(ctx context.Context,
+	req *v1alpha1.CreateInstancesRequest) (*v1alpha1.CreateInstancesResponse, error) {
+	serviceBinding := req.GetServiceBinding()
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "CreateInstances", utils.DeployAction, &resourceName, &err)
+	if !validStrings(serviceBinding, clusterName) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	creation, err := decodeCreation(req.GetCreation())
+	if err != nil {
+		return nil, toStatus(ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+	}
+	resourceName = fmt.Sprintf("Deploy Instance for Service Package [%s] to Cluster [%s]",
+		serviceBinding, clusterName)
+	if err = resource.ValidationInstance(ctx, creation); err != nil {
+		return nil, toStatus(ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+	}
+	for _, attrs := range i.binding.DeployInstanceAttributes(serviceBinding, clusterName, creation) {
+		if err = authorize(ctx, "CreateInstances", attrs); err != nil {
+			return nil, toStatus(ctx, err)
+		}
+	}
+	instances, err := i.instance.DeployInstances(ctx, &i.binding, serviceBinding, clusterName, creation)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	resp := &v1alpha1.CreateInstancesResponse{Instances: make([]*v1alpha1.Instance, 0, len(instances))}
+	for _, instance := range instances {
+		resp.Instances = append(resp.Instances, &v1alpha1.Instance{Name: instance.Name,
+			Namespace: instance.Namespace})
+	}
+	return resp, nil
+}
+
+// ListInstances get the instances of the service binding in the namespace
+func (i *instanceServer) ListInstances(ctx context.Context,
+	req *v1alpha1.ListInstancesRequest) (*v1alpha1.ListInstancesResponse, error) {
+	serviceBinding := req.GetServiceBinding()
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	namespace := defaultString(req.GetNamespace(), apis.DefaultNamespace)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "ListInstances", utils.QueryAction, &resourceName, &err)
+	if (!validStrings(serviceBinding) && len(serviceBinding) > 0) || !validStrings(clusterName, namespace) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Get Instance List of Service Binding [%s] from Namespace [%s] in Cluster [%s]",
+		serviceBinding, namespace, clusterName)
+	if err = authorize(ctx, "ListInstances", authorization.Attributes{Verb: authorization.VerbGet,
+		Cluster: clusterName, Namespace: namespace, Service: serviceBinding}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	instances, err := i.instance.GetInstances(ctx, serviceBinding, clusterName, namespace)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	resp := &v1alpha1.ListInstancesResponse{Items: make([]*v1alpha1.Instance, 0, len(instances))}
+	for _, instance := range instances {
+		item, err := toInstance(serviceBinding, instance)
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+// GetInstance get the detail information of the instance
+func (i *instanceServer) GetInstance(ctx context.Context, req *v1alpha1.GetInstanceRequest) (*v1alpha1.Instance,
+	error) {
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	namespace := defaultString(req.GetNamespace(), apis.DefaultNamespace)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "GetInstance", utils.QueryAction, &resourceName, &err)
+	if !validStrings(req.GetServiceBinding(), req.GetName(), clusterName, namespace) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Get Instance [%s] if Service Binding [%s] from Namespace [%s] in Cluster [%s]",
+		req.GetName(), req.GetServiceBinding(), namespace, clusterName)
+	if err = authorize(ctx, "GetInstance", authorization.Attributes{Verb: authorization.VerbGet,
+		Cluster: clusterName, Namespace: namespace, Service: req.GetServiceBinding()}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	instance, err := i.get(ctx, req.GetServiceBinding(), req.GetName(), clusterName, namespace)
+	return instance, toStatus(ctx, err)
+}
+
+// UpdateInstanceSettings replace or patch the settings of the instance
+func (i *instanceServer) UpdateInstanceSettings(ctx context.Context,
+	req *v1alpha1.UpdateInstanceSettingsRequest) (*v1alpha1.UpdateSettingsResponse, error) {
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	namespace := defaultString(req.GetNamespace(), apis.DefaultNamespace)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "UpdateInstanceSettings", utils.UpdateAction, &resourceName, &err)
+	if !validStrings(req.GetServiceBinding(), req.GetName(), clusterName, namespace) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Update Service Instance [%s] of Service Binding [%s] from Namespace [%s] in "+
+		"Cluster [%s]", req.GetName(), req.GetServiceBinding(), namespace, clusterName)
+	if err = authorize(ctx, "UpdateInstanceSettings", authorization.Attributes{Verb: authorization.VerbUpdate,
+		Cluster: clusterName, Namespace: namespace, Service: req.GetServiceBinding()}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	var update resource.UpdateSettings
+	switch u := req.GetUpdate().(type) {
+	case *v1alpha1.UpdateInstanceSettingsRequest_Settings:
+		update, err = settingsUpdate(u.Settings, "", nil)
+	case *v1alpha1.UpdateInstanceSettingsRequest_MergePatch:
+		update, err = settingsUpdate(nil, patch.MergePatchType, u.MergePatch)
+	case *v1alpha1.UpdateInstanceSettingsRequest_JsonPatch:
+		update, err = settingsUpdate(nil, patch.JSONPatchType, u.JsonPatch)
+	default:
+		update, err = settingsUpdate(nil, "", nil)
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	settings, version, err := i.instance.UpdateInstanceSettings(ctx, req.GetServiceBinding(), clusterName, req.GetName(),
+		namespace, req.ResourceVersion, update)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return fromSettings(settings, version), nil
+}
+
+// DeleteInstance delete the instance from cluster and database
+func (i *instanceServer) DeleteInstance(ctx context.Context,
+	req *v1alpha1.DeleteInstanceRequest) (*v1alpha1.DeleteInstanceResponse, error) {
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	namespace := defaultString(req.GetNamespace(), apis.DefaultNamespace)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "DeleteInstance", utils.UninstallAction, &resourceName, &err)
+	if !validStrings(req.GetServiceBinding(), req.GetName(), clusterName, namespace) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Uninstall Service Instance [%s] of Service Binding [%s] from Namespace [%s] in "+
+		"Cluster [%s]", req.GetName(), req.GetServiceBinding(), namespace, clusterName)
+	if err = authorize(ctx, "DeleteInstance", authorization.Attributes{Verb: authorization.VerbDelete,
+		Cluster: clusterName, Namespace: namespace, Service: req.GetServiceBinding()}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	opts, err := deleteOptions(req.ResourceVersion, req.GetTimeout(), req.GetForce(), req.GetRemoveFinalizers())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if err = authorizeForce(ctx, "DeleteInstance", opts); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if err = i.instance.DeleteInstance(ctx, req.GetServiceBinding(), clusterName, req.GetName(), namespace,
+		opts); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &v1alpha1.DeleteInstanceResponse{}, nil
+}
+
+// WatchInstance send the changes of the instance until it is deleted
+func (i *instanceServer) WatchInstance(req *v1alpha1.WatchInstanceRequest,
+	stream v1alpha1.InstanceService_WatchInstanceServer) error {
+	ctx := stream.Context()
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	namespace := defaultString(req.GetNamespace(), apis.DefaultNamespace)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "WatchInstance", utils.QueryAction, &resourceName, &err)
+	if !validStrings(req.GetServiceBinding(), req.GetName(), clusterName, namespace) {
+		err = utils.ErrIllegalParameters
+		return toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Watch Instance [%s] of Service Binding [%s] from Namespace [%s] in Cluster [%s]",
+		req.GetName(), req.GetServiceBinding(), namespace, clusterName)
+	if err = authorize(ctx, "WatchInstance", authorization.Attributes{Verb: authorization.VerbGet,
+		Cluster: clusterName, Namespace: namespace, Service: req.GetServiceBinding()}); err != nil {
+		return toStatus(ctx, err)
+	}
+	err = i.watcher.watch(ctx, func() (proto.Message, error) {
+		return i.get(ctx, req.GetServiceBinding(), req.GetName(), clusterName, namespace)
+	}, func(eventType v1alpha1.EventType, obj proto.Message) error {
+		return stream.Send(&v1alpha1.InstanceEvent{Type: eventType, Object: obj.(*v1alpha1.Instance)})
+	})
+	return toStatus(ctx, err)
+}
+
+func (i *instanceServer) get(ctx context.Context, serviceBinding, name, clusterName,
+	namespace string) (*v1alpha1.Instance, error) {
+	instance, err := i.instance.GetInstance(ctx, serviceBinding, clusterName, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return toInstance(serviceBinding, instance)
+}
+
+func toInstance(serviceBinding string, instance models.InstanceModel) (*v1alpha1.Instance, error) {
+	object, err := json.Marshal(instance)
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.Instance{
+		Name:            instance.Name,
+		Namespace:       instance.Namespace,
+		Cluster:         instance.ClusterName,
+		ServiceBinding:  serviceBinding,
+		Status:          instance.Status,
+		ResourceVersion: instance.ResourceVersion,
+		Object:          object,
+	}, nil
+}
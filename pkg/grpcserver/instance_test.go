@@ -0,0 +1,163 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/beego/beego/v2/client/orm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kappital/kappital/pkg/apis/internals"
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	"github.com/kappital/kappital/pkg/dao/instance"
+	"github.com/kappital/kappital/pkg/models"
+	mo "github.com/kappital/kappital/pkg/models/operation"
+	"github.com/kappital/kappital/pkg/utils/audit"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	co "github.com/kappital/kappital/pkg/utils/operations"
+)
+
+func TestInstanceServer_otherServiceInstance(t *testing.T) {
+	audit.SetAuditLog(&audit.FakeAuditLogger)
+	// alice operates the service orders only, the instance cache belongs to the service payments
+	authorization.SetPolicy(&authorization.Policy{Bindings: []authorization.RoleBinding{{
+		Role:     authorization.RoleOperator,
+		Subjects: []authorization.Subject{{Kind: authorization.SubjectUser, Name: "alice"}},
+		Scopes:   []authorization.Scope{{Services: []string{"orders"}}},
+	}}})
+	defer authorization.SetPolicy(nil)
+	p := gomonkey.ApplyMethod(reflect.TypeOf(mo.ServiceBindingOperation{}), "Get",
+		func(_ mo.ServiceBindingOperation, cols map[string]string) (interface{}, error) {
+			return models.ServiceBindingModel{ID: "sb-" + cols["name"], Name: cols["name"]}, nil
+		})
+	defer p.Reset()
+	p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Get",
+		func(_ instance.Instance, cols map[string]string) (interface{}, error) {
+			if cols["service_binding_id"] != "sb-payments" {
+				return nil, orm.ErrNoRows
+			}
+			return internals.ServiceInstance{ID: "i1", Name: "cache", Namespace: "prod",
+				ServiceBindingID: "sb-payments"}, nil
+		})
+	p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Update",
+		func(instance.Instance, interface{}, ...string) error {
+			t.Errorf("the instance of the other service is updated")
+			return nil
+		})
+
+	ctx := authentication.WithPrincipal(context.Background(), authentication.Principal{Name: "alice"})
+	s := &instanceServer{}
+	for _, tt := range []struct {
+		service  string
+		wantCode codes.Code
+	}{
+		{service: "orders", wantCode: codes.NotFound},
+		{service: "payments", wantCode: codes.PermissionDenied},
+	} {
+		_, err := s.DeleteInstance(ctx, &v1alpha1.DeleteInstanceRequest{ServiceBinding: tt.service, Name: "cache",
+			Namespace: "prod"})
+		if got := status.Code(err); got != tt.wantCode {
+			t.Errorf("DeleteInstance() through %s code = %v, want %v", tt.service, got, tt.wantCode)
+		}
+		_, err = s.UpdateInstanceSettings(ctx, &v1alpha1.UpdateInstanceSettingsRequest{ServiceBinding: tt.service,
+			Name: "cache", Namespace: "prod", Update: &v1alpha1.UpdateInstanceSettingsRequest_Settings{
+				Settings: &v1alpha1.Settings{}}})
+		if got := status.Code(err); got != tt.wantCode {
+			t.Errorf("UpdateInstanceSettings() through %s code = %v, want %v", tt.service, got, tt.wantCode)
+		}
+	}
+}
+
+// instanceWatchStream record the events sent to the client of WatchInstance
+type instanceWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []v1alpha1.EventType
+}
+
+func (s *instanceWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *instanceWatchStream) Send(event *v1alpha1.InstanceEvent) error {
+	s.events = append(s.events, event.GetType())
+	return nil
+}
+
+// existOperation all custom resources exist in the cluster
+type existOperation struct {
+	co.ClusterOperation
+}
+
+func (existOperation) DoesCustomResourceExist(context.Context, schema.GroupVersion, string, string,
+	string) (bool, error) {
+	return true, nil
+}
+
+func TestInstanceServer_WatchInstanceUnchanged(t *testing.T) {
+	audit.SetAuditLog(&audit.FakeAuditLogger)
+	previous := co.GetClusterOperation()
+	defer co.SetClusterOperation(previous)
+	co.SetClusterOperation(existOperation{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the row in database, the writes of the polls are applied to it
+	row := models.InstanceModel{ID: "i1", Name: "cache", Namespace: "prod", ClusterName: "default",
+		Status: "Succeeded", ResourceVersion: 1}
+	var polls int
+	p := gomonkey.ApplyMethod(reflect.TypeOf(mo.ServiceBindingOperation{}), "GetDetail",
+		func(mo.ServiceBindingOperation, map[string]string) (interface{}, error) {
+			if polls++; polls == 5 {
+				cancel()
+			}
+			instance := row
+			return models.ServiceBindingModel{ID: "sb1", Name: "redis", Resources: []*models.ResourceModel{{
+				Group: "cache.example.com", APIVersion: "cache.example.com/v1", Resource: "redis",
+				Instances: []*models.InstanceModel{&instance}}}}, nil
+		})
+	defer p.Reset()
+	p.ApplyMethod(reflect.TypeOf(mo.InstanceOperation{}), "Update",
+		func(mo.InstanceOperation, interface{}, ...string) error {
+			row.ResourceVersion++
+			return nil
+		})
+	p.ApplyMethod(reflect.TypeOf(mo.InstanceOperation{}), "UpdateStatus",
+		func(_ mo.InstanceOperation, _, _, status string) error {
+			row.Status = status
+			return nil
+		})
+
+	stream := &instanceWatchStream{ctx: ctx}
+	s := &instanceServer{watcher: watcher{interval: time.Millisecond, stopping: make(chan struct{})}}
+	if err := s.WatchInstance(&v1alpha1.WatchInstanceRequest{ServiceBinding: "redis", Name: "cache",
+		Namespace: "prod"}, stream); err != nil {
+		t.Fatalf("WatchInstance() error = %v", err)
+	}
+	if !reflect.DeepEqual(stream.events, []v1alpha1.EventType{v1alpha1.EventType_EVENT_TYPE_ADDED}) {
+		t.Errorf("WatchInstance() events = %v after %d polls, want the added event only", stream.events, polls)
+	}
+}
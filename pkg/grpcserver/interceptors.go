@@ -0,0 +1,222 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"path"
+	"runtime/debug"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/routers/flowcontroller"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/metrics"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+)
+
+const (
+	// requestIDKey the metadata key of the request id, the keys of the metadata are lower case
+	requestIDKey = "x-request-id"
+	// authorizationKey the metadata key of the bearer token
+	authorizationKey = "authorization"
+	// grpcMethod the method label of the gRPC calls in the metrics
+	grpcMethod = "GRPC"
+)
+
+// readMethodPrefixes the prefixes of the rpc names which only get the objects, the other rpcs change the objects
+var readMethodPrefixes = []string{"Get", "List", "Watch"}
+
+// check the call before it reaches the service, the returned ctx is passed to the next check and the service
+type check func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// checks the checks of the calls in order, they are the same as the filters of the REST API
+func checks(identityCheck bool) []check {
+	cs := []check{withRequestID, authenticate}
+	if identityCheck {
+		cs = append(cs, checkIdentity)
+	}
+	return append(cs, limitFlow)
+}
+
+func runChecks(ctx context.Context, cs []check, fullMethod string) (context.Context, error) {
+	var err error
+	for _, c := range cs {
+		if ctx, err = c(ctx, fullMethod); err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+func unaryInterceptors(identityCheck bool) []grpc.UnaryServerInterceptor {
+	cs := checks(identityCheck)
+	return []grpc.UnaryServerInterceptor{func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer recoverPanic(ctx, info.FullMethod, &err)
+		if ctx, err = runChecks(ctx, cs, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}}
+}
+
+func streamInterceptors(identityCheck bool) []grpc.StreamServerInterceptor {
+	cs := checks(identityCheck)
+	return []grpc.StreamServerInterceptor{func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(ss.Context(), info.FullMethod, &err)
+		ctx, err := runChecks(ss.Context(), cs, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}}
+}
+
+// serverStream replaces the context of the stream with the one of the checks
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context of the stream which carries the request id and the principal
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// recoverPanic reply the internal error instead of crashing the manager when the service panics
+func recoverPanic(ctx context.Context, fullMethod string, err *error) {
+	if r := recover(); r != nil {
+		requestid.Errorf(ctx, "the call %s panics: %v\n%s", fullMethod, r, debug.Stack())
+		*err = toStatus(ctx, errors.ErrInternal.WrapErrorReasonWith("%v", r))
+	}
+}
+
+// withRequestID accepts the x-request-id of the client or generates a new one, and puts it into the context and
+// the response header
+func withRequestID(ctx context.Context, _ string) (context.Context, error) {
+	id := requestid.Normalize(firstMetadata(ctx, requestIDKey))
+	ctx = requestid.NewContext(ctx, id)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id)); err != nil {
+		requestid.Warningf(ctx, "cannot set the request id into the header, err: %v", err)
+	}
+	return ctx, nil
+}
+
+// authenticate identify the principal of the call by the bearer token in the metadata or the verified client
+// certificate, and put it into the context. The call with the invalid bearer token is rejected, and the anonymous
+// call is left to the identity check and authorization.
+func authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	auth := firstMetadata(ctx, authorizationKey)
+	var state *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state = &info.State
+		}
+	}
+	principal, err := authentication.AuthenticateCredentials(ctx, auth, state)
+	if err == nil {
+		return authentication.WithPrincipal(ctx, principal), nil
+	}
+	if len(auth) > 0 {
+		requestid.Warningf(ctx, "reject the call %s, err: %v", fullMethod, err)
+		return ctx, toStatus(ctx, errors.ErrUnauthorized.WrapErrorReasonWith("reject the bearer token"))
+	}
+	return ctx, nil
+}
+
+// checkIdentity reject the call whose principal does not pass the identity check
+func checkIdentity(ctx context.Context, _ string) (context.Context, error) {
+	principal, ok := authentication.PrincipalFrom(ctx)
+	if !ok || !authorization.CheckIdentity(principal) {
+		return ctx, toStatus(ctx, errors.ErrUnauthorized.WrapErrorReasonWith("reject certificates"))
+	}
+	return ctx, nil
+}
+
+// limitFlow limit the call by the bucket of its client and class, and the global bucket, as the flow control of the
+// REST API. The watch only takes the token when it is started.
+func limitFlow(ctx context.Context, fullMethod string) (context.Context, error) {
+	class := classOf(fullMethod)
+	result := flowcontroller.Allow(flowControlClient(ctx), class)
+	if result.Allowed {
+		return ctx, nil
+	}
+	klog.Warningf("call %s is rejected by the %s flow controller, too many request", fullMethod, result.Scope)
+	metrics.IncFlowControlRejected(grpcMethod, fullMethod, string(class), result.Scope)
+	return ctx, toStatus(ctx, errors.ErrTooManyRequests.WrapErrorReasonWith("rejected by the %s %s limit", class,
+		result.Scope), &errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)})
+}
+
+// classOf get the class of the call by its rpc name
+func classOf(fullMethod string) flowcontroller.Class {
+	name := path.Base(fullMethod)
+	for _, prefix := range readMethodPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return flowcontroller.ReadClass
+		}
+	}
+	return flowcontroller.MutationClass
+}
+
+// flowControlClient the principal name of the call, or its source ip
+func flowControlClient(ctx context.Context) string {
+	if principal, ok := authentication.PrincipalFrom(ctx); ok {
+		return "user:" + principal.Name
+	}
+	return "ip:" + sourceHost(ctx)
+}
+
+// sourceAddr the address of the peer, it is the source ip of the audit log
+func sourceAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func sourceHost(ctx context.Context) string {
+	addr := sourceAddr(ctx)
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+// Config of the gRPC API
+type Config struct {
+	// BindAddress the address which the gRPC API binds to, empty means disable the gRPC API
+	BindAddress string
+	// WatchInterval the interval of polling the watched service bindings and instances
+	WatchInterval time.Duration
+}
+
+// DefaultGRPCConfig get the default config of the gRPC API, it is disabled by default
+func DefaultGRPCConfig() *Config {
+	return &Config{WatchInterval: defaultWatchInterval}
+}
+
+// Validate the gRPC config
+func (c *Config) Validate() error {
+	if c.WatchInterval <= 0 {
+		return fmt.Errorf("the watch interval of the gRPC API must be positive")
+	}
+	return nil
+}
+
+// Server of the gRPC API which offers the same lifecycle operations of the service bindings and the instances as the
+// REST API, they share the resource layer, thus the behaviors of the two APIs are the same
+type Server struct {
+	server   *grpc.Server
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// NewServer create the gRPC server with the TLS config of the REST API, the principals are checked if identityCheck
+// is true. The nil tlsConfig means serving without TLS, it is only used by the tests.
+func NewServer(cfg *Config, tlsConfig *tls.Config, identityCheck bool) *Server {
+	s := &Server{stopping: make(chan struct{})}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors(identityCheck)...),
+		grpc.ChainStreamInterceptor(streamInterceptors(identityCheck)...),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s.server = grpc.NewServer(opts...)
+	w := watcher{interval: cfg.WatchInterval, stopping: s.stopping}
+	v1alpha1.RegisterServiceBindingServiceServer(s.server, &serviceBindingServer{watcher: w})
+	v1alpha1.RegisterInstanceServiceServer(s.server, &instanceServer{watcher: w})
+	return s
+}
+
+// Serve accept the connections on the listener until the server is stopped
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
+}
+
+// Shutdown end the watches and wait for the in-flight calls, the connections are closed when the ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopping) })
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	"github.com/kappital/kappital/pkg/routers/flowcontroller"
+)
+
+func newTestClient(t *testing.T, identityCheck bool) (v1alpha1.ServiceBindingServiceClient, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+	s := NewServer(&Config{WatchInterval: time.Millisecond}, nil, identityCheck)
+	go func() { _ = s.Serve(lis) }()
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		t.Fatalf("cannot dial the server, err: %v", err)
+	}
+	return v1alpha1.NewServiceBindingServiceClient(conn), func() {
+		_ = conn.Close()
+		_ = s.Shutdown(context.Background())
+	}
+}
+
+func TestServer(t *testing.T) {
+	client, stop := newTestClient(t, false)
+	defer stop()
+
+	tests := []struct {
+		name          string
+		md            metadata.MD
+		wantCode      codes.Code
+		wantRequestID string
+	}{
+		{name: "request id of the client", md: metadata.Pairs(requestIDKey, "test-id"),
+			wantCode: codes.InvalidArgument, wantRequestID: "test-id"},
+		{name: "invalid request id is replaced", md: metadata.Pairs(requestIDKey, "a b"),
+			wantCode: codes.InvalidArgument},
+		{name: "invalid bearer token", md: metadata.Pairs(authorizationKey, "Basic abc"),
+			wantCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header metadata.MD
+			ctx := metadata.NewOutgoingContext(context.Background(), tt.md)
+			_, err := client.GetServiceBinding(ctx, &v1alpha1.GetServiceBindingRequest{Name: "!invalid!"},
+				grpc.Header(&header))
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("GetServiceBinding() code = %v, want %v", got, tt.wantCode)
+			}
+			ids := header.Get(requestIDKey)
+			if len(ids) != 1 || len(ids[0]) == 0 || (len(tt.wantRequestID) > 0 && ids[0] != tt.wantRequestID) {
+				t.Errorf("GetServiceBinding() request id = %v, want %s", ids, tt.wantRequestID)
+			}
+		})
+	}
+}
+
+func TestServer_identityCheck(t *testing.T) {
+	client, stop := newTestClient(t, true)
+	defer stop()
+
+	_, err := client.GetServiceBinding(context.Background(), &v1alpha1.GetServiceBindingRequest{Name: "test"})
+	if got := status.Code(err); got != codes.Unauthenticated {
+		t.Errorf("GetServiceBinding() code = %v, want %v", got, codes.Unauthenticated)
+	}
+}
+
+func TestServer_flowControl(t *testing.T) {
+	flowcontroller.Init(&flowcontroller.Config{Enable: true, QPS: 0.001, Burst: 1})
+	defer flowcontroller.Init(nil)
+	client, stop := newTestClient(t, false)
+	defer stop()
+
+	_, err := client.GetServiceBinding(context.Background(), &v1alpha1.GetServiceBindingRequest{Name: "!invalid!"})
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("GetServiceBinding() code = %v, want %v", got, codes.InvalidArgument)
+	}
+	_, err = client.GetServiceBinding(context.Background(), &v1alpha1.GetServiceBindingRequest{Name: "!invalid!"})
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("GetServiceBinding() code = %v, want %v", st.Code(), codes.ResourceExhausted)
+	}
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if r, ok := detail.(*errdetails.RetryInfo); ok {
+			retry = r
+		}
+	}
+	if retry == nil || retry.GetRetryDelay().AsDuration() <= 0 {
+		t.Errorf("GetServiceBinding() retry info = %v, want positive delay", retry)
+	}
+}
+
+func Test_classOf(t *testing.T) {
+	tests := []struct {
+		fullMethod string
+		want       flowcontroller.Class
+	}{
+		{fullMethod: "/kappital.manager.v1alpha1.ServiceBindingService/GetServiceBinding",
+			want: flowcontroller.ReadClass},
+		{fullMethod: "/kappital.manager.v1alpha1.InstanceService/ListInstances", want: flowcontroller.ReadClass},
+		{fullMethod: "/kappital.manager.v1alpha1.InstanceService/WatchInstance", want: flowcontroller.ReadClass},
+		{fullMethod: "/kappital.manager.v1alpha1.InstanceService/CreateInstances",
+			want: flowcontroller.MutationClass},
+		{fullMethod: "/kappital.manager.v1alpha1.ServiceBindingService/DeleteServiceBinding",
+			want: flowcontroller.MutationClass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fullMethod, func(t *testing.T) {
+			if got := classOf(tt.fullMethod); got != tt.want {
+				t.Errorf("classOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	s := NewServer(DefaultGRPCConfig(), nil, false)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() again error = %v", err)
+	}
+}
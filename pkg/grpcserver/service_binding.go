@@ -0,0 +1,238 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/patch"
+)
+
+// serviceBindingServer the gRPC service of the service bindings, it does the same as the ServiceBindingController
+type serviceBindingServer struct {
+	v1alpha1.UnimplementedServiceBindingServiceServer
+	resource resource.ServiceBindingResource
+	watcher  watcher
+}
+
+// CreateServiceBinding deploy the service package into cluster
+func (s *serviceBindingServer) CreateServiceBinding(ctx context.Context,
+	req *v1alpha1.CreateServiceBindingRequest) (*v1alpha1.CreateServiceBindingResponse, error) {
+	var resourceName string
+	creation, err := decodeCreation(req.GetCreation())
+	defer auditLog(ctx, "CreateServiceBinding", utils.DeployAction, &resourceName, &err)
+	if err != nil {
+		return nil, toStatus(ctx, errors.ErrServiceParam.WrapErrorReasonWith(err.Error()))
+	}
+	resourceName = fmt.Sprintf("Deploy Service [%s] into Cluster [%s]", creation.Service.Name, creation.ClusterID)
+	if err = authorize(ctx, "CreateServiceBinding", authorization.Attributes{Verb: authorization.VerbCreate,
+		Cluster: creation.ClusterID, Service: creation.Service.Spec.Description.Name}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	sb, err := s.resource.DeployServiceBinding(ctx, creation)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &v1alpha1.CreateServiceBindingResponse{Name: sb.Name, Id: sb.ID}, nil
+}
+
+// ListServiceBindings get the service bindings of the cluster
+func (s *serviceBindingServer) ListServiceBindings(ctx context.Context,
+	req *v1alpha1.ListServiceBindingsRequest) (*v1alpha1.ListServiceBindingsResponse, error) {
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "ListServiceBindings", utils.QueryAction, &resourceName, &err)
+	if !validStrings(clusterName) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Get Service Binding List from Cluster [%s]", clusterName)
+	if err = authorize(ctx, "ListServiceBindings", authorization.Attributes{Verb: authorization.VerbGet,
+		Cluster: clusterName}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	sis, err := s.resource.GetServiceBindings(clusterName)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	resp := &v1alpha1.ListServiceBindingsResponse{Items: make([]*v1alpha1.ServiceBinding, 0, len(sis))}
+	for i := range sis {
+		item, err := toServiceBinding(&sis[i])
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+// GetServiceBinding get the service binding detail information
+func (s *serviceBindingServer) GetServiceBinding(ctx context.Context,
+	req *v1alpha1.GetServiceBindingRequest) (*v1alpha1.ServiceBinding, error) {
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "GetServiceBinding", utils.QueryAction, &resourceName, &err)
+	if !validStrings(req.GetName(), clusterName) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Get Service Binding [%s] from Cluster [%s]", req.GetName(), clusterName)
+	if err = authorize(ctx, "GetServiceBinding", authorization.Attributes{Verb: authorization.VerbGet,
+		Cluster: clusterName, Service: req.GetName()}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	sb, err := s.get(ctx, req.GetName(), clusterName, req.GetDetail())
+	return sb, toStatus(ctx, err)
+}
+
+// UpdateServiceBindingSettings replace or patch the settings of the service binding
+func (s *serviceBindingServer) UpdateServiceBindingSettings(ctx context.Context,
+	req *v1alpha1.UpdateServiceBindingSettingsRequest) (*v1alpha1.UpdateSettingsResponse, error) {
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "UpdateServiceBindingSettings", utils.UpdateAction, &resourceName, &err)
+	if !validStrings(req.GetName(), clusterName) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Update Service Binding [%s] in Cluster [%s]", req.GetName(), clusterName)
+	if err = authorize(ctx, "UpdateServiceBindingSettings", authorization.Attributes{Verb: authorization.VerbUpdate,
+		Cluster: clusterName, Service: req.GetName()}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	var update resource.UpdateSettings
+	switch u := req.GetUpdate().(type) {
+	case *v1alpha1.UpdateServiceBindingSettingsRequest_Settings:
+		update, err = settingsUpdate(u.Settings, "", nil)
+	case *v1alpha1.UpdateServiceBindingSettingsRequest_MergePatch:
+		update, err = settingsUpdate(nil, patch.MergePatchType, u.MergePatch)
+	case *v1alpha1.UpdateServiceBindingSettingsRequest_JsonPatch:
+		update, err = settingsUpdate(nil, patch.JSONPatchType, u.JsonPatch)
+	default:
+		update, err = settingsUpdate(nil, "", nil)
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	settings, version, err := s.resource.UpdateServiceBindingSettings(ctx, req.GetName(), clusterName,
+		req.ResourceVersion, update)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return fromSettings(settings, version), nil
+}
+
+// DeleteServiceBinding destroy the service binding from cluster
+func (s *serviceBindingServer) DeleteServiceBinding(ctx context.Context,
+	req *v1alpha1.DeleteServiceBindingRequest) (*v1alpha1.DeleteServiceBindingResponse, error) {
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "DeleteServiceBinding", utils.UninstallAction, &resourceName, &err)
+	if !validStrings(req.GetName(), clusterName) {
+		err = utils.ErrIllegalParameters
+		return nil, toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Uninstall Service Binding [%s] in Cluster [%s]", req.GetName(), clusterName)
+	if err = authorize(ctx, "DeleteServiceBinding", authorization.Attributes{Verb: authorization.VerbDelete,
+		Cluster: clusterName, Service: req.GetName()}); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	opts, err := deleteOptions(req.ResourceVersion, req.GetTimeout(), req.GetForce(), req.GetRemoveFinalizers())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if err = authorizeForce(ctx, "DeleteServiceBinding", opts); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	opts.Cascade = req.GetCascade()
+	if err = s.resource.DeleteServiceBinding(ctx, req.GetName(), clusterName, opts); err != nil {
+		if utils.IsResourceVersionConflict(err) || utils.IsKappError(err) {
+			return nil, toStatus(ctx, err)
+		}
+		return nil, toStatus(ctx, errors.ErrServiceDelete.WrapErrorReasonWith(err.Error()))
+	}
+	return &v1alpha1.DeleteServiceBindingResponse{}, nil
+}
+
+// WatchServiceBinding send the changes of the service binding until it is deleted
+func (s *serviceBindingServer) WatchServiceBinding(req *v1alpha1.WatchServiceBindingRequest,
+	stream v1alpha1.ServiceBindingService_WatchServiceBindingServer) error {
+	ctx := stream.Context()
+	clusterName := defaultString(req.GetCluster(), apis.DefaultCluster)
+	var err error
+	var resourceName string
+	defer auditLog(ctx, "WatchServiceBinding", utils.QueryAction, &resourceName, &err)
+	if !validStrings(req.GetName(), clusterName) {
+		err = utils.ErrIllegalParameters
+		return toStatus(ctx, err)
+	}
+	resourceName = fmt.Sprintf("Watch Service Binding [%s] from Cluster [%s]", req.GetName(), clusterName)
+	if err = authorize(ctx, "WatchServiceBinding", authorization.Attributes{Verb: authorization.VerbGet,
+		Cluster: clusterName, Service: req.GetName()}); err != nil {
+		return toStatus(ctx, err)
+	}
+	err = s.watcher.watch(ctx, func() (proto.Message, error) {
+		return s.get(ctx, req.GetName(), clusterName, true)
+	}, func(eventType v1alpha1.EventType, obj proto.Message) error {
+		return stream.Send(&v1alpha1.ServiceBindingEvent{Type: eventType, Object: obj.(*v1alpha1.ServiceBinding)})
+	})
+	return toStatus(ctx, err)
+}
+
+func (s *serviceBindingServer) get(ctx context.Context, name, clusterName string,
+	detail bool) (*v1alpha1.ServiceBinding, error) {
+	si, err := s.resource.GetServiceBinding(ctx, name, clusterName, detail)
+	if err != nil {
+		return nil, err
+	}
+	if si == nil {
+		return nil, errors.ErrNotFound.WrapErrorReasonWith("the service binding %s is not found in cluster %s",
+			name, clusterName)
+	}
+	return toServiceBinding(si)
+}
+
+func toServiceBinding(si *instancev1alpha1.CloudNativeServiceInstance) (*v1alpha1.ServiceBinding, error) {
+	object, err := json.Marshal(si)
+	if err != nil {
+		return nil, err
+	}
+	version, _ := strconv.ParseInt(si.ResourceVersion, 10, 64)
+	return &v1alpha1.ServiceBinding{
+		Name:            si.Name,
+		Cluster:         si.Spec.ClusterName,
+		Phase:           string(si.Status.Phase),
+		ResourceVersion: version,
+		Object:          object,
+	}, nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	"github.com/kappital/kappital/pkg/controller/utils"
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+// watcher polls the watched objects from the resource layer at the interval, because the changes are made by the
+// processors of any replica, and the watches are ended when the server is stopping
+type watcher struct {
+	interval time.Duration
+	stopping <-chan struct{}
+}
+
+// watch poll the object by the get and send its changes until it is deleted, the client cancels the watch or the
+// server is stopping. The changes between two polls are merged, and the object which does not exist when the watch
+// is started is replied as the not found error.
+func (w watcher) watch(ctx context.Context, get func() (proto.Message, error),
+	send func(v1alpha1.EventType, proto.Message) error) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	var last proto.Message
+	for {
+		obj, err := get()
+		switch {
+		case err != nil && last != nil && isNotFound(err):
+			return send(v1alpha1.EventType_EVENT_TYPE_DELETED, last)
+		case err != nil && ctx.Err() != nil:
+			return nil
+		case err != nil:
+			return err
+		case last == nil:
+			err = send(v1alpha1.EventType_EVENT_TYPE_ADDED, obj)
+		case !proto.Equal(last, obj):
+			err = send(v1alpha1.EventType_EVENT_TYPE_MODIFIED, obj)
+		}
+		if err != nil {
+			return err
+		}
+		last = obj
+		select {
+		case <-ctx.Done():
+			// the client cancels the watch
+			return nil
+		case <-w.stopping:
+			return status.Error(codes.Unavailable, "the server is shutting down, please watch again")
+		case <-ticker.C:
+		}
+	}
+}
+
+func isNotFound(err error) bool {
+	return utils.ToKappError(err).TypeEqual(errors.ErrNotFound)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kappital/kappital/pkg/apis/manager/v1alpha1"
+	"github.com/kappital/kappital/pkg/utils/errors"
+)
+
+func Test_watcher_watch(t *testing.T) {
+	redis := &v1alpha1.ServiceBinding{Name: "redis", Phase: "Pending"}
+	succeeded := &v1alpha1.ServiceBinding{Name: "redis", Phase: "Succeeded"}
+	tests := []struct {
+		name       string
+		objects    []proto.Message
+		wantEvents []v1alpha1.EventType
+		wantErr    bool
+	}{
+		{name: "added, modified and deleted", objects: []proto.Message{redis, redis, succeeded, nil},
+			wantEvents: []v1alpha1.EventType{v1alpha1.EventType_EVENT_TYPE_ADDED,
+				v1alpha1.EventType_EVENT_TYPE_MODIFIED, v1alpha1.EventType_EVENT_TYPE_DELETED}},
+		{name: "not found at first", objects: []proto.Message{nil}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := watcher{interval: time.Millisecond, stopping: make(chan struct{})}
+			var index int
+			get := func() (proto.Message, error) {
+				obj := tt.objects[index]
+				index++
+				if obj == nil {
+					return nil, errors.ErrNotFound
+				}
+				return obj, nil
+			}
+			var events []v1alpha1.EventType
+			send := func(eventType v1alpha1.EventType, _ proto.Message) error {
+				events = append(events, eventType)
+				return nil
+			}
+			err := w.watch(context.Background(), get, send)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("watch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(events) != len(tt.wantEvents) {
+				t.Fatalf("watch() events = %v, want %v", events, tt.wantEvents)
+			}
+			for i := range events {
+				if events[i] != tt.wantEvents[i] {
+					t.Errorf("watch() events = %v, want %v", events, tt.wantEvents)
+				}
+			}
+		})
+	}
+}
+
+func Test_watcher_watchStopping(t *testing.T) {
+	stopping := make(chan struct{})
+	close(stopping)
+	w := watcher{interval: time.Hour, stopping: stopping}
+	get := func() (proto.Message, error) { return &v1alpha1.ServiceBinding{Name: "redis"}, nil }
+	send := func(v1alpha1.EventType, proto.Message) error { return nil }
+	if got := status.Code(w.watch(context.Background(), get, send)); got != codes.Unavailable {
+		t.Errorf("watch() code = %v, want %v", got, codes.Unavailable)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.stopping = make(chan struct{})
+	if err := w.watch(ctx, get, send); err != nil {
+		t.Errorf("watch() error = %v, want nil", err)
+	}
+}
+
+func Test_isNotFound(t *testing.T) {
+	if !isNotFound(errors.ErrNotFound) || isNotFound(errors.ErrInternal) {
+		t.Errorf("isNotFound() does not match the not found error")
+	}
+}
@@ -17,6 +17,8 @@
 package handler
 
 import (
+	"context"
+
 	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/handler/instance"
@@ -35,15 +37,15 @@ const (
 
 // IHandler the handler of synchronizing
 type IHandler interface {
-	BeforeInstall(obj interface{}) (bool, error)
-	Install(obj interface{}) (bool, error)
-	AfterInstall(obj interface{}) (bool, error)
-	BeforeUpgrade(obj interface{}) (bool, error)
-	Upgrade(obj interface{}) (bool, error)
-	AfterUpgrade(obj interface{}) (bool, error)
-	BeforeDelete(obj interface{}) (bool, error)
-	Delete(obj interface{}) (bool, error)
-	AfterDelete(obj interface{}) (bool, error)
+	BeforeInstall(ctx context.Context, obj interface{}) (bool, error)
+	Install(ctx context.Context, obj interface{}) (bool, error)
+	AfterInstall(ctx context.Context, obj interface{}) (bool, error)
+	BeforeUpgrade(ctx context.Context, obj interface{}) (bool, error)
+	Upgrade(ctx context.Context, obj interface{}) (bool, error)
+	AfterUpgrade(ctx context.Context, obj interface{}) (bool, error)
+	BeforeDelete(ctx context.Context, obj interface{}) (bool, error)
+	Delete(ctx context.Context, obj interface{}) (bool, error)
+	AfterDelete(ctx context.Context, obj interface{}) (bool, error)
 }
 
 var handlerMap map[Type]IHandler
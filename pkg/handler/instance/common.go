@@ -0,0 +1,50 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+
+	"github.com/kappital/kappital/pkg/apis/internals"
+	"github.com/kappital/kappital/pkg/utils/audit"
+)
+
+const (
+	deployAction    = "Deploy"
+	updateAction    = "Update"
+	uninstallAction = "Uninstall"
+)
+
+// auditSystemAction write the audit log of the action which is done to the service instance for the initiator
+func auditSystemAction(item *internals.ServiceInstance, traceName, action string, err error) {
+	audit.SystemActionLog(item.Initiator, traceName, action, item.Namespace+"/"+item.Name, err)
+}
+
+// auditForceDelete write the incident audit log of the service instance which is removed by force, and the left error
+// tells why its custom resource may be left in the cluster
+func auditForceDelete(item *internals.ServiceInstance, traceName string, left error) {
+	info := audit.SystemActionInfo(item.Initiator, traceName, uninstallAction, item.Namespace+"/"+item.Name)
+	info.Message = "the record is removed by force"
+	switch {
+	case left != nil:
+		info.Message += fmt.Sprintf(", the custom resource %s %s/%s in cluster %s may be left, err: %v",
+			item.Resource, item.Namespace, item.Name, item.ClusterName, left)
+	case item.RemoveFinalizers:
+		info.Message += ", the finalizers of its custom resource are cleared"
+	}
+	audit.Fault(info)
+}
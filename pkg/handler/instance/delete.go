@@ -17,47 +17,90 @@
 package instance
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"reflect"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/apis/internals"
 	"github.com/kappital/kappital/pkg/dao/instance"
 	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 // BeforeDelete do some processes before delete the service instance
-func (h *Handler) BeforeDelete(_ interface{}) (bool, error) {
+func (h *Handler) BeforeDelete(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
 
 // Delete is to delete the whole cloud native service instance
-func (h *Handler) Delete(obj interface{}) (bool, error) {
+func (h *Handler) Delete(ctx context.Context, obj interface{}) (bool, error) {
 	si, ok := obj.(*internals.ServiceInstance)
 	if !ok {
-		klog.Errorf("invalid object type for upgrade instance handler, expected: models.ServiceInstance, "+
+		requestid.Errorf(ctx, "invalid object type for upgrade instance handler, expected: models.ServiceInstance, "+
 			"actual: %s", reflect.TypeOf(obj).Name())
 		return false, nil
 	}
+	// start the delete timeout at the first time, thus the shorter one than the longest duration is honored
+	if err := updateProcessTimeout(ctx, si, si.Timeouts.DeleteDuration()); err != nil {
+		return true, err
+	}
+	if si.ForceDelete {
+		return h.forceDelete(ctx, si)
+	}
 	// Delete the instance cr in cluster
-	repeat, err := h.deleteInstanceCR(*si)
+	repeat, err := h.deleteInstanceCR(ctx, *si)
 	if err != nil {
 		return true, err
 	}
 	// Delete the instance in database
-	instanceDao := instance.Instance{}
-	if err = instanceDao.Delete(*si); err != nil {
+	instanceDao := instance.Instance{}.WithContext(ctx)
+	err = instanceDao.Delete(*si)
+	auditSystemAction(si, "DeleteInstanceRecord", uninstallAction, err)
+	if err != nil {
 		return true, err
 	}
-	klog.Infof("delete the instance %s during the service %s", si.Name, si.ServiceName)
+	requestid.Infof(ctx, "delete the instance %s during the service %s", si.Name, si.ServiceName)
 	return repeat, nil
 }
 
-func (h *Handler) deleteInstanceCR(si internals.ServiceInstance) (bool, error) {
+// forceDelete delete the custom resource of the instance without waiting for it to be removed from cluster, then
+// remove the record. The record is kept and retried if the custom resource cannot be deleted from the reachable
+// cluster, but it is removed anyway if the cluster is unreachable or the custom resource cannot be located, and the
+// custom resource which may be left is written into the incident audit log.
+func (h *Handler) forceDelete(ctx context.Context, si *internals.ServiceInstance) (bool, error) {
+	var left error
+	gv, err := schema.ParseGroupVersion(si.APIVersion)
+	if err != nil {
+		left, err = err, nil
+	} else {
+		err = co.ForceDeleteCustomResource(ctx, si.ClusterName, gv.WithResource(si.Resource), si.Name,
+			si.Namespace, si.RemoveFinalizers)
+		if stderrors.Is(err, co.ErrClusterUnreachable) {
+			left, err = err, nil
+		}
+	}
+	if err != nil {
+		auditSystemAction(si, "ForceDeleteInstanceCustomResource", uninstallAction, err)
+		requestid.Errorf(ctx, "failed to delete the custom resource of instance %s/%s by force, err: %s",
+			si.Namespace, si.Name, err)
+		return true, err
+	}
+	instanceDao := instance.Instance{}.WithContext(ctx)
+	if err = instanceDao.Delete(*si); err != nil {
+		auditSystemAction(si, "DeleteInstanceRecord", uninstallAction, err)
+		return true, err
+	}
+	auditForceDelete(si, "ForceDeleteInstance", left)
+	requestid.Infof(ctx, "delete the instance %s during the service %s by force", si.Name, si.ServiceName)
+	return false, nil
+}
+
+func (h *Handler) deleteInstanceCR(ctx context.Context, si internals.ServiceInstance) (bool, error) {
 	apiVersionSplit := strings.Split(si.APIVersion, "/")
 	if len(apiVersionSplit) < 2 {
 		return false, fmt.Errorf("the ServiceInstance's APIVersion is illeagle")
@@ -67,19 +110,23 @@ func (h *Handler) deleteInstanceCR(si internals.ServiceInstance) (bool, error) {
 		Version:  apiVersionSplit[1],
 		Resource: si.Resource,
 	}
-	err := co.GetClusterOperation().DeleteCustomResource(gvr, si.Name, si.Namespace)
+	err := co.GetClusterOperation().DeleteCustomResource(ctx, gvr, si.Name, si.Namespace)
+	// the custom resource which is not found has been deleted by the previous loop
+	if !errors.IsNotFound(err) {
+		auditSystemAction(&si, "DeleteInstanceCustomResource", uninstallAction, err)
+	}
 	if err != nil {
 		// this custom resource is deleting, and wait for it already deleted, in other words, the error is not found
 		return true, nil
 	}
 	if !errors.IsNotFound(err) {
-		klog.Errorf("delete custom resource %s in cluster failed, err: %s", si.Name, err)
+		requestid.Errorf(ctx, "delete custom resource %s in cluster failed, err: %s", si.Name, err)
 		return true, err
 	}
 	return false, nil
 }
 
 // AfterDelete delete service instance does not need to implement this method
-func (h *Handler) AfterDelete(_ interface{}) (bool, error) {
+func (h *Handler) AfterDelete(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kappital/kappital/pkg/apis/internals"
+	"github.com/kappital/kappital/pkg/dao/instance"
+	"github.com/kappital/kappital/pkg/utils/audit"
+	co "github.com/kappital/kappital/pkg/utils/operations"
+)
+
+// deleteOperation fail the deletion of the custom resources and the ping of the cluster with the errors
+type deleteOperation struct {
+	co.ClusterOperation
+	deleteErr error
+	pingErr   error
+}
+
+func (d deleteOperation) Ping(context.Context, string) error {
+	return d.pingErr
+}
+
+func (d deleteOperation) DeleteCustomResource(context.Context, schema.GroupVersionResource, string, string) error {
+	return d.deleteErr
+}
+
+func TestHandler_Delete_force(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteErr   error
+		pingErr     error
+		wantRetry   bool
+		wantDeleted []string
+		wantLeft    bool
+	}{
+		{name: "Test Delete (force)", wantDeleted: []string{"cache"}},
+		{name: "Test Delete (force, forbidden)", deleteErr: fmt.Errorf("forbidden"), wantRetry: true},
+		{name: "Test Delete (force, cluster is gone)", deleteErr: fmt.Errorf("connection refused"),
+			pingErr: fmt.Errorf("connection refused"), wantDeleted: []string{"cache"}, wantLeft: true},
+		{name: "Test Delete (force, fenced)", deleteErr: co.ErrFenced, wantRetry: true},
+	}
+	audit.SetAuditLog(&audit.FakeAuditLogger)
+	previous := co.GetClusterOperation()
+	defer co.SetClusterOperation(previous)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			co.SetClusterOperation(deleteOperation{deleteErr: tt.deleteErr, pingErr: tt.pingErr})
+			var deleted []string
+			var faults []audit.AuditLogInfo
+			p := gomonkey.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Delete",
+				func(_ instance.Instance, obj interface{}) error {
+					deleted = append(deleted, obj.(internals.ServiceInstance).Name)
+					return nil
+				})
+			defer p.Reset()
+			p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Update",
+				func(instance.Instance, interface{}, ...string) error { return nil })
+			p.ApplyFunc(audit.Fault, func(info audit.AuditLogInfo) { faults = append(faults, info) })
+
+			si := &internals.ServiceInstance{Name: "cache", Namespace: "prod", APIVersion: "cache.example.com/v1",
+				Resource: "redis", ForceDelete: true}
+			retry, err := (&Handler{}).Delete(context.Background(), si)
+			if retry != tt.wantRetry || (err != nil) != tt.wantRetry {
+				t.Errorf("Delete() = %v, %v, want retry %v", retry, err, tt.wantRetry)
+			}
+			if si.ProcessTime.IsZero() {
+				t.Errorf("Delete() did not start the delete timeout")
+			}
+			if !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Errorf("Delete() removed the records %v, want %v", deleted, tt.wantDeleted)
+			}
+			if len(faults) != len(tt.wantDeleted) {
+				t.Fatalf("Delete() audit faults = %+v, want one incident for each removed record", faults)
+			}
+			if len(faults) > 0 && strings.Contains(faults[0].Message, "may be left") != tt.wantLeft {
+				t.Errorf("Delete() audit message = %s, want the left custom resource %v", faults[0].Message,
+					tt.wantLeft)
+			}
+		})
+	}
+}
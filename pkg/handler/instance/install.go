@@ -17,13 +17,13 @@
 package instance
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/apis"
 	"github.com/kappital/kappital/pkg/apis/internals"
@@ -31,11 +31,7 @@ import (
 	"github.com/kappital/kappital/pkg/models"
 	"github.com/kappital/kappital/pkg/resource"
 	co "github.com/kappital/kappital/pkg/utils/operations"
-)
-
-const (
-	instanceProcessTimeout     = 3 * time.Minute
-	bindingReadyProcessTimeout = 2 * time.Minute
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 // Handler singleton pattern of install, delete, or upgrade the service instance
@@ -44,112 +40,126 @@ type Handler struct {
 }
 
 // BeforeInstall do some processes before install the service instance
-func (h *Handler) BeforeInstall(obj interface{}) (retry bool, err error) {
+func (h *Handler) BeforeInstall(ctx context.Context, obj interface{}) (retry bool, err error) {
 	serviceInstance, ok := obj.(*internals.ServiceInstance)
 	if !ok {
-		klog.Errorf("invalid object type for upgrade instance handler, expected: models.ServiceInstance, "+
+		requestid.Errorf(ctx, "invalid object type for upgrade instance handler, expected: models.ServiceInstance, "+
 			"actual: %s", reflect.TypeOf(obj).Name())
 		return false, nil
 	}
 	var operatorSuccess bool
 	defer func() {
 		if err == nil {
-			err = h.instance.UpdateInstallCondition(serviceInstance, instance.CreateResource, instance.Running, "")
+			err = h.instance.UpdateInstallCondition(ctx, serviceInstance, instance.CreateResource, instance.Running, "")
 			if err != nil {
-				klog.Errorf("failed to update instance %s condition, error: %v", serviceInstance.Name, err)
+				requestid.Errorf(ctx, "failed to update instance %s condition, error: %v", serviceInstance.Name, err)
 			}
 		} else if operatorSuccess {
-			innerErr := h.instance.UpdateInstallCondition(serviceInstance, instance.InstallOperator,
+			innerErr := h.instance.UpdateInstallCondition(ctx, serviceInstance, instance.InstallOperator,
 				instance.Success, "all servicebindings status are succeed")
 			if innerErr != nil {
-				klog.Errorf("failed to update instance %s condition, error: %v", serviceInstance.Name, err)
+				requestid.Errorf(ctx, "failed to update instance %s condition, error: %v", serviceInstance.Name, err)
 				retry = true
 			}
 		}
 	}()
-	sp, found, err := co.GetClusterOperation().GetServicePackageByName(serviceInstance.ServiceBindingName,
+	sp, found, err := co.GetClusterOperation().GetServicePackageByName(ctx, serviceInstance.ServiceBindingName,
 		apis.KappitalSystemNamespace)
 	if err != nil {
-		klog.Errorf("get service binding %s failed, err: %s", serviceInstance.ServiceBindingName, err)
+		requestid.Errorf(ctx, "get service binding %s failed, err: %s", serviceInstance.ServiceBindingName, err)
 		return true, err
 	}
 	if !found {
 		return true, nil
 	}
 
-	if err = updateProcessTimeout(serviceInstance, bindingReadyProcessTimeout); err != nil {
+	if err = updateProcessTimeout(ctx, serviceInstance, apis.GetProcessTimeoutConfig().BindingReadyTimeout); err != nil {
 		return true, err
 	}
 
 	if strContains(sp.Status.Phase, models.FailedStatusList) {
-		err = h.instance.UpdateProcessFailed(serviceInstance, models.StatusInitFailed,
-			fmt.Sprintf("servicebinding %s failed status in cluster %s",
-				serviceInstance.ServiceBindingName, serviceInstance.ClusterID))
+		msg := fmt.Sprintf("servicebinding %s failed status in cluster %s",
+			serviceInstance.ServiceBindingName, serviceInstance.ClusterID)
+		err = h.instance.UpdateProcessFailed(ctx, serviceInstance, models.StatusInitFailed, msg)
 		if err != nil {
 			// only for synchronize with database, other situation will not retry
-			klog.Errorf("failed to update failed record of operator %s, error: %v", serviceInstance.ID, err)
+			requestid.Errorf(ctx, "failed to update failed record of operator %s, error: %v", serviceInstance.ID, err)
 			return true, err
 		}
+		auditSystemAction(serviceInstance, "MarkInstanceInitFailed", updateAction, fmt.Errorf("the %s", msg))
 		return false, fmt.Errorf("operator process failed, stop install instance %s", serviceInstance.ID)
 	}
 
+	// the service binding is ready, thus reset the process time for renewing it with the instance install timeout
+	if err = resetProcessTimeout(ctx, serviceInstance); err != nil {
+		return true, err
+	}
 	operatorSuccess = true
 
 	return false, nil
 }
 
-func updateProcessTimeout(ins *internals.ServiceInstance, timeout time.Duration) error {
+func updateProcessTimeout(ctx context.Context, ins *internals.ServiceInstance, timeout time.Duration) error {
 	if !ins.ProcessTime.IsZero() {
 		return nil
 	}
 	ins.ProcessTime = time.Now().Add(timeout)
-	instanceDB := instance.Instance{}
-	err := instanceDB.Update(*ins, "process_time")
+	instanceDB := instance.Instance{}.WithContext(ctx)
+	err := instanceDB.Update(ins, "process_time")
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+func resetProcessTimeout(ctx context.Context, ins *internals.ServiceInstance) error {
+	if ins.ProcessTime.IsZero() {
+		return nil
+	}
+	ins.ProcessTime = time.Time{}
+	instanceDB := instance.Instance{}.WithContext(ctx)
+	return instanceDB.Update(ins, "process_time")
+}
+
 // Install deploy/apply the service custom resource into cluster
-func (h *Handler) Install(obj interface{}) (retry bool, err error) {
+func (h *Handler) Install(ctx context.Context, obj interface{}) (retry bool, err error) {
 	item, ok := obj.(*internals.ServiceInstance)
 	if !ok {
-		klog.Errorf("invalid object type for upgrade instance handler, expected: models.ServiceInstance, "+
+		requestid.Errorf(ctx, "invalid object type for upgrade instance handler, expected: models.ServiceInstance, "+
 			"actual: %s", reflect.TypeOf(obj).Name())
 		return false, nil
 	}
 	var exist bool
 	defer func() {
 		if exist {
-			err = h.instance.UpdateInstallCondition(item, instance.CreateResource, instance.Success,
+			err = h.instance.UpdateInstallCondition(ctx, item, instance.CreateResource, instance.Success,
 				"instance resource create success")
 			if err != nil {
-				klog.Errorf("failed to update instance %s condition, error: %v", item.Name, err)
+				requestid.Errorf(ctx, "failed to update instance %s condition, error: %v", item.Name, err)
 				retry = true
 			}
 		} else if err != nil {
-			innerErr := h.instance.UpdateInstallCondition(item, instance.CreateResource, instance.Running,
+			innerErr := h.instance.UpdateInstallCondition(ctx, item, instance.CreateResource, instance.Running,
 				fmt.Sprintf("failed to install instance, error: %v", err))
 			if innerErr != nil {
-				klog.Errorf("failed to update instance %s condition, error: %v", item.Name, innerErr)
+				requestid.Errorf(ctx, "failed to update instance %s condition, error: %v", item.Name, innerErr)
 				retry = true
 			}
 		}
 	}()
 
 	// renew the process time
-	if err = updateProcessTimeout(item, instanceProcessTimeout); err != nil {
-		klog.Errorf("failed to update process time for instance %s", item.Name)
+	if err = updateProcessTimeout(ctx, item, item.Timeouts.InstallDuration()); err != nil {
+		requestid.Errorf(ctx, "failed to update process time for instance %s", item.Name)
 		return true, err
 	}
 
-	retry, nextProcess, err := doesCustomResourceExist(item)
+	retry, nextProcess, err := doesCustomResourceExist(ctx, item)
 	if !nextProcess {
 		return retry, err
 	}
 
-	retry, nextProcess, err = deployCustomResource(item)
+	retry, nextProcess, err = deployCustomResource(ctx, item)
 	if !nextProcess {
 		return retry, err
 	}
@@ -158,7 +168,7 @@ func (h *Handler) Install(obj interface{}) (retry bool, err error) {
 }
 
 // AfterInstall install service instance does not need to implement this method
-func (h *Handler) AfterInstall(_ interface{}) (bool, error) {
+func (h *Handler) AfterInstall(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
 
@@ -183,15 +193,15 @@ func getGroupVersion(apiVersion string) (schema.GroupVersion, error) {
 	}, nil
 }
 
-func doesCustomResourceExist(item *internals.ServiceInstance) (bool, bool, error) {
+func doesCustomResourceExist(ctx context.Context, item *internals.ServiceInstance) (bool, bool, error) {
 	gv, err := getGroupVersion(item.APIVersion)
 	if err != nil {
-		klog.Errorf("cannot get group version, err: %s", err)
+		requestid.Errorf(ctx, "cannot get group version, err: %s", err)
 		return true, false, err
 	}
-	exist, err := co.GetClusterOperation().DoesCustomResourceExist(gv, item.Resource, item.Name, item.Namespace)
+	exist, err := co.GetClusterOperation().DoesCustomResourceExist(ctx, gv, item.Resource, item.Name, item.Namespace)
 	if err != nil {
-		klog.Errorf("query cr %s is exist failed, err: %s", item.Name, err)
+		requestid.Errorf(ctx, "query cr %s is exist failed, err: %s", item.Name, err)
 		return true, false, err
 	}
 	if exist {
@@ -200,16 +210,18 @@ func doesCustomResourceExist(item *internals.ServiceInstance) (bool, bool, error
 	return false, true, nil
 }
 
-func deployCustomResource(item *internals.ServiceInstance) (bool, bool, error) {
+func deployCustomResource(ctx context.Context, item *internals.ServiceInstance) (bool, bool, error) {
 	gv, err := getGroupVersion(item.APIVersion)
 	if err != nil {
-		klog.Errorf("cannot get group version, err: %s", err)
+		requestid.Errorf(ctx, "cannot get group version, err: %s", err)
 		return true, false, err
 	}
 	gvr := schema.GroupVersionResource{Group: gv.Group, Version: gv.Version, Resource: item.Resource}
-	klog.Infof("gvr %s", gvr)
-	if err = co.GetClusterOperation().DeployCustomResource(gvr, item.Namespace, item.RawResource); err != nil {
-		klog.Errorf("create cr %s failed, err: %s", item.Name, err)
+	requestid.Infof(ctx, "gvr %s", gvr)
+	err = co.GetClusterOperation().DeployCustomResource(ctx, gvr, item.Namespace, item.RawResource)
+	auditSystemAction(item, "DeployInstanceCustomResource", deployAction, err)
+	if err != nil {
+		requestid.Errorf(ctx, "create cr %s failed, err: %s", item.Name, err)
 		return true, false, err
 	}
 	return false, true, nil
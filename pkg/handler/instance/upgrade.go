@@ -16,17 +16,30 @@
 
 package instance
 
-// BeforeUpgrade todo: planning
-func (h *Handler) BeforeUpgrade(_ interface{}) (bool, error) {
+import (
+	"context"
+
+	"github.com/kappital/kappital/pkg/apis/internals"
+)
+
+// BeforeUpgrade start the upgrade timeout of the service instance, the upgrade itself is planning
+func (h *Handler) BeforeUpgrade(ctx context.Context, obj interface{}) (bool, error) {
+	si, ok := obj.(*internals.ServiceInstance)
+	if !ok {
+		return false, nil
+	}
+	if err := updateProcessTimeout(ctx, si, si.Timeouts.UpgradeDuration()); err != nil {
+		return true, err
+	}
 	return false, nil
 }
 
 // Upgrade todo: planning
-func (h *Handler) Upgrade(_ interface{}) (bool, error) {
+func (h *Handler) Upgrade(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
 
 // AfterUpgrade todo: planning
-func (h *Handler) AfterUpgrade(_ interface{}) (bool, error) {
+func (h *Handler) AfterUpgrade(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
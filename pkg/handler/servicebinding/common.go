@@ -17,6 +17,8 @@
 package servicebinding
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"time"
 
@@ -25,6 +27,13 @@ import (
 	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
 	"github.com/kappital/kappital/pkg/apis/internals"
 	"github.com/kappital/kappital/pkg/dao/servicebinding"
+	"github.com/kappital/kappital/pkg/utils/audit"
+)
+
+const (
+	deployAction    = "Deploy"
+	updateAction    = "Update"
+	uninstallAction = "Uninstall"
 )
 
 func getTypedObj(param interface{}) *internals.ServiceBinding {
@@ -37,23 +46,43 @@ func getTypedObj(param interface{}) *internals.ServiceBinding {
 	return binding
 }
 
-func updateSuccessStatus(binding *internals.ServiceBinding) error {
+func updateSuccessStatus(ctx context.Context, binding *internals.ServiceBinding) error {
 	binding.Status = enginev1alpha1.SucceededPhase
 	binding.ProcessTime = time.Time{}
 	binding.Message = ""
-	dbStore := servicebinding.ServiceBinding{}
-	return dbStore.Update(*binding)
+	dbStore := servicebinding.ServiceBinding{}.WithContext(ctx)
+	return dbStore.Update(binding)
 }
 
-func updateProcessTimeout(binding *internals.ServiceBinding, timeout time.Duration) error {
+func updateProcessTimeout(ctx context.Context, binding *internals.ServiceBinding, timeout time.Duration) error {
 	if !binding.ProcessTime.IsZero() {
 		return nil
 	}
 	binding.ProcessTime = time.Now().Add(timeout)
-	dbStore := servicebinding.ServiceBinding{}
-	err := dbStore.Update(*binding)
+	dbStore := servicebinding.ServiceBinding{}.WithContext(ctx)
+	err := dbStore.Update(binding)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// auditSystemAction write the audit log of the action which is done to the service binding for the initiator
+func auditSystemAction(binding *internals.ServiceBinding, traceName, action string, err error) {
+	audit.SystemActionLog(binding.Initiator, traceName, action, binding.Name, err)
+}
+
+// auditForceDelete write the incident audit log of the service binding which is removed by force, and the left error
+// tells why its service package may be left in the cluster
+func auditForceDelete(binding *internals.ServiceBinding, traceName string, left error) {
+	info := audit.SystemActionInfo(binding.Initiator, traceName, uninstallAction, binding.Name)
+	info.Message = "the record is removed by force"
+	switch {
+	case left != nil:
+		info.Message += fmt.Sprintf(", the service package %s/%s and its resources in cluster %s may be left, "+
+			"err: %v", binding.Namespace, binding.Name, binding.ClusterName, left)
+	case binding.RemoveFinalizers:
+		info.Message += ", the finalizers of its service package are cleared"
+	}
+	audit.Fault(info)
+}
@@ -17,113 +17,161 @@
 package servicebinding
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/klog/v2"
 
 	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
 	"github.com/kappital/kappital/pkg/apis/internals"
 	"github.com/kappital/kappital/pkg/dao/instance"
 	"github.com/kappital/kappital/pkg/dao/servicebinding"
 	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 // BeforeDelete do some processes before delete the service binding
-func (h *Handler) BeforeDelete(obj interface{}) (retry bool, err error) {
+func (h *Handler) BeforeDelete(ctx context.Context, obj interface{}) (retry bool, err error) {
 	binding := getTypedObj(obj)
 	// renew the timeout period at the first time
-	if err = updateProcessTimeout(binding, bindingProcessTimeout); err != nil {
+	if err = updateProcessTimeout(ctx, binding, binding.Timeouts.DeleteDuration()); err != nil {
 		return true, err
 	}
 
-	insExists, err := instanceExists(binding)
+	// the instances are deleted with the service binding by cascade, and their custom resources must be removed
+	// before the service package
+	names, err := remainingInstances(ctx, binding)
 	if err != nil {
-		klog.Errorf("failed to check instance for binding[%s], error: %v", binding.Name, err)
+		requestid.Errorf(ctx, "failed to check instance for binding[%s], error: %v", binding.Name, err)
 		return true, err
 	}
 
-	if insExists {
-		return true, fmt.Errorf("[delete binding] instance exists, waiting for next loop")
+	if len(names) > 0 {
+		return true, fmt.Errorf("[delete binding] waiting for the instances %s to be deleted",
+			strings.Join(names, ", "))
 	}
 
 	return false, nil
 }
 
 // Delete the cloud native service instance in cluster
-func (h *Handler) Delete(obj interface{}) (retry bool, err error) {
+func (h *Handler) Delete(ctx context.Context, obj interface{}) (retry bool, err error) {
 	binding := getTypedObj(obj)
-	if err = updateProcessTimeout(binding, bindingProcessTimeout); err != nil {
+	if err = updateProcessTimeout(ctx, binding, binding.Timeouts.DeleteDuration()); err != nil {
 		return true, err
 	}
-	if err = deleteResources(binding); err != nil {
-		klog.Errorf("failed to delete resource for binding[%s]", binding.Name)
+	if binding.ForceDelete {
+		return forceDelete(ctx, binding)
+	}
+	if err = deleteResources(ctx, binding); err != nil {
+		requestid.Errorf(ctx, "failed to delete resource for binding[%s]", binding.Name)
 		return true, err
 	}
 
-	return deleteRecord(binding)
+	return deleteRecord(ctx, binding)
 }
 
 // AfterDelete delete service binding does not need to implement this method
-func (h *Handler) AfterDelete(_ interface{}) (bool, error) {
+func (h *Handler) AfterDelete(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
 
-func instanceExists(binding *internals.ServiceBinding) (bool, error) {
+// remainingInstances the namespaced names of the instances of the service binding which are not deleted yet
+func remainingInstances(ctx context.Context, binding *internals.ServiceBinding) ([]string, error) {
 	filter := map[string]string{"service_binding_id": binding.ID}
 
-	dbStore := instance.Instance{}
+	dbStore := instance.Instance{}.WithContext(ctx)
 	obj, err := dbStore.GetList(filter)
 	if err != nil {
-		klog.Errorf("failed to query instances for binding[%s] from db, error: %s", binding.Name, err)
-		return true, err
+		requestid.Errorf(ctx, "failed to query instances for binding[%s] from db, error: %s", binding.Name, err)
+		return nil, err
 	}
 
 	instances, ok := obj.([]internals.ServiceInstance)
 	if !ok {
-		klog.Errorf("failed to trans instances for binding[%s] from db, error: %s", binding.Name, err)
-		return true, err
+		return nil, fmt.Errorf("failed to trans instances for binding[%s] from db", binding.Name)
+	}
+
+	names := make([]string, 0, len(instances))
+	for _, ins := range instances {
+		names = append(names, ins.Namespace+"/"+ins.Name)
 	}
+	sort.Strings(names)
+	return names, nil
+}
 
-	if len(instances) > 0 {
-		return true, nil
+// forceDelete delete the service package of the service binding without waiting for its resources to be removed from
+// cluster, then remove the record. The record is kept and retried if the service package cannot be deleted from the
+// reachable cluster, but it is removed anyway if the cluster is unreachable, and the service package which may be
+// left is written into the incident audit log.
+func forceDelete(ctx context.Context, binding *internals.ServiceBinding) (bool, error) {
+	var left error
+	err := co.ForceDeleteCustomResource(ctx, binding.ClusterName, enginev1alpha1.ServicePackageGroupVersionResource,
+		binding.Name, binding.Namespace, binding.RemoveFinalizers)
+	if errors.Is(err, co.ErrClusterUnreachable) {
+		left, err = err, nil
 	}
+	if err != nil {
+		auditSystemAction(binding, "ForceDeleteServicePackage", uninstallAction, err)
+		requestid.Errorf(ctx, "[delete binding] delete binding %s resource by force failed, err: %s", binding.Name,
+			err)
+		return true, err
+	}
+	dbStore := servicebinding.ServiceBinding{}.WithContext(ctx)
+	if err = dbStore.Delete(*binding); err != nil {
+		auditSystemAction(binding, "DeleteServiceBindingRecord", uninstallAction, err)
+		return true, err
+	}
+	auditForceDelete(binding, "ForceDeleteServiceBinding", left)
 	return false, nil
 }
 
-func deleteResources(binding *internals.ServiceBinding) error {
+func deleteResources(ctx context.Context, binding *internals.ServiceBinding) error {
 	gvr := schema.GroupVersionResource{
 		Group:    enginev1alpha1.ServicePackageGroupVersionResource.Group,
 		Version:  enginev1alpha1.ServicePackageGroupVersionResource.Version,
 		Resource: enginev1alpha1.ServicePackageGroupVersionResource.Resource,
 	}
-	sp, found, err := co.GetClusterOperation().GetServicePackageByName(binding.Name, binding.Namespace)
+	sp, found, err := co.GetClusterOperation().GetServicePackageByName(ctx, binding.Name, binding.Namespace)
 	if err != nil {
-		klog.Errorf("[delete binding] get binding %s resource failed, err: %s", binding.Name, err)
+		requestid.Errorf(ctx, "[delete binding] get binding %s resource failed, err: %s", binding.Name, err)
 		return err
 	}
 	if !found {
 		return nil
 	}
 	// delete the service package resources, such as cluster role, service account, and etc.
+	cleared := len(sp.Spec.Version) == 0
 	sp.Spec.Version = ""
-	if err = co.GetClusterOperation().UpdateCustomResource(gvr, binding.Namespace, sp); err != nil {
+	err = co.GetClusterOperation().UpdateCustomResource(ctx, gvr, binding.Namespace, sp)
+	// the update is retried in each loop, only the first one changes the cluster
+	if !cleared || err != nil {
+		auditSystemAction(binding, "ClearServicePackageResources", updateAction, err)
+	}
+	if err != nil {
 		return err
 	}
 	// when all resources have been deleted, delete the service package cr in cluster
 	if sp.Status.Phase == enginev1alpha1.DeletingPhase {
 		return fmt.Errorf("waiting for resource delete")
 	}
-	if err := co.GetClusterOperation().DeleteCustomResource(gvr, binding.Name, binding.Namespace); err != nil {
-		klog.Errorf("[delete binding] delete binding %s resource failed, err: %s", binding.Name, err)
+	err = co.GetClusterOperation().DeleteCustomResource(ctx, gvr, binding.Name, binding.Namespace)
+	auditSystemAction(binding, "DeleteServicePackage", uninstallAction, err)
+	if err != nil {
+		requestid.Errorf(ctx, "[delete binding] delete binding %s resource failed, err: %s", binding.Name, err)
 		return err
 	}
 	return fmt.Errorf("waiting for resource delete")
 }
 
-func deleteRecord(binding *internals.ServiceBinding) (bool, error) {
-	dbStore := servicebinding.ServiceBinding{}
-	if err := dbStore.Delete(*binding); err != nil {
+func deleteRecord(ctx context.Context, binding *internals.ServiceBinding) (bool, error) {
+	dbStore := servicebinding.ServiceBinding{}.WithContext(ctx)
+	err := dbStore.Delete(*binding)
+	auditSystemAction(binding, "DeleteServiceBindingRecord", uninstallAction, err)
+	if err != nil {
 		return true, err
 	}
 	return false, nil
@@ -17,67 +17,66 @@
 package servicebinding
 
 import (
+	"context"
 	"fmt"
-	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/apis"
 	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
 	"github.com/kappital/kappital/pkg/apis/internals"
 	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
-const bindingProcessTimeout = 3 * time.Minute
-
 // Handler singleton pattern of install, delete, or upgrade the service binding
 type Handler struct{}
 
 // BeforeInstall do some processes before install the service binding
-func (h *Handler) BeforeInstall(_ interface{}) (bool, error) {
+func (h *Handler) BeforeInstall(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
 
 // Install the cloud native service instance in cluster
-func (h *Handler) Install(obj interface{}) (retry bool, err error) {
+func (h *Handler) Install(ctx context.Context, obj interface{}) (retry bool, err error) {
 	serviceBinding := getTypedObj(obj)
 	// renew the timeout period at the first time
-	if err = updateProcessTimeout(serviceBinding, bindingProcessTimeout); err != nil {
-		klog.Errorf("update process time for binding %s error: %v", serviceBinding.Name, err)
+	if err = updateProcessTimeout(ctx, serviceBinding, serviceBinding.Timeouts.InstallDuration()); err != nil {
+		requestid.Errorf(ctx, "update process time for binding %s error: %v", serviceBinding.Name, err)
 		return true, err
 	}
 
-	exist, ready, er := h.checkBindingStatus(serviceBinding)
+	exist, ready, er := h.checkBindingStatus(ctx, serviceBinding)
 	if er != nil {
-		klog.Errorf("failed to check binding status, error: %v", er)
+		requestid.Errorf(ctx, "failed to check binding status, error: %v", er)
 		return true, er
 	}
 	if ready {
 		// binding ready, exist the asynchronizing and will not retry
-		klog.Infof("[install binding] binding %s is already succeed, stop retry", serviceBinding.Name)
+		requestid.Infof(ctx, "[install binding] binding %s is already succeed, stop retry", serviceBinding.Name)
 		return false, nil
 	}
 	if exist {
 		return true, fmt.Errorf("[install binding] binding is already exist but not succeed, rechecking")
 	}
 
-	err = h.createServiceBinding(serviceBinding)
+	err = h.createServiceBinding(ctx, serviceBinding)
 	if err != nil {
-		klog.Errorf("failed to create binding servicepackage, error: %v", err)
+		requestid.Errorf(ctx, "failed to create binding servicepackage, error: %v", err)
 		return true, err
 	}
 
-	klog.Infof("[install binding] binding %s servicepackage is created, check for next loop", serviceBinding.Name)
+	requestid.Infof(ctx, "[install binding] binding %s servicepackage is created, check for next loop",
+		serviceBinding.Name)
 	return true, nil
 }
 
 // AfterInstall install service binding does not need to implement this method
-func (h *Handler) AfterInstall(_ interface{}) (bool, error) {
+func (h *Handler) AfterInstall(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
 
-func (h *Handler) createServiceBinding(binding *internals.ServiceBinding) error {
+func (h *Handler) createServiceBinding(ctx context.Context, binding *internals.ServiceBinding) error {
 	servicePackage := enginev1alpha1.ServicePackage{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       enginev1alpha1.ServicePackageKind,
@@ -104,23 +103,25 @@ func (h *Handler) createServiceBinding(binding *internals.ServiceBinding) error
 	var err error
 	servicePackage.Spec.Resources, err = enginev1alpha1.TranslateResourcesToBase64(serviceResource)
 	if err != nil {
-		klog.Errorf("trans binding %s service resource to base64 failed", binding.Name)
+		requestid.Errorf(ctx, "trans binding %s service resource to base64 failed", binding.Name)
 		return err
 	}
 
-	if err = co.GetClusterOperation().DeployCustomResource(enginev1alpha1.ServicePackageGroupVersionResource,
-		apis.KappitalSystemNamespace, servicePackage); err != nil {
-		klog.Errorf("create service binding %s failed.", binding.Name)
+	err = co.GetClusterOperation().DeployCustomResource(ctx, enginev1alpha1.ServicePackageGroupVersionResource,
+		apis.KappitalSystemNamespace, servicePackage)
+	if err != nil {
+		requestid.Errorf(ctx, "create service binding %s failed.", binding.Name)
 	}
+	auditSystemAction(binding, "DeployServicePackage", deployAction, err)
 
 	return err
 }
 
-func (h *Handler) checkBindingStatus(binding *internals.ServiceBinding) (exist, ready bool, err error) {
-	subExist, ready := checkBindingReady(binding)
+func (h *Handler) checkBindingStatus(ctx context.Context, binding *internals.ServiceBinding) (exist, ready bool, err error) {
+	subExist, ready := checkBindingReady(ctx, binding)
 	if ready {
-		if err = updateSuccessStatus(binding); err != nil {
-			klog.Errorf("[binding handler] update binding status failed, error: %v", err)
+		if err = updateSuccessStatus(ctx, binding); err != nil {
+			requestid.Errorf(ctx, "[binding handler] update binding status failed, error: %v", err)
 			return true, true, err
 		}
 	}
@@ -128,8 +129,8 @@ func (h *Handler) checkBindingStatus(binding *internals.ServiceBinding) (exist,
 }
 
 // checkBindingReady return retry and errMsg
-func checkBindingReady(binding *internals.ServiceBinding) (bool, bool) {
-	sp, found, err := co.GetClusterOperation().GetServicePackageByName(binding.Name, apis.KappitalSystemNamespace)
+func checkBindingReady(ctx context.Context, binding *internals.ServiceBinding) (bool, bool) {
+	sp, found, err := co.GetClusterOperation().GetServicePackageByName(ctx, binding.Name, apis.KappitalSystemNamespace)
 	if err != nil {
 		return true, false
 	}
@@ -16,17 +16,26 @@
 
 package servicebinding
 
-// BeforeUpgrade todo: planning
-func (h *Handler) BeforeUpgrade(_ interface{}) (bool, error) {
+import "context"
+
+// BeforeUpgrade start the upgrade timeout of the service binding, the upgrade itself is planning
+func (h *Handler) BeforeUpgrade(ctx context.Context, obj interface{}) (bool, error) {
+	binding := getTypedObj(obj)
+	if binding == nil {
+		return false, nil
+	}
+	if err := updateProcessTimeout(ctx, binding, binding.Timeouts.UpgradeDuration()); err != nil {
+		return true, err
+	}
 	return false, nil
 }
 
 // Upgrade todo: planning
-func (h *Handler) Upgrade(_ interface{}) (bool, error) {
+func (h *Handler) Upgrade(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
 
 // AfterUpgrade todo: planning
-func (h *Handler) AfterUpgrade(_ interface{}) (bool, error) {
+func (h *Handler) AfterUpgrade(_ context.Context, _ interface{}) (bool, error) {
 	return false, nil
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/kappital/kappital/pkg/kappctl/cmd/delete"
 	"github.com/kappital/kappital/pkg/kappctl/cmd/get"
 	"github.com/kappital/kappital/pkg/kappctl/cmd/initiate"
+	"github.com/kappital/kappital/pkg/kappctl/cmd/version"
 )
 
 // NewKappctlCmd create the kappctl root command
@@ -38,6 +39,7 @@ func NewKappctlCmd() *cobra.Command {
 	cmd.AddCommand(get.NewCommand())
 	cmd.AddCommand(delete.NewCommand())
 	cmd.AddCommand(create.NewCommand())
+	cmd.AddCommand(version.NewCommand())
 	return cmd
 }
 
@@ -43,6 +43,7 @@ type operation struct {
 	managerKeyFilePath  string
 	managerCAFilePath   string
 	managerSkipVerify   bool
+	managerToken        string
 }
 
 func (o operation) isValid() bool {
@@ -57,6 +58,7 @@ func (o *operation) cleanSpaceCharacter() {
 	o.managerHTTPSPort = strings.ReplaceAll(o.managerHTTPSPort, " ", "")
 	o.managerCertFilePath = strings.ReplaceAll(o.managerCertFilePath, " ", "")
 	o.managerKeyFilePath = strings.ReplaceAll(o.managerKeyFilePath, " ", "")
+	o.managerToken = strings.TrimSpace(o.managerToken)
 }
 
 func (o operation) constructNewConfig() kappctl.Config {
@@ -66,6 +68,7 @@ func (o operation) constructNewConfig() kappctl.Config {
 	cfg.ManagerClientKeyData = file.ReadFileToBase64(o.managerKeyFilePath)
 	cfg.ManagerCA = file.ReadFileToBase64(o.managerCAFilePath)
 	cfg.ManagerSkipVerify = o.managerSkipVerify
+	cfg.ManagerToken = o.managerToken
 	return cfg
 }
 
@@ -89,6 +92,7 @@ func NewCommand() *cobra.Command {
 	kappctl.ManagerClientKeyFile.AddStringFlag(&o.managerKeyFilePath, cmd)
 	kappctl.ManagerCAFile.AddStringFlag(&o.managerCAFilePath, cmd)
 	kappctl.ManagerSkipVerify.AddBoolFlag(&o.managerSkipVerify, cmd)
+	kappctl.ManagerToken.AddStringFlag(&o.managerToken, cmd)
 	return cmd
 }
 
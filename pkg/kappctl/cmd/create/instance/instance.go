@@ -18,11 +18,11 @@ package instance
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
@@ -34,7 +34,6 @@ import (
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	"github.com/kappital/kappital/pkg/kappctl"
 	"github.com/kappital/kappital/pkg/utils/convert"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 type operation struct {
@@ -43,8 +42,9 @@ type operation struct {
 	serviceName string
 	dirPath     string
 
-	cns          *svcv1alpha1.CloudNativeService
-	resourcePath string
+	cns                *svcv1alpha1.CloudNativeService
+	resourcePath       string
+	deletionProtection bool
 }
 
 // Cmd singleton pattern of create Instance to the cluster
@@ -65,6 +65,7 @@ func (o *operation) NewCommand() *cobra.Command {
 	}
 	kappctl.FilePath.AddStringFlag(&o.resourcePath, cmd)
 	kappctl.PackageDir.AddStringFlag(&o.dirPath, cmd)
+	kappctl.DeletionProtection.AddBoolFlag(&o.deletionProtection, cmd)
 	return cmd
 }
 
@@ -101,7 +102,8 @@ func (o *operation) PreRunE(args []string) error {
 // RunE create the service instance to cluster
 func (o *operation) RunE() error {
 	sic := instancev1alpha1.ServiceInstanceCreation{
-		ClusterID: apis.DefaultCluster,
+		ClusterID:          apis.DefaultCluster,
+		DeletionProtection: o.deletionProtection,
 	}
 	if o.cns != nil {
 		sic.Service = *(o.cns)
@@ -120,20 +122,13 @@ func (o *operation) RunE() error {
 			return err
 		}
 	}
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodPost,
-		Path:      o.config.BuildManagerURL(kappctl.DeployInstanceURL, []interface{}{o.serviceName, apis.DefaultCluster}),
-		Body:      sic,
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
+	cli, err := o.config.NewClient()
 	if err != nil {
-		return fmt.Errorf("deploy service %s failed, err: %s", o.serviceName, err)
+		return err
 	}
-	if code != http.StatusOK {
-		return fmt.Errorf("deploy service %s failed, statusCode: %d, detail: %s", o.serviceName, code, string(buf))
+	if err = cli.Cluster(apis.DefaultCluster).Instances(o.serviceName, "").Create(context.Background(),
+		sic); err != nil {
+		return fmt.Errorf("deploy service %s failed, err: %s", o.serviceName, err)
 	}
 	fmt.Printf("deploy service %s success.\n", o.serviceName)
 	return nil
@@ -32,8 +32,8 @@ import (
 	svcv1alpha1 "github.com/kappital/kappital/pkg/apis/service/v1alpha1"
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	"github.com/kappital/kappital/pkg/kappctl"
+	"github.com/kappital/kappital/pkg/kappctl/kappctltest"
 	"github.com/kappital/kappital/pkg/utils/convert"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 func Test_getCRContent(t *testing.T) {
@@ -190,20 +190,19 @@ func Test_operation_RunE(t *testing.T) {
 				}},
 			},
 		}
-		p := gomonkey.ApplyFuncSeq(gateway.CommonUtilRequest, []gomonkey.OutputCell{
-			{Values: gomonkey.Params{0, nil, fmt.Errorf("mock error")}},
-			{Values: gomonkey.Params{0, nil, nil}},
-			{Values: gomonkey.Params{http.StatusOK, nil, nil}},
-			{Values: gomonkey.Params{http.StatusOK, nil, nil}},
-		})
-		defer p.Reset()
-		err = o.RunE()
-		convey.So(err, convey.ShouldNotBeNil)
+		srv, config := kappctltest.FakeManager(http.StatusInternalServerError, "success")
+		o.config = config
 		err = o.RunE()
+		srv.Close()
 		convey.So(err, convey.ShouldNotBeNil)
+		srv, config = kappctltest.FakeManager(http.StatusOK, "success")
+		defer srv.Close()
+		o.config = config
 		err = o.RunE()
 		convey.So(err, convey.ShouldBeNil)
 
+		p := gomonkey.NewPatches()
+		defer p.Reset()
 		o.resourcePath = "xx"
 		p.ApplyFuncSeq(getCRContent, []gomonkey.OutputCell{
 			{Values: gomonkey.Params{nil, fmt.Errorf("mock error")}},
@@ -17,9 +17,8 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
 
@@ -28,18 +27,13 @@ import (
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	"github.com/kappital/kappital/pkg/kappctl"
 	"github.com/kappital/kappital/pkg/utils/convert"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 type operation struct {
 	config *kappctl.Config
 
-	cns *svcv1alpha1.CloudNativeService
-}
-
-type resp struct {
-	ServiceID   string `json:"ID"`
-	ServiceName string `json:"name"`
+	cns                *svcv1alpha1.CloudNativeService
+	deletionProtection bool
 }
 
 // Cmd singleton pattern of create Service to the cluster
@@ -58,6 +52,7 @@ func (o *operation) NewCommand() *cobra.Command {
 			return o.RunE()
 		},
 	}
+	kappctl.DeletionProtection.AddBoolFlag(&o.deletionProtection, cmd)
 	return cmd
 }
 
@@ -78,32 +73,20 @@ func (o *operation) PreRunE(args []string) error {
 // RunE create the service to cluster
 func (o *operation) RunE() error {
 	sic := instancev1alpha1.ServiceInstanceCreation{
-		InstanceName: o.cns.Spec.Description.Name,
-		ClusterID:    apis.DefaultCluster,
-		Service:      *(o.cns),
+		InstanceName:       o.cns.Spec.Description.Name,
+		ClusterID:          apis.DefaultCluster,
+		Service:            *(o.cns),
+		DeletionProtection: o.deletionProtection,
 	}
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodPost,
-		Path:      o.config.BuildManagerURL(kappctl.DeployServiceURL, []interface{}{}),
-		Body:      sic,
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
+	cli, err := o.config.NewClient()
 	if err != nil {
-		return fmt.Errorf("deploy service %s failed, err: %s", o.cns.Name, err)
-	}
-	if code != http.StatusOK {
-		return fmt.Errorf("deploy service %s failed, statusCode: %d, detail: %s", o.cns.Name, code, string(buf))
+		return err
 	}
-
-	var result resp
-	if err = json.Unmarshal(buf, &result); err != nil {
-		return fmt.Errorf("json Unmarshal response %s failed, err: %s", string(buf), err)
+	result, err := cli.ServiceBindings().Create(context.Background(), sic)
+	if err != nil {
+		return fmt.Errorf("deploy service %s failed, err: %s", o.cns.Name, err)
 	}
-
 	fmt.Printf("deploy service %s success.\n%s\n", o.cns.Name,
-		fmt.Sprintf("{'service_name': %s, 'service_id': %s}\n", o.cns.Spec.Description.Name, result.ServiceID))
+		fmt.Sprintf("{'service_name': %s, 'service_id': %s}\n", o.cns.Spec.Description.Name, result.ID))
 	return nil
 }
@@ -27,8 +27,8 @@ import (
 
 	svcv1alpha1 "github.com/kappital/kappital/pkg/apis/service/v1alpha1"
 	"github.com/kappital/kappital/pkg/kappctl"
+	"github.com/kappital/kappital/pkg/kappctl/kappctltest"
 	"github.com/kappital/kappital/pkg/utils/convert"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 func Test_operation_NewCommand(t *testing.T) {
@@ -63,21 +63,23 @@ func Test_operation_PreRunE(t *testing.T) {
 func Test_operation_RunE(t *testing.T) {
 	convey.Convey("Test operation RunE", t, func() {
 		o := &operation{config: &kappctl.Config{}, cns: &svcv1alpha1.CloudNativeService{}}
-		p := gomonkey.ApplyFuncSeq(gateway.CommonUtilRequest, []gomonkey.OutputCell{
-			{Values: gomonkey.Params{-1, nil, fmt.Errorf("mock error")}},
-			{Values: gomonkey.Params{-1, nil, nil}},
-			{Values: gomonkey.Params{http.StatusOK, nil, nil}},
-			{Values: gomonkey.Params{http.StatusOK, []byte("{\"ID\":\"x\",\"name\":\"xxx\"}"), nil}},
-		})
-		defer p.Reset()
-
 		err := o.RunE()
 		convey.So(err, convey.ShouldNotBeNil)
-		err = o.RunE()
-		convey.So(err, convey.ShouldNotBeNil)
-		err = o.RunE()
-		convey.So(err, convey.ShouldNotBeNil)
-		err = o.RunE()
-		convey.So(err, convey.ShouldBeNil)
+
+		for _, reply := range []struct {
+			code    int
+			body    string
+			wantErr bool
+		}{
+			{code: http.StatusInternalServerError, wantErr: true},
+			{code: http.StatusOK, body: "invalid", wantErr: true},
+			{code: http.StatusOK, body: "{\"ID\":\"x\",\"Name\":\"xxx\"}"},
+		} {
+			srv, config := kappctltest.FakeManager(reply.code, []byte(reply.body))
+			o.config = config
+			err = o.RunE()
+			srv.Close()
+			convey.So(err != nil, convey.ShouldEqual, reply.wantErr)
+		}
 	})
 }
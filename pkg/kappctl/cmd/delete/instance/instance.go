@@ -17,21 +17,23 @@
 package instance
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
 
+	"github.com/kappital/kappital/pkg/client"
 	"github.com/kappital/kappital/pkg/kappctl"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 type operation struct {
 	config *kappctl.Config
 
-	serviceName  string
-	instanceName string
-	clusterName  string
+	serviceName      string
+	instanceName     string
+	clusterName      string
+	force            bool
+	removeFinalizers bool
 }
 
 // Cmd singleton pattern of delete Instance to the cluster
@@ -61,12 +63,18 @@ func (o *operation) NewCommand() *cobra.Command {
 	kappctl.Cluster.AddStringFlag(&o.clusterName, cmd)
 	kappctl.ServiceName.AddStringFlag(&o.serviceName, cmd)
 	kappctl.ServiceName.MarkFlagRequired(cmd)
+	kappctl.Force.AddBoolFlag(&o.force, cmd)
+	kappctl.RemoveFinalizers.AddBoolFlag(&o.removeFinalizers, cmd)
 	return cmd
 }
 
 // PreRunE run before deleting the service instance, check does the arguments has some problem or not
 func (o *operation) PreRunE(args []string) error {
 	o.instanceName = args[0]
+	if o.removeFinalizers && !o.force {
+		return fmt.Errorf("the flag --%s can only be used with --%s", kappctl.RemoveFinalizers.GetFlagName(),
+			kappctl.Force.GetFlagName())
+	}
 	var err error
 	o.config, err = kappctl.GetConfig()
 	if err != nil {
@@ -77,20 +85,13 @@ func (o *operation) PreRunE(args []string) error {
 
 // RunE delete the service instance to cluster
 func (o *operation) RunE() error {
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodDelete,
-		Path:      o.config.BuildManagerURL(kappctl.DeleteInstanceURL, []interface{}{o.serviceName, o.instanceName, o.clusterName}),
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
+	cli, err := o.config.NewClient()
 	if err != nil {
-		return fmt.Errorf("delete service instance %s failed, err: %s", o.instanceName, err)
+		return err
 	}
-	if code != http.StatusOK {
-		return fmt.Errorf("delete service instance %s failed, statusCode: %d, detail: %s",
-			o.instanceName, code, string(buf))
+	if err = cli.Cluster(o.clusterName).Instances(o.serviceName, "").Delete(context.Background(), o.instanceName,
+		client.DeleteOptions{Force: o.force, RemoveFinalizers: o.removeFinalizers}); err != nil {
+		return fmt.Errorf("delete service instance %s failed, err: %s", o.instanceName, err)
 	}
 	fmt.Printf("delete service instance %s success.\n", o.instanceName)
 	return nil
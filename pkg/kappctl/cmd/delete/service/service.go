@@ -17,20 +17,23 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
 
+	"github.com/kappital/kappital/pkg/client"
 	"github.com/kappital/kappital/pkg/kappctl"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 type operation struct {
 	config *kappctl.Config
 
-	serviceName string
-	clusterName string
+	serviceName      string
+	clusterName      string
+	cascade          bool
+	force            bool
+	removeFinalizers bool
 }
 
 // Cmd singleton pattern of delete Whole Service to the cluster
@@ -57,12 +60,19 @@ func (o *operation) NewCommand() *cobra.Command {
 		},
 	}
 	kappctl.Cluster.AddStringFlag(&o.clusterName, cmd)
+	kappctl.Cascade.AddBoolFlag(&o.cascade, cmd)
+	kappctl.Force.AddBoolFlag(&o.force, cmd)
+	kappctl.RemoveFinalizers.AddBoolFlag(&o.removeFinalizers, cmd)
 	return cmd
 }
 
 // PreRunE run before deleting the service, check does the arguments has some problem or not
 func (o *operation) PreRunE(args []string) error {
 	o.serviceName = args[0]
+	if o.removeFinalizers && !o.force {
+		return fmt.Errorf("the flag --%s can only be used with --%s", kappctl.RemoveFinalizers.GetFlagName(),
+			kappctl.Force.GetFlagName())
+	}
 	var err error
 	o.config, err = kappctl.GetConfig()
 	if err != nil {
@@ -73,20 +83,14 @@ func (o *operation) PreRunE(args []string) error {
 
 // RunE delete the service to cluster
 func (o *operation) RunE() error {
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodDelete,
-		Path:      o.config.BuildManagerURL(kappctl.DeleteServiceURL, []interface{}{o.serviceName, o.clusterName}),
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
+	cli, err := o.config.NewClient()
 	if err != nil {
-		return fmt.Errorf("delete service instance %s failed, err: %s", o.serviceName, err)
+		return err
 	}
-	if code != http.StatusOK {
-		return fmt.Errorf("delete service %s failed, statusCode: %d, detail: %s",
-			o.serviceName, code, string(buf))
+	opts := client.DeleteOptions{Cascade: o.cascade, Force: o.force, RemoveFinalizers: o.removeFinalizers}
+	if err = cli.Cluster(o.clusterName).ServiceBindings().Delete(context.Background(), o.serviceName,
+		opts); err != nil {
+		return fmt.Errorf("delete service %s failed, err: %s", o.serviceName, err)
 	}
 	fmt.Printf("delete service %s success.\n", o.serviceName)
 	return nil
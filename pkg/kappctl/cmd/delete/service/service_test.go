@@ -24,7 +24,7 @@ import (
 	"github.com/smartystreets/goconvey/convey"
 
 	"github.com/kappital/kappital/pkg/kappctl"
-	"github.com/kappital/kappital/pkg/utils/gateway"
+	"github.com/kappital/kappital/pkg/kappctl/kappctltest"
 )
 
 func Test_operation_NewCommand(t *testing.T) {
@@ -57,23 +57,21 @@ func Test_operation_PreRunE(t *testing.T) {
 func Test_operation_RunE(t *testing.T) {
 	convey.Convey("test delete service operation RunE", t, func() {
 		o := &operation{config: &kappctl.Config{}}
-		convey.Convey("case 1: gateway.CommonUtilRequest get error for delete service", func() {
+		convey.Convey("case 1: cannot create the client of the manager for delete service", func() {
 			err := o.RunE()
 			convey.So(err, convey.ShouldNotBeNil)
 		})
-		convey.Convey("case 2: gateway.CommonUtilRequest get failed http code for delete service", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return -1, nil, nil
-			})
-			defer p.Reset()
+		convey.Convey("case 2: the manager replies the failed http code for delete service", func() {
+			srv, config := kappctltest.FakeManager(http.StatusInternalServerError, nil)
+			defer srv.Close()
+			o.config = config
 			err := o.RunE()
 			convey.So(err, convey.ShouldNotBeNil)
 		})
-		convey.Convey("case 3: gateway.CommonUtilRequest get valid data for delete service", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return http.StatusOK, []byte{}, nil
-			})
-			defer p.Reset()
+		convey.Convey("case 3: the manager replies the success for delete service", func() {
+			srv, config := kappctltest.FakeManager(http.StatusOK, []byte("success"))
+			defer srv.Close()
+			o.config = config
 			err := o.RunE()
 			convey.So(err, convey.ShouldBeNil)
 		})
@@ -17,18 +17,19 @@
 package instance
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	out "github.com/kappital/kappital/pkg/apis/view"
+	"github.com/kappital/kappital/pkg/client"
 	"github.com/kappital/kappital/pkg/kappctl"
 	"github.com/kappital/kappital/pkg/models"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 type operation struct {
@@ -121,24 +122,14 @@ func (o *operation) RunE() error {
 }
 
 func (o *operation) getAllServiceInstances() ([]interface{}, error) {
-	// 1. Get deployed service list
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodGet,
-		Path:      o.config.BuildManagerURL(kappctl.GetServicesURL, []interface{}{o.clusterName}),
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
+	cli, err := o.config.NewClient()
 	if err != nil {
 		return nil, err
 	}
-	if code != http.StatusOK {
-		return nil, fmt.Errorf("cannot get the service list, because get the http code: %d", code)
-	}
-	var svcs []instancev1alpha1.CloudNativeServiceInstance
-	if err = json.Unmarshal(buf, &svcs); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal http response: %s", err)
+	// 1. Get deployed service list
+	svcs, err := cli.Cluster(o.clusterName).ServiceBindings().List(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the service list, err: %v", err)
 	}
 	if len(svcs) == 0 {
 		fmt.Println("No service deployed into cluster.")
@@ -147,7 +138,7 @@ func (o *operation) getAllServiceInstances() ([]interface{}, error) {
 	// 2. Get each instance from the service list, and add all instance to a slice
 	var itfs []interface{}
 	for _, svc := range svcs {
-		instances, err := o.getInstanceListServiceName(svc)
+		instances, err := o.getInstanceListServiceName(cli, svc)
 		if err != nil {
 			return nil, err
 		}
@@ -159,49 +150,24 @@ func (o *operation) getAllServiceInstances() ([]interface{}, error) {
 }
 
 func (o *operation) getServiceInstances() ([]interface{}, error) {
-	o.serviceName = fmt.Sprintf("/%s", o.serviceName)
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodGet,
-		Path:      o.config.BuildManagerURL(kappctl.GetServiceURL+"&detail=true", []interface{}{o.serviceName, o.clusterName}),
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
+	cli, err := o.config.NewClient()
 	if err != nil {
 		return nil, err
 	}
-	if code != http.StatusOK {
-		return nil, fmt.Errorf("cannot get the service list, because get the http code: %d", code)
-	}
-
-	var svc instancev1alpha1.CloudNativeServiceInstance
-	if err := json.Unmarshal(buf, &svc); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal http response: %s", err)
+	svc, err := cli.Cluster(o.clusterName).ServiceBindings().Get(context.Background(), o.serviceName,
+		client.GetOptions{Detail: true})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the service, err: %v", err)
 	}
-	return o.getInstanceListServiceName(svc)
+	return o.getInstanceListServiceName(cli, *svc)
 }
 
-func (o *operation) getInstanceListServiceName(svc instancev1alpha1.CloudNativeServiceInstance) ([]interface{}, error) {
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodGet,
-		Path:      o.config.BuildManagerURL(kappctl.GetInstancesURL, []interface{}{svc.Name}),
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
+func (o *operation) getInstanceListServiceName(cli *client.Client, svc instancev1alpha1.CloudNativeServiceInstance) (
+	[]interface{}, error) {
+	ins, err := cli.Cluster(o.clusterName).Instances(svc.Name, o.namespace).List(context.Background())
 	if err != nil {
-		return nil, err
-	}
-	if code != http.StatusOK {
-		return nil, fmt.Errorf("cannot get the instance list, because get the http code: %d", code)
+		return nil, fmt.Errorf("cannot get the instance list, err: %v", err)
 	}
-	var ins []models.InstanceModel
-	if err = json.Unmarshal(buf, &ins); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal http response: %s", err)
-	}
-
 	if len(o.outputFormat) > 0 {
 		return nil, outputYamlOrJSON(svc, ins, o.outputFormat)
 	}
@@ -225,11 +191,12 @@ func outputYamlOrJSON(svc instancev1alpha1.CloudNativeServiceInstance, ins []mod
 				Kind:       item.Kind,
 				APIVersion: item.APIVersion,
 			},
-			Name:       item.Name,
-			Namespace:  item.Namespace,
-			UID:        item.ServiceID,
-			Status:     item.Status,
-			RawMessage: item.RawResource,
+			Name:               item.Name,
+			Namespace:          item.Namespace,
+			UID:                item.ServiceID,
+			Status:             item.Status,
+			RawMessage:         item.RawResource,
+			DeletionProtection: item.DeletionProtection,
 		}
 		if svc.Status.Phase == instancev1alpha1.PendingPhase {
 			addIn.Status = string(instancev1alpha1.PendingPhase)
@@ -252,6 +219,7 @@ func convertInstanceToTable(ins models.InstanceModel, serviceName string, phase
 		InstanceName: ins.Name,
 		Namespace:    ins.Namespace,
 		Status:       ins.Status,
+		Protected:    strconv.FormatBool(ins.DeletionProtection),
 		Created:      kappctl.GetAgeOutput(ins.CreateTimestamp),
 	}
 	switch phase {
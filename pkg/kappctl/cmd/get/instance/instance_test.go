@@ -17,9 +17,9 @@
 package instance
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
@@ -32,8 +32,8 @@ import (
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	"github.com/kappital/kappital/pkg/apis/view"
 	"github.com/kappital/kappital/pkg/kappctl"
+	"github.com/kappital/kappital/pkg/kappctl/kappctltest"
 	"github.com/kappital/kappital/pkg/models"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 func Test_convertInstanceToTable(t *testing.T) {
@@ -50,11 +50,13 @@ func Test_convertInstanceToTable(t *testing.T) {
 		{
 			name: "Test convertInstanceToTable (SucceededPhase)",
 			args: args{
-				ins:         models.InstanceModel{Name: "test", Namespace: "test", Status: "x1", ClusterName: "x1"},
+				ins: models.InstanceModel{Name: "test", Namespace: "test", Status: "x1", ClusterName: "x1",
+					DeletionProtection: true},
 				serviceName: "test",
 				phase:       instancev1alpha1.SucceededPhase,
 			},
-			want: view.Instance{InstanceName: "test", Namespace: "test", ServiceName: "test", ClusterName: "x1", Status: "x1"},
+			want: view.Instance{InstanceName: "test", Namespace: "test", ServiceName: "test", ClusterName: "x1", Status: "x1",
+				Protected: "true"},
 		},
 		{
 			name: "Test convertInstanceToTable (PendingPhase)",
@@ -63,7 +65,8 @@ func Test_convertInstanceToTable(t *testing.T) {
 				serviceName: "test",
 				phase:       instancev1alpha1.PendingPhase,
 			},
-			want: view.Instance{InstanceName: "test", Namespace: "test", ServiceName: "test", ClusterName: "x1", Status: "Pending"},
+			want: view.Instance{InstanceName: "test", Namespace: "test", ServiceName: "test", ClusterName: "x1", Status: "Pending",
+				Protected: "false"},
 		},
 		{
 			name: "Test convertInstanceToTable (UnknownPhase)",
@@ -72,7 +75,8 @@ func Test_convertInstanceToTable(t *testing.T) {
 				serviceName: "test",
 				phase:       instancev1alpha1.UnknownPhase,
 			},
-			want: view.Instance{InstanceName: "test", Namespace: "test", ServiceName: "test", ClusterName: "x1", Status: "Unknown"},
+			want: view.Instance{InstanceName: "test", Namespace: "test", ServiceName: "test", ClusterName: "x1", Status: "Unknown",
+				Protected: "false"},
 		},
 	}
 	for _, tt := range tests {
@@ -155,10 +159,6 @@ func Test_operation_PreRunE(t *testing.T) {
 func Test_operation_RunE(t *testing.T) {
 	convey.Convey("test operation RunE", t, func() {
 		o := &operation{config: &kappctl.Config{}}
-		globalMock := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-			return http.StatusOK, []byte(""), nil
-		})
-		defer globalMock.Reset()
 		convey.Convey("case 1: getAll and getAllServiceInstances has error", func() {
 			p := gomonkey.ApplyFunc(o.getAllServiceInstances, func() ([]interface{}, error) {
 				return nil, fmt.Errorf("mock error")
@@ -169,14 +169,9 @@ func Test_operation_RunE(t *testing.T) {
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 2: getAll and getAllServiceInstances without error", func() {
-			p := gomonkey.ApplyFunc(o.getAllServiceInstances, func() ([]interface{}, error) {
-				return []interface{}{}, nil
-			})
-			defer p.Reset()
-			p.ApplyFunc(json.Unmarshal, func(_ []byte, _ interface{}) error {
-				return nil
-			})
-			o.allResult = true
+			srv, config := fakeManager(http.StatusOK, []instancev1alpha1.CloudNativeServiceInstance{}, nil)
+			defer srv.Close()
+			o.config, o.allResult = config, true
 			err := o.RunE()
 			convey.So(err, convey.ShouldBeNil)
 		})
@@ -189,13 +184,9 @@ func Test_operation_RunE(t *testing.T) {
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 4: getAll and getServiceInstances has nil res", func() {
-			p := gomonkey.ApplyFunc(o.getServiceInstances, func() ([]interface{}, error) {
-				return nil, nil
-			})
-			defer p.Reset()
-			p.ApplyFunc(json.Unmarshal, func(_ []byte, _ interface{}) error {
-				return nil
-			})
+			srv, config := fakeManager(http.StatusOK, nil, []models.InstanceModel{})
+			defer srv.Close()
+			o.config = config
 			err := o.RunE()
 			convey.So(err, convey.ShouldBeNil)
 		})
@@ -211,30 +202,25 @@ func Test_operation_getAllServiceInstances_withHttpRequestError(t *testing.T) {
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 2; http request cannot get the http.StatusOK", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return 0, nil, nil
-			})
-			defer p.Reset()
+			srv, config := fakeManager(http.StatusInternalServerError, nil, nil)
+			defer srv.Close()
+			o.config = config
 			got, err := o.getAllServiceInstances()
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 3: http request get invalid buf", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return http.StatusOK, []byte("invalid"), nil
-			})
-			defer p.Reset()
+			srv, config := fakeManager(http.StatusOK, "invalid", nil)
+			defer srv.Close()
+			o.config = config
 			got, err := o.getAllServiceInstances()
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 4: http request get valid buf but no CloudNativeServiceInstance", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				var svcs []instancev1alpha1.CloudNativeServiceInstance
-				jsonBytes, err := json.Marshal(svcs)
-				return http.StatusOK, jsonBytes, err
-			})
-			defer p.Reset()
+			srv, config := fakeManager(http.StatusOK, []instancev1alpha1.CloudNativeServiceInstance{}, nil)
+			defer srv.Close()
+			o.config = config
 			got, err := o.getAllServiceInstances()
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldBeNil)
@@ -245,29 +231,19 @@ func Test_operation_getAllServiceInstances_withHttpRequestError(t *testing.T) {
 func Test_operation_getAllServiceInstances(t *testing.T) {
 	convey.Convey("test operation getAllServiceInstances", t, func() {
 		o := &operation{config: &kappctl.Config{}}
+		svcs := []instancev1alpha1.CloudNativeServiceInstance{{ObjectMeta: metav1.ObjectMeta{Name: "1"}}}
 		convey.Convey("case 2: getInstanceListServiceName with error", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				svcs := []instancev1alpha1.CloudNativeServiceInstance{{}}
-				jsonBytes, err := json.Marshal(svcs)
-				return http.StatusOK, jsonBytes, err
-			})
-			defer p.Reset()
+			srv, config := fakeManager(http.StatusOK, svcs, "invalid")
+			defer srv.Close()
+			o.config = config
 			got, err := o.getAllServiceInstances()
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 3: getInstanceListServiceName without error", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(info *gateway.RequestInfo) (int, []byte, error) {
-				if strings.Contains(info.Path, "instance") {
-					ins := []models.InstanceModel{{Name: "1"}}
-					jsonBytes, err := json.Marshal(ins)
-					return http.StatusOK, jsonBytes, err
-				}
-				svcs := []instancev1alpha1.CloudNativeServiceInstance{{ObjectMeta: metav1.ObjectMeta{Name: "1"}}}
-				jsonBytes, err := json.Marshal(svcs)
-				return http.StatusOK, jsonBytes, err
-			})
-			defer p.Reset()
+			srv, config := fakeManager(http.StatusOK, svcs, []models.InstanceModel{{Name: "1"}})
+			defer srv.Close()
+			o.config = config
 			got, err := o.getAllServiceInstances()
 			convey.So(got, convey.ShouldNotBeNil)
 			convey.So(err, convey.ShouldBeNil)
@@ -277,27 +253,31 @@ func Test_operation_getAllServiceInstances(t *testing.T) {
 
 func Test_operation_getInstanceListServiceName_withError(t *testing.T) {
 	convey.Convey("test operation getInstanceListServiceName withError", t, func() {
-		o := &operation{config: &kappctl.Config{}}
-		convey.Convey("case 1: error url for the http request", func() {
-			got, err := o.getInstanceListServiceName(instancev1alpha1.CloudNativeServiceInstance{})
+		o := &operation{}
+		convey.Convey("case 1: the manager cannot be connected", func() {
+			srv, config := fakeManager(http.StatusOK, nil, nil)
+			srv.Close()
+			cli, err := config.NewClient()
+			convey.So(err, convey.ShouldBeNil)
+			got, err := o.getInstanceListServiceName(cli, instancev1alpha1.CloudNativeServiceInstance{})
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 2; http request cannot get the http.StatusOK", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return 0, nil, nil
-			})
-			defer p.Reset()
-			got, err := o.getInstanceListServiceName(instancev1alpha1.CloudNativeServiceInstance{})
+			srv, config := fakeManager(http.StatusInternalServerError, nil, nil)
+			defer srv.Close()
+			cli, err := config.NewClient()
+			convey.So(err, convey.ShouldBeNil)
+			got, err := o.getInstanceListServiceName(cli, instancev1alpha1.CloudNativeServiceInstance{})
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 3: http request get invalid buf", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return http.StatusOK, []byte("invalid"), nil
-			})
-			defer p.Reset()
-			got, err := o.getInstanceListServiceName(instancev1alpha1.CloudNativeServiceInstance{})
+			srv, config := fakeManager(http.StatusOK, nil, "invalid")
+			defer srv.Close()
+			cli, err := config.NewClient()
+			convey.So(err, convey.ShouldBeNil)
+			got, err := o.getInstanceListServiceName(cli, instancev1alpha1.CloudNativeServiceInstance{})
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
@@ -306,42 +286,28 @@ func Test_operation_getInstanceListServiceName_withError(t *testing.T) {
 
 func Test_operation_getInstanceListServiceName_withoutError(t *testing.T) {
 	convey.Convey("test operation getInstanceListServiceName withoutError", t, func() {
-		o := &operation{config: &kappctl.Config{}}
+		o := &operation{}
+		srv, config := fakeManager(http.StatusOK, nil, []models.InstanceModel{{Name: "1"}})
+		defer srv.Close()
+		cli, err := config.NewClient()
+		convey.So(err, convey.ShouldBeNil)
 		convey.Convey("case 1: http request get correct result (w/ outputFormat)", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				ins := []models.InstanceModel{{}}
-				jsonBytes, err := json.Marshal(ins)
-				return http.StatusOK, jsonBytes, err
-			})
-			defer p.Reset()
 			o.outputFormat = "json"
-			got, err := o.getInstanceListServiceName(instancev1alpha1.CloudNativeServiceInstance{})
+			got, err := o.getInstanceListServiceName(cli, instancev1alpha1.CloudNativeServiceInstance{})
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldBeNil)
 		})
 		convey.Convey("case 2: http request get correct result (w/o outputFormat and w/o instanceName)", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				ins := []models.InstanceModel{{}}
-				jsonBytes, err := json.Marshal(ins)
-				return http.StatusOK, jsonBytes, err
-			})
-			defer p.Reset()
 			o.outputFormat = ""
 			o.instanceName = ""
-			got, err := o.getInstanceListServiceName(instancev1alpha1.CloudNativeServiceInstance{})
+			got, err := o.getInstanceListServiceName(cli, instancev1alpha1.CloudNativeServiceInstance{})
 			convey.So(got, convey.ShouldNotBeNil)
 			convey.So(err, convey.ShouldBeNil)
 		})
 		convey.Convey("case 3: http request get correct result (w/o outputFormat and w/ instanceName)", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				ins := []models.InstanceModel{{Name: "1"}}
-				jsonBytes, err := json.Marshal(ins)
-				return http.StatusOK, jsonBytes, err
-			})
-			defer p.Reset()
 			o.outputFormat = ""
 			o.instanceName = "1"
-			got, err := o.getInstanceListServiceName(instancev1alpha1.CloudNativeServiceInstance{})
+			got, err := o.getInstanceListServiceName(cli, instancev1alpha1.CloudNativeServiceInstance{})
 			convey.So(got, convey.ShouldNotBeNil)
 			convey.So(err, convey.ShouldBeNil)
 		})
@@ -350,44 +316,50 @@ func Test_operation_getInstanceListServiceName_withoutError(t *testing.T) {
 
 func Test_operation_getServiceInstances(t *testing.T) {
 	convey.Convey("test operation getServiceInstances", t, func() {
-		o := &operation{config: &kappctl.Config{}}
+		o := &operation{config: &kappctl.Config{}, serviceName: "1"}
 		convey.Convey("case 1: error url for the http request", func() {
 			got, err := o.getServiceInstances()
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 2; http request cannot get the http.StatusOK", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return 0, nil, nil
-			})
-			defer p.Reset()
+			srv, config := fakeManager(http.StatusNotFound, nil, nil)
+			defer srv.Close()
+			o.config = config
 			got, err := o.getServiceInstances()
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 3: http request get invalid buf", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return http.StatusOK, []byte("invalid"), nil
-			})
-			defer p.Reset()
+			srv, config := fakeManager(http.StatusOK, "invalid", nil)
+			defer srv.Close()
+			o.config = config
 			got, err := o.getServiceInstances()
 			convey.So(got, convey.ShouldBeNil)
 			convey.So(err, convey.ShouldNotBeNil)
 		})
 		convey.Convey("case 4: http request get correct result", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				svc := instancev1alpha1.CloudNativeServiceInstance{}
-				jsonBytes, err := json.Marshal(svc)
-				return http.StatusOK, jsonBytes, err
-			})
-			defer p.Reset()
+			svc := instancev1alpha1.CloudNativeServiceInstance{ObjectMeta: metav1.ObjectMeta{Name: "1"}}
+			srv, config := fakeManager(http.StatusOK, svc, []models.InstanceModel{{Name: "1"}})
+			defer srv.Close()
+			o.config = config
 			got, err := o.getServiceInstances()
-			convey.So(got, convey.ShouldBeNil)
-			convey.So(err, convey.ShouldNotBeNil)
+			convey.So(got, convey.ShouldNotBeNil)
+			convey.So(err, convey.ShouldBeNil)
 		})
 	})
 }
 
+// fakeManager serve the service bindings and the instances with the http code, the config connects to it
+func fakeManager(code int, bindings, instances interface{}) (*httptest.Server, *kappctl.Config) {
+	return kappctltest.FakeManagerFunc(func(r *http.Request) (int, interface{}) {
+		if strings.HasSuffix(r.URL.Path, "/instance") {
+			return code, instances
+		}
+		return code, bindings
+	})
+}
+
 func Test_outputYamlOrJSON(t *testing.T) {
 	type args struct {
 		svc    instancev1alpha1.CloudNativeServiceInstance
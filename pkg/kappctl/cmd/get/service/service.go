@@ -17,16 +17,17 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	out "github.com/kappital/kappital/pkg/apis/view"
+	"github.com/kappital/kappital/pkg/client"
 	"github.com/kappital/kappital/pkg/kappctl"
-	"github.com/kappital/kappital/pkg/utils/gateway"
 )
 
 type operation struct {
@@ -84,28 +85,23 @@ func (o *operation) PreRunE(args []string) error {
 
 // RunE get the runtime service
 func (o *operation) RunE() error {
-	getDetail := false
-	if len(o.serviceName) > 0 {
-		o.serviceName = fmt.Sprintf("/%s", o.serviceName)
-		getDetail = true
+	cli, err := o.config.NewClient()
+	if err != nil {
+		return err
 	}
-	url := o.config.BuildManagerURL(kappctl.GetServiceURL, []interface{}{o.serviceName, o.clusterName})
-	if getDetail {
-		url += fmt.Sprintf("&detail=%v", getDetail)
+	bindings := cli.Cluster(o.clusterName).ServiceBindings()
+	var result interface{}
+	if len(o.serviceName) > 0 {
+		result, err = bindings.Get(context.Background(), o.serviceName, client.GetOptions{Detail: true})
+	} else {
+		result, err = bindings.List(context.Background())
 	}
-	code, buf, err := gateway.CommonUtilRequest(&gateway.RequestInfo{
-		Method:    http.MethodGet,
-		Path:      url,
-		CaCrt:     o.config.ManagerCA,
-		ClientCrt: o.config.ManagerClientCertificateData,
-		ClientKey: o.config.ManagerClientKeyData,
-		Skip:      o.config.ManagerSkipVerify,
-	})
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot get the service binding, err: %v", err)
 	}
-	if code != http.StatusOK {
-		return fmt.Errorf("cannot get the service binding, http code: %d, msg: %s", code, string(buf))
+	buf, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("cannot marshal the service binding, err: %v", err)
 	}
 	return outputResult(buf, len(o.serviceName) == 0, o.outputFormat)
 }
@@ -147,6 +143,7 @@ func convertServiceToTable(svc instancev1alpha1.CloudNativeServiceInstance) out.
 		Namespace: svc.Namespace,
 		Phase:     string(svc.Status.Phase),
 		Message:   svc.Status.Message,
+		Protected: strconv.FormatBool(svc.Spec.DeletionProtection),
 		Created:   kappctl.GetAgeOutput(svc.CreationTimestamp.Time),
 	}
 	return res
@@ -27,7 +27,7 @@ import (
 
 	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
 	"github.com/kappital/kappital/pkg/kappctl"
-	"github.com/kappital/kappital/pkg/utils/gateway"
+	"github.com/kappital/kappital/pkg/kappctl/kappctltest"
 )
 
 var (
@@ -78,27 +78,32 @@ func Test_operation_PreRunE(t *testing.T) {
 func Test_operation_RunE(t *testing.T) {
 	convey.Convey("test get service operation RunE", t, func() {
 		o := &operation{config: &kappctl.Config{}}
-		convey.Convey("case 1: gateway.CommonUtilRequest get error for get service", func() {
+		convey.Convey("case 1: cannot create the client of the manager for get service", func() {
 			err := o.RunE()
 			convey.So(err, convey.ShouldNotBeNil)
 		})
-		convey.Convey("case 2: gateway.CommonUtilRequest get failed http code for get service", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return 0, nil, nil
-			})
-			defer p.Reset()
+		convey.Convey("case 2: the manager replies the failed http code for get service", func() {
+			srv, config := kappctltest.FakeManager(http.StatusInternalServerError, nil)
+			defer srv.Close()
+			o.config = config
 			err := o.RunE()
 			convey.So(err, convey.ShouldNotBeNil)
 		})
-		convey.Convey("case 3: gateway.CommonUtilRequest get valid data", func() {
-			p := gomonkey.ApplyFunc(gateway.CommonUtilRequest, func(_ *gateway.RequestInfo) (int, []byte, error) {
-				return http.StatusOK, singleBytes, nil
-			})
-			defer p.Reset()
+		convey.Convey("case 3: the manager replies the valid service binding", func() {
+			srv, config := kappctltest.FakeManager(http.StatusOK, singleBytes)
+			defer srv.Close()
+			o.config = config
 			o.serviceName = "xxxx"
 			err := o.RunE()
 			convey.So(err, convey.ShouldBeNil)
 		})
+		convey.Convey("case 4: the manager replies the valid service binding list", func() {
+			srv, config := kappctltest.FakeManager(http.StatusOK, multipleBytes)
+			defer srv.Close()
+			o.config = config
+			err := o.RunE()
+			convey.So(err, convey.ShouldBeNil)
+		})
 	})
 }
 
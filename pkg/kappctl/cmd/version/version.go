@@ -0,0 +1,151 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	"github.com/kappital/kappital/pkg/kappctl"
+	"github.com/kappital/kappital/pkg/utils/version"
+)
+
+// output the versions of kappctl and the manager in yaml or json
+type output struct {
+	ClientVersion version.Info             `json:"clientVersion"`
+	ServerInfo    *infov1alpha1.ServerInfo `json:"serverInfo,omitempty"`
+}
+
+type operation struct {
+	config *kappctl.Config
+
+	clientOnly   bool
+	outputFormat string
+}
+
+func (o operation) getArgumentMap() map[string]interface{} {
+	return map[string]interface{}{
+		kappctl.OutputFormat.GetFlagName(): o.outputFormat,
+	}
+}
+
+// NewCommand create the version command
+func NewCommand() *cobra.Command {
+	o := &operation{}
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the versions of kappctl and Kappital-Manager",
+		Args:  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.PreRunE()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunE()
+		},
+	}
+	cmd.Flags().BoolVar(&o.clientOnly, "client", false, "only print the version of kappctl")
+	kappctl.OutputFormat.AddStringFlag(&o.outputFormat, cmd)
+	return cmd
+}
+
+// PreRunE check the arguments, and get the config of the manager if its version is printed
+func (o *operation) PreRunE() error {
+	if err := kappctl.IsInputValidate(o.getArgumentMap()); err != nil {
+		return err
+	}
+	if o.clientOnly {
+		return nil
+	}
+	var err error
+	o.config, err = kappctl.GetConfig()
+	return err
+}
+
+// RunE print the version of kappctl, and the version and the capabilities of the manager, it warns when their
+// versions are skewed
+func (o *operation) RunE() error {
+	result := output{ClientVersion: version.Get(version.ServiceNameKappctl)}
+	var serverErr error
+	if !o.clientOnly {
+		result.ServerInfo, serverErr = o.getServerInfo()
+	}
+	if len(o.outputFormat) > 0 {
+		buf, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("cannot marshal the versions, err: %v", err)
+		}
+		if err = kappctl.OutputYAMLOrJSONString(buf, o.outputFormat); err != nil {
+			return err
+		}
+	} else {
+		printVersions(result)
+	}
+	if serverErr != nil {
+		return serverErr
+	}
+	if result.ServerInfo != nil {
+		if err := version.CheckSkew(result.ClientVersion.GitVersion,
+			result.ServerInfo.Version.GitVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func (o *operation) getServerInfo() (*infov1alpha1.ServerInfo, error) {
+	cli, err := o.config.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	info, err := cli.ServerInfo(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the version of the manager, err: %v", err)
+	}
+	return info, nil
+}
+
+func printVersions(result output) {
+	fmt.Printf("Client Version: %s\n", formatVersion(result.ClientVersion))
+	info := result.ServerInfo
+	if info == nil {
+		return
+	}
+	fmt.Printf("Server Version: %s\n", formatVersion(info.Version))
+	fmt.Printf("Authentication Methods: %s\n", strings.Join(info.Features.AuthenticationMethods, ", "))
+	fmt.Printf("Database Driver: %s\n", info.Features.DatabaseDriver)
+	fmt.Printf("Multi Cluster: %v\n", info.Features.MultiCluster)
+	fmt.Printf("Package Formats: %s\n", strings.Join(info.PackageFormats, ", "))
+	fmt.Println("Clusters:")
+	for _, cluster := range info.Clusters {
+		if len(cluster.Error) > 0 {
+			fmt.Printf("  %s: unknown (%s)\n", cluster.Name, cluster.Error)
+			continue
+		}
+		fmt.Printf("  %s: %s\n", cluster.Name, cluster.Version)
+	}
+}
+
+func formatVersion(info version.Info) string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s, %s)", info.GitVersion, info.GitCommit, info.BuildDate,
+		info.GoVersion, info.Platform)
+}
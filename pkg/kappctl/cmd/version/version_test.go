@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/smartystreets/goconvey/convey"
+
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	"github.com/kappital/kappital/pkg/kappctl"
+	"github.com/kappital/kappital/pkg/kappctl/kappctltest"
+	"github.com/kappital/kappital/pkg/utils/version"
+)
+
+func TestNewCommand(t *testing.T) {
+	if got := NewCommand(); got == nil {
+		t.Errorf("version NewCommand() = %v, do not want nil", got)
+	}
+}
+
+func Test_operation_PreRunE(t *testing.T) {
+	convey.Convey("test version operation PreRunE", t, func() {
+		convey.Convey("case 1: the output format is invalid", func() {
+			o := &operation{clientOnly: true, outputFormat: "xml"}
+			convey.So(o.PreRunE(), convey.ShouldNotBeNil)
+		})
+		convey.Convey("case 2: only the client version does not need the config", func() {
+			o := &operation{clientOnly: true}
+			convey.So(o.PreRunE(), convey.ShouldBeNil)
+			convey.So(o.config, convey.ShouldBeNil)
+		})
+		convey.Convey("case 3: get the config of the manager", func() {
+			p := gomonkey.ApplyFunc(kappctl.GetConfig, func() (*kappctl.Config, error) {
+				return &kappctl.Config{}, nil
+			})
+			defer p.Reset()
+			o := &operation{}
+			convey.So(o.PreRunE(), convey.ShouldBeNil)
+			convey.So(o.config, convey.ShouldNotBeNil)
+		})
+	})
+}
+
+func Test_operation_RunE(t *testing.T) {
+	convey.Convey("test version operation RunE", t, func() {
+		convey.Convey("case 1: only print the client version", func() {
+			o := &operation{clientOnly: true, outputFormat: "json"}
+			convey.So(o.RunE(), convey.ShouldBeNil)
+		})
+		convey.Convey("case 2: cannot create the client of the manager", func() {
+			o := &operation{config: &kappctl.Config{}}
+			convey.So(o.RunE(), convey.ShouldNotBeNil)
+		})
+		convey.Convey("case 3: the manager replies the failed http code", func() {
+			srv, config := kappctltest.FakeManager(http.StatusInternalServerError, nil)
+			defer srv.Close()
+			o := &operation{config: config}
+			convey.So(o.RunE(), convey.ShouldNotBeNil)
+		})
+		convey.Convey("case 4: the manager replies its version", func() {
+			srv, config := kappctltest.FakeManager(http.StatusOK, &infov1alpha1.ServerInfo{
+				Version:  version.Info{GitVersion: "v0.1.0"},
+				Clusters: []infov1alpha1.ClusterInfo{{Name: "default", Version: "v1.23.0"}, {Name: "remote", Error: "x"}},
+			})
+			defer srv.Close()
+			o := &operation{config: config}
+			convey.So(o.RunE(), convey.ShouldBeNil)
+			o.outputFormat = "yaml"
+			convey.So(o.RunE(), convey.ShouldBeNil)
+		})
+	})
+}
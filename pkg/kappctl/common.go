@@ -41,23 +41,6 @@ const (
 	// ConfigFile the default config file path
 	ConfigFile = ".kappital/config"
 
-	// DeployInstanceURL the url format for deploying the instance
-	DeployInstanceURL = "%v/api/v1alpha1/servicebinding/%v/instance?cluster_name=%v"
-	// DeployServiceURL the url format for deploying the service package into cluster
-	DeployServiceURL = "%v/api/v1alpha1/servicebinding"
-
-	// GetInstancesURL the url format for getting the instances from the cluster
-	GetInstancesURL = "%v/api/v1alpha1/servicebinding/%v/instance"
-	// GetServiceURL the url format for getting the service from the cluster
-	GetServiceURL = "%v/api/v1alpha1/servicebinding%v?cluster_name=%v"
-	// GetServicesURL the url format for getting the service list from the cluster
-	GetServicesURL = "%v/api/v1alpha1/servicebinding?cluster_name=%v"
-
-	// DeleteInstanceURL the url format for deleting the instance from the cluster
-	DeleteInstanceURL = "%v/api/v1alpha1/servicebinding/%v/instance/%v?cluster_name=%v"
-	// DeleteServiceURL the url format for deleting service
-	DeleteServiceURL = "%v/api/v1alpha1/servicebinding/%v?cluster_name=%v"
-
 	// yamlOutputFormat output the format as a yaml structure
 	yamlOutputFormat = "yaml"
 	// jsonOutputFormat output the format as a json structure
@@ -17,7 +17,10 @@
 package kappctl
 
 import (
+	"encoding/base64"
 	"fmt"
+
+	"github.com/kappital/kappital/pkg/client"
 )
 
 // Config the address and port of the manager
@@ -27,12 +30,25 @@ type Config struct {
 	ManagerClientKeyData         string `json:"manager-client-key-data,omitempty"`
 	ManagerCA                    string `json:"manager-ca,omitempty"`
 	ManagerSkipVerify            bool   `json:"manager-skip-verify,omitempty"`
+	ManagerToken                 string `json:"manager-token,omitempty"`
 }
 
-// BuildManagerURL build the manager http/https request
-func (c Config) BuildManagerURL(urlFormat string, args []interface{}) string {
-	parameters := make([]interface{}, 0, len(args)+1)
-	parameters = append(parameters, c.ManagerHTTPSServer)
-	parameters = append(parameters, args...)
-	return fmt.Sprintf(urlFormat, parameters...)
+// NewClient create the client of the manager, the certificates in the config are the base64 encoded PEM data
+func (c Config) NewClient() (*client.Client, error) {
+	cfg := client.Config{
+		Server:             c.ManagerHTTPSServer,
+		InsecureSkipVerify: c.ManagerSkipVerify,
+		BearerToken:        c.ManagerToken,
+	}
+	var err error
+	if cfg.CAData, err = base64.StdEncoding.DecodeString(c.ManagerCA); err != nil {
+		return nil, fmt.Errorf("cannot decode the manager CA, err: %v", err)
+	}
+	if cfg.CertData, err = base64.StdEncoding.DecodeString(c.ManagerClientCertificateData); err != nil {
+		return nil, fmt.Errorf("cannot decode the client certificate, err: %v", err)
+	}
+	if cfg.KeyData, err = base64.StdEncoding.DecodeString(c.ManagerClientKeyData); err != nil {
+		return nil, fmt.Errorf("cannot decode the client key, err: %v", err)
+	}
+	return client.New(cfg)
 }
@@ -18,35 +18,45 @@ package kappctl
 
 import "testing"
 
-func TestConfig_BuildManagerURL(t *testing.T) {
-	type fields struct {
-		ManagerHTTPServer  string
-		ManagerHTTPSServer string
-	}
-	type args struct {
-		urlFormat string
-		args      []interface{}
-	}
+func TestConfig_NewClient(t *testing.T) {
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   string
+		name    string
+		config  Config
+		wantErr bool
 	}{
 		{
-			name:   "TestConfig BuildManagerURL (https)",
-			fields: fields{ManagerHTTPSServer: "https://x"},
-			args:   args{urlFormat: "%v/%v", args: []interface{}{1}},
-			want:   "https://x/1",
+			name:    "TestConfig NewClient (empty server)",
+			config:  Config{},
+			wantErr: true,
+		},
+		{
+			name:    "TestConfig NewClient (invalid ca)",
+			config:  Config{ManagerHTTPSServer: "https://x", ManagerCA: "%%"},
+			wantErr: true,
+		},
+		{
+			name:    "TestConfig NewClient (invalid client certificate)",
+			config:  Config{ManagerHTTPSServer: "https://x", ManagerClientCertificateData: "%%"},
+			wantErr: true,
+		},
+		{
+			name:    "TestConfig NewClient (invalid client key)",
+			config:  Config{ManagerHTTPSServer: "https://x", ManagerClientKeyData: "%%"},
+			wantErr: true,
+		},
+		{
+			name:   "TestConfig NewClient (token)",
+			config: Config{ManagerHTTPSServer: "https://x", ManagerToken: "token", ManagerSkipVerify: true},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := Config{
-				ManagerHTTPSServer: tt.fields.ManagerHTTPSServer,
+			got, err := tt.config.NewClient()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if got := c.BuildManagerURL(tt.args.urlFormat, tt.args.args); got != tt.want {
-				t.Errorf("BuildManagerURL() = %v, want %v", got, tt.want)
+			if !tt.wantErr && got == nil {
+				t.Errorf("NewClient() = nil, do not want nil")
 			}
 		})
 	}
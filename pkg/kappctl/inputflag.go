@@ -33,6 +33,8 @@ var (
 	ManagerClientKeyFile = newInputFlag("manager-client-key", "", "", "the HTTPS client key file of kappital-manager")
 	// ManagerCAFile the manager ca file path
 	ManagerCAFile = newInputFlag("manager-ca", "", "", "the HTTPS ca file for kappital-manager")
+	// ManagerToken the API token or the OIDC ID token of kappital-manager
+	ManagerToken = newInputFlag("manager-token", "", "", "the bearer token (API token or OIDC ID token) of kappital-manager")
 	// ManagerSkipVerify the manager skip verify controller
 	ManagerSkipVerify = newInputFlag("manager-skip-verify", "", false, "connect to kappital-manager need to skip verify")
 	// OutputFormat the output result format of the result, now only offer yaml and json
@@ -53,6 +55,16 @@ var (
 	FileName = newInputFlag("name", "", "kappital-demo", "the kappital package name")
 	// PackageVersion of the kappital
 	PackageVersion = newInputFlag("version", "v", "0.1.0", "the kappital package version")
+	// Cascade delete the service with its instances
+	Cascade = newInputFlag("cascade", "", false, "delete the Cloud Native Service with its instances")
+	// Force delete the records without waiting for the cluster to clean them up, only the admin can use it
+	Force = newInputFlag("force", "", false, "remove the records without waiting for the cluster to clean them up")
+	// RemoveFinalizers clear the finalizers of the custom resources which are deleted by force
+	RemoveFinalizers = newInputFlag("remove-finalizers", "", false,
+		"clear the finalizers of the custom resources, it can only be used with --force")
+	// DeletionProtection of the created service or service instance
+	DeletionProtection = newInputFlag("deletion-protection", "", false,
+		"reject the deletion of the created resources until the protection is disabled")
 	// PackageDir of Cloud Native Package
 	PackageDir = newInputFlag("dir", "d", "", "the Cloud Native Package Path")
 )
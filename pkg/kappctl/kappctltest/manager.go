@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package kappctltest provides the fake manager for the tests of the kappctl commands
+package kappctltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/kappital/kappital/pkg/kappctl"
+)
+
+// FakeManager start the fake manager which replies every request with the code and the body, and the kappctl config
+// which connects it. The caller must close the server.
+func FakeManager(code int, body interface{}) (*httptest.Server, *kappctl.Config) {
+	return FakeManagerFunc(func(*http.Request) (int, interface{}) {
+		return code, body
+	})
+}
+
+// FakeManagerFunc start the fake manager which replies each request with the code and the body got from the reply
+// func. The string and []byte body is written as it is, the nil body is not written, and the others are encoded as
+// JSON.
+func FakeManagerFunc(reply func(r *http.Request) (int, interface{})) (*httptest.Server, *kappctl.Config) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, body := reply(r)
+		w.WriteHeader(code)
+		switch raw := body.(type) {
+		case nil:
+		case string:
+			_, _ = w.Write([]byte(raw))
+		case []byte:
+			_, _ = w.Write(raw)
+		default:
+			_ = json.NewEncoder(w).Encode(body)
+		}
+	}))
+	return srv, &kappctl.Config{ManagerHTTPSServer: srv.URL}
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	"github.com/kappital/kappital/pkg/utils/uuid"
+)
+
+// APITokenModel defines the table fields of api_token_model in database, only the SHA-256 digest of the token is
+// stored, thus the token cannot be recovered from the database
+type APITokenModel struct {
+	ID         string    `orm:"size(40);pk;column(id)"`
+	Name       string    `orm:"size(64);unique;column(name)"`
+	User       string    `orm:"size(128);column(user)"`
+	Groups     string    `orm:"type(text);null;column(groups)"`
+	TokenHash  string    `orm:"size(64);unique;column(token_hash)"`
+	CreateTime time.Time `orm:"type(datetime);auto_now_add;column(create_timestamp)"`
+	ExpireTime time.Time `orm:"type(datetime);null;column(expire_timestamp)"`
+}
+
+// Generate fills an api_token_model record with id and timestamps
+func (a *APITokenModel) Generate(currTimestamp time.Time) {
+	if len(a.ID) == 0 {
+		a.ID = uuid.NewUUID()
+	}
+	if a.CreateTime.Equal(time.Time{}) {
+		a.CreateTime = currTimestamp
+	}
+}
@@ -76,7 +76,14 @@ const (
 	Manager serviceType = "Manager"
 )
 
+// ErrResourceVersionConflict will be returned when the record has been modified by others, and the resource version
+// in database is not the same as the updating one
+var ErrResourceVersionConflict = errors.New("the record has been modified, please apply the changes to the latest version")
+
 const (
+	// ResourceVersionColumn the column name of the optimistic concurrency version
+	ResourceVersionColumn = "resource_version"
+
 	noLastInsertIDAvailableErr  = "no LastInsertId available"
 	lastInsertIDNotSupportedErr = "LastInsertId is not supported by this driver"
 )
@@ -95,3 +102,18 @@ func IgnoreDBInsertIDError(err error) error {
 	}
 	return nil
 }
+
+// CompareAndSwapVersion increase the resource version of the record in database if its resource version is the same
+// as the expected one, otherwise return ErrResourceVersionConflict. It should be called in the same transaction with
+// updating the record.
+func CompareAndSwapVersion(o orm.DQL, model interface{}, id string, version int64) error {
+	num, err := o.QueryTable(model).Filter("id", id).Filter(ResourceVersionColumn, version).
+		Update(orm.Params{ResourceVersionColumn: version + 1})
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return ErrResourceVersionConflict
+	}
+	return nil
+}
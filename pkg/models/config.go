@@ -49,6 +49,8 @@ type DatabaseWatcherConfig struct {
 	ListenerMinReconnectInterval time.Duration
 	// max interval seconds for database reconnection
 	ListenerMaxReconnectInterval time.Duration
+	// interval for listing the processing records again, 0 means never resync
+	ResyncPeriod time.Duration
 }
 
 // DefaultDatabaseWatcherConfig get the default database watcher config
@@ -56,5 +58,6 @@ func DefaultDatabaseWatcherConfig() *DatabaseWatcherConfig {
 	return &DatabaseWatcherConfig{
 		ListenerMinReconnectInterval: 1 * time.Second,
 		ListenerMaxReconnectInterval: 20 * time.Second,
+		ResyncPeriod:                 30 * time.Second,
 	}
 }
@@ -17,6 +17,7 @@
 package models
 
 import (
+	"context"
 	"os"
 
 	"github.com/beego/beego/v2/client/orm"
@@ -56,6 +57,24 @@ func GetNewOrm() orm.Ormer {
 	return orm.NewOrmUsingDB(dbAliasName)
 }
 
+// CloseDatabase close all connections of the database, it should be called when the server is stopping
+func CloseDatabase() error {
+	db, err := orm.GetDB(dbAliasName)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// PingDatabase verify the connection of the database is still alive
+func PingDatabase(ctx context.Context) error {
+	db, err := orm.GetDB(dbAliasName)
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
 // NewTransaction create a new transaction
 func NewTransaction(sqlSession orm.Ormer) Transaction {
 	if os.Getenv(constants.UsingFakeEnv) == "true" {
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/kappital/kappital/pkg/models"
+)
+
+// APITokenOperation to manager the api token data in database
+type APITokenOperation struct {
+	ctx context.Context
+}
+
+// WithContext get a copy of the operation, the database calls of which are traced as the children of the span in ctx
+func (a APITokenOperation) WithContext(ctx context.Context) APITokenOperation {
+	a.ctx = ctx
+	return a
+}
+
+// Insert the api token to database
+func (a APITokenOperation) Insert(obj interface{}) error {
+	defer observe(a.ctx, apiTokenTable, "Insert")()
+	token, ok := obj.(models.APITokenModel)
+	if !ok {
+		return fmt.Errorf("obj type is not APITokenModel")
+	}
+	token.Generate(time.Now().UTC())
+	_, err := models.GetNewOrm().Insert(&token)
+	return models.IgnoreDBInsertIDError(err)
+}
+
+// Get the api token from the database and filter by cols
+func (a APITokenOperation) Get(cols map[string]string) (interface{}, error) {
+	defer observe(a.ctx, apiTokenTable, "Get")()
+	seter := models.GetNewOrm().QueryTable(models.APITokenModel{})
+	for k, v := range cols {
+		seter = seter.Filter(k, v)
+	}
+	var item models.APITokenModel
+	err := seter.One(&item)
+	return item, err
+}
+
+// GetList of api token
+func (a APITokenOperation) GetList(cols map[string]string) (interface{}, error) {
+	defer observe(a.ctx, apiTokenTable, "GetList")()
+	seter := models.GetNewOrm().QueryTable(models.APITokenModel{})
+	for k, v := range cols {
+		seter = seter.Filter(k, v)
+	}
+	var items []models.APITokenModel
+	_, err := seter.OrderBy("name").All(&items)
+	return items, err
+}
+
+// Delete the api token by its id or name, it does nothing if the api token does not exist
+func (a APITokenOperation) Delete(obj interface{}) error {
+	defer observe(a.ctx, apiTokenTable, "Delete")()
+	token, ok := obj.(models.APITokenModel)
+	if !ok {
+		return fmt.Errorf("obj type is not APITokenModel")
+	}
+	sql := models.GetNewOrm()
+	if len(token.ID) == 0 {
+		if err := sql.Read(&token, "name"); err != nil {
+			if errors.Is(err, orm.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+	}
+	_, err := sql.Delete(&token)
+	return err
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"testing"
+
+	"github.com/kappital/kappital/pkg/models"
+)
+
+var apiToken = APITokenOperation{}
+
+func TestAPITokenOperation(t *testing.T) {
+	token := models.APITokenModel{Name: "ci", User: "ci-bot", Groups: "ci", TokenHash: "hash-ci"}
+	if err := ignoreDBLockError(apiToken.Insert(token)); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := apiToken.Insert(token); err == nil {
+		t.Errorf("Insert() the duplicated name, want error")
+	}
+	if err := apiToken.Insert(models.ServiceBindingModel{}); err == nil {
+		t.Errorf("Insert() the wrong type, want error")
+	}
+
+	got, err := apiToken.Get(map[string]string{"token_hash": "hash-ci"})
+	if err != nil || got.(models.APITokenModel).User != "ci-bot" || len(got.(models.APITokenModel).ID) == 0 {
+		t.Errorf("Get() = %v, error = %v, want the api token of ci-bot", got, err)
+	}
+	items, err := apiToken.GetList(nil)
+	if err != nil || len(items.([]models.APITokenModel)) != 1 {
+		t.Errorf("GetList() = %v, error = %v, want one api token", items, err)
+	}
+
+	if err = ignoreDBLockError(apiToken.Delete(models.APITokenModel{Name: "ci"})); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err = apiToken.Delete(models.APITokenModel{Name: "not-exist"}); err != nil {
+		t.Errorf("Delete() the not existed api token, error = %v", err)
+	}
+	if _, err = apiToken.Get(map[string]string{"name": "ci"}); err == nil {
+		t.Errorf("Get() the deleted api token, want error")
+	}
+}
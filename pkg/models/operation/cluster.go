@@ -0,0 +1,32 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"context"
+
+	"github.com/kappital/kappital/pkg/models"
+)
+
+// ListClusterNames list the clusters which the service bindings or instances are registered in
+func ListClusterNames(ctx context.Context) ([]string, error) {
+	defer observe(ctx, serviceBindingTable, "ListClusterNames")()
+	var names []string
+	_, err := models.GetNewOrm().Raw("SELECT DISTINCT cluster_name FROM service_binding_model UNION " +
+		"SELECT DISTINCT cluster_name FROM instance_model").QueryRows(&names)
+	return names, err
+}
@@ -17,6 +17,7 @@
 package operation
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -27,7 +28,15 @@ import (
 )
 
 // InstanceOperation to manager the instance data in database
-type InstanceOperation struct{}
+type InstanceOperation struct {
+	ctx context.Context
+}
+
+// WithContext get a copy of the operation, the database calls of which are traced as the children of the span in ctx
+func (i InstanceOperation) WithContext(ctx context.Context) InstanceOperation {
+	i.ctx = ctx
+	return i
+}
 
 // Insert instance information to database
 func (i InstanceOperation) Insert(interface{}) error {
@@ -36,6 +45,7 @@ func (i InstanceOperation) Insert(interface{}) error {
 
 // InsertTx instance information to database with transaction
 func (i InstanceOperation) InsertTx(obj interface{}, tx orm.TxOrmer) error {
+	defer observe(i.ctx, instanceTable, "InsertTx")()
 	repo, ok := obj.(models.InstanceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not InstanceModel")
@@ -50,6 +60,7 @@ func (i InstanceOperation) InsertTx(obj interface{}, tx orm.TxOrmer) error {
 
 // InsertWithRelFk insert the instance with its relation foreign key
 func (i InstanceOperation) InsertWithRelFk(obj interface{}, fk interface{}, tx orm.TxOrmer) error {
+	defer observe(i.ctx, instanceTable, "InsertWithRelFk")()
 	instance, ok := obj.(models.InstanceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not InstanceModel")
@@ -68,6 +79,7 @@ func (i InstanceOperation) InsertWithRelFk(obj interface{}, fk interface{}, tx o
 
 // Get the instance from the database and filter by cols
 func (i InstanceOperation) Get(cols map[string]string) (interface{}, error) {
+	defer observe(i.ctx, instanceTable, "Get")()
 	setter := models.GetNewOrm().QueryTable(models.InstanceModel{})
 	for k, v := range cols {
 		setter = setter.Filter(k, v)
@@ -79,6 +91,7 @@ func (i InstanceOperation) Get(cols map[string]string) (interface{}, error) {
 
 // GetByPrimaryKey get the instance with its primary key (id)
 func (i InstanceOperation) GetByPrimaryKey(id string) (interface{}, error) {
+	defer observe(i.ctx, instanceTable, "GetByPrimaryKey")()
 	instance := models.InstanceModel{ID: id}
 	err := models.GetNewOrm().Read(&instance)
 	return instance, err
@@ -86,11 +99,13 @@ func (i InstanceOperation) GetByPrimaryKey(id string) (interface{}, error) {
 
 // GetDetail of instance
 func (i InstanceOperation) GetDetail(cols map[string]string) (interface{}, error) {
+	defer observe(i.ctx, instanceTable, "GetDetail")()
 	return i.Get(cols)
 }
 
 // GetList of instance
 func (i InstanceOperation) GetList(cols map[string]string) (interface{}, error) {
+	defer observe(i.ctx, instanceTable, "GetList")()
 	setter := models.GetNewOrm().QueryTable(models.InstanceModel{})
 	for k, v := range cols {
 		setter = setter.Filter(k, v)
@@ -102,6 +117,7 @@ func (i InstanceOperation) GetList(cols map[string]string) (interface{}, error)
 
 // GetListByFilter get the instance information by filter
 func (i InstanceOperation) GetListByFilter(filter map[string][]interface{}) (interface{}, error) {
+	defer observe(i.ctx, instanceTable, "GetListByFilter")()
 	seter := models.GetNewOrm().QueryTable(models.InstanceModel{})
 	for k, v := range filter {
 		if v == nil {
@@ -117,6 +133,7 @@ func (i InstanceOperation) GetListByFilter(filter map[string][]interface{}) (int
 
 // IsExist does the instance information is existed in database with cols filter
 func (i InstanceOperation) IsExist(cols map[string]string) bool {
+	defer observe(i.ctx, instanceTable, "IsExist")()
 	seter := models.GetNewOrm().QueryTable(models.InstanceModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -124,44 +141,77 @@ func (i InstanceOperation) IsExist(cols map[string]string) bool {
 	return seter.Exist()
 }
 
-// Update instance information
-func (i InstanceOperation) Update(obj interface{}, cols ...string) error {
-	instance, ok := obj.(models.InstanceModel)
+// Update instance information, the resource version will be checked and increased. If the obj is a pointer,
+// the new resource version will be set back to it.
+func (i InstanceOperation) Update(obj interface{}, cols ...string) (err error) {
+	defer observe(i.ctx, instanceTable, "Update")()
+	instance, ok := toInstanceModelPtr(obj)
 	if !ok {
 		return fmt.Errorf("obj type is not InstanceModel")
 	}
-	instance.Generate(time.Now().UTC(), true)
-	old := models.InstanceModel{ID: instance.ID}
-	sql := models.GetNewOrm()
-	if err := sql.Read(&old); err != nil {
+	tx := models.NewTransaction(models.GetNewOrm())
+	if err = tx.BeginTransaction(); err != nil {
 		return err
 	}
-	instance.CreateTimestamp = old.CreateTimestamp
-	_, err := sql.Update(&instance, cols...)
+	defer models.Handler(&err, tx)
+	err = i.updateWithVersion(instance, tx.GetTransaction(), cols...)
 	return err
 }
 
 // UpdateTx update instance information with transaction
 func (i InstanceOperation) UpdateTx(obj interface{}, tx orm.TxOrmer, cols ...string) error {
-	instance, ok := obj.(models.InstanceModel)
+	defer observe(i.ctx, instanceTable, "UpdateTx")()
+	instance, ok := toInstanceModelPtr(obj)
 	if !ok {
 		return fmt.Errorf("obj type is not RepositoryModel")
 	}
 	if tx == nil {
 		return fmt.Errorf("transaction should not be nil")
 	}
+	return i.updateWithVersion(instance, tx, cols...)
+}
+
+func (i InstanceOperation) updateWithVersion(instance *models.InstanceModel, tx orm.TxOrmer, cols ...string) error {
 	instance.Generate(time.Now().UTC(), true)
 	old := models.InstanceModel{ID: instance.ID}
 	if err := tx.Read(&old); err != nil {
 		return err
 	}
 	instance.CreateTimestamp = old.CreateTimestamp
-	_, err := tx.Update(&instance, cols...)
+	if err := models.CompareAndSwapVersion(tx, &old, instance.ID, instance.ResourceVersion); err != nil {
+		return err
+	}
+	instance.ResourceVersion++
+	if _, err := tx.Update(instance, cols...); err != nil {
+		instance.ResourceVersion--
+		return err
+	}
+	return nil
+}
+
+// UpdateStatus set the status of the instance observed from the cluster, if its status in database is still the old
+// one. Only the status column is written, the resource version is neither checked nor increased.
+func (i InstanceOperation) UpdateStatus(id, oldStatus, status string) error {
+	defer observe(i.ctx, instanceTable, "UpdateStatus")()
+	_, err := models.GetNewOrm().QueryTable(models.InstanceModel{}).Filter("id", id).Filter("status", oldStatus).
+		Update(orm.Params{"status": status})
 	return err
 }
 
+func toInstanceModelPtr(obj interface{}) (*models.InstanceModel, bool) {
+	switch instance := obj.(type) {
+	case models.InstanceModel:
+		return &instance, true
+	case *models.InstanceModel:
+		return instance, instance != nil
+	default:
+		return nil, false
+	}
+}
+
 // Delete the instance
 func (i InstanceOperation) Delete(obj interface{}) error {
+	defer observe(i.ctx, instanceTable, "Delete")()
 	sql := models.GetNewOrm()
 	instance, ok := obj.(models.InstanceModel)
 	if !ok {
@@ -181,6 +231,7 @@ func (i InstanceOperation) Delete(obj interface{}) error {
 
 // DeleteTx the instance with transaction
 func (i InstanceOperation) DeleteTx(obj interface{}, tx orm.TxOrmer) error {
+	defer observe(i.ctx, instanceTable, "DeleteTx")()
 	instance, ok := obj.(models.InstanceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not InstanceModel")
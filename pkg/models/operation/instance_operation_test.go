@@ -17,6 +17,7 @@
 package operation
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -474,7 +475,40 @@ func TestInstanceOperation_Update(t *testing.T) {
 	}
 }
 
+func TestInstanceOperation_UpdateConflict(t *testing.T) {
+	err := instance.Update(&models.InstanceModel{ID: "instance-1", ResourceVersion: 100})
+	if err == nil || (ignoreDBLockError(err) != nil && !errors.Is(err, models.ErrResourceVersionConflict)) {
+		t.Errorf("Update() error = %v, want %v", err, models.ErrResourceVersionConflict)
+	}
+}
+
+func TestInstanceOperation_UpdateStatus(t *testing.T) {
+	current := models.InstanceModel{ID: "instance-1"}
+	if err := models.GetNewOrm().Read(&current); err != nil {
+		t.Fatalf("cannot read the instance, err: %s", err)
+	}
+	defer func() { _ = instance.UpdateStatus(current.ID, "Failed", current.Status) }()
+	for _, status := range [][2]string{{"Deleting", "Unknown"}, {current.Status, "Failed"}} {
+		if err := instance.UpdateStatus(current.ID, status[0], status[1]); err != nil {
+			if ignoreDBLockError(err) == nil {
+				t.Skipf("UpdateStatus() error = %v", err)
+			}
+			t.Fatalf("UpdateStatus() error = %v", err)
+		}
+	}
+	got := models.InstanceModel{ID: current.ID}
+	if err := models.GetNewOrm().Read(&got); err != nil {
+		t.Fatalf("cannot read the instance, err: %s", err)
+	}
+	if got.Status != "Failed" || got.ResourceVersion != current.ResourceVersion {
+		t.Errorf("UpdateStatus() = (%s, %d), want (Failed, %d)", got.Status, got.ResourceVersion,
+			current.ResourceVersion)
+	}
+}
+
 func TestInstanceOperation_UpdateTx(t *testing.T) {
+	current := models.InstanceModel{ID: "instance-1"}
+	_ = models.GetNewOrm().Read(&current)
 	tx, err := models.GetNewOrm().Begin()
 	if err != nil {
 		t.Errorf("cannot get the tx, err: %s", err)
@@ -520,6 +554,7 @@ func TestInstanceOperation_UpdateTx(t *testing.T) {
 					ClusterName:      "default",
 					CreateTimestamp:  now,
 					Resource:         &models.ResourceModel{ID: "binding-id-1-resource-1"},
+					ResourceVersion:  current.ResourceVersion,
 				},
 				tx: tx,
 			},
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/utils/metrics"
+	"github.com/kappital/kappital/pkg/utils/tracing"
+)
+
+// the table labels of the database metrics
+const (
+	serviceBindingTable = "service_binding"
+	instanceTable       = "instance"
+	resourceTable       = "resource"
+	apiTokenTable       = "api_token"
+)
+
+// observe the database call, the returned function should be deferred to record the latency and end the span
+func observe(ctx context.Context, table, operation string) func() {
+	start := time.Now()
+	_, span := tracing.StartChild(ctx, fmt.Sprintf("db.%s.%s", table, operation),
+		attribute.String("db.table", table), attribute.String("db.operation", operation))
+	return func() {
+		span.End()
+		metrics.ObserveDBQuery(table, operation, start)
+	}
+}
+
+// statusCount the row of counting the records by status and cluster
+type statusCount struct {
+	Status      string
+	ClusterName string
+	Count       int64
+}
+
+// StatusCollector collects the counts of the service bindings and the instances by status and cluster. The counts
+// are queried from the database when scraping, thus all replicas of the manager report the same values.
+type StatusCollector struct {
+	bindingDesc  *prometheus.Desc
+	instanceDesc *prometheus.Desc
+}
+
+// NewStatusCollector create the collector of the service binding and instance counts
+func NewStatusCollector() *StatusCollector {
+	return &StatusCollector{
+		bindingDesc: prometheus.NewDesc("kappital_service_bindings",
+			"Number of the service bindings by status and cluster.", []string{"status", "cluster"}, nil),
+		instanceDesc: prometheus.NewDesc("kappital_instances",
+			"Number of the instances by status and cluster.", []string{"status", "cluster"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector interface
+func (c *StatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bindingDesc
+	ch <- c.instanceDesc
+}
+
+// Collect implements prometheus.Collector interface
+func (c *StatusCollector) Collect(ch chan<- prometheus.Metric) {
+	for desc, table := range map[*prometheus.Desc]string{
+		c.bindingDesc:  "service_binding_model",
+		c.instanceDesc: "instance_model",
+	} {
+		counts, err := countByStatus(table)
+		if err != nil {
+			klog.Errorf("cannot count the %s by status, err: %v", table, err)
+			continue
+		}
+		for _, item := range counts {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(item.Count),
+				item.Status, item.ClusterName)
+		}
+	}
+}
+
+func countByStatus(table string) ([]statusCount, error) {
+	var counts []statusCount
+	_, err := models.GetNewOrm().Raw(fmt.Sprintf(
+		"SELECT status, cluster_name, COUNT(*) AS count FROM %s GROUP BY status, cluster_name", table)).
+		QueryRows(&counts)
+	return counts, err
+}
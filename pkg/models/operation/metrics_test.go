@@ -0,0 +1,32 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package operation
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStatusCollector_Collect(t *testing.T) {
+	c := NewStatusCollector()
+	if got := testutil.CollectAndCount(c, "kappital_service_bindings"); got == 0 {
+		t.Errorf("CollectAndCount() of service bindings got 0, want > 0")
+	}
+	if got := testutil.CollectAndCount(c, "kappital_instances"); got == 0 {
+		t.Errorf("CollectAndCount() of instances got 0, want > 0")
+	}
+}
@@ -101,7 +101,8 @@ func TestMain(m *testing.M) {
 		fmt.Printf("cannot register database for operation, err: %v\n", err)
 		return
 	}
-	orm.RegisterModel(new(models.ServiceBindingModel), new(models.ResourceModel), new(models.InstanceModel))
+	orm.RegisterModel(new(models.ServiceBindingModel), new(models.ResourceModel), new(models.InstanceModel),
+		new(models.APITokenModel))
 	if err = orm.RunSyncdb("default", false, true); err != nil {
 		fmt.Printf("run sync db error %v", err)
 		return
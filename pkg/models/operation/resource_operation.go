@@ -17,6 +17,7 @@
 package operation
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -26,7 +27,15 @@ import (
 )
 
 // ResourceOperation to manager the resource data in database
-type ResourceOperation struct{}
+type ResourceOperation struct {
+	ctx context.Context
+}
+
+// WithContext get a copy of the operation, the database calls of which are traced as the children of the span in ctx
+func (r ResourceOperation) WithContext(ctx context.Context) ResourceOperation {
+	r.ctx = ctx
+	return r
+}
 
 // Insert resource information to database
 func (r ResourceOperation) Insert(interface{}) error {
@@ -35,6 +44,7 @@ func (r ResourceOperation) Insert(interface{}) error {
 
 // InsertTx insert the resource with transaction
 func (r ResourceOperation) InsertTx(obj interface{}, tx orm.TxOrmer) error {
+	defer observe(r.ctx, resourceTable, "InsertTx")()
 	resource, ok := obj.(models.ResourceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ResourceModel")
@@ -44,7 +54,7 @@ func (r ResourceOperation) InsertTx(obj interface{}, tx orm.TxOrmer) error {
 	}
 	resource.Generate(time.Now().UTC(), false)
 	for i := range resource.Instances {
-		err := InstanceOperation{}.InsertWithRelFk(*resource.Instances[i], resource, tx)
+		err := InstanceOperation{ctx: r.ctx}.InsertWithRelFk(*resource.Instances[i], resource, tx)
 		if err != nil {
 			return err
 		}
@@ -55,6 +65,7 @@ func (r ResourceOperation) InsertTx(obj interface{}, tx orm.TxOrmer) error {
 
 // InsertWithRelFk insert the resource with its relation foreign key
 func (r ResourceOperation) InsertWithRelFk(obj interface{}, fk interface{}, tx orm.TxOrmer) error {
+	defer observe(r.ctx, resourceTable, "InsertWithRelFk")()
 	resource, ok := obj.(models.ResourceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ResourceModel")
@@ -74,6 +85,7 @@ func (r ResourceOperation) InsertWithRelFk(obj interface{}, fk interface{}, tx o
 
 // Get the resource from the database and filter by cols
 func (r ResourceOperation) Get(cols map[string]string) (interface{}, error) {
+	defer observe(r.ctx, resourceTable, "Get")()
 	seter := models.GetNewOrm().QueryTable(models.ResourceModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -85,6 +97,7 @@ func (r ResourceOperation) Get(cols map[string]string) (interface{}, error) {
 
 // GetByPrimaryKey get the resource with its primary key (id)
 func (r ResourceOperation) GetByPrimaryKey(id string) (interface{}, error) {
+	defer observe(r.ctx, resourceTable, "GetByPrimaryKey")()
 	resource := models.ResourceModel{ID: id}
 	err := models.GetNewOrm().Read(&resource)
 	return resource, err
@@ -92,6 +105,7 @@ func (r ResourceOperation) GetByPrimaryKey(id string) (interface{}, error) {
 
 // GetDetail of resource
 func (r ResourceOperation) GetDetail(cols map[string]string) (interface{}, error) {
+	defer observe(r.ctx, resourceTable, "GetDetail")()
 	seter := models.GetNewOrm().QueryTable(models.ResourceModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -106,6 +120,7 @@ func (r ResourceOperation) GetDetail(cols map[string]string) (interface{}, error
 
 // GetList of resource
 func (r ResourceOperation) GetList(cols map[string]string) (interface{}, error) {
+	defer observe(r.ctx, resourceTable, "GetList")()
 	seter := models.GetNewOrm().QueryTable(models.ResourceModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -117,6 +132,7 @@ func (r ResourceOperation) GetList(cols map[string]string) (interface{}, error)
 
 // GetListByFilter get the resource information by filter
 func (r ResourceOperation) GetListByFilter(filter map[string][]interface{}) (interface{}, error) {
+	defer observe(r.ctx, resourceTable, "GetListByFilter")()
 	seter := models.GetNewOrm().QueryTable(models.ResourceModel{})
 	for k, v := range filter {
 		if v == nil {
@@ -132,6 +148,7 @@ func (r ResourceOperation) GetListByFilter(filter map[string][]interface{}) (int
 
 // IsExist does the resource information is existed in database with cols filter
 func (r ResourceOperation) IsExist(cols map[string]string) bool {
+	defer observe(r.ctx, resourceTable, "IsExist")()
 	seter := models.GetNewOrm().QueryTable(models.InstanceModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -141,6 +158,7 @@ func (r ResourceOperation) IsExist(cols map[string]string) bool {
 
 // Update resource information
 func (r ResourceOperation) Update(obj interface{}, cols ...string) error {
+	defer observe(r.ctx, resourceTable, "Update")()
 	sql := models.GetNewOrm()
 	tx := models.NewTransaction(sql)
 	err := tx.BeginTransaction()
@@ -153,6 +171,7 @@ func (r ResourceOperation) Update(obj interface{}, cols ...string) error {
 
 // UpdateTx update resource information with transaction
 func (r ResourceOperation) UpdateTx(obj interface{}, tx orm.TxOrmer, cols ...string) error {
+	defer observe(r.ctx, resourceTable, "UpdateTx")()
 	resource, ok := obj.(models.ResourceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ResourceModel")
@@ -162,7 +181,7 @@ func (r ResourceOperation) UpdateTx(obj interface{}, tx orm.TxOrmer, cols ...str
 		return fmt.Errorf("transaction should not be nil")
 	}
 	for i := range resource.Instances {
-		err := InstanceOperation{}.UpdateTx(*resource.Instances[i], tx)
+		err := InstanceOperation{ctx: r.ctx}.UpdateTx(*resource.Instances[i], tx)
 		if err != nil {
 			return err
 		}
@@ -178,6 +197,7 @@ func (r ResourceOperation) UpdateTx(obj interface{}, tx orm.TxOrmer, cols ...str
 
 // Delete the resource
 func (r ResourceOperation) Delete(obj interface{}) error {
+	defer observe(r.ctx, resourceTable, "Delete")()
 	resource, ok := obj.(models.ResourceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ResourceModel")
@@ -192,6 +212,7 @@ func (r ResourceOperation) Delete(obj interface{}) error {
 
 // DeleteTx the resource
 func (r ResourceOperation) DeleteTx(obj interface{}, tx orm.TxOrmer) error {
+	defer observe(r.ctx, resourceTable, "DeleteTx")()
 	resource, ok := obj.(models.ResourceModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ResourceModel")
@@ -17,6 +17,7 @@
 package operation
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -27,10 +28,19 @@ import (
 )
 
 // ServiceBindingOperation to manager the service binding data in database
-type ServiceBindingOperation struct{}
+type ServiceBindingOperation struct {
+	ctx context.Context
+}
+
+// WithContext get a copy of the operation, the database calls of which are traced as the children of the span in ctx
+func (s ServiceBindingOperation) WithContext(ctx context.Context) ServiceBindingOperation {
+	s.ctx = ctx
+	return s
+}
 
 // Insert service binding information to database
 func (s ServiceBindingOperation) Insert(obj interface{}) error {
+	defer observe(s.ctx, serviceBindingTable, "Insert")()
 	sb, ok := obj.(models.ServiceBindingModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceBindingModel")
@@ -44,7 +54,7 @@ func (s ServiceBindingOperation) Insert(obj interface{}) error {
 	defer models.Handler(&err, tx)
 
 	for i := range sb.Resources {
-		err = ResourceOperation{}.InsertWithRelFk(*sb.Resources[i], sb, tx.GetTransaction())
+		err = ResourceOperation{ctx: s.ctx}.InsertWithRelFk(*sb.Resources[i], sb, tx.GetTransaction())
 		if err != nil {
 			return err
 		}
@@ -55,6 +65,7 @@ func (s ServiceBindingOperation) Insert(obj interface{}) error {
 
 // InsertTx service binding information to database with transaction
 func (s ServiceBindingOperation) InsertTx(obj interface{}, tx orm.TxOrmer) error {
+	defer observe(s.ctx, serviceBindingTable, "InsertTx")()
 	sb, ok := obj.(models.ServiceBindingModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceBindingModel")
@@ -62,7 +73,7 @@ func (s ServiceBindingOperation) InsertTx(obj interface{}, tx orm.TxOrmer) error
 	sb.Generate(time.Now().UTC(), false)
 
 	for i := range sb.Resources {
-		err := ResourceOperation{}.InsertWithRelFk(*sb.Resources[i], sb, tx)
+		err := ResourceOperation{ctx: s.ctx}.InsertWithRelFk(*sb.Resources[i], sb, tx)
 		if err != nil {
 			return err
 		}
@@ -79,6 +90,7 @@ func (s ServiceBindingOperation) InsertWithRelFk(interface{}, interface{}, orm.T
 
 // Get the service binding from the database and filter by cols
 func (s ServiceBindingOperation) Get(cols map[string]string) (interface{}, error) {
+	defer observe(s.ctx, serviceBindingTable, "Get")()
 	seter := models.GetNewOrm().QueryTable(models.ServiceBindingModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -90,6 +102,7 @@ func (s ServiceBindingOperation) Get(cols map[string]string) (interface{}, error
 
 // GetByPrimaryKey get the service binding with its primary key (id)
 func (s ServiceBindingOperation) GetByPrimaryKey(id string) (interface{}, error) {
+	defer observe(s.ctx, serviceBindingTable, "GetByPrimaryKey")()
 	binding := models.ServiceBindingModel{ID: id}
 	err := models.GetNewOrm().Read(&binding)
 	return binding, err
@@ -97,6 +110,7 @@ func (s ServiceBindingOperation) GetByPrimaryKey(id string) (interface{}, error)
 
 // GetDetail of service binding
 func (s ServiceBindingOperation) GetDetail(cols map[string]string) (interface{}, error) {
+	defer observe(s.ctx, serviceBindingTable, "GetDetail")()
 	seter := models.GetNewOrm().QueryTable(models.ServiceBindingModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -118,6 +132,7 @@ func (s ServiceBindingOperation) GetDetail(cols map[string]string) (interface{},
 
 // GetList of service binding
 func (s ServiceBindingOperation) GetList(cols map[string]string) (interface{}, error) {
+	defer observe(s.ctx, serviceBindingTable, "GetList")()
 	seter := models.GetNewOrm().QueryTable(models.ServiceBindingModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -129,6 +144,7 @@ func (s ServiceBindingOperation) GetList(cols map[string]string) (interface{}, e
 
 // GetListByFilter get the service binding information by filter
 func (s ServiceBindingOperation) GetListByFilter(filter map[string][]interface{}) (interface{}, error) {
+	defer observe(s.ctx, serviceBindingTable, "GetListByFilter")()
 	seter := models.GetNewOrm().QueryTable(models.ServiceBindingModel{})
 	for k, v := range filter {
 		if v == nil {
@@ -144,6 +160,7 @@ func (s ServiceBindingOperation) GetListByFilter(filter map[string][]interface{}
 
 // IsExist does the service binding information is existed in database with cols filter
 func (s ServiceBindingOperation) IsExist(cols map[string]string) bool {
+	defer observe(s.ctx, serviceBindingTable, "IsExist")()
 	seter := models.GetNewOrm().QueryTable(models.ServiceBindingModel{})
 	for k, v := range cols {
 		seter = seter.Filter(k, v)
@@ -151,26 +168,27 @@ func (s ServiceBindingOperation) IsExist(cols map[string]string) bool {
 	return seter.Exist()
 }
 
-// Update service binding information
-func (s ServiceBindingOperation) Update(obj interface{}, cols ...string) error {
-	sb, ok := obj.(models.ServiceBindingModel)
+// Update service binding information, the resource version will be checked and increased. If the obj is a pointer,
+// the new resource version will be set back to it.
+func (s ServiceBindingOperation) Update(obj interface{}, cols ...string) (err error) {
+	defer observe(s.ctx, serviceBindingTable, "Update")()
+	sb, ok := toServiceBindingModelPtr(obj)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceBindingModel")
 	}
-	sb.Generate(time.Now().UTC(), true)
-	sql := models.GetNewOrm()
-	old := models.ServiceBindingModel{ID: sb.ID}
-	if err := sql.Read(&old); err != nil {
+	tx := models.NewTransaction(models.GetNewOrm())
+	if err = tx.BeginTransaction(); err != nil {
 		return err
 	}
-	sb.CreateTime = old.CreateTime
-	_, err := sql.Update(&sb, cols...)
+	defer models.Handler(&err, tx)
+	err = s.updateWithVersion(sb, tx.GetTransaction(), cols...)
 	return err
 }
 
 // UpdateTx update service binding information with transaction
 func (s ServiceBindingOperation) UpdateTx(obj interface{}, tx orm.TxOrmer, cols ...string) error {
-	sb, ok := obj.(models.ServiceBindingModel)
+	defer observe(s.ctx, serviceBindingTable, "UpdateTx")()
+	sb, ok := toServiceBindingModelPtr(obj)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceBindingModel")
 	}
@@ -178,23 +196,47 @@ func (s ServiceBindingOperation) UpdateTx(obj interface{}, tx orm.TxOrmer, cols
 		return fmt.Errorf("transaction should not be nil")
 	}
 	for i := range sb.Resources {
-		err := ResourceOperation{}.UpdateTx(*sb.Resources[i], tx)
+		err := ResourceOperation{ctx: s.ctx}.UpdateTx(*sb.Resources[i], tx)
 		if err != nil {
 			return err
 		}
 	}
+	return s.updateWithVersion(sb, tx, cols...)
+}
 
+func (s ServiceBindingOperation) updateWithVersion(sb *models.ServiceBindingModel, tx orm.TxOrmer,
+	cols ...string) error {
+	sb.Generate(time.Now().UTC(), true)
 	old := models.ServiceBindingModel{ID: sb.ID}
 	if err := tx.Read(&old); err != nil {
 		return err
 	}
 	sb.CreateTime = old.CreateTime
-	_, err := tx.Update(&sb, cols...)
-	return err
+	if err := models.CompareAndSwapVersion(tx, &old, sb.ID, sb.ResourceVersion); err != nil {
+		return err
+	}
+	sb.ResourceVersion++
+	if _, err := tx.Update(sb, cols...); err != nil {
+		sb.ResourceVersion--
+		return err
+	}
+	return nil
+}
+
+func toServiceBindingModelPtr(obj interface{}) (*models.ServiceBindingModel, bool) {
+	switch sb := obj.(type) {
+	case models.ServiceBindingModel:
+		return &sb, true
+	case *models.ServiceBindingModel:
+		return sb, sb != nil
+	default:
+		return nil, false
+	}
 }
 
 // Delete the service binding
 func (s ServiceBindingOperation) Delete(obj interface{}) error {
+	defer observe(s.ctx, serviceBindingTable, "Delete")()
 	sb, ok := obj.(models.ServiceBindingModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceBindingModel")
@@ -214,6 +256,7 @@ func (s ServiceBindingOperation) Delete(obj interface{}) error {
 
 // DeleteTx the service binding with transaction
 func (s ServiceBindingOperation) DeleteTx(obj interface{}, tx orm.TxOrmer) error {
+	defer observe(s.ctx, serviceBindingTable, "DeleteTx")()
 	sb, ok := obj.(models.ServiceBindingModel)
 	if !ok {
 		return fmt.Errorf("obj type is not ServiceBindingModel")
@@ -17,6 +17,7 @@
 package operation
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -389,7 +390,16 @@ func TestServiceBindingOperation_Update(t *testing.T) {
 	}
 }
 
+func TestServiceBindingOperation_UpdateConflict(t *testing.T) {
+	err := binding.Update(&models.ServiceBindingModel{ID: "binding-id-1", ResourceVersion: 100})
+	if err == nil || (ignoreDBLockError(err) != nil && !errors.Is(err, models.ErrResourceVersionConflict)) {
+		t.Errorf("Update() error = %v, want %v", err, models.ErrResourceVersionConflict)
+	}
+}
+
 func TestServiceBindingOperation_UpdateTx(t *testing.T) {
+	current := testServiceBindingModels[0]
+	_ = models.GetNewOrm().Read(&current)
 	tx, err := models.GetNewOrm().Begin()
 	if err != nil {
 		t.Errorf("cannot get the tx, err: %s", err)
@@ -420,7 +430,7 @@ func TestServiceBindingOperation_UpdateTx(t *testing.T) {
 		},
 		{
 			name: "Test ServiceBindingOperation Update ",
-			args: args{obj: testServiceBindingModels[0], tx: tx},
+			args: args{obj: current, tx: tx},
 		},
 	}
 	for _, tt := range tests {
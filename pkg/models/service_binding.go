@@ -43,6 +43,12 @@ type ServiceBindingModel struct {
 	CreateTime               time.Time `orm:"type(datetime);auto_now_add;column(create_timestamp)"`
 	UpdateTime               time.Time `orm:"type(datetime);null;column(update_timestamp)"`
 	ProcessTime              time.Time `orm:"type(datetime);null;column(process_timestamp)"`
+	Timeouts                 string    `orm:"type(text);null;column(timeouts)"`
+	Initiator                string    `orm:"type(text);null;column(initiator)"`
+	ResourceVersion          int64     `json:"resourceVersion" orm:"default(0);column(resource_version)"`
+	DeletionProtection       bool      `json:"deletionProtection" orm:"default(false);column(deletion_protection)"`
+	ForceDelete              bool      `json:"forceDelete" orm:"default(false);column(force_delete)"`
+	RemoveFinalizers         bool      `json:"removeFinalizers" orm:"default(false);column(remove_finalizers)"`
 
 	Resources []*ResourceModel `json:"resources" orm:"null;reverse(many)"`
 }
@@ -147,6 +153,12 @@ type InstanceModel struct {
 	ProcessTime         time.Time              `orm:"type(datetime);null;column(process_time)"`
 	UpdateTime          time.Time              `orm:"type(datetime);null;column(update_timestamp)"`
 	InstallState        string                 `orm:"type(text);column(install_state)"`
+	Timeouts            string                 `orm:"type(text);null;column(timeouts)"`
+	Initiator           string                 `orm:"type(text);null;column(initiator)"`
+	ResourceVersion     int64                  `json:"resourceVersion" orm:"default(0);column(resource_version)"`
+	DeletionProtection  bool                   `json:"deletionProtection" orm:"default(false);column(deletion_protection)"`
+	ForceDelete         bool                   `json:"forceDelete" orm:"default(false);column(force_delete)"`
+	RemoveFinalizers    bool                   `json:"removeFinalizers" orm:"default(false);column(remove_finalizers)"`
 
 	Resource *ResourceModel `orm:"null;rel(fk)"`
 }
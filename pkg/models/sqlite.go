@@ -63,7 +63,7 @@ func (s sqlite) GetDBConnection() string {
 func (s sqlite) registerModels(serviceType serviceType) {
 	switch serviceType {
 	case Manager:
-		orm.RegisterModel(new(ServiceBindingModel), new(ResourceModel), new(InstanceModel))
+		orm.RegisterModel(new(ServiceBindingModel), new(ResourceModel), new(InstanceModel), new(APITokenModel))
 	}
 }
 
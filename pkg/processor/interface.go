@@ -17,6 +17,10 @@
 package processor
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/kappital/kappital/pkg/apis"
@@ -34,13 +38,18 @@ const (
 // IProcessor is asynchronous thread interface
 type IProcessor interface {
 	List() ([]interface{}, error)
-	Process(opType string, obj interface{})
+	Process(ctx context.Context, opType string, obj interface{})
 	Run(stopCh <-chan struct{})
 	ProcessType() string
 	ProcessObject() interface{}
 }
 
-var processors map[string]IProcessor
+var (
+	processors map[string]IProcessor
+	running    sync.WaitGroup
+	// runningCount the number of the processors which are running
+	runningCount int32
+)
 
 func init() {
 	processors = map[string]IProcessor{}
@@ -67,6 +76,33 @@ func GetProcesses() map[string]IProcessor {
 // StartAllProcessors start all register process
 func StartAllProcessors(stopCh <-chan struct{}) {
 	for _, process := range processors {
-		go process.Run(stopCh)
+		running.Add(1)
+		atomic.AddInt32(&runningCount, 1)
+		go func(process IProcessor) {
+			defer running.Done()
+			defer atomic.AddInt32(&runningCount, -1)
+			process.Run(stopCh)
+		}(process)
+	}
+}
+
+// RunningProcessors the number of the running processors and the registered ones
+func RunningProcessors() (running, registered int) {
+	return int(atomic.LoadInt32(&runningCount)), len(processors)
+}
+
+// WaitForProcessors wait for all started processors finishing their in-flight items after the stopCh is closed.
+// The ctx error will be returned if the processors cannot be stopped before the ctx is done.
+func WaitForProcessors(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -17,27 +17,32 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/beego/beego/v2/client/orm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"github.com/kappital/kappital/pkg/apis"
 	"github.com/kappital/kappital/pkg/handler"
 	"github.com/kappital/kappital/pkg/models"
 	"github.com/kappital/kappital/pkg/resource"
+	"github.com/kappital/kappital/pkg/utils/audit"
+	"github.com/kappital/kappital/pkg/utils/metrics"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+	"github.com/kappital/kappital/pkg/utils/tracing"
 	"github.com/kappital/kappital/pkg/watcher"
 )
 
-const (
-	retryInterval = 5 * time.Second
-	// LongestDurationForProcess defines the timeout interval for asynchronous event processing
-	LongestDurationForProcess = 20 * time.Minute
-)
+const retryInterval = 5 * time.Second
 
 // Processor the struct of process attribute
 type Processor struct {
@@ -48,6 +53,17 @@ type Processor struct {
 	processName     string
 	processorObject interface{}
 	careStatusSet   sets.String
+
+	// traceParents the span contexts of the operations which add the work-queue items, the item spans are the
+	// children of them, thus the processing can be traced from the API requests
+	traceParents map[string]trace.SpanContext
+	traceMutex   sync.Mutex
+}
+
+// processStep the named step of processing the object
+type processStep struct {
+	name string
+	f    func(context.Context, interface{}) (bool, error)
 }
 
 // NewProcessor return the entity object of process
@@ -62,6 +78,7 @@ func NewProcessor(name string, processorObj interface{}, handlerType handler.Typ
 		processName:     name,
 		processorObject: processorObj,
 		careStatusSet:   actionSets,
+		traceParents:    map[string]trace.SpanContext{},
 	}
 }
 
@@ -75,18 +92,24 @@ func (p *Processor) ProcessObject() interface{} {
 	return p.processorObject
 }
 
-// Run start to run process
+// Run start to run process, it returns after the stopCh is closed and all in-flight items are finished
 func (p *Processor) Run(stopCh <-chan struct{}) {
-	defer p.workQueue.ShutDown()
-
 	klog.Infof("Starting %s processor", p.processName)
 	defer klog.Infof("Shutting down %s processor", p.processName)
 
+	var wg sync.WaitGroup
 	for i := 0; i < p.workers; i++ {
-		go wait.Until(p.sync, 0, stopCh)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(p.sync, 0, stopCh)
+		}()
 	}
 
 	<-stopCh
+	// unblock the idle workers, and wait for the busy ones finishing the current item
+	p.workQueue.ShutDown()
+	wg.Wait()
 }
 
 func (p *Processor) sync() {
@@ -97,7 +120,16 @@ func (p *Processor) sync() {
 	}
 	defer p.workQueue.Done(key)
 
-	retry, err := p.syncHandler(key.(string))
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), p.getTraceParent(key.(string), false))
+	ctx, span := tracing.Start(ctx, p.processName+".sync", attribute.String("id", key.(string)))
+	start := time.Now()
+	retry, err := p.syncHandler(ctx, key.(string))
+	metrics.ObserveProcessorSync(p.processName, retry, err, start)
+	span.SetAttributes(attribute.Bool("retry", retry))
+	tracing.End(span, err)
+	if !retry {
+		p.getTraceParent(key.(string), true)
+	}
 	if err != nil {
 		klog.Errorf("Error processing %s %s: %s", p.processName, key, err.Error())
 	}
@@ -106,16 +138,34 @@ func (p *Processor) sync() {
 	}
 }
 
-func (p *Processor) syncHandler(name string) (retry bool, err error) {
-	obj, err := p.resource.GetCommonDBObject(name)
+// getTraceParent get the span context of the operation which adds the item, and forget it if remove is true
+func (p *Processor) getTraceParent(key string, remove bool) trace.SpanContext {
+	p.traceMutex.Lock()
+	defer p.traceMutex.Unlock()
+	parent := p.traceParents[key]
+	if remove {
+		delete(p.traceParents, key)
+	}
+	return parent
+}
+
+func (p *Processor) syncHandler(ctx context.Context, name string) (retry bool, err error) {
+	obj, err := p.resource.GetCommonDBObject(ctx, name)
 	if err != nil {
 		return !errors.Is(err, orm.ErrNoRows), err
 	}
+	// the handlers log with the id of the request which causes the processing
+	ctx = requestid.NewContext(ctx, p.resource.GetObjectInitiator(obj).RequestID)
 	status := p.resource.GetObjectStatus(obj)
 	defer func() {
-		if !retry {
+		if errors.Is(err, models.ErrResourceVersionConflict) {
+			// the object has been modified by others, re-read it from database and retry later, unless the
+			// processing has been timeout, otherwise the object which keeps conflicting will never be failed
+			klog.V(3).Infof("%s %s has been modified, retry later", p.processName, name)
+			retry = true
+		} else if !retry {
 			// reset process timeout when retry false, error nil
-			innerErr := p.resource.UpdateObjProcessTime(obj, time.Time{})
+			innerErr := p.resource.UpdateObjProcessTime(ctx, obj, time.Time{})
 			if innerErr != nil {
 				retry = true
 				err = innerErr
@@ -124,14 +174,23 @@ func (p *Processor) syncHandler(name string) (retry bool, err error) {
 			return
 		}
 		// check handle timeout
-		if !p.subProcessTimeout(obj) {
+		if !p.subProcessTimeout(obj, status) {
 			return
 		}
+		if errors.Is(err, models.ErrResourceVersionConflict) {
+			// the stale object conflicts again when it is marked failed, thus re-read it, and the object whose
+			// status has been changed by others is not failed
+			latest, readErr := p.resource.GetCommonDBObject(ctx, name)
+			if readErr != nil || p.resource.GetObjectStatus(latest) != status {
+				return
+			}
+			obj = latest
+		}
 		errMsg := ""
 		if err != nil {
 			errMsg = err.Error()
 		}
-		innerErr := p.resource.UpdateProcessFailed(obj, getFailedStatus(status),
+		innerErr := p.resource.UpdateProcessFailed(ctx, obj, getFailedStatus(status),
 			fmt.Sprintf("timeout to handle resource %s, status %s, last error: %s",
 				p.resource.GetResourceType(), p.resource.GetObjectStatus(obj), errMsg))
 		if innerErr != nil {
@@ -142,25 +201,29 @@ func (p *Processor) syncHandler(name string) (retry bool, err error) {
 		retry = false
 		err = fmt.Errorf("timeout to handle resource %s, status %s, last error:%s",
 			p.resource.GetResourceType(), p.resource.GetObjectStatus(obj), errMsg)
+		info := audit.SystemActionInfo(p.resource.GetObjectInitiator(obj), "MarkProcessTimeout", "Update",
+			fmt.Sprintf("%s/%s", p.resource.GetResourceType(), p.resource.GetObjectID(obj)))
+		info.Message = err.Error()
+		audit.Fault(info)
 	}()
 	if p.careStatusSet.Has(status) {
 		switch status {
 		case models.StatusDeleting:
-			return processStatus(obj, []func(interface{}) (bool, error){p.handler.BeforeDelete,
-				p.handler.Delete, p.handler.AfterDelete})
+			return p.processStatus(ctx, obj, []processStep{{"BeforeDelete", p.handler.BeforeDelete},
+				{"Delete", p.handler.Delete}, {"AfterDelete", p.handler.AfterDelete}})
 		case models.StatusUpgrading, models.StatusRollingBack:
-			return processStatus(obj, []func(interface{}) (bool, error){p.handler.BeforeUpgrade,
-				p.handler.Upgrade, p.handler.AfterUpgrade})
+			return p.processStatus(ctx, obj, []processStep{{"BeforeUpgrade", p.handler.BeforeUpgrade},
+				{"Upgrade", p.handler.Upgrade}, {"AfterUpgrade", p.handler.AfterUpgrade}})
 		case models.StatusInstalling, models.StatusInitializing:
-			return processStatus(obj, []func(interface{}) (bool, error){p.handler.BeforeInstall,
-				p.handler.Install, p.handler.AfterInstall})
+			return p.processStatus(ctx, obj, []processStep{{"BeforeInstall", p.handler.BeforeInstall},
+				{"Install", p.handler.Install}, {"AfterInstall", p.handler.AfterInstall}})
 		}
 	}
 	return false, nil
 }
 
-func (p *Processor) subProcessTimeout(obj interface{}) bool {
-	if time.Now().UTC().After(p.resource.GetObjUpdateTime(obj).Add(LongestDurationForProcess)) {
+func (p *Processor) subProcessTimeout(obj interface{}, status string) bool {
+	if time.Now().UTC().After(p.resource.GetObjUpdateTime(obj).Add(p.longestDurationForProcess(obj, status))) {
 		return true
 	}
 	if p.resource.GetObjectProcessTime(obj).IsZero() {
@@ -169,6 +232,26 @@ func (p *Processor) subProcessTimeout(obj interface{}) bool {
 	return time.Now().After(p.resource.GetObjectProcessTime(obj))
 }
 
+// longestDurationForProcess get the longest duration for processing the object since its last update, it will be
+// extended to the timeout of the current operation if the operation timeout is longer
+func (p *Processor) longestDurationForProcess(obj interface{}, status string) time.Duration {
+	longest := apis.GetProcessTimeoutConfig().LongestDurationForProcess
+	timeouts := p.resource.GetObjectTimeouts(obj)
+	var operation time.Duration
+	switch status {
+	case models.StatusDeleting:
+		operation = timeouts.DeleteDuration()
+	case models.StatusUpgrading, models.StatusRollingBack:
+		operation = timeouts.UpgradeDuration()
+	default:
+		operation = timeouts.InstallDuration()
+	}
+	if operation > longest {
+		return operation
+	}
+	return longest
+}
+
 func getFailedStatus(status string) string {
 	switch status {
 	case models.StatusDeleting:
@@ -183,13 +266,19 @@ func getFailedStatus(status string) string {
 }
 
 // Process implements watcher.Processor interface
-func (p *Processor) Process(opType string, obj interface{}) {
+func (p *Processor) Process(ctx context.Context, opType string, obj interface{}) {
 	switch opType {
 	case watcher.OPList, watcher.OPCreate:
 		fallthrough
 	case watcher.OPUpdate, watcher.OPDelete:
 		if p.careStatusSet.Has(p.resource.GetObjectStatus(obj)) {
-			p.workQueue.Add(p.resource.GetObjectID(obj))
+			key := p.resource.GetObjectID(obj)
+			if parent := trace.SpanContextFromContext(ctx); parent.IsValid() {
+				p.traceMutex.Lock()
+				p.traceParents[key] = parent
+				p.traceMutex.Unlock()
+			}
+			p.workQueue.Add(key)
 		}
 	}
 }
@@ -203,9 +292,12 @@ func (p *Processor) List() ([]interface{}, error) {
 	return res, nil
 }
 
-func processStatus(obj interface{}, funcs []func(interface{}) (bool, error)) (bool, error) {
-	for _, f := range funcs {
-		retry, err := f(obj)
+func (p *Processor) processStatus(ctx context.Context, obj interface{}, steps []processStep) (bool, error) {
+	for _, step := range steps {
+		stepCtx, span := tracing.Start(ctx, fmt.Sprintf("%s.%s", p.processName, step.name))
+		retry, err := step.f(stepCtx, obj)
+		span.SetAttributes(attribute.Bool("retry", retry))
+		tracing.End(span, err)
 		if err != nil || retry {
 			return retry, err
 		}
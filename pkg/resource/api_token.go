@@ -0,0 +1,130 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"k8s.io/klog/v2"
+
+	tokenv1alpha1 "github.com/kappital/kappital/pkg/apis/apitoken/v1alpha1"
+	"github.com/kappital/kappital/pkg/apis/internals"
+	"github.com/kappital/kappital/pkg/dao/apitoken"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+)
+
+// ErrAPITokenExists the API token with the same name has been created
+var ErrAPITokenExists = errors.New("the API token with the same name already exists")
+
+// APITokenResource operate the static API tokens in database, it is also the token store of the authentication
+type APITokenResource struct {
+	tokenDao apitoken.APIToken
+}
+
+// CreateAPIToken generate the API token and store its digest, the token is only returned here and cannot be
+// recovered later
+func (a *APITokenResource) CreateAPIToken(ctx context.Context, creation tokenv1alpha1.APITokenCreation) (
+	*tokenv1alpha1.APIToken, error) {
+	dao := a.tokenDao.WithContext(ctx)
+	if _, err := dao.Get(map[string]string{"name": creation.Name}); err == nil {
+		return nil, ErrAPITokenExists
+	} else if !errors.Is(err, orm.ErrNoRows) {
+		return nil, err
+	}
+	token, err := authentication.GenerateToken()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate the API token, err: %v", err)
+	}
+	item := internals.APIToken{
+		Name:       creation.Name,
+		User:       creation.User,
+		Groups:     creation.Groups,
+		TokenHash:  authentication.HashToken(token),
+		CreateTime: time.Now().UTC(),
+	}
+	if len(creation.ExpiresIn) > 0 {
+		d, err := time.ParseDuration(creation.ExpiresIn)
+		if err != nil {
+			return nil, err
+		}
+		item.ExpireTime = item.CreateTime.Add(d)
+	}
+	if err = dao.Create(item); err != nil {
+		return nil, err
+	}
+	klog.Infof("API token %s of user %s is created", item.Name, item.User)
+	resp := transAPITokenToResponse(item)
+	resp.Token = token
+	return &resp, nil
+}
+
+// GetAPITokens get the API tokens without the tokens themselves
+func (a *APITokenResource) GetAPITokens(ctx context.Context) ([]tokenv1alpha1.APIToken, error) {
+	obj, err := a.tokenDao.WithContext(ctx).GetList(nil)
+	if err != nil {
+		return nil, err
+	}
+	items := obj.([]internals.APIToken)
+	result := make([]tokenv1alpha1.APIToken, 0, len(items))
+	for _, item := range items {
+		result = append(result, transAPITokenToResponse(item))
+	}
+	return result, nil
+}
+
+// DeleteAPIToken revoke the API token by its name, it does nothing if the API token does not exist
+func (a *APITokenResource) DeleteAPIToken(ctx context.Context, name string) error {
+	if err := a.tokenDao.WithContext(ctx).Delete(internals.APIToken{Name: name}); err != nil {
+		return err
+	}
+	klog.Infof("API token %s is revoked", name)
+	return nil
+}
+
+// LookupToken find the principal of the API token by its digest, the expired API token is not found
+func (a *APITokenResource) LookupToken(ctx context.Context, hash string) (authentication.Principal, error) {
+	obj, err := a.tokenDao.WithContext(ctx).Get(map[string]string{"token_hash": hash})
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return authentication.Principal{}, authentication.ErrTokenNotFound
+		}
+		return authentication.Principal{}, err
+	}
+	token := obj.(internals.APIToken)
+	if token.Expired(time.Now()) {
+		return authentication.Principal{}, authentication.ErrTokenNotFound
+	}
+	return authentication.Principal{Name: token.User, Groups: token.Groups}, nil
+}
+
+func transAPITokenToResponse(item internals.APIToken) tokenv1alpha1.APIToken {
+	token := tokenv1alpha1.APIToken{
+		Name:       item.Name,
+		User:       item.User,
+		Groups:     item.Groups,
+		CreateTime: item.CreateTime,
+	}
+	if !item.ExpireTime.IsZero() {
+		expireTime := item.ExpireTime
+		token.ExpireTime = &expireTime
+	}
+	return token
+}
@@ -0,0 +1,277 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/apis"
+	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
+	"github.com/kappital/kappital/pkg/apis/internals"
+	svcv1alpha1 "github.com/kappital/kappital/pkg/apis/service/v1alpha1"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/patch"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+	"github.com/kappital/kappital/pkg/utils/uuid"
+	"github.com/kappital/kappital/pkg/utils/version"
+)
+
+// NewSettingsUpdate get the update which replaces the settings with the body if replace is true, or applies the body
+// as the patch of the content type to the current settings
+func NewSettingsUpdate(replace bool, contentType string, body []byte) UpdateSettings {
+	return func(current apis.Settings) (apis.Settings, error) {
+		var settings apis.Settings
+		var err error
+		if replace {
+			err = patch.Decode(body, &settings)
+		} else {
+			err = patch.Apply(contentType, current, body, &settings)
+		}
+		if err != nil {
+			return apis.Settings{}, errors.ErrInvalidBody.WrapErrorReasonWith(err.Error())
+		}
+		return settings, nil
+	}
+}
+
+// DeployServiceBinding deploy the service binding of the creation into the cluster if it has not been deployed, and
+// return the service binding
+func (s *ServiceBindingResource) DeployServiceBinding(ctx context.Context,
+	creation *instancev1alpha1.ServiceInstanceCreation) (*internals.ServiceBinding, error) {
+	sb, err := s.GetInternalServiceBinding(creation.Service.Spec.Description.Name, creation.ClusterID)
+	if err != nil {
+		return nil, errors.ErrServiceInstall.WrapErrorReasonWith(err.Error())
+	}
+	if sb != nil {
+		return sb, nil
+	}
+	sb, err = transCreationToServiceBinding(creation)
+	if err != nil {
+		return nil, errors.ErrServiceParam.WrapErrorReasonWith(err.Error())
+	}
+	if err = s.CreateServiceBinding(ctx, *sb); err != nil {
+		return nil, errors.ErrServiceInstall.WrapErrorReasonWith(err.Error())
+	}
+	requestid.Infof(ctx, "service %s serviceBinding create in cluster %s success.", sb.Name, sb.ClusterID)
+	return sb, nil
+}
+
+// DeployInstanceAttributes get the authorization attributes of deploying the instances of the creation, the service
+// binding will be deployed with the instances if it has not been deployed, thus its attributes are included too
+func (s *ServiceBindingResource) DeployInstanceAttributes(bindingName, clusterName string,
+	creation *instancev1alpha1.ServiceInstanceCreation) []authorization.Attributes {
+	attrs := make([]authorization.Attributes, 0, len(creation.InstanceCustomResources)+1)
+	for _, cr := range creation.InstanceCustomResources {
+		attrs = append(attrs, authorization.Attributes{Verb: authorization.VerbCreate, Cluster: clusterName,
+			Namespace: cr.Namespace, Service: bindingName})
+	}
+	if !s.IsServiceBindingDeployed(bindingName, clusterName) {
+		attrs = append(attrs, authorization.Attributes{Verb: authorization.VerbCreate, Cluster: clusterName,
+			Service: bindingName})
+	}
+	return attrs
+}
+
+// DeployInstances deploy the instances of the creation into the cluster, and the service binding is deployed first
+// if it has not been deployed
+func (i *InstanceResource) DeployInstances(ctx context.Context, bindings *ServiceBindingResource, bindingName,
+	clusterName string, creation *instancev1alpha1.ServiceInstanceCreation) ([]internals.ServiceInstance, error) {
+	if !bindings.IsServiceBindingDeployed(bindingName, clusterName) {
+		sb, err := transCreationToServiceBinding(creation)
+		if err != nil {
+			return nil, errors.ErrServiceParam.WrapErrorReasonWith(err.Error())
+		}
+		if err = bindings.CreateServiceBinding(ctx, *sb); err != nil {
+			return nil, errors.ErrServiceInstall.WrapErrorReasonWith(err.Error())
+		}
+	}
+	binding, err := bindings.GetInternalServiceBinding(bindingName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if binding == nil {
+		return nil, errors.ErrNotFound.WrapErrorReasonWith("the service binding %s is not found in cluster %s",
+			bindingName, clusterName)
+	}
+	if binding.Status == models.StatusDeleting {
+		return nil, errors.ErrServiceDeleting.WrapErrorReasonWith("the service binding %s is deleting in cluster %s",
+			bindingName, clusterName)
+	}
+	instances, err := transCreationToServiceInstance(*binding, creation)
+	if err != nil {
+		return nil, errors.ErrDataUnmarshal.WrapErrorReasonWith(err.Error())
+	}
+	if err = i.CreateInstance(ctx, instances, map[string]string{"name": bindingName,
+		"cluster_name": clusterName}); err != nil {
+		return nil, errors.ErrServiceInstanceCreate.WrapErrorReasonWith(err.Error())
+	}
+	for _, instance := range instances {
+		requestid.Infof(ctx, "create service instance %s success", instance.Name)
+	}
+	return instances, nil
+}
+
+func transCreationToServiceBinding(sbReq *instancev1alpha1.ServiceInstanceCreation) (*internals.ServiceBinding, error) {
+	if sbReq == nil {
+		return nil, fmt.Errorf("the pass in variable is empty, cannot translate to the Service Binding")
+	}
+	var err error
+	now := time.Now()
+	serviceBinding := &internals.ServiceBinding{
+		ID:          uuid.NewUUID(),
+		Name:        sbReq.Service.Spec.Description.Name,
+		Version:     sbReq.Service.Spec.Version,
+		Namespace:   apis.KappitalSystemNamespace,
+		ServiceName: sbReq.Service.Spec.Description.Name,
+		ServiceID:   string(sbReq.Service.UID),
+		ClusterID:   sbReq.ClusterID,
+		ClusterName: sbReq.ClusterID,
+		ProcessTime: now.UTC(),
+		UpdateTime:  now.UTC(),
+		Permissions: servicePermissionBuilder(sbReq.Service.Spec.Operator.ClusterRoles,
+			sbReq.Service.Spec.Operator.ClusterRoleBindings),
+		CapabilityPlugin: serviceCapabilityPluginBuilder(sbReq.Service.Spec.Manifests),
+		// the timeouts in request have the higher priority than the ones in service metadata
+		Timeouts:           sbReq.Service.Spec.Description.Timeouts.Override(sbReq.Timeouts),
+		DeletionProtection: sbReq.DeletionProtection,
+	}
+	serviceBinding.CRD, err = serviceCRDBuilder(sbReq.Service.Spec.Manifests)
+	if err != nil {
+		return nil, err
+	}
+	serviceBinding.Workload.Deployments = deploymentWorkloadBuilder(sbReq.Service.Spec.Operator.Deployments)
+
+	return serviceBinding, nil
+}
+
+func serviceCRDBuilder(csds []svcv1alpha1.CustomServiceDefinition) ([]string, error) {
+	var customResource []string
+	for _, csd := range csds {
+		crdString, err := json.Marshal(csd.Spec.CRD)
+		if err != nil {
+			klog.Errorf("json Marshal service %s crd to string failed", csd.Name)
+			return nil, err
+		}
+		customResource = append(customResource, string(crdString))
+	}
+	return customResource, nil
+}
+
+func servicePermissionBuilder(crs []rbacv1.ClusterRole, crbs []rbacv1.ClusterRoleBinding) []enginev1alpha1.Permission {
+	crMap := getClusterRoleMap(crs)
+	permissions := make([]enginev1alpha1.Permission, 0, len(crs))
+	for _, binding := range crbs {
+		cr, find := crMap[binding.RoleRef.Name]
+		if !find {
+			continue
+		}
+		permissions = append(permissions, enginev1alpha1.Permission{
+			ServiceAccountName: binding.Subjects[0].Name,
+			Rules:              cr.Rules,
+		})
+	}
+	return permissions
+}
+
+func serviceCapabilityPluginBuilder(_ []svcv1alpha1.CustomServiceDefinition) enginev1alpha1.CapabilityPlugin {
+	return enginev1alpha1.CapabilityPlugin{}
+}
+
+func deploymentWorkloadBuilder(deployments []appsv1.Deployment) []enginev1alpha1.ServiceDeploymentSpec {
+	var deploymentSpecs []enginev1alpha1.ServiceDeploymentSpec
+	for _, deployment := range deployments {
+		deploymentSpec := enginev1alpha1.ServiceDeploymentSpec{
+			Name: deployment.Name,
+			Spec: deployment.Spec,
+		}
+		deploymentSpecs = append(deploymentSpecs, deploymentSpec)
+	}
+	return deploymentSpecs
+}
+
+func getClusterRoleMap(crs []rbacv1.ClusterRole) map[string]rbacv1.ClusterRole {
+	crMap := make(map[string]rbacv1.ClusterRole, len(crs))
+	for _, cr := range crs {
+		crMap[cr.Name] = cr
+	}
+	return crMap
+}
+
+func transCreationToServiceInstance(binding internals.ServiceBinding,
+	serviceBindingReq *instancev1alpha1.ServiceInstanceCreation) ([]internals.ServiceInstance, error) {
+	now := time.Now()
+	var instances []internals.ServiceInstance
+	for _, cr := range serviceBindingReq.InstanceCustomResources {
+		crByte, err := json.Marshal(cr)
+		if err != nil {
+			return nil, err
+		}
+		instance := internals.ServiceInstance{
+			ID:                 uuid.NewUUID(),
+			Name:               cr.Name,
+			Namespace:          cr.Namespace,
+			RawResource:        string(crByte),
+			ClusterName:        binding.ClusterName,
+			CreateTime:         now,
+			Status:             models.StatusInitializing,
+			Kind:               cr.Kind,
+			APIVersion:         cr.APIVersion,
+			ServiceBindingName: binding.Name,
+			ServiceBindingID:   binding.ID,
+			ServiceName:        binding.ServiceName,
+			ServiceID:          binding.ServiceID,
+			UpdateTime:         now,
+			Timeouts:           binding.Timeouts.Override(serviceBindingReq.Timeouts),
+			DeletionProtection: serviceBindingReq.DeletionProtection,
+		}
+		plural, err := getResourceFromCRD(binding.CRD, instance.Kind, cr.GroupVersionKind().Group)
+		if err != nil {
+			return nil, err
+		}
+		instance.Resource = plural
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+func getResourceFromCRD(crds []string, kind, group string) (string, error) {
+	v1CRDs, v1beta1CRDs := version.GetCrdV1AndBeta1Slice(crds)
+	for _, v1CRD := range v1CRDs {
+		if v1CRD.Spec.Names.Kind == kind && v1CRD.Spec.Group == group {
+			return v1CRD.Spec.Names.Plural, nil
+		}
+	}
+
+	for _, v1beta1CRD := range v1beta1CRDs {
+		if v1beta1CRD.Spec.Names.Kind == kind && v1beta1CRD.Spec.Group == group {
+			return v1beta1CRD.Spec.Names.Plural, nil
+		}
+	}
+
+	return "", fmt.Errorf("can not find cr plural because the cr is not fit CRD")
+}
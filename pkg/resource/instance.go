@@ -17,6 +17,7 @@
 package resource
 
 import (
+	"context"
 	errs "errors"
 	"fmt"
 	"reflect"
@@ -35,7 +36,9 @@ import (
 	"github.com/kappital/kappital/pkg/dao/instance"
 	"github.com/kappital/kappital/pkg/models"
 	mo "github.com/kappital/kappital/pkg/models/operation"
+	"github.com/kappital/kappital/pkg/utils/errors"
 	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 	"github.com/kappital/kappital/pkg/watcher"
 )
 
@@ -51,15 +54,15 @@ func (i *InstanceResource) GetResourceType() Type {
 }
 
 // GetCommonDBObject get the data from database and return the inner structure will use in manager
-func (i *InstanceResource) GetCommonDBObject(pk string) (interface{}, error) {
-	obj, err := i.instanceStore.GetByPrimaryKey(pk)
+func (i *InstanceResource) GetCommonDBObject(ctx context.Context, pk string) (interface{}, error) {
+	obj, err := i.instanceStore.WithContext(ctx).GetByPrimaryKey(pk)
 	if err != nil {
-		klog.Errorf("failed to get instance, error: %v", err)
+		requestid.Errorf(ctx, "failed to get instance, error: %v", err)
 		return nil, err
 	}
 	ins, ok := obj.(internals.ServiceInstance)
 	if !ok {
-		klog.Errorf("failed to trans service binding, error: %v", err)
+		requestid.Errorf(ctx, "failed to trans service binding, error: %v", err)
 		return nil, err
 	}
 	return &ins, nil
@@ -114,25 +117,25 @@ func (i *InstanceResource) GetObjectListByStatusSets(status sets.String) ([]inte
 }
 
 // UpdateProcessFailed update the service instance status to Failed
-func (i *InstanceResource) UpdateProcessFailed(obj interface{}, status string, msg string) error {
+func (i *InstanceResource) UpdateProcessFailed(ctx context.Context, obj interface{}, status string, msg string) error {
 	ins, ok := obj.(*internals.ServiceInstance)
 	if !ok {
-		klog.Errorf("invalid object type, expected: internals.instance, actual: %s", reflect.TypeOf(obj).Name())
+		requestid.Errorf(ctx, "invalid object type, expected: internals.instance, actual: %s", reflect.TypeOf(obj).Name())
 		return fmt.Errorf("invalid object type,expected:models.instance, actual: %s", reflect.TypeOf(obj).Name())
 	}
 
-	return i.instanceStore.UpdateStatusMsg(*ins, status, msg)
+	return i.instanceStore.WithContext(ctx).UpdateStatusMsg(ins, status, msg)
 }
 
 // UpdateObjProcessTime update the process time for the service instance (using for synchronizing)
-func (i *InstanceResource) UpdateObjProcessTime(obj interface{}, processTime time.Time) error {
+func (i *InstanceResource) UpdateObjProcessTime(ctx context.Context, obj interface{}, processTime time.Time) error {
 	ins, ok := obj.(*internals.ServiceInstance)
 	if !ok {
 		return fmt.Errorf("invalid object type, expected: internals.instance, actual: %s", reflect.TypeOf(obj).Name())
 	}
 	ins.ProcessTime = processTime
 
-	return i.instanceStore.Update(*ins, "process_time")
+	return i.instanceStore.WithContext(ctx).Update(ins, "process_time")
 }
 
 // GetObjUpdateTime get the service instance update timestamp
@@ -145,49 +148,73 @@ func (i *InstanceResource) GetObjUpdateTime(obj interface{}) time.Time {
 	return ins.UpdateTime
 }
 
+// GetObjectTimeouts get the lifecycle timeouts of the service instance
+func (i *InstanceResource) GetObjectTimeouts(obj interface{}) apis.Timeouts {
+	ins, ok := obj.(*internals.ServiceInstance)
+	if !ok {
+		klog.Errorf("invalid object type, expected: internals.instance, actual: %s", reflect.TypeOf(obj).Name())
+		return apis.Timeouts{}
+	}
+	return ins.Timeouts
+}
+
+// GetObjectInitiator get the initiator of the request which causes the processing of the service instance
+func (i *InstanceResource) GetObjectInitiator(obj interface{}) apis.Initiator {
+	ins, ok := obj.(*internals.ServiceInstance)
+	if !ok {
+		klog.Errorf("invalid object type, expected: internals.instance, actual: %s", reflect.TypeOf(obj).Name())
+		return apis.Initiator{}
+	}
+	return ins.Initiator
+}
+
 // CreateInstance into database, and add event to the synchronizing list
 // which for deploying the service instance into cluster
-func (i *InstanceResource) CreateInstance(instances []internals.ServiceInstance, param map[string]string) error {
+func (i *InstanceResource) CreateInstance(ctx context.Context, instances []internals.ServiceInstance,
+	param map[string]string) error {
+	instanceStore := i.instanceStore.WithContext(ctx)
 	var needAddInstances []internals.ServiceInstance
+	initiator := initiatorFrom(ctx)
 	for _, instanceTemp := range instances {
-		indb, err := i.instanceStore.Get(map[string]string{
+		indb, err := instanceStore.Get(map[string]string{
 			"name":       instanceTemp.Name,
 			"namespace":  instanceTemp.Namespace,
 			"cluster_id": instanceTemp.ClusterID,
 		})
 		if err != nil {
 			if errs.Is(err, orm.ErrNoRows) {
+				instanceTemp.Initiator = initiator
 				needAddInstances = append(needAddInstances, instanceTemp)
 				continue
 			}
 			return err
 		}
-		klog.Infof("service instance %s has been created", indb.(internals.ServiceInstance).Name)
+		requestid.Infof(ctx, "service instance %s has been created", indb.(internals.ServiceInstance).Name)
 	}
 
 	if len(needAddInstances) == 0 {
-		klog.Infof("all instance has created, no need to create")
+		requestid.Infof(ctx, "all instance has created, no need to create")
 		return nil
 	}
 
-	if err := i.instanceStore.Create(needAddInstances, param); err != nil {
-		klog.Infof("create instance failed, error: %s", err)
+	if err := instanceStore.Create(needAddInstances, param); err != nil {
+		requestid.Infof(ctx, "create instance failed, error: %s", err)
 		return err
 	}
 
 	for _, needCreateInstance := range needAddInstances {
-		if err := watcher.AddEvent(needCreateInstance, watcher.OPCreate, apis.InstanceProcessor); err != nil {
-			klog.Errorf("[ADD EVENT] add instance %s created event failed, err: %s", needCreateInstance.Name, err)
+		if err := watcher.AddEvent(ctx, needCreateInstance, watcher.OPCreate, apis.InstanceProcessor); err != nil {
+			requestid.Errorf(ctx, "[ADD EVENT] add instance %s created event failed, err: %s", needCreateInstance.Name, err)
 			return err
 		}
-		klog.Infof("[ADD EVENT] add instance %s created event success", needCreateInstance.Name)
+		requestid.Infof(ctx, "[ADD EVENT] add instance %s created event success", needCreateInstance.Name)
 	}
 
 	return nil
 }
 
 // UpdateInstallCondition of the instance
-func (i *InstanceResource) UpdateInstallCondition(ins *internals.ServiceInstance,
+func (i *InstanceResource) UpdateInstallCondition(ctx context.Context, ins *internals.ServiceInstance,
 	conType instance.InstallConditionType, status instance.ConditionStatus, msg string) error {
 	if ins.InstallState.SubPhase == nil || len(ins.InstallState.SubPhase) == 0 {
 		// init the parameters if not init
@@ -228,9 +255,9 @@ func (i *InstanceResource) UpdateInstallCondition(ins *internals.ServiceInstance
 		}
 	}
 
-	err := i.instanceStore.Update(*ins, "install_state", "status")
+	err := i.instanceStore.WithContext(ctx).Update(ins, "install_state", "status")
 	if err != nil {
-		klog.Errorf("failed to update instance %s in cluster %s to db, error: %v", ins.Name, ins.ClusterID, err)
+		requestid.Errorf(ctx, "failed to update instance %s in cluster %s to db, error: %v", ins.Name, ins.ClusterID, err)
 		return err
 	}
 
@@ -262,7 +289,7 @@ func needUpdateMsg(old, new string) bool {
 }
 
 // ValidationInstance check the Instance data in memory is valid or not
-func ValidationInstance(instanceCreation *instancev1alpha1.ServiceInstanceCreation) error {
+func ValidationInstance(ctx context.Context, instanceCreation *instancev1alpha1.ServiceInstanceCreation) error {
 	// check cr namespace is existed
 	for _, cr := range instanceCreation.InstanceCustomResources {
 		if cr.Name == "" {
@@ -270,7 +297,7 @@ func ValidationInstance(instanceCreation *instancev1alpha1.ServiceInstanceCreati
 				instanceCreation.InstanceName)
 		}
 		// check namespace is existed
-		isExist, err := co.GetClusterOperation().IsNamespaceExist(cr.Namespace)
+		isExist, err := co.GetClusterOperation().IsNamespaceExist(ctx, cr.Namespace)
 		if err != nil {
 			return fmt.Errorf("check cr namespace %s failed, err: %s", cr.Namespace, err)
 		}
@@ -282,15 +309,16 @@ func ValidationInstance(instanceCreation *instancev1alpha1.ServiceInstanceCreati
 }
 
 // GetInstances get the instance list from database, and filter it by service binding name, cluster name, and namespace
-func (i *InstanceResource) GetInstances(sbName, clusterName, ns string) ([]models.InstanceModel, error) {
+func (i *InstanceResource) GetInstances(ctx context.Context, sbName, clusterName,
+	ns string) ([]models.InstanceModel, error) {
 	// get ServiceBindingModel from the database
-	sb, err := i.binding.GetDetail(map[string]string{"name": sbName, "cluster_name": clusterName})
+	sb, err := i.binding.WithContext(ctx).GetDetail(map[string]string{"name": sbName, "cluster_name": clusterName})
 	if err != nil {
 		return nil, err
 	}
 	// get the related resources' instances from the ServiceBindingModel,
 	// and check does the instance is existed in cluster, if not exist, update the status in database
-	instances, err := i.getAndCheckInstanceInCluster(sb.(models.ServiceBindingModel).Resources, ns)
+	instances, err := i.getAndCheckInstanceInCluster(ctx, sb.(models.ServiceBindingModel).Resources, ns)
 	if err != nil {
 		return nil, err
 	}
@@ -298,8 +326,9 @@ func (i *InstanceResource) GetInstances(sbName, clusterName, ns string) ([]model
 }
 
 // GetInstance get the instance from database, and filter it by service binding name, cluster name, and namespace
-func (i *InstanceResource) GetInstance(sbName, clusterName, ns, instanceName string) (models.InstanceModel, error) {
-	instances, err := i.GetInstances(sbName, clusterName, ns)
+func (i *InstanceResource) GetInstance(ctx context.Context, sbName, clusterName, ns,
+	instanceName string) (models.InstanceModel, error) {
+	instances, err := i.GetInstances(ctx, sbName, clusterName, ns)
 	if err != nil {
 		return models.InstanceModel{}, err
 	}
@@ -308,10 +337,11 @@ func (i *InstanceResource) GetInstance(sbName, clusterName, ns, instanceName str
 			return item, nil
 		}
 	}
-	return models.InstanceModel{}, fmt.Errorf("the instance [%s] is not found in cluster or database", instanceName)
+	return models.InstanceModel{}, fmt.Errorf("the instance [%s] is not found in cluster or database, err: %w",
+		instanceName, orm.ErrNoRows)
 }
 
-func (i *InstanceResource) getAndCheckInstanceInCluster(resources []*models.ResourceModel,
+func (i *InstanceResource) getAndCheckInstanceInCluster(ctx context.Context, resources []*models.ResourceModel,
 	ns string) ([]models.InstanceModel, error) {
 	var instances []models.InstanceModel
 	for _, item := range resources {
@@ -323,17 +353,17 @@ func (i *InstanceResource) getAndCheckInstanceInCluster(resources []*models.Reso
 			if ins.Namespace != ns {
 				continue
 			}
-			status, err := i.checkInstanceAndUpdate(gv, item.Resource, ins.Name, ns)
+			status, err := i.checkInstanceAndUpdate(ctx, gv, item.Resource, ins.Name, ns)
 			if err != nil {
 				return nil, err
 			}
-			if ins.Status != string(instancev1alpha1.PendingPhase) {
+			if ins.Status != string(instancev1alpha1.PendingPhase) && ins.Status != status {
+				io := mo.InstanceOperation{}.WithContext(ctx)
+				if err = io.UpdateStatus(ins.ID, ins.Status, status); err != nil {
+					return nil, err
+				}
 				ins.Status = status
 			}
-			io := mo.InstanceOperation{}
-			if err = io.Update(*ins); err != nil {
-				return nil, err
-			}
 			instances = append(instances, *ins)
 		}
 	}
@@ -341,8 +371,8 @@ func (i *InstanceResource) getAndCheckInstanceInCluster(resources []*models.Reso
 	return instances, nil
 }
 
-func (i *InstanceResource) checkInstanceAndUpdate(gv schema.GroupVersion, plural, name, ns string) (string, error) {
-	find, err := co.GetClusterOperation().DoesCustomResourceExist(gv, plural, name, ns)
+func (i *InstanceResource) checkInstanceAndUpdate(ctx context.Context, gv schema.GroupVersion, plural, name, ns string) (string, error) {
+	find, err := co.GetClusterOperation().DoesCustomResourceExist(ctx, gv, plural, name, ns)
 	if err != nil {
 		return "", err
 	}
@@ -352,30 +382,85 @@ func (i *InstanceResource) checkInstanceAndUpdate(gv schema.GroupVersion, plural
 	return string(instancev1alpha1.SucceededPhase), nil
 }
 
-// DeleteInstance in database and cluster
-func (i *InstanceResource) DeleteInstance(clusterName, instanceName, namespace string) error {
-	tmp, err := i.instanceStore.Get(map[string]string{"name": instanceName, "namespace": namespace,
-		"cluster_name": clusterName})
+// getBindingInstance get the instance which belongs to the service binding, the instance of another service binding is
+// not found even if its name is the same, thus the permission on one service cannot reach the instances of the others
+func (i *InstanceResource) getBindingInstance(ctx context.Context, sbName, clusterName, instanceName,
+	namespace string) (internals.ServiceInstance, error) {
+	sb, err := i.binding.WithContext(ctx).Get(map[string]string{"name": sbName, "cluster_name": clusterName})
 	if err != nil {
-		return err
+		return internals.ServiceInstance{}, err
+	}
+	tmp, err := i.instanceStore.WithContext(ctx).Get(map[string]string{"name": instanceName, "namespace": namespace,
+		"cluster_name": clusterName, "service_binding_id": sb.(models.ServiceBindingModel).ID})
+	if err != nil {
+		return internals.ServiceInstance{}, err
 	}
-
 	item, ok := tmp.(internals.ServiceInstance)
 	if !ok {
-		klog.Errorf("obj type is not ServiceInstance, actual: %s", reflect.TypeOf(item).Name())
-		return fmt.Errorf("delete instance %s failed, because get data from db failed", instanceName)
+		requestid.Errorf(ctx, "obj type is not ServiceInstance, actual: %s", reflect.TypeOf(tmp).Name())
+		return internals.ServiceInstance{}, fmt.Errorf("get instance %s failed, because get data from db failed",
+			instanceName)
 	}
+	return item, nil
+}
 
+// UpdateInstanceSettings update the settings of the instance by the update function, and return the new settings and
+// resource version. If the resourceVersion is not nil, it must be the same as the one in database, otherwise the
+// ErrResourceVersionConflict will be returned.
+func (i *InstanceResource) UpdateInstanceSettings(ctx context.Context, sbName, clusterName, instanceName,
+	namespace string, resourceVersion *int64, update UpdateSettings) (apis.Settings, int64, error) {
+	instanceStore := i.instanceStore.WithContext(ctx)
+	item, err := i.getBindingInstance(ctx, sbName, clusterName, instanceName, namespace)
+	if err != nil {
+		return apis.Settings{}, 0, err
+	}
+	if resourceVersion != nil && *resourceVersion != item.ResourceVersion {
+		return apis.Settings{}, 0, models.ErrResourceVersionConflict
+	}
+	settings, err := update(apis.Settings{Timeouts: item.Timeouts, DeletionProtection: item.DeletionProtection})
+	if err != nil {
+		return apis.Settings{}, 0, err
+	}
+	item.Timeouts, item.DeletionProtection = settings.Timeouts, settings.DeletionProtection
+	if err = instanceStore.Update(&item, "timeouts", "deletion_protection"); err != nil {
+		return apis.Settings{}, 0, err
+	}
+	requestid.Infof(ctx, "the settings of instance %s in cluster %s have been updated", instanceName, clusterName)
+	return settings, item.ResourceVersion, nil
+}
+
+// DeleteInstance in database and cluster. If the opts.ResourceVersion is not nil, it must be the same as the one in
+// database, otherwise the ErrResourceVersionConflict will be returned. With opts.Force, the processor of the leader
+// replica removes the record once the cluster accepts the deletion of the custom resource instead of waiting for it to
+// be removed. The instance whose deletion protection is enabled cannot be deleted even by force.
+func (i *InstanceResource) DeleteInstance(ctx context.Context, sbName, clusterName, instanceName, namespace string,
+	opts DeleteOptions) error {
+	instanceStore := i.instanceStore.WithContext(ctx)
+	item, err := i.getBindingInstance(ctx, sbName, clusterName, instanceName, namespace)
+	if err != nil {
+		return err
+	}
+	if opts.ResourceVersion != nil && *opts.ResourceVersion != item.ResourceVersion {
+		return models.ErrResourceVersionConflict
+	}
+	if item.DeletionProtection {
+		return errors.ErrDeletionProtected.WrapErrorReasonWith("the instance %s/%s is protected from deletion, "+
+			"disable its deletionProtection first", namespace, instanceName)
+	}
 	item.Status = models.StatusDeleting
 	item.ProcessTime = time.Time{}
 	item.UpdateTime = time.Now().UTC()
-	if err = i.instanceStore.Update(item, "status", "process_time", "update_timestamp"); err != nil {
-		klog.Errorf("failed to update instance[%s] in cluster[%s] into db, error: %s", instanceName, clusterName, err)
+	item.Initiator = initiatorFrom(ctx)
+	cols := append([]string{"status", "process_time", "update_timestamp", "initiator"}, opts.apply(&item.Timeouts)...)
+	cols = append(cols, opts.applyForce(&item.ForceDelete, &item.RemoveFinalizers)...)
+	if err = instanceStore.Update(&item, cols...); err != nil {
+		requestid.Errorf(ctx, "failed to update instance[%s] in cluster[%s] into db, error: %s", instanceName,
+			clusterName, err)
 		return err
 	}
 
-	if err = watcher.AddEvent(item, watcher.OPDelete, apis.InstanceProcessor); err != nil {
-		klog.Infof("[ADD EVENT]service binding %s created", item.Name)
+	if err = watcher.AddEvent(ctx, item, watcher.OPDelete, apis.InstanceProcessor); err != nil {
+		requestid.Infof(ctx, "[ADD EVENT]service binding %s created", item.Name)
 		return err
 	}
 	return nil
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kappital/kappital/pkg/apis/internals"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/dao/instance"
+	"github.com/kappital/kappital/pkg/dao/servicebinding"
+	"github.com/kappital/kappital/pkg/models"
+	mo "github.com/kappital/kappital/pkg/models/operation"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/watcher"
+)
+
+func TestInstanceResource_DeleteInstance(t *testing.T) {
+	tests := []struct {
+		name          string
+		protected     bool
+		opts          DeleteOptions
+		wantProtected bool
+		wantStatus    []string
+	}{
+		{name: "Test DeleteInstance (not protected)", wantStatus: []string{models.StatusDeleting}},
+		{name: "Test DeleteInstance (protected)", protected: true, wantProtected: true},
+		{name: "Test DeleteInstance (protected with force)", protected: true, opts: DeleteOptions{Force: true},
+			wantProtected: true},
+		{name: "Test DeleteInstance (force)", opts: DeleteOptions{Force: true, RemoveFinalizers: true},
+			wantStatus: []string{models.StatusDeleting}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := internals.ServiceInstance{ID: "i1", Name: "orders", Namespace: "prod",
+				Status: models.StatusSuccess, DeletionProtection: tt.protected}
+			var status []string
+			p := gomonkey.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Get",
+				func(instance.Instance, map[string]string) (interface{}, error) { return item, nil })
+			defer p.Reset()
+			p.ApplyMethod(reflect.TypeOf(mo.ServiceBindingOperation{}), "Get",
+				func(mo.ServiceBindingOperation, map[string]string) (interface{}, error) {
+					return models.ServiceBindingModel{ID: "sb1"}, nil
+				})
+			p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Update",
+				func(_ instance.Instance, obj interface{}, _ ...string) error {
+					ins := obj.(*internals.ServiceInstance)
+					if ins.ForceDelete != tt.opts.Force || ins.RemoveFinalizers != tt.opts.RemoveFinalizers {
+						t.Errorf("DeleteInstance() marked the instance by force %v, want %v", ins.ForceDelete,
+							tt.opts.Force)
+					}
+					status = append(status, ins.Status)
+					return nil
+				})
+			p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Delete", func(instance.Instance, interface{}) error {
+				t.Errorf("DeleteInstance() removed the record, want it removed by the processor")
+				return nil
+			})
+			p.ApplyFunc(watcher.AddEvent, func(context.Context, interface{}, string, string) error { return nil })
+
+			err := (&InstanceResource{}).DeleteInstance(context.Background(), "shop", "default", "orders", "prod",
+				tt.opts)
+			if tt.wantProtected {
+				if kappErr, ok := err.(errors.KappError); !ok || !kappErr.TypeEqual(errors.ErrDeletionProtected) {
+					t.Errorf("DeleteInstance() error = %v, want ErrDeletionProtected", err)
+				}
+			} else if err != nil {
+				t.Errorf("DeleteInstance() error = %v", err)
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("DeleteInstance() updated the status %v, want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// existOperation report whether the custom resources exist in the cluster
+type existOperation struct {
+	co.ClusterOperation
+	exist bool
+}
+
+func (e existOperation) DoesCustomResourceExist(context.Context, schema.GroupVersion, string, string,
+	string) (bool, error) {
+	return e.exist, nil
+}
+
+func TestInstanceResource_getAndCheckInstanceInCluster(t *testing.T) {
+	succeeded, failed := string(instancev1alpha1.SucceededPhase), string(instancev1alpha1.FailedPhase)
+	tests := []struct {
+		name       string
+		status     string
+		exist      bool
+		wantStatus string
+		wantWrites []string
+	}{
+		{name: "Test getAndCheckInstanceInCluster (unchanged)", status: succeeded, exist: true, wantStatus: succeeded},
+		{name: "Test getAndCheckInstanceInCluster (gone from cluster)", status: succeeded, wantStatus: failed,
+			wantWrites: []string{succeeded + "->" + failed}},
+		{name: "Test getAndCheckInstanceInCluster (pending)", status: string(instancev1alpha1.PendingPhase),
+			wantStatus: string(instancev1alpha1.PendingPhase)},
+	}
+	previous := co.GetClusterOperation()
+	defer co.SetClusterOperation(previous)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			co.SetClusterOperation(existOperation{exist: tt.exist})
+			var writes []string
+			p := gomonkey.ApplyMethod(reflect.TypeOf(mo.InstanceOperation{}), "UpdateStatus",
+				func(_ mo.InstanceOperation, _, oldStatus, status string) error {
+					writes = append(writes, oldStatus+"->"+status)
+					return nil
+				})
+			defer p.Reset()
+			p.ApplyMethod(reflect.TypeOf(mo.InstanceOperation{}), "Update",
+				func(mo.InstanceOperation, interface{}, ...string) error {
+					t.Errorf("getAndCheckInstanceInCluster() updated the whole record")
+					return nil
+				})
+			resources := []*models.ResourceModel{{Group: "cache.example.com", APIVersion: "cache.example.com/v1",
+				Resource: "redis", Instances: []*models.InstanceModel{
+					{ID: "i1", Name: "cache", Namespace: "prod", Status: tt.status, ResourceVersion: 3}}}}
+
+			got, err := (&InstanceResource{}).getAndCheckInstanceInCluster(context.Background(), resources, "prod")
+			if err != nil {
+				t.Fatalf("getAndCheckInstanceInCluster() error = %v", err)
+			}
+			if len(got) != 1 || got[0].Status != tt.wantStatus || got[0].ResourceVersion != 3 {
+				t.Errorf("getAndCheckInstanceInCluster() = %+v, want status %s", got, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(writes, tt.wantWrites) {
+				t.Errorf("getAndCheckInstanceInCluster() wrote %v, want %v", writes, tt.wantWrites)
+			}
+		})
+	}
+}
+
+func TestInstanceResource_DeployInstances(t *testing.T) {
+	p := gomonkey.ApplyMethod(reflect.TypeOf(mo.ServiceBindingOperation{}), "IsExist",
+		func(mo.ServiceBindingOperation, map[string]string) bool { return true })
+	defer p.Reset()
+	p.ApplyMethod(reflect.TypeOf(servicebinding.ServiceBinding{}), "Get",
+		func(servicebinding.ServiceBinding, map[string]string) (interface{}, error) {
+			return internals.ServiceBinding{ID: "sb1", Name: "shop", Status: models.StatusDeleting}, nil
+		})
+	p.ApplyMethod(reflect.TypeOf(&InstanceResource{}), "CreateInstance",
+		func(*InstanceResource, context.Context, []internals.ServiceInstance, map[string]string) error {
+			t.Errorf("DeployInstances() created the instances in the deleting service binding")
+			return nil
+		})
+
+	_, err := (&InstanceResource{}).DeployInstances(context.Background(), &ServiceBindingResource{}, "shop",
+		"default", &instancev1alpha1.ServiceInstanceCreation{})
+	if kappErr, ok := err.(errors.KappError); !ok || !kappErr.TypeEqual(errors.ErrServiceDeleting) {
+		t.Errorf("DeployInstances() error = %v, want ErrServiceDeleting", err)
+	}
+}
@@ -17,9 +17,15 @@
 package resource
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 // Type of the service instance and binding resource
@@ -35,14 +41,73 @@ const (
 // IResource of the interface to use the database and/or cluster
 type IResource interface {
 	GetResourceType() Type
-	GetCommonDBObject(key string) (interface{}, error)
+	GetCommonDBObject(ctx context.Context, key string) (interface{}, error)
 	GetObjectProcessTime(obj interface{}) time.Time
 	GetObjectStatus(obj interface{}) string
 	GetObjectID(obj interface{}) string
 	GetObjectListByStatusSets(status sets.String) ([]interface{}, error)
-	UpdateProcessFailed(obj interface{}, status string, msg string) error
-	UpdateObjProcessTime(obj interface{}, processTime time.Time) error
+	UpdateProcessFailed(ctx context.Context, obj interface{}, status string, msg string) error
+	UpdateObjProcessTime(ctx context.Context, obj interface{}, processTime time.Time) error
 	GetObjUpdateTime(obj interface{}) time.Time
+	GetObjectTimeouts(obj interface{}) apis.Timeouts
+	GetObjectInitiator(obj interface{}) apis.Initiator
+}
+
+// DeleteOptions of deleting the service binding or the service instance
+type DeleteOptions struct {
+	// ResourceVersion the expected resource version of the record, nil means no precondition
+	ResourceVersion *int64
+	// Timeout overrides the delete timeout of the record if it is not empty
+	Timeout string
+	// Cascade deletes the instances of the service binding with it, otherwise the service binding which has
+	// instances cannot be deleted
+	Cascade bool
+	// Force makes the processors delete the custom resources without waiting for them to be removed from the
+	// cluster, and the records are removed once the cluster accepts the deletions
+	Force bool
+	// RemoveFinalizers clears the finalizers of the custom resources which are deleted by force
+	RemoveFinalizers bool
+}
+
+// Validate the options, the finalizers can only be removed when the records are deleted by force
+func (o DeleteOptions) Validate() error {
+	if o.RemoveFinalizers && !o.Force {
+		return fmt.Errorf("the finalizers can only be removed when deleting by force")
+	}
+	return nil
+}
+
+// apply the options to the timeouts of the record, and return the columns which need to be updated
+func (o DeleteOptions) apply(timeouts *apis.Timeouts) []string {
+	if len(o.Timeout) == 0 {
+		return nil
+	}
+	timeouts.DeleteTimeout = o.Timeout
+	return []string{"timeouts"}
+}
+
+// applyForce mark the record to be deleted by force in the processor of the leader replica, and return the columns
+// which need to be updated
+func (o DeleteOptions) applyForce(force, removeFinalizers *bool) []string {
+	if !o.Force {
+		return nil
+	}
+	*force, *removeFinalizers = true, o.RemoveFinalizers
+	return []string{"force_delete", "remove_finalizers"}
+}
+
+// UpdateSettings get the new settings of the service binding or the service instance from the current ones
+type UpdateSettings func(current apis.Settings) (apis.Settings, error)
+
+// initiatorFrom get the principal and the correlation ID of the request from the context, the processors audit the
+// system actions which are caused by the request with them
+func initiatorFrom(ctx context.Context) apis.Initiator {
+	var initiator apis.Initiator
+	if principal, ok := authentication.PrincipalFrom(ctx); ok {
+		initiator.Principal, initiator.AuthMethod = principal.Name, principal.Method
+	}
+	initiator.RequestID = requestid.FromContext(ctx)
+	return initiator
 }
 
 var insResource InstanceResource
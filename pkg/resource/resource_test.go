@@ -17,8 +17,13 @@
 package resource
 
 import (
+	"context"
 	"reflect"
 	"testing"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 func TestGetResourceByType(t *testing.T) {
@@ -50,3 +55,37 @@ func TestGetResourceByType(t *testing.T) {
 		})
 	}
 }
+
+func Test_initiatorFrom(t *testing.T) {
+	ctx := authentication.WithPrincipal(context.Background(),
+		authentication.Principal{Name: "alice", Method: "token"})
+	ctx = requestid.NewContext(ctx, "0af7651916cd43dd8448eb211c80319c")
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want apis.Initiator
+	}{
+		{name: "Test initiatorFrom (background)", ctx: context.Background()},
+		{
+			name: "Test initiatorFrom (request)",
+			ctx:  ctx,
+			want: apis.Initiator{Principal: "alice", AuthMethod: "token", RequestID: "0af7651916cd43dd8448eb211c80319c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := initiatorFrom(tt.ctx); got != tt.want {
+				t.Errorf("initiatorFrom() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteOptions_Validate(t *testing.T) {
+	if err := (DeleteOptions{Force: true, RemoveFinalizers: true}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (DeleteOptions{RemoveFinalizers: true}).Validate(); err == nil {
+		t.Errorf("Validate() error = nil, want the finalizers are removed without force")
+	}
+}
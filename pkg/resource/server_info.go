@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kappital/kappital/pkg/apis"
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	mo "github.com/kappital/kappital/pkg/models/operation"
+	co "github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/version"
+)
+
+// clusterVersionTimeout the longest duration of getting the version of each cluster
+const clusterVersionTimeout = 5 * time.Second
+
+var (
+	featuresMu sync.RWMutex
+	features   infov1alpha1.Features
+)
+
+// SetFeatures set the optional features of the manager, they are decided by the options when it is started
+func SetFeatures(f infov1alpha1.Features) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	features = f
+}
+
+// ServerInfoResource get the version and the capabilities of the manager
+type ServerInfoResource struct{}
+
+// GetServerInfo get the version, the features, the supported package formats of the manager, and the versions of
+// the default cluster and the clusters which the service bindings or instances are registered in
+func (s *ServerInfoResource) GetServerInfo(ctx context.Context) (infov1alpha1.ServerInfo, error) {
+	names, err := mo.ListClusterNames(ctx)
+	if err != nil {
+		return infov1alpha1.ServerInfo{}, fmt.Errorf("cannot list the registered clusters, err: %v", err)
+	}
+	clusters := map[string]struct{}{apis.DefaultCluster: {}}
+	for _, name := range names {
+		clusters[name] = struct{}{}
+	}
+	infos := make([]infov1alpha1.ClusterInfo, 0, len(clusters))
+	for name := range clusters {
+		infos = append(infos, clusterInfo(ctx, name))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+	return infov1alpha1.ServerInfo{
+		Version:        version.Get(version.ServiceNameManager),
+		Features:       features,
+		Clusters:       infos,
+		PackageFormats: apis.SupportedServiceTypes(),
+	}, nil
+}
+
+// clusterInfo get the version of the cluster, the error is reported in the info instead of failing the request
+func clusterInfo(ctx context.Context, name string) infov1alpha1.ClusterInfo {
+	ctx, cancel := context.WithTimeout(ctx, clusterVersionTimeout)
+	defer cancel()
+	v, err := co.GetClusterOperation().ServerVersion(ctx, name)
+	if err != nil {
+		return infov1alpha1.ClusterInfo{Name: name, Error: err.Error()}
+	}
+	return infov1alpha1.ClusterInfo{Name: name, Version: v}
+}
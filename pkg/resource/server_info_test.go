@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	mo "github.com/kappital/kappital/pkg/models/operation"
+	co "github.com/kappital/kappital/pkg/utils/operations"
+)
+
+// versionOperation reply the versions of the clusters, the unknown clusters are unreachable
+type versionOperation struct {
+	co.ClusterOperation
+	versions map[string]string
+}
+
+func (v versionOperation) ServerVersion(_ context.Context, cluster string) (string, error) {
+	if version, ok := v.versions[cluster]; ok {
+		return version, nil
+	}
+	return "", fmt.Errorf("the cluster %s is not connected by the manager", cluster)
+}
+
+func TestServerInfoResource_GetServerInfo(t *testing.T) {
+	previous := co.GetClusterOperation()
+	co.SetClusterOperation(versionOperation{versions: map[string]string{"default": "v1.23.0"}})
+	defer co.SetClusterOperation(previous)
+	SetFeatures(infov1alpha1.Features{AuthenticationMethods: []string{"certificate"}, DatabaseDriver: "sqlite"})
+	defer SetFeatures(infov1alpha1.Features{})
+
+	tests := []struct {
+		name         string
+		clusterNames []string
+		listErr      error
+		want         []infov1alpha1.ClusterInfo
+		wantErr      bool
+	}{
+		{name: "Test GetServerInfo (cannot list the clusters)", listErr: fmt.Errorf("database is closed"),
+			wantErr: true},
+		{name: "Test GetServerInfo (default cluster)", want: []infov1alpha1.ClusterInfo{
+			{Name: "default", Version: "v1.23.0"}}},
+		{name: "Test GetServerInfo (unreachable cluster)", clusterNames: []string{"remote", "default"},
+			want: []infov1alpha1.ClusterInfo{{Name: "default", Version: "v1.23.0"},
+				{Name: "remote", Error: "the cluster remote is not connected by the manager"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := gomonkey.ApplyFunc(mo.ListClusterNames, func(context.Context) ([]string, error) {
+				return tt.clusterNames, tt.listErr
+			})
+			defer p.Reset()
+			got, err := (&ServerInfoResource{}).GetServerInfo(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetServerInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Clusters, tt.want) {
+				t.Errorf("GetServerInfo() clusters = %+v, want %+v", got.Clusters, tt.want)
+			}
+			if got.Features.DatabaseDriver != "sqlite" || len(got.PackageFormats) == 0 ||
+				len(got.Version.GitVersion) == 0 {
+				t.Errorf("GetServerInfo() = %+v", got)
+			}
+		})
+	}
+}
@@ -17,9 +17,13 @@
 package resource
 
 import (
-	"errors"
+	"context"
+	stderrors "errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/beego/beego/v2/client/orm"
@@ -35,7 +39,9 @@ import (
 	"github.com/kappital/kappital/pkg/dao/servicebinding"
 	"github.com/kappital/kappital/pkg/models"
 	mo "github.com/kappital/kappital/pkg/models/operation"
+	"github.com/kappital/kappital/pkg/utils/errors"
 	"github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 	"github.com/kappital/kappital/pkg/watcher"
 )
 
@@ -44,6 +50,16 @@ type ServiceBindingResource struct {
 	bindingDao  servicebinding.ServiceBinding
 	instanceDao instance.Instance
 	mo.ServiceBindingOperation
+	// newTransaction create the transaction of the database, the default one is used if it is nil
+	newTransaction func() models.Transaction
+}
+
+// transaction create the transaction of the database to update the records together
+func (s *ServiceBindingResource) transaction() models.Transaction {
+	if s.newTransaction != nil {
+		return s.newTransaction()
+	}
+	return models.NewTransaction(models.GetNewOrm())
 }
 
 // DeleteServiceInCluster delete the service in cluster
@@ -57,15 +73,15 @@ func (s *ServiceBindingResource) GetResourceType() Type {
 }
 
 // GetCommonDBObject get the service binding information from the database
-func (s *ServiceBindingResource) GetCommonDBObject(pk string) (interface{}, error) {
-	obj, err := s.bindingDao.GetByPrimaryKey(pk)
+func (s *ServiceBindingResource) GetCommonDBObject(ctx context.Context, pk string) (interface{}, error) {
+	obj, err := s.bindingDao.WithContext(ctx).GetByPrimaryKey(pk)
 	if err != nil {
-		klog.Errorf("failed to get service binding, error: %v", err)
+		requestid.Errorf(ctx, "failed to get service binding, error: %v", err)
 		return nil, err
 	}
 	ins, ok := obj.(internals.ServiceBinding)
 	if !ok {
-		klog.Errorf("failed to trans service binding, error: %v", err)
+		requestid.Errorf(ctx, "failed to trans service binding, error: %v", err)
 		return nil, err
 	}
 
@@ -121,28 +137,30 @@ func (s *ServiceBindingResource) GetObjectListByStatusSets(status sets.String) (
 }
 
 // UpdateProcessFailed update the object to failed status
-func (s *ServiceBindingResource) UpdateProcessFailed(obj interface{}, status string, msg string) error {
+func (s *ServiceBindingResource) UpdateProcessFailed(ctx context.Context, obj interface{}, status string, msg string) error {
 	binding, ok := obj.(*internals.ServiceBinding)
 	if !ok {
-		klog.Errorf("invalid object type, expected: internals.servicebinding, actual :%s", reflect.TypeOf(obj).Name())
+		requestid.Errorf(ctx, "invalid object type, expected: internals.servicebinding, actual :%s",
+			reflect.TypeOf(obj).Name())
 		return fmt.Errorf("invalid object type, expected: internals.servicebinding, actual :%s",
 			reflect.TypeOf(obj).Name())
 	}
 
-	return s.bindingDao.UpdateStatusMsg(*binding, status, msg)
+	return s.bindingDao.WithContext(ctx).UpdateStatusMsg(binding, status, msg)
 }
 
 // UpdateObjProcessTime update the process timestamp
-func (s *ServiceBindingResource) UpdateObjProcessTime(obj interface{}, processTime time.Time) error {
+func (s *ServiceBindingResource) UpdateObjProcessTime(ctx context.Context, obj interface{}, processTime time.Time) error {
 	binding, ok := obj.(*internals.ServiceBinding)
 	if !ok {
-		klog.Errorf("invalid object type, expected: internals.servicebinding, actual :%s", reflect.TypeOf(obj).Name())
+		requestid.Errorf(ctx, "invalid object type, expected: internals.servicebinding, actual :%s",
+			reflect.TypeOf(obj).Name())
 		return fmt.Errorf("invalid object type, expected: internals.servicebinding, actual :%s",
 			reflect.TypeOf(obj).Name())
 	}
 
 	binding.ProcessTime = processTime
-	return s.bindingDao.Update(*binding)
+	return s.bindingDao.WithContext(ctx).Update(binding)
 }
 
 // GetObjUpdateTime get the object update timestamp
@@ -156,32 +174,54 @@ func (s *ServiceBindingResource) GetObjUpdateTime(obj interface{}) time.Time {
 	return binding.UpdateTime
 }
 
+// GetObjectTimeouts get the lifecycle timeouts of the service binding
+func (s *ServiceBindingResource) GetObjectTimeouts(obj interface{}) apis.Timeouts {
+	binding, ok := obj.(*internals.ServiceBinding)
+	if !ok {
+		klog.Errorf("invalid object type, expected: internals.servicebinding, actual :%s", reflect.TypeOf(obj).Name())
+		return apis.Timeouts{}
+	}
+	return binding.Timeouts
+}
+
+// GetObjectInitiator get the initiator of the request which causes the processing of the service binding
+func (s *ServiceBindingResource) GetObjectInitiator(obj interface{}) apis.Initiator {
+	binding, ok := obj.(*internals.ServiceBinding)
+	if !ok {
+		klog.Errorf("invalid object type, expected: internals.servicebinding, actual :%s", reflect.TypeOf(obj).Name())
+		return apis.Initiator{}
+	}
+	return binding.Initiator
+}
+
 // CreateServiceBinding create the service binding into cluster and insert the record to the database
-func (s *ServiceBindingResource) CreateServiceBinding(serviceBinding internals.ServiceBinding) error {
-	klog.Infof("create service binding %s", serviceBinding.Name)
-	_, err := s.bindingDao.Get(map[string]string{"name": serviceBinding.Name,
+func (s *ServiceBindingResource) CreateServiceBinding(ctx context.Context, serviceBinding internals.ServiceBinding) error {
+	requestid.Infof(ctx, "create service binding %s", serviceBinding.Name)
+	bindingDao := s.bindingDao.WithContext(ctx)
+	_, err := bindingDao.Get(map[string]string{"name": serviceBinding.Name,
 		"cluster_name": serviceBinding.ClusterName})
 	if err == nil {
-		klog.Infof("service binding %s has been created", serviceBinding.Name)
+		requestid.Infof(ctx, "service binding %s has been created", serviceBinding.Name)
 		return nil
 	}
 
-	if !errors.Is(err, orm.ErrNoRows) {
-		klog.Infof("get binding %s failed", serviceBinding.Name)
+	if !stderrors.Is(err, orm.ErrNoRows) {
+		requestid.Infof(ctx, "get binding %s failed", serviceBinding.Name)
 		return err
 	}
 
 	serviceBinding.Status = models.StatusInstalling
-	if err = s.bindingDao.Create(serviceBinding, map[string]string{}); err != nil {
+	serviceBinding.Initiator = initiatorFrom(ctx)
+	if err = bindingDao.Create(serviceBinding, map[string]string{}); err != nil {
 		return err
 	}
 
-	if err = watcher.AddEvent(serviceBinding, watcher.OPCreate, apis.OperatorProcessor); err != nil {
-		klog.Infof("service binding %s add watcher event failed", serviceBinding.Name)
+	if err = watcher.AddEvent(ctx, serviceBinding, watcher.OPCreate, apis.OperatorProcessor); err != nil {
+		requestid.Infof(ctx, "service binding %s add watcher event failed", serviceBinding.Name)
 		return err
 	}
 
-	klog.Infof("service binding %s has been created", serviceBinding.Name)
+	requestid.Infof(ctx, "service binding %s has been created", serviceBinding.Name)
 	return nil
 }
 
@@ -190,15 +230,24 @@ func (s *ServiceBindingResource) IsServiceBindingDeployed(name, cluster string)
 	return s.IsExist(map[string]string{"name": name, "cluster_name": cluster})
 }
 
-// DeleteServiceBinding use the service binding name and cluster name to delete the service binding
-func (s *ServiceBindingResource) DeleteServiceBinding(bindingName, clusterName string) error {
+// DeleteServiceBinding use the service binding name and cluster name to delete the service binding. If the
+// opts.ResourceVersion is not nil, it must be the same as the one in database, otherwise the
+// ErrResourceVersionConflict will be returned. The service binding which has instances is only deleted with
+// opts.Cascade, which marks the instances deleting, and the service package is removed after their custom resources,
+// otherwise the ErrServiceHasInstances with the blocking instances will be returned. With opts.Force, the processors of
+// the leader replica remove the records once the cluster accepts the deletions of the custom resources instead of
+// waiting for them to be removed. The ErrDeletionProtected will be returned if the deletion protection of the service
+// binding or any instance deleted by cascade is enabled.
+func (s *ServiceBindingResource) DeleteServiceBinding(ctx context.Context, bindingName, clusterName string,
+	opts DeleteOptions) error {
+	bindingDao, instanceDao := s.bindingDao.WithContext(ctx), s.instanceDao.WithContext(ctx)
 	filter := map[string]string{
 		"name":         bindingName,
 		"cluster_name": clusterName,
 	}
-	obj, err := s.bindingDao.Get(filter)
+	obj, err := bindingDao.Get(filter)
 	if err != nil {
-		if errors.Is(err, orm.ErrNoRows) {
+		if stderrors.Is(err, orm.ErrNoRows) {
 			return nil
 		}
 		return err
@@ -207,34 +256,135 @@ func (s *ServiceBindingResource) DeleteServiceBinding(bindingName, clusterName s
 	if !ok {
 		return fmt.Errorf("get binding %s cluster %s to binding failed", bindingName, clusterName)
 	}
+	if opts.ResourceVersion != nil && *opts.ResourceVersion != binding.ResourceVersion {
+		return models.ErrResourceVersionConflict
+	}
+	if binding.DeletionProtection {
+		return errors.ErrDeletionProtected.WrapErrorReasonWith("the service binding %s is protected from deletion, "+
+			"disable its deletionProtection first", bindingName)
+	}
 
-	objInstances, err := s.instanceDao.GetList(map[string]string{"service_binding_id": binding.ID})
+	objInstances, err := instanceDao.GetList(map[string]string{"service_binding_id": binding.ID})
 	if err != nil {
 		return err
 	}
 
+	var instances []internals.ServiceInstance
 	if objInstances != nil {
-		instances, ok := objInstances.([]internals.ServiceInstance)
+		instances, ok = objInstances.([]internals.ServiceInstance)
 		if !ok {
 			return fmt.Errorf("get binding %s cluster %s instances failed", bindingName, clusterName)
 		}
-		for _, instanceObj := range instances {
-			instanceObj.Status = models.StatusDeleting
-			if err = s.instanceDao.Update(instanceObj, "status"); err != nil {
-				return err
-			}
-
-			if err = watcher.AddEvent(instanceObj, watcher.OPDelete, apis.InstanceProcessor); err != nil {
-				return err
-			}
+		if len(instances) > 0 && !opts.Cascade {
+			return errors.ErrServiceHasInstances.WrapErrorReasonWith("the service binding %s has the instances "+
+				"%s, delete them first or delete with cascade", bindingName, instanceNames(instances))
+		}
+		if protected := protectedInstances(instances); len(protected) > 0 {
+			return errors.ErrDeletionProtected.WrapErrorReasonWith("the instances %s of the service binding %s are "+
+				"protected from deletion, disable their deletionProtection first", instanceNames(protected),
+				bindingName)
 		}
 	}
 
-	binding.Status = models.StatusDeleting
-	if err = s.bindingDao.Update(binding, "status"); err != nil {
+	marked, err := s.markDeleting(ctx, &binding, instances, opts)
+	if err != nil {
 		return err
 	}
-	return watcher.AddEvent(binding, watcher.OPDelete, apis.OperatorProcessor)
+	// the events are added after the transaction is committed, thus the processors read the marked records
+	for _, instanceObj := range marked {
+		if err = watcher.AddEvent(ctx, instanceObj, watcher.OPDelete, apis.InstanceProcessor); err != nil {
+			return err
+		}
+	}
+	return watcher.AddEvent(ctx, binding, watcher.OPDelete, apis.OperatorProcessor)
+}
+
+// markDeleting mark the instances and the service binding deleting in one transaction, thus the service binding is
+// never left with the instances which are not marked, and return the instances which are marked
+func (s *ServiceBindingResource) markDeleting(ctx context.Context, binding *internals.ServiceBinding,
+	instances []internals.ServiceInstance, opts DeleteOptions) (marked []internals.ServiceInstance, err error) {
+	bindingDao, instanceDao := s.bindingDao.WithContext(ctx), s.instanceDao.WithContext(ctx)
+	initiator := initiatorFrom(ctx)
+	tx := s.transaction()
+	if err = tx.BeginTransaction(); err != nil {
+		return nil, err
+	}
+	defer models.Handler(&err, tx)
+	for _, instanceObj := range instances {
+		// the deleting instances are marked again by force, and get a new period to be deleted
+		if instanceObj.Status == models.StatusDeleting && !opts.Force {
+			continue
+		}
+		instanceObj.Status, instanceObj.Initiator = models.StatusDeleting, initiator
+		cols := append([]string{"status", "initiator"}, opts.apply(&instanceObj.Timeouts)...)
+		if opts.Force {
+			instanceObj.ProcessTime = time.Time{}
+			cols = append(cols, "process_time")
+		}
+		cols = append(cols, opts.applyForce(&instanceObj.ForceDelete, &instanceObj.RemoveFinalizers)...)
+		if err = instanceDao.UpdateTx(&instanceObj, tx.GetTransaction(), cols...); err != nil {
+			return nil, err
+		}
+		marked = append(marked, instanceObj)
+	}
+
+	binding.Status, binding.Initiator = models.StatusDeleting, initiator
+	cols := append([]string{"status", "initiator"}, opts.apply(&binding.Timeouts)...)
+	cols = append(cols, opts.applyForce(&binding.ForceDelete, &binding.RemoveFinalizers)...)
+	if err = bindingDao.UpdateTx(binding, tx.GetTransaction(), cols...); err != nil {
+		return nil, err
+	}
+	return marked, nil
+}
+
+// protectedInstances the instances whose deletion protection is enabled
+func protectedInstances(instances []internals.ServiceInstance) []internals.ServiceInstance {
+	var protected []internals.ServiceInstance
+	for _, ins := range instances {
+		if ins.DeletionProtection {
+			protected = append(protected, ins)
+		}
+	}
+	return protected
+}
+
+// instanceNames the namespaced names of the instances for the messages
+func instanceNames(instances []internals.ServiceInstance) string {
+	names := make([]string, 0, len(instances))
+	for _, ins := range instances {
+		names = append(names, ins.Namespace+"/"+ins.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// UpdateServiceBindingSettings update the settings of the service binding by the update function, and return the new
+// settings and resource version. If the resourceVersion is not nil, it must be the same as the one in database,
+// otherwise the ErrResourceVersionConflict will be returned.
+func (s *ServiceBindingResource) UpdateServiceBindingSettings(ctx context.Context, bindingName, clusterName string,
+	resourceVersion *int64, update UpdateSettings) (apis.Settings, int64, error) {
+	bindingDao := s.bindingDao.WithContext(ctx)
+	obj, err := bindingDao.Get(map[string]string{"name": bindingName, "cluster_name": clusterName})
+	if err != nil {
+		return apis.Settings{}, 0, err
+	}
+	binding, ok := obj.(internals.ServiceBinding)
+	if !ok {
+		return apis.Settings{}, 0, fmt.Errorf("get binding %s cluster %s to binding failed", bindingName, clusterName)
+	}
+	if resourceVersion != nil && *resourceVersion != binding.ResourceVersion {
+		return apis.Settings{}, 0, models.ErrResourceVersionConflict
+	}
+	settings, err := update(apis.Settings{Timeouts: binding.Timeouts, DeletionProtection: binding.DeletionProtection})
+	if err != nil {
+		return apis.Settings{}, 0, err
+	}
+	binding.Timeouts, binding.DeletionProtection = settings.Timeouts, settings.DeletionProtection
+	if err = bindingDao.Update(&binding, "timeouts", "deletion_protection"); err != nil {
+		return apis.Settings{}, 0, err
+	}
+	requestid.Infof(ctx, "the settings of service binding %s in cluster %s have been updated", bindingName, clusterName)
+	return settings, binding.ResourceVersion, nil
 }
 
 // GetInternalServiceBinding get the ServiceBinding as the internal format
@@ -242,7 +392,7 @@ func (s *ServiceBindingResource) GetInternalServiceBinding(serviceBindingName st
 	clusterName string) (*internals.ServiceBinding, error) {
 	tmp, err := s.bindingDao.Get(map[string]string{"name": serviceBindingName, "cluster_name": clusterName})
 	if err != nil {
-		if errors.Is(err, orm.ErrNoRows) {
+		if stderrors.Is(err, orm.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
@@ -264,16 +414,16 @@ func (s *ServiceBindingResource) GetServiceBindings(clusterName string) ([]insta
 }
 
 // GetServiceBinding use name, clusterId to get the service binding information
-func (s *ServiceBindingResource) GetServiceBinding(name, clusterName string,
+func (s *ServiceBindingResource) GetServiceBinding(ctx context.Context, name, clusterName string,
 	detail bool) (*instancev1alpha1.CloudNativeServiceInstance, error) {
-	sb, err := s.Get(map[string]string{"name": name, "cluster_name": clusterName})
+	sb, err := s.ServiceBindingOperation.WithContext(ctx).Get(map[string]string{"name": name, "cluster_name": clusterName})
 	if err != nil {
-		if errors.Is(err, orm.ErrNoRows) {
+		if stderrors.Is(err, orm.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	engine, _, err := operations.GetClusterOperation().GetServicePackageByName(name, apis.KappitalSystemNamespace)
+	engine, _, err := operations.GetClusterOperation().GetServicePackageByName(ctx, name, apis.KappitalSystemNamespace)
 	if err != nil {
 		return nil, err
 	}
@@ -329,10 +479,11 @@ func (s *ServiceBindingResource) transferInstanceModelToResponse(
 				Kind:       m.Kind,
 				APIVersion: m.APIVersion,
 			},
-			Name:      m.Name,
-			Namespace: m.Namespace,
-			UID:       m.ID,
-			Status:    m.Status,
+			Name:               m.Name,
+			Namespace:          m.Namespace,
+			UID:                m.ID,
+			Status:             m.Status,
+			DeletionProtection: m.DeletionProtection,
 		})
 	}
 	return resource, notFound, pending
@@ -357,13 +508,15 @@ func (s *ServiceBindingResource) getServiceBindingMeta(item models.ServiceBindin
 			Name:              item.Name,
 			Namespace:         item.Namespace,
 			CreationTimestamp: metav1.Time{Time: item.CreateTime},
+			ResourceVersion:   strconv.FormatInt(item.ResourceVersion, 10),
 		},
 		Spec: instancev1alpha1.CloudNativeServiceInstanceSpec{
-			Name:        item.Name,
-			Version:     item.Version,
-			ServiceName: item.ServiceName,
-			ServiceID:   item.ServiceID,
-			ClusterName: item.ClusterName,
+			Name:               item.Name,
+			Version:            item.Version,
+			ServiceName:        item.ServiceName,
+			ServiceID:          item.ServiceID,
+			ClusterName:        item.ClusterName,
+			DeletionProtection: item.DeletionProtection,
 		},
 	}
 }
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/kappital/kappital/pkg/apis/internals"
+	"github.com/kappital/kappital/pkg/dao/instance"
+	"github.com/kappital/kappital/pkg/dao/servicebinding"
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/watcher"
+)
+
+func TestServiceBindingResource_DeleteServiceBinding(t *testing.T) {
+	binding := internals.ServiceBinding{ID: "binding-id", Name: "redis", ClusterName: "default"}
+	instances := []internals.ServiceInstance{
+		{ID: "i2", Name: "cache", Namespace: "prod", Status: models.StatusDeleting},
+		{ID: "i1", Name: "session", Namespace: "dev", Status: models.StatusSuccess},
+	}
+	tests := []struct {
+		name          string
+		instances     []internals.ServiceInstance
+		protected     bool
+		opts          DeleteOptions
+		updateErr     error
+		wantConflict  bool
+		wantProtected bool
+		wantUpdated   []string
+		wantEventKind []string
+	}{
+		{name: "Test DeleteServiceBinding (no instances)", wantUpdated: []string{"redis"},
+			wantEventKind: []string{"ServiceBinding"}},
+		{name: "Test DeleteServiceBinding (instances without cascade)", instances: instances, wantConflict: true},
+		{name: "Test DeleteServiceBinding (instances with cascade)", instances: instances, opts: DeleteOptions{Cascade: true},
+			wantUpdated: []string{"session", "redis"}, wantEventKind: []string{"ServiceInstance", "ServiceBinding"}},
+		{name: "Test DeleteServiceBinding (instances with force but without cascade)", instances: instances,
+			opts: DeleteOptions{Force: true}, wantConflict: true},
+		{name: "Test DeleteServiceBinding (instances with cascade and force)", instances: instances,
+			opts:          DeleteOptions{Cascade: true, Force: true, RemoveFinalizers: true},
+			wantUpdated:   []string{"cache", "session", "redis"},
+			wantEventKind: []string{"ServiceInstance", "ServiceInstance", "ServiceBinding"}},
+		{name: "Test DeleteServiceBinding (binding is not updated)", instances: instances,
+			opts: DeleteOptions{Cascade: true}, updateErr: fmt.Errorf("database is locked"),
+			wantUpdated: []string{"session"}},
+		{name: "Test DeleteServiceBinding (protected)", protected: true, opts: DeleteOptions{Force: true},
+			wantProtected: true},
+		{name: "Test DeleteServiceBinding (protected instances with cascade)", opts: DeleteOptions{Cascade: true},
+			instances: append([]internals.ServiceInstance{{ID: "i3", Name: "orders", Namespace: "prod",
+				DeletionProtection: true}}, instances...), wantProtected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated, events, deleted []string
+			p := gomonkey.ApplyMethod(reflect.TypeOf(servicebinding.ServiceBinding{}), "Get",
+				func(servicebinding.ServiceBinding, map[string]string) (interface{}, error) {
+					protectedBinding := binding
+					protectedBinding.DeletionProtection = tt.protected
+					return protectedBinding, nil
+				})
+			defer p.Reset()
+			p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "GetList",
+				func(instance.Instance, map[string]string) (interface{}, error) {
+					return append([]internals.ServiceInstance{}, tt.instances...), nil
+				})
+			p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "UpdateTx",
+				func(_ instance.Instance, obj interface{}, _ orm.TxOrmer, _ ...string) error {
+					ins := obj.(*internals.ServiceInstance)
+					if ins.ForceDelete != tt.opts.Force || ins.RemoveFinalizers != tt.opts.RemoveFinalizers {
+						t.Errorf("DeleteServiceBinding() marked the instance %s by force %v, want %v", ins.Name,
+							ins.ForceDelete, tt.opts.Force)
+					}
+					updated = append(updated, ins.Name)
+					return nil
+				})
+			p.ApplyMethod(reflect.TypeOf(servicebinding.ServiceBinding{}), "UpdateTx",
+				func(_ servicebinding.ServiceBinding, obj interface{}, _ orm.TxOrmer, _ ...string) error {
+					sb := obj.(*internals.ServiceBinding)
+					if sb.ForceDelete != tt.opts.Force || sb.RemoveFinalizers != tt.opts.RemoveFinalizers {
+						t.Errorf("DeleteServiceBinding() marked the binding by force %v, want %v", sb.ForceDelete,
+							tt.opts.Force)
+					}
+					if tt.updateErr != nil {
+						return tt.updateErr
+					}
+					updated = append(updated, sb.Name)
+					return nil
+				})
+			p.ApplyMethod(reflect.TypeOf(instance.Instance{}), "Delete",
+				func(_ instance.Instance, obj interface{}) error {
+					deleted = append(deleted, obj.(internals.ServiceInstance).Name)
+					return nil
+				})
+			p.ApplyMethod(reflect.TypeOf(servicebinding.ServiceBinding{}), "Delete",
+				func(_ servicebinding.ServiceBinding, obj interface{}) error {
+					deleted = append(deleted, obj.(internals.ServiceBinding).Name)
+					return nil
+				})
+			p.ApplyFunc(watcher.AddEvent, func(_ context.Context, obj interface{}, _, _ string) error {
+				events = append(events, reflect.TypeOf(obj).Name())
+				return nil
+			})
+
+			s := &ServiceBindingResource{newTransaction: func() models.Transaction {
+				return &models.FakeTransactionImpl{}
+			}}
+			err := s.DeleteServiceBinding(context.Background(), "redis", "default", tt.opts)
+			if tt.wantProtected {
+				if kappErr, ok := err.(errors.KappError); !ok || !kappErr.TypeEqual(errors.ErrDeletionProtected) {
+					t.Fatalf("DeleteServiceBinding() error = %v, want ErrDeletionProtected", err)
+				}
+				if len(updated) > 0 || len(deleted) > 0 {
+					t.Errorf("DeleteServiceBinding() updated %v and deleted %v, want nothing", updated, deleted)
+				}
+				return
+			}
+			if tt.updateErr != nil {
+				if !stderrors.Is(err, tt.updateErr) || len(events) > 0 {
+					t.Errorf("DeleteServiceBinding() error = %v and events %v, want %v without events", err, events,
+						tt.updateErr)
+				}
+				return
+			}
+			if tt.wantConflict {
+				if kappErr, ok := err.(errors.KappError); !ok || !kappErr.TypeEqual(errors.ErrServiceHasInstances) {
+					t.Fatalf("DeleteServiceBinding() error = %v, want ErrServiceHasInstances", err)
+				}
+				if want := "dev/session, prod/cache"; !strings.Contains(err.Error(), want) {
+					t.Errorf("DeleteServiceBinding() error = %v, want the instances %s", err, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeleteServiceBinding() error = %v", err)
+			}
+			if !reflect.DeepEqual(updated, tt.wantUpdated) || !reflect.DeepEqual(events, tt.wantEventKind) {
+				t.Errorf("DeleteServiceBinding() updated %v and events %v, want %v and %v", updated, events,
+					tt.wantUpdated, tt.wantEventKind)
+			}
+			if len(deleted) > 0 {
+				t.Errorf("DeleteServiceBinding() deleted %v, want the records removed by the processors", deleted)
+			}
+		})
+	}
+}
@@ -17,45 +17,143 @@
 package routers
 
 import (
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
-	"os"
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/beego/beego/v2/server/web"
 	"github.com/beego/beego/v2/server/web/context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	"k8s.io/klog/v2"
 
 	"github.com/kappital/kappital/pkg/constants"
+	"github.com/kappital/kappital/pkg/controller/utils"
 	"github.com/kappital/kappital/pkg/routers/flowcontroller"
+	"github.com/kappital/kappital/pkg/utils/authentication"
+	"github.com/kappital/kappital/pkg/utils/authorization"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/metrics"
+	"github.com/kappital/kappital/pkg/utils/patch"
+	"github.com/kappital/kappital/pkg/utils/requestid"
+	"github.com/kappital/kappital/pkg/utils/tracing"
 )
 
-var validMethodSet = map[string]struct{}{http.MethodGet: {}, http.MethodDelete: {}, http.MethodPost: {}}
+var validMethodSet = map[string]struct{}{http.MethodGet: {}, http.MethodDelete: {}, http.MethodPost: {},
+	http.MethodPut: {}, http.MethodPatch: {}}
+
+// updateSchemas the schemas of the PUT and PATCH bodies by the router patterns
+var updateSchemas = map[string]interface{}{}
 
 const (
-	checkIdentityEnv            = "CHECK_IDENTITY"
-	acceptCertificateCommonName = "Kappital - Client"
+	// routerPatternKey the key of the matched router pattern in the beego input data
+	routerPatternKey = "RouterPattern"
+	// flowControlledKey marks the request which has been limited by the flow controller
+	flowControlledKey = "FlowControlled"
+	// bodyValidatedKey marks the PUT or PATCH request whose body has been validated
+	bodyValidatedKey = "BodyValidated"
 )
 
-// InitFilters for url, and pre-check the requests
-func InitFilters() {
+// InitFilters for url, and pre-check the requests, the principals are checked if identityCheck is true
+func InitFilters(identityCheck bool) {
+	web.InsertFilterChain("/*", requestIDFilterChain)
+	web.InsertFilterChain("/*", metricsFilterChain)
+	web.InsertFilterChain("/*", tracingFilterChain)
+
 	web.InsertFilter("/api/*", web.BeforeStatic, formatFilter)
 	web.InsertFilter("/api/*", web.BeforeStatic, beforeStaticFilter)
+	web.InsertFilter("/api/*", web.BeforeExec, authenticationFilter)
 	web.InsertFilter("/api/*", web.BeforeExec, flowControlFilter)
 
 	web.InsertFilter("/*", web.BeforeStatic, formatFilter)
 	web.InsertFilter("/*", web.BeforeStatic, beforeStaticFilter)
+	web.InsertFilter("/*", web.BeforeExec, authenticationFilter)
 	web.InsertFilter("/*", web.BeforeExec, flowControlFilter)
 
-	if check, err := strconv.ParseBool(os.Getenv(checkIdentityEnv)); check && err == nil {
+	if identityCheck {
 		klog.Info("Open the Identity Check")
 		web.InsertFilter("/api/*", web.BeforeExec, checkIdentity)
-		web.InsertFilter("/*", web.BeforeStatic, checkIdentity)
+		web.InsertFilter("/*", web.BeforeExec, checkIdentity)
+	}
+
+	web.InsertFilter("/api/*", web.BeforeExec, updateBodyFilter)
+	web.InsertFilter("/*", web.BeforeExec, updateBodyFilter)
+}
+
+// RegisterUpdateSchema register the schema of the PUT and PATCH bodies of the router pattern, the bodies are validated
+// against it before they reach the controller
+func RegisterUpdateSchema(pattern string, schema interface{}) {
+	updateSchemas[pattern] = schema
+}
+
+// requestIDFilterChain accepts the X-Request-ID of the client or generates a new one, and puts it into the request
+// context and the response header, thus the logs, the audit entries and the responses of the request can be correlated
+func requestIDFilterChain(next web.FilterFunc) web.FilterFunc {
+	return func(ctx *context.Context) {
+		id := requestid.Normalize(ctx.Input.Header(requestid.Header))
+		ctx.Request = ctx.Request.WithContext(requestid.NewContext(ctx.Request.Context(), id))
+		ctx.Output.Header(requestid.Header, id)
+		next(ctx)
 	}
 }
 
+// metricsFilterChain wraps all filters and the controllers, thus the requests rejected by the filters are counted
+func metricsFilterChain(next web.FilterFunc) web.FilterFunc {
+	return func(ctx *context.Context) {
+		start := time.Now()
+		next(ctx)
+		code := ctx.ResponseWriter.Status
+		if code == 0 {
+			code = http.StatusOK
+		}
+		metrics.ObserveHTTPRequest(ctx.Input.Method(), routePattern(ctx), code, start)
+	}
+}
+
+// tracingFilterChain starts the server span of the request, the trace context of the caller is continued if it is
+// carried by the headers. The span is put into the request context, thus the controllers can pass it down.
+func tracingFilterChain(next web.FilterFunc) web.FilterFunc {
+	return func(ctx *context.Context) {
+		parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(),
+			propagation.HeaderCarrier(ctx.Request.Header))
+		spanCtx, span := tracing.Start(parent, ctx.Input.Method(), semconv.HTTPMethodKey.String(ctx.Input.Method()),
+			semconv.HTTPTargetKey.String(ctx.Input.URL()),
+			attribute.String("http.request_id", requestid.FromContext(ctx.Request.Context())))
+		defer span.End()
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+		next(ctx)
+
+		code := ctx.ResponseWriter.Status
+		if code == 0 {
+			code = http.StatusOK
+		}
+		if route := routePattern(ctx); len(route) > 0 {
+			span.SetName(fmt.Sprintf("%s %s", ctx.Input.Method(), route))
+			span.SetAttributes(semconv.HTTPRouteKey.String(route))
+		}
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(code))
+		if code >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(code))
+		}
+	}
+}
+
+// routePattern get the registered router pattern instead of the raw url, to keep the metrics cardinality bounded
+func routePattern(ctx *context.Context) string {
+	pattern, _ := ctx.Input.GetData(routerPatternKey).(string)
+	return pattern
+}
+
 func beforeStaticFilter(ctx *context.Context) {
 	if !strings.HasPrefix(ctx.Request.Header.Get("Content-Type"), "multipart/form-data") {
 		return
@@ -64,7 +162,7 @@ func beforeStaticFilter(ctx *context.Context) {
 	if ctx.Request.ContentLength > constants.FileSize {
 		errMsg := fmt.Sprintf("Uploaded Content Length Exceed %dM", constants.FileSize/1024/1024)
 		klog.Errorf(errMsg)
-		setFilterErrorMsg(ctx, http.StatusBadRequest, errMsg)
+		setFilterError(ctx, errors.ErrRequestTooLarge.WrapErrorReasonWith(errMsg))
 	}
 }
 
@@ -74,45 +172,135 @@ func formatFilter(ctx *context.Context) {
 	_, find := validMethodSet[method]
 	if !find {
 		klog.Errorf("[FormatURL] illegal Method! method: %s", method)
-		setFilterErrorMsg(ctx, http.StatusNotFound, "method is illegal")
+		setFilterError(ctx, errors.ErrMethodNotAllowed.WrapErrorReasonWith("method %s is illegal", method))
 		return
 	}
 	if int64(len(ctx.Input.RequestBody)) > constants.FileSize {
 		klog.Errorf("[FormatURL] illegal Request Body Size! current size %d, max size %d", len(ctx.Input.RequestBody), constants.FileSize)
-		setFilterErrorMsg(ctx, http.StatusBadRequest, "request body size is too large")
+		setFilterError(ctx, errors.ErrRequestTooLarge.WrapErrorReasonWith("request body size is too large"))
 		return
 	}
 	formatURL := path.Clean(rawURL)
 	// note: only formatURL allowed ( trailing slash allowed for compatibility)
 	if (rawURL != formatURL && rawURL != formatURL+"/") || !path.IsAbs(formatURL) {
 		klog.Errorf("[FormatURL] illegal URL format! rawURL:%s %s(%s)", method, rawURL, formatURL)
-		setFilterErrorMsg(ctx, http.StatusNotFound, "url is illegal")
+		setFilterError(ctx, errors.ErrNotFound.WrapErrorReasonWith("url is illegal"))
 	}
 }
 
-func setFilterErrorMsg(ctx *context.Context, code int, msg string) {
-	ctx.ResponseWriter.WriteHeader(code)
-	ctx.WriteString(msg)
+// setFilterError reply the ErrorResp from the filter, the http code is decided by the error
+func setFilterError(ctx *context.Context, err errors.KappError) {
+	buf, marshalErr := json.Marshal(utils.NewErrorResp(ctx, err))
+	if marshalErr != nil {
+		klog.Errorf("failed to marshal the error response, err: %v", marshalErr)
+	}
+	ctx.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	ctx.ResponseWriter.WriteHeader(err.GetHTTPCode())
+	_, _ = ctx.ResponseWriter.Write(buf)
+}
+
+// updateBodyFilter validate the PUT and PATCH bodies against the schema of the route. The PUT body is the whole
+// document in JSON, and the PATCH body is the JSON merge patch or the JSON patch.
+func updateBodyFilter(ctx *context.Context) {
+	method := ctx.Input.Method()
+	if method != http.MethodPut && method != http.MethodPatch {
+		return
+	}
+	// the filter is registered for both /api/* and /*, the body is only validated once
+	if ctx.Input.GetData(bodyValidatedKey) != nil {
+		return
+	}
+	ctx.Input.SetData(bodyValidatedKey, true)
+	schema, ok := updateSchemas[routePattern(ctx)]
+	if !ok {
+		setFilterError(ctx, errors.ErrMethodNotAllowed.WrapErrorReasonWith("method %s is not supported by %s",
+			method, routePattern(ctx)))
+		return
+	}
+	contentType := ctx.Input.Header("Content-Type")
+	var err error
+	if method == http.MethodPut {
+		if mediaType := patch.MediaType(contentType); len(mediaType) > 0 && mediaType != patch.JSONType {
+			setFilterError(ctx, errors.ErrUnsupportedMediaType.WrapErrorReasonWith("the content type %s is not %s",
+				contentType, patch.JSONType))
+			return
+		}
+		err = patch.ValidateDocument(ctx.Input.RequestBody, schema)
+	} else {
+		err = patch.ValidatePatch(contentType, ctx.Input.RequestBody, schema)
+	}
+	if stderrors.Is(err, patch.ErrUnsupportedMediaType) {
+		setFilterError(ctx, errors.ErrUnsupportedMediaType.WrapErrorReasonWith(err.Error()))
+		return
+	}
+	if err != nil {
+		requestid.Warningf(ctx.Request.Context(), "reject the %s body of %s, err: %v", method, ctx.Input.URI(), err)
+		setFilterError(ctx, errors.ErrInvalidBody.WrapErrorReasonWith(err.Error()))
+	}
 }
 
-// Check whether the server could handle the request
+// flowControlFilter limit the request by the bucket of its client and route class, and the global bucket. The client
+// is the authenticated principal, or the source ip of the anonymous request.
 func flowControlFilter(ctx *context.Context) {
-	if !flowcontroller.TryToPassReq() {
-		klog.Warningf("request(%s %s) is rejected by flow controller, too many request", ctx.Input.Method(), ctx.Input.URI())
-		setFilterErrorMsg(ctx, http.StatusTooManyRequests, "too many request")
+	// the filter is registered for both /api/* and /*, the request only takes the tokens once
+	if ctx.Input.GetData(flowControlledKey) != nil {
 		return
 	}
+	ctx.Input.SetData(flowControlledKey, true)
+	class := flowcontroller.ClassOf(ctx.Input.Method())
+	result := flowcontroller.Allow(flowControlClient(ctx), class)
+	if result.Limit > 0 {
+		ctx.Output.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Output.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Output.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	}
+	if !result.Allowed {
+		klog.Warningf("request(%s %s) is rejected by the %s flow controller, too many request",
+			ctx.Input.Method(), ctx.Input.URI(), result.Scope)
+		metrics.IncFlowControlRejected(ctx.Input.Method(), routePattern(ctx), string(class), result.Scope)
+		ctx.Output.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		setFilterError(ctx, errors.ErrTooManyRequests.WrapErrorReasonWith("rejected by the %s %s limit",
+			class, result.Scope))
+	}
 }
 
-func checkIdentity(ctx *context.Context) {
-	var find bool
-	for _, cert := range ctx.Request.TLS.PeerCertificates {
-		if cert.Subject.CommonName == acceptCertificateCommonName {
-			find = true
-			break
-		}
+// flowControlClient the principal name of the request, or its source ip. The X-Forwarded-For header is not used
+// because it is given by the client.
+func flowControlClient(ctx *context.Context) string {
+	if principal, ok := authentication.PrincipalFrom(ctx.Request.Context()); ok {
+		return "user:" + principal.Name
 	}
-	if !find {
-		setFilterErrorMsg(ctx, http.StatusUnauthorized, "reject certificates")
+	host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr)
+	if err != nil {
+		host = ctx.Request.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// authenticationFilter identify the principal of the request, and put it into the request context. The request with
+// the invalid bearer token is rejected, and the anonymous request is left to the identity check and authorization.
+func authenticationFilter(ctx *context.Context) {
+	if _, ok := authentication.PrincipalFrom(ctx.Request.Context()); ok {
+		return
+	}
+	principal, err := authentication.Authenticate(ctx.Request)
+	if err == nil {
+		ctx.Request = ctx.Request.WithContext(authentication.WithPrincipal(ctx.Request.Context(), principal))
+		return
+	}
+	if len(ctx.Input.Header("Authorization")) > 0 {
+		klog.Warningf("reject the request %s %s, err: %v", ctx.Input.Method(), ctx.Input.URI(), err)
+		setFilterError(ctx, errors.ErrUnauthorized.WrapErrorReasonWith("reject the bearer token"))
+	}
+}
+
+func checkIdentity(ctx *context.Context) {
+	principal, ok := authentication.PrincipalFrom(ctx.Request.Context())
+	if !ok || !authorization.CheckIdentity(principal) {
+		setFilterError(ctx, errors.ErrUnauthorized.WrapErrorReasonWith("reject certificates"))
 	}
 }
@@ -18,13 +18,21 @@ package routers
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/beego/beego/v2/server/web/context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/kappital/kappital/pkg/apis"
 	"github.com/kappital/kappital/pkg/constants"
+	"github.com/kappital/kappital/pkg/routers/flowcontroller"
 	"github.com/kappital/kappital/pkg/utils/gateway"
+	"github.com/kappital/kappital/pkg/utils/patch"
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 var fakeCtx = &context.Context{
@@ -33,11 +41,11 @@ var fakeCtx = &context.Context{
 	Request: &http.Request{
 		Header: http.Header{"test": []string{}},
 	},
-	ResponseWriter: &context.Response{ResponseWriter: gateway.FakeResponseWriter{}},
+	ResponseWriter: &context.Response{ResponseWriter: httptest.NewRecorder()},
 }
 
 func TestInitFilters(t *testing.T) {
-	InitFilters()
+	InitFilters(false)
 }
 
 func Test_beforeStaticFilter(t *testing.T) {
@@ -50,7 +58,7 @@ func Test_beforeStaticFilter(t *testing.T) {
 }
 
 func Test_formatFilter(t *testing.T) {
-	fakeCtx.Reset(gateway.FakeResponseWriter{}, &http.Request{})
+	fakeCtx.Reset(httptest.NewRecorder(), &http.Request{})
 
 	fakeCtx.Input = &context.BeegoInput{
 		Context: &context.Context{
@@ -83,3 +91,147 @@ func Test_formatFilter(t *testing.T) {
 	}
 	formatFilter(fakeCtx)
 }
+
+func Test_requestIDFilterChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{name: "Test requestIDFilterChain (given)", header: "kappctl-42", wantSame: true},
+		{name: "Test requestIDFilterChain (generated)"},
+		{name: "Test requestIDFilterChain (invalid)", header: "a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx := context.NewContext()
+			req := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/a"}, Header: http.Header{}}
+			req.Header.Set(requestid.Header, tt.header)
+			ctx.Reset(recorder, req)
+			var got string
+			requestIDFilterChain(func(ctx *context.Context) {
+				got = requestid.FromContext(ctx.Request.Context())
+			})(ctx)
+			if !requestid.Valid(got) || (got == tt.header) != tt.wantSame {
+				t.Errorf("the request id in the request context = %q, header %q", got, tt.header)
+			}
+			if header := recorder.Header().Get(requestid.Header); header != got {
+				t.Errorf("the request id in the response header = %q, want %q", header, got)
+			}
+		})
+	}
+}
+
+func Test_metricsFilterChain(t *testing.T) {
+	ctx := context.NewContext()
+	ctx.Reset(&gateway.FakeResponseWriter{}, &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/a"}})
+	metricsFilterChain(func(ctx *context.Context) {
+		ctx.Input.SetData(routerPatternKey, "/a")
+	})(ctx)
+	if got := routePattern(ctx); got != "/a" {
+		t.Errorf("routePattern() = %s, want /a", got)
+	}
+}
+
+func Test_tracingFilterChain(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	ctx := context.NewContext()
+	ctx.Reset(&gateway.FakeResponseWriter{}, &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/a"},
+		Header: http.Header{"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}})
+	var traceID string
+	tracingFilterChain(func(ctx *context.Context) {
+		traceID = trace.SpanContextFromContext(ctx.Request.Context()).TraceID().String()
+	})(ctx)
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("the trace id in the request context = %s, want 4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	}
+}
+
+func Test_updateBodyFilter(t *testing.T) {
+	RegisterUpdateSchema("/api/test/:name", apis.Settings{})
+	defer delete(updateSchemas, "/api/test/:name")
+	tests := []struct {
+		name        string
+		method      string
+		pattern     string
+		contentType string
+		body        string
+		wantCode    int
+	}{
+		{name: "get is skipped", method: http.MethodGet, pattern: "/api/other", wantCode: http.StatusOK},
+		{name: "put", method: http.MethodPut, pattern: "/api/test/:name", contentType: patch.JSONType,
+			body: `{"timeouts":{"installTimeout":"5m"}}`, wantCode: http.StatusOK},
+		{name: "put without schema", method: http.MethodPut, pattern: "/api/other", contentType: patch.JSONType,
+			body: `{}`, wantCode: http.StatusMethodNotAllowed},
+		{name: "put with merge patch", method: http.MethodPut, pattern: "/api/test/:name",
+			contentType: patch.MergePatchType, body: `{}`, wantCode: http.StatusUnsupportedMediaType},
+		{name: "put with unknown field", method: http.MethodPut, pattern: "/api/test/:name",
+			contentType: patch.JSONType, body: `{"timeout":"5m"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "merge patch", method: http.MethodPatch, pattern: "/api/test/:name",
+			contentType: patch.MergePatchType, body: `{"timeouts":{"deleteTimeout":"1m"}}`, wantCode: http.StatusOK},
+		{name: "json patch", method: http.MethodPatch, pattern: "/api/test/:name", contentType: patch.JSONPatchType,
+			body: `[{"op":"remove","path":"/timeouts/deleteTimeout"}]`, wantCode: http.StatusOK},
+		{name: "invalid json patch", method: http.MethodPatch, pattern: "/api/test/:name",
+			contentType: patch.JSONPatchType, body: `[{"op":"add","path":"/name","value":"a"}]`,
+			wantCode: http.StatusUnprocessableEntity},
+		{name: "patch with json", method: http.MethodPatch, pattern: "/api/test/:name", contentType: patch.JSONType,
+			body: `{}`, wantCode: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx := context.NewContext()
+			req := &http.Request{Method: tt.method, URL: &url.URL{Path: "/api/test/a"}, Header: http.Header{}}
+			req.Header.Set("Content-Type", tt.contentType)
+			ctx.Reset(recorder, req)
+			ctx.Input.RequestBody = []byte(tt.body)
+			ctx.Input.SetData(routerPatternKey, tt.pattern)
+			updateBodyFilter(ctx)
+			// the request passing both /api/* and /* filters is only validated once
+			updateBodyFilter(ctx)
+			if recorder.Code != tt.wantCode {
+				t.Errorf("updateBodyFilter() code = %d, want %d, body %s", recorder.Code, tt.wantCode,
+					recorder.Body.String())
+			}
+		})
+	}
+}
+
+func Test_flowControlFilter(t *testing.T) {
+	flowcontroller.Init(&flowcontroller.Config{Enable: true, QPS: 10, Burst: 10, ClientMutationQPS: 1,
+		ClientMutationBurst: 1})
+	defer flowcontroller.Init(nil)
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		wantCode       int
+		wantRemaining  string
+		wantRetryAfter string
+	}{
+		{name: "first mutation", remoteAddr: "10.0.0.1:1234", wantCode: http.StatusOK, wantRemaining: "0"},
+		{name: "second mutation from the same ip", remoteAddr: "10.0.0.1:4321", wantCode: http.StatusTooManyRequests,
+			wantRemaining: "0", wantRetryAfter: "1"},
+		{name: "mutation from the other ip", remoteAddr: "10.0.0.2:1234", wantCode: http.StatusOK, wantRemaining: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx := context.NewContext()
+			ctx.Reset(recorder, &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/a"},
+				RemoteAddr: tt.remoteAddr})
+			flowControlFilter(ctx)
+			// the request passing both /api/* and /* filters only takes the tokens once
+			flowControlFilter(ctx)
+			if recorder.Code != tt.wantCode {
+				t.Errorf("flowControlFilter() code = %d, want %d", recorder.Code, tt.wantCode)
+			}
+			if got := recorder.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Errorf("X-RateLimit-Remaining = %s, want %s", got, tt.wantRemaining)
+			}
+			if got := recorder.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %s, want %s", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
@@ -17,42 +17,267 @@
 package flowcontroller
 
 import (
+	"fmt"
+	"math"
 	"sync"
+	"time"
 
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
 )
 
-var rateLimiter flowcontrol.RateLimiter
-var once sync.Once
+// Class the route class of the request, the reads and the mutations are limited separately
+type Class string
 
+const (
+	// ReadClass the requests which only get the resources, such as GET and HEAD
+	ReadClass Class = "read"
+	// MutationClass the requests which change the resources, such as POST and DELETE
+	MutationClass Class = "mutation"
+
+	// GlobalScope the request is limited by the bucket of its route class shared by all clients
+	GlobalScope = "global"
+	// ClientScope the request is limited by the bucket of its client
+	ClientScope = "client"
+
+	// idleTimeout the buckets of the clients which are idle longer than it are evicted
+	idleTimeout = 10 * time.Minute
+)
+
+var (
+	limiter *rateLimiter
+	mu      sync.RWMutex
+	now     = time.Now
+)
+
+// Config of the flow controller
 type Config struct {
-	QPS    float64
-	Burst  int
+	// QPS and Burst of the read bucket shared by all clients
+	QPS   float64
+	Burst int
+	// MutationQPS and MutationBurst of the mutation bucket shared by all clients, thus the reads cannot use up the
+	// capacity of the mutations. 0 QPS means the mutation bucket has the same QPS and burst as the read one
+	MutationQPS   float64
+	MutationBurst int
+	// Enable the flow controller, all requests pass if it is false
 	Enable bool
+	// ClientReadQPS and ClientReadBurst of the read requests of each client, 0 QPS disables the client read limit
+	ClientReadQPS   float64
+	ClientReadBurst int
+	// ClientMutationQPS and ClientMutationBurst of the mutation requests of each client, 0 QPS disables the client
+	// mutation limit
+	ClientMutationQPS   float64
+	ClientMutationBurst int
 }
 
+// DefaultFlowControllerConfig get the default flow controller config
 func DefaultFlowControllerConfig() *Config {
 	return &Config{
-		QPS:    10,
-		Burst:  30,
-		Enable: true,
+		QPS:                 10,
+		Burst:               30,
+		MutationQPS:         5,
+		MutationBurst:       10,
+		Enable:              true,
+		ClientReadQPS:       5,
+		ClientReadBurst:     20,
+		ClientMutationQPS:   2,
+		ClientMutationBurst: 10,
+	}
+}
+
+// Validate the QPS and burst of the buckets
+func (c *Config) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.QPS <= 0 || c.Burst < 1 {
+		return fmt.Errorf("the flow control qps must be positive and the burst must be at least 1")
+	}
+	if c.MutationQPS < 0 || (c.MutationQPS > 0 && c.MutationBurst < 1) {
+		return fmt.Errorf("the flow control mutation qps cannot be negative and the burst must be at least 1")
+	}
+	if c.ClientReadQPS < 0 || (c.ClientReadQPS > 0 && c.ClientReadBurst < 1) {
+		return fmt.Errorf("the client read qps cannot be negative and the burst must be at least 1")
+	}
+	if c.ClientMutationQPS < 0 || (c.ClientMutationQPS > 0 && c.ClientMutationBurst < 1) {
+		return fmt.Errorf("the client mutation qps cannot be negative and the burst must be at least 1")
+	}
+	return nil
+}
+
+// Init the flow controller by the config, the previous buckets are dropped
+func Init(cfg *Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	if cfg == nil || !cfg.Enable {
+		limiter = nil
+		klog.Info("flow controller is disabled")
+		return
+	}
+	mutationQPS, mutationBurst := cfg.MutationQPS, cfg.MutationBurst
+	if mutationQPS == 0 {
+		mutationQPS, mutationBurst = cfg.QPS, cfg.Burst
+	}
+	limiter = &rateLimiter{config: *cfg, clients: map[clientKey]*bucket{}, lastSweep: now(),
+		globals: map[Class]*bucket{
+			ReadClass:     newBucket(cfg.QPS, cfg.Burst, now()),
+			MutationClass: newBucket(mutationQPS, mutationBurst, now()),
+		}}
+}
+
+// ClassOf get the route class of the http method
+func ClassOf(method string) Class {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return ReadClass
+	default:
+		return MutationClass
+	}
+}
+
+// Result of the flow control, it is used to set the rate limit headers
+type Result struct {
+	Allowed bool
+	// Scope which bucket limits the request, it is the client bucket if the client limit is enabled
+	Scope string
+	// Limit the burst of the bucket
+	Limit int
+	// Remaining the tokens left in the bucket
+	Remaining int
+	// Reset the duration until the bucket is full
+	Reset time.Duration
+	// RetryAfter the duration until the next token is available, it is 0 if the request is allowed
+	RetryAfter time.Duration
+}
+
+// Allow take one token from the bucket of the client and the route class, and one from the global bucket of the route
+// class. The client is the principal name or the source ip of the request.
+func Allow(client string, class Class) Result {
+	mu.RLock()
+	l := limiter
+	mu.RUnlock()
+	if l == nil {
+		return Result{Allowed: true}
+	}
+	return l.allow(clientKey{client: client, class: class}, now())
+}
+
+type clientKey struct {
+	client string
+	class  Class
+}
+
+type rateLimiter struct {
+	sync.Mutex
+	config    Config
+	globals   map[Class]*bucket
+	clients   map[clientKey]*bucket
+	lastSweep time.Time
+}
+
+func (l *rateLimiter) allow(key clientKey, t time.Time) Result {
+	l.Lock()
+	defer l.Unlock()
+	l.sweep(t)
+	client := l.clientBucket(key, t)
+	if client != nil && !client.take(t) {
+		return client.result(false, ClientScope)
+	}
+	global := l.globals[key.class]
+	if !global.take(t) {
+		if client != nil {
+			client.refund()
+		}
+		return global.result(false, GlobalScope)
+	}
+	if client != nil {
+		return client.result(true, ClientScope)
+	}
+	return global.result(true, GlobalScope)
+}
+
+func (l *rateLimiter) clientBucket(key clientKey, t time.Time) *bucket {
+	var qps float64
+	var burst int
+	switch key.class {
+	case ReadClass:
+		qps, burst = l.config.ClientReadQPS, l.config.ClientReadBurst
+	case MutationClass:
+		qps, burst = l.config.ClientMutationQPS, l.config.ClientMutationBurst
+	}
+	if qps <= 0 {
+		return nil
+	}
+	b, ok := l.clients[key]
+	if !ok {
+		b = newBucket(qps, burst, t)
+		l.clients[key] = b
+	}
+	return b
+}
+
+// sweep evict the buckets of the idle clients, thus the buckets do not grow with the source ips
+func (l *rateLimiter) sweep(t time.Time) {
+	if t.Sub(l.lastSweep) < idleTimeout {
+		return
 	}
+	for key, b := range l.clients {
+		if t.Sub(b.last) >= idleTimeout {
+			delete(l.clients, key)
+		}
+	}
+	l.lastSweep = t
+}
+
+// bucket the token bucket which is refilled qps tokens per second up to burst
+type bucket struct {
+	qps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
 }
 
-func Init(fcInit *Config) {
-	if rateLimiter == nil {
-		// init flow controller bucket
-		once.Do(func() {
-			rateLimiter = flowcontrol.NewTokenBucketRateLimiter(float32(fcInit.QPS), fcInit.Burst)
-		})
+func newBucket(qps float64, burst int, t time.Time) *bucket {
+	return &bucket{qps: qps, burst: float64(burst), tokens: float64(burst), last: t}
+}
+
+func (b *bucket) refill(t time.Time) {
+	if t.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+t.Sub(b.last).Seconds()*b.qps)
+		b.last = t
 	}
 }
 
-func TryToPassReq() bool {
-	if !rateLimiter.TryAccept() {
-		klog.Errorf("flow controller tokens exhaust, please try later")
+func (b *bucket) take(t time.Time) bool {
+	b.refill(t)
+	if b.tokens < 1 {
 		return false
 	}
+	b.tokens--
 	return true
 }
+
+func (b *bucket) refund() {
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+func (b *bucket) result(allowed bool, scope string) Result {
+	r := Result{
+		Allowed:   allowed,
+		Scope:     scope,
+		Limit:     int(b.burst),
+		Remaining: int(math.Floor(b.tokens)),
+		Reset:     b.duration(b.burst - b.tokens),
+	}
+	if !allowed {
+		r.RetryAfter = b.duration(1 - b.tokens)
+	}
+	return r
+}
+
+// duration until the tokens are refilled
+func (b *bucket) duration(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / b.qps * float64(time.Second))
+}
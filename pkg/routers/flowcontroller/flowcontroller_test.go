@@ -0,0 +1,111 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowcontroller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	current := time.Unix(0, 0)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now; Init(nil) }()
+	Init(&Config{Enable: true, QPS: 100, Burst: 3, MutationQPS: 100, MutationBurst: 3, ClientReadQPS: 1,
+		ClientReadBurst: 2, ClientMutationQPS: 1, ClientMutationBurst: 1})
+
+	tests := []struct {
+		name          string
+		client        string
+		class         Class
+		advance       time.Duration
+		wantAllowed   bool
+		wantScope     string
+		wantRemaining int
+	}{
+		{name: "first read", client: "a", class: ReadClass, wantAllowed: true, wantScope: ClientScope, wantRemaining: 1},
+		{name: "second read", client: "a", class: ReadClass, wantAllowed: true, wantScope: ClientScope},
+		{name: "read exhausts the client bucket", client: "a", class: ReadClass, wantScope: ClientScope},
+		{name: "mutation has its own bucket", client: "a", class: MutationClass, wantAllowed: true,
+			wantScope: ClientScope},
+		{name: "other client is not affected", client: "b", class: ReadClass, wantAllowed: true,
+			wantScope: ClientScope, wantRemaining: 1},
+		{name: "global bucket exhausts", client: "c", class: ReadClass, wantScope: GlobalScope},
+		{name: "client bucket refills", client: "a", class: ReadClass, advance: time.Second, wantAllowed: true,
+			wantScope: ClientScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current = current.Add(tt.advance)
+			got := Allow(tt.client, tt.class)
+			if got.Allowed != tt.wantAllowed || got.Scope != tt.wantScope || got.Remaining != tt.wantRemaining {
+				t.Errorf("Allow() = %+v, want allowed %v, scope %s and remaining %d", got, tt.wantAllowed,
+					tt.wantScope, tt.wantRemaining)
+			}
+			if !got.Allowed && got.RetryAfter <= 0 {
+				t.Errorf("Allow() RetryAfter = %v, want positive", got.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestAllow_readLoad(t *testing.T) {
+	current := time.Unix(0, 0)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now; Init(nil) }()
+	Init(&Config{Enable: true, QPS: 1, Burst: 2, MutationQPS: 1, MutationBurst: 1})
+
+	for i := 0; i < 2; i++ {
+		if got := Allow("reader", ReadClass); !got.Allowed {
+			t.Fatalf("Allow() read %d = %+v, want allowed", i, got)
+		}
+	}
+	if got := Allow("reader", ReadClass); got.Allowed || got.Scope != GlobalScope {
+		t.Fatalf("Allow() read = %+v, want rejected by the global bucket", got)
+	}
+	if got := Allow("writer", MutationClass); !got.Allowed {
+		t.Errorf("Allow() mutation = %+v, want allowed although the reads use up their global bucket", got)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr bool
+	}{
+		{name: "default", config: DefaultFlowControllerConfig()},
+		{name: "disabled", config: &Config{}},
+		{name: "client limits disabled", config: &Config{Enable: true, QPS: 10, Burst: 30}},
+		{name: "zero global qps", config: &Config{Enable: true, Burst: 30}, wantErr: true},
+		{name: "negative mutation qps", config: &Config{Enable: true, QPS: 10, Burst: 30, MutationQPS: -1},
+			wantErr: true},
+		{name: "zero mutation burst", config: &Config{Enable: true, QPS: 10, Burst: 30, MutationQPS: 1},
+			wantErr: true},
+		{name: "negative client qps", config: &Config{Enable: true, QPS: 10, Burst: 30, ClientReadQPS: -1},
+			wantErr: true},
+		{name: "zero client burst", config: &Config{Enable: true, QPS: 10, Burst: 30, ClientMutationQPS: 1},
+			wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
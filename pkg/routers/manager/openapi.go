@@ -0,0 +1,151 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/kappital/kappital/pkg/apis"
+	tokenv1alpha1 "github.com/kappital/kappital/pkg/apis/apitoken/v1alpha1"
+	infov1alpha1 "github.com/kappital/kappital/pkg/apis/serverinfo/v1alpha1"
+	instancev1alpha1 "github.com/kappital/kappital/pkg/apis/serviceinstance/v1alpha1"
+	"github.com/kappital/kappital/pkg/constants"
+	"github.com/kappital/kappital/pkg/controller/manager"
+	"github.com/kappital/kappital/pkg/models"
+	"github.com/kappital/kappital/pkg/utils/audit"
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/openapi"
+	"github.com/kappital/kappital/pkg/utils/patch"
+)
+
+const (
+	serviceBindingPattern = "/api/v1alpha1/servicebinding/:service_binding"
+	instancePattern       = "/api/v1alpha1/servicebinding/:service_binding/instance/:instance"
+)
+
+var (
+	clusterNameParam = openapi.Query(constants.ClusterNameQueryParam, "The cluster name, default is default.")
+	namespaceParam   = openapi.Query(constants.NamespaceQueryParam, "The namespace, default is default.")
+	timeoutParam     = openapi.Query(constants.TimeoutQueryParam, "Override the delete timeout, such as 10m.")
+	cascadeParam     = openapi.Query(constants.CascadeQueryParam, "Delete with the instances, otherwise 409 if any.")
+	forceParam       = openapi.Query(constants.ForceQueryParam, "Remove without waiting for the cluster, admin only.")
+	finalizersParam  = openapi.Query(constants.RemoveFinalizersQueryParam, "Clear the finalizers, only with force.")
+	ifMatchParam     = openapi.HeaderParam("If-Match", "The expected version of the record, 409 if it is changed.")
+
+	etagHeaders = map[string]openapi.Header{
+		"ETag": {Description: "The version of the record.", Schema: &openapi.Schema{Type: "string"}},
+	}
+	creationBody = map[string]interface{}{patch.JSONType: instancev1alpha1.ServiceInstanceCreation{}}
+	settingsBody = map[string]interface{}{patch.JSONType: apis.Settings{}}
+	patchBody    = map[string]interface{}{
+		patch.MergePatchType: apis.Settings{},
+		patch.JSONPatchType:  []patch.Operation{},
+	}
+)
+
+// registerOpenAPI describe the controller routes in the OpenAPI document and serve it
+func registerOpenAPI() {
+	web.Router("/api/openapi.json", &manager.OpenAPIController{}, "get:GetOpenAPI")
+
+	openapi.Register(
+		openapi.Route{Method: http.MethodPost, Pattern: "/api/v1alpha1/servicebinding",
+			OperationID: "CreateServiceBinding", Tag: "ServiceBinding", Summary: "Deploy the service into the cluster.",
+			Request: creationBody, Response: map[string]string{}},
+		openapi.Route{Method: http.MethodGet, Pattern: "/api/v1alpha1/servicebinding",
+			OperationID: "GetServiceBindings", Tag: "ServiceBinding", Summary: "List the service bindings.",
+			Parameters: []openapi.Parameter{clusterNameParam},
+			Response:   []instancev1alpha1.CloudNativeServiceInstance{}},
+		openapi.Route{Method: http.MethodGet, Pattern: serviceBindingPattern,
+			OperationID: "GetServiceBindingDetail", Tag: "ServiceBinding", Summary: "Get the service binding.",
+			Parameters: []openapi.Parameter{clusterNameParam,
+				openapi.Query(constants.Detail, "Get the status of the resources in the cluster if it is true.")},
+			Response: instancev1alpha1.CloudNativeServiceInstance{}, Headers: etagHeaders},
+		openapi.Route{Method: http.MethodPut, Pattern: serviceBindingPattern,
+			OperationID: "UpdateServiceBinding", Tag: "ServiceBinding", Summary: "Replace the settings.",
+			Parameters: []openapi.Parameter{clusterNameParam, ifMatchParam},
+			Request:    settingsBody, Response: apis.Settings{}, Headers: etagHeaders},
+		openapi.Route{Method: http.MethodPatch, Pattern: serviceBindingPattern,
+			OperationID: "PatchServiceBinding", Tag: "ServiceBinding", Summary: "Edit the settings.",
+			Parameters: []openapi.Parameter{clusterNameParam, ifMatchParam},
+			Request:    patchBody, Response: apis.Settings{}, Headers: etagHeaders},
+		openapi.Route{Method: http.MethodDelete, Pattern: serviceBindingPattern,
+			OperationID: "DeleteServiceBinding", Tag: "ServiceBinding", Summary: "Uninstall the service binding.",
+			Parameters: []openapi.Parameter{clusterNameParam, timeoutParam, cascadeParam, forceParam,
+				finalizersParam, ifMatchParam}},
+	)
+
+	openapi.Register(
+		openapi.Route{Method: http.MethodPost, Pattern: "/api/v1alpha1/servicebinding/:service_binding/instance",
+			OperationID: "CreateInstance", Tag: "Instance", Summary: "Deploy the instances of the service binding.",
+			Parameters: []openapi.Parameter{clusterNameParam}, Request: creationBody, Response: ""},
+		openapi.Route{Method: http.MethodGet, Pattern: "/api/v1alpha1/servicebinding/:service_binding/instance",
+			OperationID: "GetInstances", Tag: "Instance", Summary: "List the instances of the service binding.",
+			Parameters: []openapi.Parameter{clusterNameParam, namespaceParam}, Response: []models.InstanceModel{}},
+		openapi.Route{Method: http.MethodGet, Pattern: instancePattern,
+			OperationID: "GetInstanceDetail", Tag: "Instance", Summary: "Get the instance.",
+			Parameters: []openapi.Parameter{clusterNameParam, namespaceParam},
+			Response:   models.InstanceModel{}, Headers: etagHeaders},
+		openapi.Route{Method: http.MethodPut, Pattern: instancePattern,
+			OperationID: "UpdateInstance", Tag: "Instance", Summary: "Replace the settings.",
+			Parameters: []openapi.Parameter{clusterNameParam, namespaceParam, ifMatchParam},
+			Request:    settingsBody, Response: apis.Settings{}, Headers: etagHeaders},
+		openapi.Route{Method: http.MethodPatch, Pattern: instancePattern,
+			OperationID: "PatchInstance", Tag: "Instance", Summary: "Edit the settings.",
+			Parameters: []openapi.Parameter{clusterNameParam, namespaceParam, ifMatchParam},
+			Request:    patchBody, Response: apis.Settings{}, Headers: etagHeaders},
+		openapi.Route{Method: http.MethodDelete, Pattern: instancePattern,
+			OperationID: "DeleteInstance", Tag: "Instance", Summary: "Uninstall the instance.",
+			Parameters: []openapi.Parameter{clusterNameParam, namespaceParam, timeoutParam, forceParam,
+				finalizersParam, ifMatchParam}},
+	)
+
+	openapi.Register(
+		openapi.Route{Method: http.MethodPost, Pattern: "/api/v1alpha1/tokens",
+			OperationID: "CreateAPIToken", Tag: "APIToken", Summary: "Generate the API token, only for the admin.",
+			Request: map[string]interface{}{patch.JSONType: tokenv1alpha1.APITokenCreation{}},
+			Status:  http.StatusCreated, Response: tokenv1alpha1.APIToken{}},
+		openapi.Route{Method: http.MethodGet, Pattern: "/api/v1alpha1/tokens",
+			OperationID: "GetAPITokens", Tag: "APIToken", Summary: "List the API tokens, only for the admin.",
+			Response: []tokenv1alpha1.APIToken{}},
+		openapi.Route{Method: http.MethodDelete, Pattern: "/api/v1alpha1/tokens/:token",
+			OperationID: "DeleteAPIToken", Tag: "APIToken", Summary: "Revoke the API token, only for the admin."},
+		openapi.Route{Method: http.MethodGet, Pattern: "/api/v1alpha1/errors",
+			OperationID: "GetErrors", Tag: "Meta", Summary: "List the registered errors.",
+			Response: []errors.CatalogItem{}},
+		openapi.Route{Method: http.MethodGet, Pattern: "/api/v1alpha1/info",
+			OperationID: "GetServerInfo", Tag: "Meta", Summary: "Get the version and the capabilities of the manager.",
+			Response: infov1alpha1.ServerInfo{}},
+		openapi.Route{Method: http.MethodGet, Pattern: "/api/v1alpha1/audit",
+			OperationID: "GetAuditLogs", Tag: "Audit", Summary: "Query the audit log, only for the admin.",
+			Parameters: []openapi.Parameter{
+				openapi.Query(constants.SinceQueryParam, "The start of the time range in RFC3339."),
+				openapi.Query(constants.UntilQueryParam, "The end of the time range in RFC3339."),
+				openapi.Query(constants.PrincipalQueryParam, "The principal name."),
+				openapi.Query(constants.RequestIDQueryParam, "The request id."),
+				openapi.Query(constants.ResourceTypeQueryParam, "The audited resource type."),
+				openapi.Query(constants.ResourceNameQueryParam, "The audited resource name."),
+				openapi.Query(constants.TraceTypeQueryParam, "The audit trace type."),
+				openapi.Query(constants.LimitQueryParam, "The max number of the returned entries."),
+			},
+			Response: []audit.Entry{}},
+		openapi.Route{Method: http.MethodGet, Pattern: "/api/openapi.json",
+			OperationID: "GetOpenAPI", Tag: "Meta", Summary: "Get the OpenAPI document.",
+			Response: openapi.Document{}},
+	)
+}
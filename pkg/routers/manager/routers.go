@@ -19,16 +19,24 @@ package manager
 import (
 	"github.com/beego/beego/v2/server/web"
 
+	"github.com/kappital/kappital/pkg/apis"
 	"github.com/kappital/kappital/pkg/controller/manager"
 	"github.com/kappital/kappital/pkg/routers"
+	"github.com/kappital/kappital/pkg/utils/metrics"
 )
 
-// InitRouters init the routers for manager
-func InitRouters() {
+// InitRouters init the routers for manager, the principals are checked if checkIdentity is true
+func InitRouters(checkIdentity bool) {
 	registerServiceBindingAPI()
 	registerInstanceAPI()
+	registerAPITokenAPI()
+	registerErrorCatalogAPI()
+	registerServerInfoAPI()
+	registerAuditAPI()
+	registerMetricsAPI()
+	registerOpenAPI()
 
-	routers.InitFilters()
+	routers.InitFilters(checkIdentity)
 }
 
 func registerServiceBindingAPI() {
@@ -41,6 +49,9 @@ func registerServiceBindingAPI() {
 		"get:GetServiceBindings")
 	web.Router("/api/v1alpha1/servicebinding/:service_binding", &manager.ServiceBindingController{},
 		"get:GetServiceBindingDetail")
+	web.Router("/api/v1alpha1/servicebinding/:service_binding", &manager.ServiceBindingController{},
+		"put:UpdateServiceBinding;patch:PatchServiceBinding")
+	routers.RegisterUpdateSchema("/api/v1alpha1/servicebinding/:service_binding", apis.Settings{})
 }
 
 func registerInstanceAPI() {
@@ -52,4 +63,29 @@ func registerInstanceAPI() {
 		"get:GetInstances")
 	web.Router("/api/v1alpha1/servicebinding/:service_binding/instance/:instance", &manager.InstanceController{},
 		"get:GetInstanceDetail")
+	web.Router("/api/v1alpha1/servicebinding/:service_binding/instance/:instance", &manager.InstanceController{},
+		"put:UpdateInstance;patch:PatchInstance")
+	routers.RegisterUpdateSchema("/api/v1alpha1/servicebinding/:service_binding/instance/:instance", apis.Settings{})
+}
+
+func registerAPITokenAPI() {
+	web.Router("/api/v1alpha1/tokens", &manager.APITokenController{}, "post:CreateAPIToken")
+	web.Router("/api/v1alpha1/tokens", &manager.APITokenController{}, "get:GetAPITokens")
+	web.Router("/api/v1alpha1/tokens/:token", &manager.APITokenController{}, "delete:DeleteAPIToken")
+}
+
+func registerErrorCatalogAPI() {
+	web.Router("/api/v1alpha1/errors", &manager.ErrorCatalogController{}, "get:GetErrors")
+}
+
+func registerServerInfoAPI() {
+	web.Router("/api/v1alpha1/info", &manager.ServerInfoController{}, "get:GetServerInfo")
+}
+
+func registerAuditAPI() {
+	web.Router("/api/v1alpha1/audit", &manager.AuditController{}, "get:GetAuditLogs")
+}
+
+func registerMetricsAPI() {
+	web.Handler("/metrics", metrics.Handler())
 }
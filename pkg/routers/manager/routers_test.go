@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/kappital/kappital/pkg/utils/errors"
+	"github.com/kappital/kappital/pkg/utils/openapi"
+)
+
+// TestInitRouters_OpenAPI fails when the routes of the controllers and the OpenAPI document drift apart
+func TestInitRouters_OpenAPI(t *testing.T) {
+	InitRouters(false)
+	registered := registeredOperations(t)
+	doc := openapi.Spec(openapi.Info{Title: "test", Version: "test"}, errors.ErrorResp{})
+	described := map[string]string{}
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			described[strings.ToUpper(method)+" "+path] = op.OperationID
+		}
+	}
+	for op, controllerMethod := range registered {
+		operationID, ok := described[op]
+		if !ok {
+			t.Errorf("the route %s served by %s is not in the OpenAPI document", op, controllerMethod)
+			continue
+		}
+		if operationID != controllerMethod {
+			t.Errorf("the operationId of %s = %s, but it is served by %s", op, operationID, controllerMethod)
+		}
+	}
+	for op := range described {
+		if _, ok := registered[op]; !ok {
+			t.Errorf("the operation %s in the OpenAPI document is not routed", op)
+		}
+	}
+}
+
+// registeredOperations get the controller methods of the routes by "METHOD path", the handlers are skipped
+func registeredOperations(t *testing.T) map[string]string {
+	data, ok := web.BeeApp.PrintTree()["Data"].(web.M)
+	if !ok {
+		t.Fatalf("cannot get the router tree")
+	}
+	operations := map[string]string{}
+	for method, tree := range data {
+		for _, r := range *tree.(*[][]string) {
+			// the handlers such as /metrics have no controller, they are not the REST API
+			if len(r[2]) == 0 {
+				continue
+			}
+			path, _ := openapi.ConvertPattern(r[0])
+			// the methods are printed as map[GET:GetInstances PUT:UpdateInstance]
+			for _, m := range strings.Fields(strings.TrimSuffix(strings.TrimPrefix(r[1], "map["), "]")) {
+				if kv := strings.SplitN(m, ":", 2); len(kv) == 2 && kv[0] == method {
+					operations[method+" "+path] = kv[1]
+				}
+			}
+		}
+	}
+	return operations
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	prevHashField  = "prev_hash"
+	signatureField = "signature"
+
+	// SignChainTraceName the trace name of the record which signs the hash chain
+	SignChainTraceName = "SignAuditChain"
+)
+
+// hashChain links each record to the hash of the previous one, and signs the chain periodically, thus the edited,
+// inserted or removed records break the chain
+type hashChain struct {
+	lastHash string
+	key      ed25519.PrivateKey
+	// unsigned the number of the records after the last signature
+	unsigned int
+	stopCh   chan struct{}
+}
+
+// newHashChain create the chain which continues from the last record of the audit log file, and load the signing key
+func newHashChain(config AuditLogConfig) (*hashChain, error) {
+	chain := &hashChain{stopCh: make(chan struct{})}
+	if len(config.SigningKeyFile) > 0 {
+		key, err := LoadSigningKey(config.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		chain.key = key
+	}
+	if config.hasFileSink() {
+		lastHash, err := lastRecordHash(config.Filename)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the last record of the audit log, err: %v", err)
+		}
+		chain.lastHash = lastHash
+	}
+	return chain, nil
+}
+
+// link add the hash of the previous record into the entry
+func (c *hashChain) link(entry *logrus.Entry) {
+	entry.Data[prevHashField] = c.lastHash
+}
+
+// append the formatted record into the chain
+func (c *hashChain) append(line []byte) {
+	c.lastHash = hashRecord(line)
+	c.unsigned++
+}
+
+// runSigner sign the chain periodically if there are the unsigned records, until the chain is stopped
+func (d *defaultLog) runSigner(chain *hashChain, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-chain.stopCh:
+			return
+		case <-ticker.C:
+			d.mu.Lock()
+			if d.chain == chain {
+				d.signLocked()
+			}
+			d.mu.Unlock()
+		}
+	}
+}
+
+// signLocked write the record which signs the hash of the last record, the caller must hold the lock
+func (d *defaultLog) signLocked() {
+	chain := d.chain
+	if chain == nil || chain.key == nil || chain.unsigned == 0 {
+		return
+	}
+	signature := ed25519.Sign(chain.key, []byte(chain.lastHash))
+	d.writeLocked(logrus.InfoLevel, AuditLogInfo{
+		Timestamp:   time.Now().Unix(),
+		SourceIP:    "localhost",
+		TraceName:   SignChainTraceName,
+		TraceRating: NormalRating,
+		TraceType:   SystemAction,
+		Message:     fmt.Sprintf("sign the audit chain after %d records", chain.unsigned),
+	}, logrus.Fields{signatureField: base64.StdEncoding.EncodeToString(signature)})
+	chain.unsigned = 0
+}
+
+// hashRecord the hex sha256 of the record without the line break
+func hashRecord(line []byte) string {
+	sum := sha256.Sum256(bytes.TrimRight(line, "\r\n"))
+	return hex.EncodeToString(sum[:])
+}
+
+// lastRecordHash the hash of the last record in the file, or in the newest rotated file if the file is empty
+func lastRecordHash(filename string) (string, error) {
+	files := []string{filename}
+	backups, err := listBackups(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		files = append(files, backups[i].path)
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		lines := bytes.Split(bytes.TrimRight(content, "\r\n"), []byte("\n"))
+		if last := lines[len(lines)-1]; len(last) > 0 {
+			return hashRecord(last), nil
+		}
+	}
+	return "", nil
+}
+
+// LoadSigningKey load the ed25519 private key in PKCS#8 PEM which signs the hash chain
+func LoadSigningKey(filename string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(filename)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the signing key %s, err: %v", filename, err)
+	}
+	signingKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the signing key %s is not an ed25519 private key", filename)
+	}
+	return signingKey, nil
+}
+
+// LoadVerifyingKey load the ed25519 public key in PKIX PEM, or derive it from the private key in PKCS#8 PEM
+func LoadVerifyingKey(filename string) (ed25519.PublicKey, error) {
+	block, err := readPEM(filename)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "PRIVATE KEY" {
+		key, err := LoadSigningKey(filename)
+		if err != nil {
+			return nil, err
+		}
+		return key.Public().(ed25519.PublicKey), nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the verifying key %s, err: %v", filename, err)
+	}
+	verifyingKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the verifying key %s is not an ed25519 public key", filename)
+	}
+	return verifyingKey, nil
+}
+
+func readPEM(filename string) (*pem.Block, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the key %s, err: %v", filename, err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("the key %s is not in PEM", filename)
+	}
+	return block, nil
+}
+
+// BrokenLink the first record which breaks the chain
+type BrokenLink struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+// String of the broken link in file:line: reason
+func (b BrokenLink) String() string {
+	return fmt.Sprintf("%s:%d: %s", b.File, b.Line, b.Reason)
+}
+
+// VerifyReport the result of verifying the hash chain
+type VerifyReport struct {
+	// Records the number of the verified records
+	Records int
+	// Signatures the number of the verified signatures
+	Signatures int
+	// Unsigned the number of the records after the last signature, they can be removed without breaking the chain
+	Unsigned int
+	// FirstPrevHash the prev_hash of the first record, it is not checked because the older records are not given,
+	// thus the records removed before the first given file can only be found by comparing it with the archived ones
+	FirstPrevHash string
+	// Broken the first broken link, nil means the chain is intact
+	Broken *BrokenLink
+}
+
+// VerifyChain verify the hash chain of the files which are given from the oldest to the newest, such as the rotated
+// files and then the current file. The signatures are verified if the key is not nil. The verification stops at the
+// first broken link.
+func VerifyChain(files []string, key ed25519.PublicKey) (*VerifyReport, error) {
+	report := &VerifyReport{}
+	lastHash := ""
+	for _, file := range files {
+		if err := verifyFile(file, key, &lastHash, report); err != nil || report.Broken != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func verifyFile(filename string, key ed25519.PublicKey, lastHash *string, report *VerifyReport) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		broken := func(format string, args ...interface{}) error {
+			report.Broken = &BrokenLink{File: filename, Line: lineNo, Reason: fmt.Sprintf(format, args...)}
+			return nil
+		}
+		line := scanner.Bytes()
+		var entry Entry
+		if err = json.Unmarshal(line, &entry); err != nil {
+			return broken("the record is not in JSON, err: %v", err)
+		}
+		// the previous hash of the first record cannot be checked, because the older records are not given
+		if report.Records == 0 {
+			report.FirstPrevHash = entry.PrevHash
+		} else if entry.PrevHash != *lastHash {
+			return broken("the prev_hash %s does not match the hash %s of the previous record, the records before "+
+				"it are edited, inserted or removed", entry.PrevHash, *lastHash)
+		}
+		if len(entry.Signature) > 0 && key != nil {
+			signature, err := base64.StdEncoding.DecodeString(entry.Signature)
+			if err != nil || !ed25519.Verify(key, []byte(entry.PrevHash), signature) {
+				return broken("the signature is invalid")
+			}
+			report.Signatures++
+			report.Unsigned = 0
+		} else {
+			report.Unsigned++
+		}
+		*lastHash = hashRecord(line)
+		report.Records++
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("cannot read the audit log %s, err: %v", filename, err)
+	}
+	return nil
+}
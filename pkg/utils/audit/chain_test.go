@@ -0,0 +1,177 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSigningKey(t *testing.T, dir string) (string, string) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate the key, err: %v", err)
+	}
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(private)
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+	privateFile, publicFile := filepath.Join(dir, "signing.pem"), filepath.Join(dir, "verifying.pem")
+	if err = ioutil.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		0600); err != nil {
+		t.Fatalf("cannot write the key, err: %v", err)
+	}
+	if err = ioutil.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}),
+		0600); err != nil {
+		t.Fatalf("cannot write the key, err: %v", err)
+	}
+	return privateFile, publicFile
+}
+
+// writeChainedLog write the records with the hash chain, the log is closed and reopened once to continue the chain
+func writeChainedLog(t *testing.T, config AuditLogConfig) {
+	for round := 0; round < 2; round++ {
+		d := &defaultLog{}
+		if err := d.initConfig(config); err != nil {
+			t.Fatalf("initConfig() error = %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			d.info(AuditLogInfo{Principal: "alice", ResourceName: "demo", Message: "success"})
+		}
+		if err := d.close(); err != nil {
+			t.Fatalf("close() error = %v", err)
+		}
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	dir := t.TempDir()
+	signingKey, verifyingKeyFile := writeSigningKey(t, dir)
+	verifyingKey, err := LoadVerifyingKey(verifyingKeyFile)
+	if err != nil {
+		t.Fatalf("LoadVerifyingKey() error = %v", err)
+	}
+	derivedKey, err := LoadVerifyingKey(signingKey)
+	if err != nil || !derivedKey.Equal(verifyingKey) {
+		t.Fatalf("LoadVerifyingKey() from the private key = %v, error = %v", derivedKey, err)
+	}
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	filename := filepath.Join(dir, "audit.log")
+	writeChainedLog(t, AuditLogConfig{AppName: "x", Filename: filename, HashChain: true, SigningKeyFile: signingKey,
+		SignInterval: time.Hour})
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("cannot read the audit log, err: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+
+	tests := []struct {
+		name           string
+		tamper         func(lines [][]byte) [][]byte
+		key            ed25519.PublicKey
+		wantRecords    int
+		wantSignatures int
+		wantBrokenLine int
+	}{
+		{
+			name:           "Test VerifyChain intact",
+			tamper:         func(lines [][]byte) [][]byte { return lines },
+			key:            verifyingKey,
+			wantRecords:    8,
+			wantSignatures: 2,
+		},
+		{
+			name: "Test VerifyChain edited record",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte("alice"), []byte("bob"), 1)
+				return lines
+			},
+			key:            verifyingKey,
+			wantBrokenLine: 3,
+		},
+		{
+			name:           "Test VerifyChain removed record",
+			tamper:         func(lines [][]byte) [][]byte { return append(lines[:2:2], lines[3:]...) },
+			key:            verifyingKey,
+			wantBrokenLine: 3,
+		},
+		{
+			name:           "Test VerifyChain signed by other key",
+			tamper:         func(lines [][]byte) [][]byte { return lines },
+			key:            otherKey,
+			wantBrokenLine: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := make([][]byte, len(lines))
+			for i := range lines {
+				copied[i] = append([]byte(nil), lines[i]...)
+			}
+			file := filepath.Join(t.TempDir(), "audit.log")
+			if err := ioutil.WriteFile(file, append(bytes.Join(tt.tamper(copied), []byte("\n")), '\n'),
+				0600); err != nil {
+				t.Fatalf("cannot write the audit log, err: %v", err)
+			}
+			report, err := VerifyChain([]string{file}, tt.key)
+			if err != nil {
+				t.Fatalf("VerifyChain() error = %v", err)
+			}
+			if tt.wantBrokenLine == 0 {
+				if report.Broken != nil || report.Records != tt.wantRecords || report.Signatures != tt.wantSignatures {
+					t.Errorf("VerifyChain() = %+v, broken %v", report, report.Broken)
+				}
+				return
+			}
+			if report.Broken == nil || report.Broken.Line != tt.wantBrokenLine {
+				t.Errorf("VerifyChain() broken = %v, want line %d", report.Broken, tt.wantBrokenLine)
+			}
+		})
+	}
+}
+
+func TestVerifyChain_rotated(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	writeChainedLog(t, AuditLogConfig{AppName: "x", Filename: filename, MaxSize: 300, HashChain: true})
+	backups, err := listBackups(filename)
+	if err != nil || len(backups) == 0 {
+		t.Fatalf("the audit log should be rotated, err: %v", err)
+	}
+	files := make([]string, 0, len(backups)+1)
+	for _, backup := range backups {
+		files = append(files, backup.path)
+	}
+	report, err := VerifyChain(append(files, filename), nil)
+	if err != nil || report.Broken != nil || report.Records != 6 {
+		t.Errorf("VerifyChain() = %+v, error = %v", report, err)
+	}
+	// the chain is broken if the rotated file is missing
+	if len(files) > 1 {
+		report, _ = VerifyChain(append(files[1:2], filename), nil)
+		if report.Broken == nil || !strings.Contains(report.Broken.Reason, "prev_hash") ||
+			len(report.FirstPrevHash) == 0 {
+			t.Errorf("VerifyChain() without the first rotated file = %+v", report)
+		}
+	}
+}
@@ -17,21 +17,26 @@
 package audit
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-type traceRating string
+// TraceRating the severity of the trace
+type TraceRating string
+
+// TraceType the source of the trace
 type TraceType string
 
 const (
 	// NormalRating of trace
-	NormalRating traceRating = "Normal"
+	NormalRating TraceRating = "Normal"
 	// WarningRating of trace
-	WarningRating traceRating = "Warning"
+	WarningRating TraceRating = "Warning"
 	// IncidentRating of trace
-	IncidentRating traceRating = "Incident"
+	IncidentRating TraceRating = "Incident"
 
 	// ConsoleActionType of trace
 	ConsoleActionType TraceType = "ConsoleAction"
@@ -45,10 +50,13 @@ const (
 type AuditLogInfo struct {
 	Timestamp    int64
 	SourceIP     string
+	Principal    string
+	AuthMethod   string
+	RequestID    string
 	ResourceType string
 	ResourceName string
 	TraceName    string
-	TraceRating  traceRating
+	TraceRating  TraceRating
 	TraceType    TraceType
 	Message      string
 }
@@ -57,6 +65,9 @@ func (a AuditLogInfo) getLogrusFields() logrus.Fields {
 	return logrus.Fields{
 		"timestamp":     a.Timestamp,
 		"source_ip":     a.SourceIP,
+		"principal":     a.Principal,
+		"auth_method":   a.AuthMethod,
+		"request_id":    a.RequestID,
 		"resource_type": a.ResourceType,
 		"resource_name": a.ResourceName,
 		"trace_name":    a.TraceName,
@@ -65,18 +76,103 @@ func (a AuditLogInfo) getLogrusFields() logrus.Fields {
 	}
 }
 
+const (
+	// FileSink writes the audit log into the rotated file, it writes into the stdout if the file name is empty
+	FileSink = "file"
+	// StdoutSink writes the audit log into the stdout
+	StdoutSink = "stdout"
+	// SyslogSink writes the audit log into the syslog
+	SyslogSink = "syslog"
+	// WebhookSink posts the audit log to the HTTP webhook
+	WebhookSink = "webhook"
+)
+
 // AuditLogConfig of audit
 type AuditLogConfig struct {
 	AppName  string
 	Filename string
-	MaxSize  int64
+	// MaxSize the size of the audit log file in bytes which triggers the rotation
+	MaxSize int64
+	// RotationPeriod the duration after which the audit log file is rotated, 0 means only rotating by the size
+	RotationPeriod time.Duration
+	// MaxBackups the number of the rotated files which are kept, 0 means keeping all
+	MaxBackups int
+	// MaxBackupAge the duration which the rotated files are kept, 0 means keeping them forever
+	MaxBackupAge time.Duration
+	// Sinks the names of the sinks which the audit log is written to
+	Sinks []string
+
+	// SyslogNetwork and SyslogAddress of the syslog server, empty means the local syslog
+	SyslogNetwork string
+	SyslogAddress string
+	// SyslogTag the tag of the syslog messages, empty means the app name
+	SyslogTag string
+
+	// WebhookURL the url which the audit log entries are posted to
+	WebhookURL string
+	// WebhookTimeout the timeout of each post
+	WebhookTimeout time.Duration
+
+	// HashChain add the hash of the previous record into each record, thus the edited records can be found
+	HashChain bool
+	// SigningKeyFile the ed25519 private key in PKCS#8 PEM which signs the hash chain, empty means not signing
+	SigningKeyFile string
+	// SignInterval the interval of signing the hash chain
+	SignInterval time.Duration
 }
 
 // DefaultAuditLogConfig get the default audit log config
 func DefaultAuditLogConfig() AuditLogConfig {
 	return AuditLogConfig{
-		AppName:  os.Getenv("APP_NAME"),
-		Filename: "/opt/kappital/audit/audit.log", // Default audit log location
-		MaxSize:  1024 * 1024,                     // Default the audit will be 1M
+		AppName:        os.Getenv("APP_NAME"),
+		Filename:       "/opt/kappital/audit/audit.log", // Default audit log location
+		MaxSize:        1024 * 1024,                     // Default the audit will be 1M
+		RotationPeriod: 24 * time.Hour,
+		MaxBackups:     10,
+		MaxBackupAge:   30 * 24 * time.Hour,
+		Sinks:          []string{FileSink},
+		WebhookTimeout: 5 * time.Second,
+		SignInterval:   5 * time.Minute,
+	}
+}
+
+// Validate the audit log config
+func (c *AuditLogConfig) Validate() error {
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("the max size of the audit log must be positive")
+	}
+	if c.RotationPeriod < 0 || c.MaxBackupAge < 0 || c.MaxBackups < 0 {
+		return fmt.Errorf("the rotation period, max backups and max backup age of the audit log cannot be negative")
+	}
+	if len(c.Sinks) == 0 {
+		return fmt.Errorf("at least one audit log sink is required")
+	}
+	if len(c.SigningKeyFile) > 0 && !c.HashChain {
+		return fmt.Errorf("the signing key of the audit log requires the hash chain")
+	}
+	if c.HashChain && len(c.SigningKeyFile) > 0 && c.SignInterval <= 0 {
+		return fmt.Errorf("the sign interval of the audit log must be positive")
+	}
+	for _, name := range c.Sinks {
+		if !sinkRegistered(name) {
+			return fmt.Errorf("unknown audit log sink %s", name)
+		}
+		if name == WebhookSink && len(c.WebhookURL) == 0 {
+			return fmt.Errorf("the webhook url is required by the webhook audit log sink")
+		}
+	}
+	return nil
+}
+
+// hasFileSink the audit log is written into the file, thus it can be queried
+func (c *AuditLogConfig) hasFileSink() bool {
+	if len(c.Filename) == 0 {
+		return false
+	}
+	for _, name := range c.Sinks {
+		if name == FileSink {
+			return true
+		}
 	}
+	return false
 }
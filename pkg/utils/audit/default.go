@@ -18,61 +18,129 @@ package audit
 
 import (
 	"fmt"
-	"os"
+	"sync"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/klog/v2"
 )
 
 type defaultLog struct {
-	log *logrus.Logger
-}
-
-type defaultHook struct {
-	appName string
-}
-
-// Levels of the hook which watch
-func (d *defaultHook) Levels() []logrus.Level {
-	return []logrus.Level{logrus.InfoLevel, logrus.ErrorLevel, logrus.FatalLevel}
-}
-
-// Fire the entry.Data constance attribute
-func (d *defaultHook) Fire(entry *logrus.Entry) error {
-	entry.Data["app"] = d.appName
-	return nil
+	// mu keeps the order of the entries in all sinks
+	mu        sync.Mutex
+	config    AuditLogConfig
+	formatter logrus.Formatter
+	sinks     map[string]Sink
+	// chain is nil if the hash chain is disabled
+	chain *hashChain
 }
 
 func (d *defaultLog) initConfig(config AuditLogConfig) error {
 	if len(config.AppName) == 0 {
 		return fmt.Errorf("missing the app name, will not use the audit log, and stop the running")
 	}
-	hook := &defaultHook{appName: config.AppName}
-	d.log = logrus.New()
-	// set the output format as json, and not use logrus timestamp format
-	d.log.SetFormatter(&logrus.JSONFormatter{DisableTimestamp: true})
-	d.log.SetLevel(logrus.InfoLevel)
-	d.log.AddHook(hook)
-
-	if len(config.Filename) == 0 {
-		d.log.SetOutput(os.Stdout)
-		return nil
+	if len(config.Sinks) == 0 {
+		config.Sinks = []string{FileSink}
+	}
+	var chain *hashChain
+	if config.HashChain {
+		var err error
+		if chain, err = newHashChain(config); err != nil {
+			return err
+		}
 	}
-	file, err := os.OpenFile(config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	sinks, err := newSinks(config)
 	if err != nil {
 		return err
 	}
-	d.log.SetOutput(file)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.chain != nil {
+		close(d.chain.stopCh)
+	}
+	d.config = config
+	// set the output format as json, and not use logrus timestamp format
+	d.formatter = &logrus.JSONFormatter{DisableTimestamp: true}
+	d.sinks = sinks
+	d.chain = chain
+	if chain != nil && chain.key != nil && config.SignInterval > 0 {
+		go d.runSigner(chain, config.SignInterval)
+	}
 	return nil
 }
 
-func (d defaultLog) info(info AuditLogInfo) {
-	d.log.WithFields(info.getLogrusFields()).Infoln(info.Message)
+func (d *defaultLog) info(info AuditLogInfo) {
+	d.write(logrus.InfoLevel, info)
+}
+
+func (d *defaultLog) error(info AuditLogInfo) {
+	d.write(logrus.WarnLevel, info)
 }
 
-func (d defaultLog) error(info AuditLogInfo) {
-	d.log.WithFields(info.getLogrusFields()).Errorln(info.Message)
+// fault is logged with the error level, the fatal level is not used because it exits the process
+func (d *defaultLog) fault(info AuditLogInfo) {
+	d.write(logrus.ErrorLevel, info)
 }
 
-func (d defaultLog) fault(info AuditLogInfo) {
-	d.log.WithFields(info.getLogrusFields()).Fatalln(info.Message)
+// write format the entry and write it into all sinks, the failure of one sink does not stop the others
+func (d *defaultLog) write(level logrus.Level, info AuditLogInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.writeLocked(level, info, nil)
+}
+
+// writeLocked write the entry with the extra fields, the caller must hold the lock
+func (d *defaultLog) writeLocked(level logrus.Level, info AuditLogInfo, extra logrus.Fields) {
+	if d.formatter == nil {
+		return
+	}
+	entry := logrus.WithFields(info.getLogrusFields()).WithFields(extra)
+	entry.Data["app"] = d.config.AppName
+	entry.Level, entry.Message = level, info.Message
+	if d.chain != nil {
+		d.chain.link(entry)
+	}
+	line, err := d.formatter.Format(entry)
+	if err != nil {
+		klog.Errorf("cannot format the audit log, err: %v", err)
+		return
+	}
+	if d.chain != nil {
+		d.chain.append(line)
+	}
+	for name, sink := range d.sinks {
+		if err = sink.Write(info.TraceRating, line); err != nil {
+			klog.Errorf("cannot write the audit log into the sink %s, err: %v", name, err)
+		}
+	}
+}
+
+func (d *defaultLog) query(filter QueryFilter) ([]Entry, error) {
+	d.mu.Lock()
+	config := d.config
+	d.mu.Unlock()
+	if !config.hasFileSink() {
+		return nil, ErrQueryUnsupported
+	}
+	return queryFiles(config.Filename, filter)
+}
+
+func (d *defaultLog) close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.chain != nil {
+		// sign the last records, thus the whole chain is signed when the server is stopped
+		d.signLocked()
+		close(d.chain.stopCh)
+		d.chain = nil
+	}
+	var closeErr error
+	for name, sink := range d.sinks {
+		// the sinks flush the entries which are not written before closing
+		if err := sink.Close(); err != nil {
+			closeErr = fmt.Errorf("cannot close the audit log sink %s, err: %v", name, err)
+			klog.Error(closeErr)
+		}
+	}
+	d.sinks = nil
+	return closeErr
 }
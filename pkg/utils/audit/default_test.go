@@ -17,12 +17,15 @@
 package audit
 
 import (
+	"encoding/json"
 	"fmt"
-	"github.com/agiledragon/gomonkey/v2"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
-	"github.com/sirupsen/logrus"
+	"github.com/agiledragon/gomonkey/v2"
 )
 
 func Test_defaultLog_initConfig(t *testing.T) {
@@ -31,38 +34,79 @@ func Test_defaultLog_initConfig(t *testing.T) {
 		{Values: gomonkey.Params{&os.File{}, nil}},
 	})
 	defer p.Reset()
-	type fields struct {
-		log *logrus.Logger
-	}
 	type args struct {
 		config AuditLogConfig
 	}
 	tests := []struct {
 		name    string
-		fields  fields
 		args    args
 		wantErr bool
 	}{
 		{
 			name:    "Test defaultLog initConfig (err)",
-			fields:  fields{log: logrus.New()},
 			args:    args{config: AuditLogConfig{AppName: "x", Filename: "xx"}},
 			wantErr: true,
 		},
 		{
-			name:   "Test defaultLog initConfig",
-			fields: fields{log: logrus.New()},
-			args:   args{config: AuditLogConfig{AppName: "x", Filename: "xx"}},
+			name: "Test defaultLog initConfig",
+			args: args{config: AuditLogConfig{AppName: "x", Filename: "xx"}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			d := &defaultLog{
-				log: tt.fields.log,
-			}
+			d := &defaultLog{}
 			if err := d.initConfig(tt.args.config); (err != nil) != tt.wantErr {
 				t.Errorf("initConfig() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_defaultLog_close(t *testing.T) {
+	d := &defaultLog{}
+	if err := d.close(); err != nil {
+		t.Errorf("close() without file error = %v", err)
+	}
+	if err := d.initConfig(AuditLogConfig{AppName: "x", Filename: t.TempDir() + "/audit.log"}); err != nil {
+		t.Fatalf("initConfig() error = %v", err)
+	}
+	d.info(AuditLogInfo{Message: "test"})
+	if err := d.close(); err != nil {
+		t.Errorf("close() error = %v", err)
+	}
+	if d.sinks != nil {
+		t.Errorf("close() should reset the sinks")
+	}
+}
+
+func Test_defaultLog_levels(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	d := &defaultLog{}
+	if err := d.initConfig(AuditLogConfig{AppName: "x", Filename: filename}); err != nil {
+		t.Fatalf("initConfig() error = %v", err)
+	}
+	d.info(AuditLogInfo{TraceRating: NormalRating, Message: "info"})
+	d.error(AuditLogInfo{TraceRating: WarningRating, Message: "error"})
+	d.fault(AuditLogInfo{TraceRating: IncidentRating, Message: "fault"})
+	if err := d.close(); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("cannot read the audit log, err: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	want := []string{"info", "warning", "error"}
+	if len(lines) != len(want) {
+		t.Fatalf("the audit log has %d lines, want %d", len(lines), len(want))
+	}
+	for i, line := range lines {
+		var entry Entry
+		if err = json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("cannot unmarshal the audit log, err: %v", err)
+		}
+		if entry.Level != want[i] || entry.App != "x" {
+			t.Errorf("the entry %d level = %s, app = %s, want level %s", i, entry.Level, entry.App, want[i])
+		}
+	}
+}
@@ -28,3 +28,7 @@ func (f fake) info(AuditLogInfo) {}
 func (f fake) error(AuditLogInfo) {}
 
 func (f fake) fault(AuditLogInfo) {}
+
+func (f fake) query(QueryFilter) ([]Entry, error) { return nil, nil }
+
+func (f fake) close() error { return nil }
@@ -0,0 +1,210 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// backupTimeFormat the time in the name of the rotated file, it is sorted by the name
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// fileSink writes the entries into the file, the file is rotated by the size and the time, and the rotated files are
+// removed by the count and the age
+type fileSink struct {
+	mu             sync.Mutex
+	filename       string
+	maxSize        int64
+	rotationPeriod time.Duration
+	maxBackups     int
+	maxBackupAge   time.Duration
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// newFileSinkFromConfig create the file sink, the entries are written into the stdout if the file name is empty
+func newFileSinkFromConfig(config AuditLogConfig) (Sink, error) {
+	if len(config.Filename) == 0 {
+		return &writerSink{writer: os.Stdout}, nil
+	}
+	f := &fileSink{
+		filename:       config.Filename,
+		maxSize:        config.MaxSize,
+		rotationPeriod: config.RotationPeriod,
+		maxBackups:     config.MaxBackups,
+		maxBackupAge:   config.MaxBackupAge,
+		now:            time.Now,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fileSink) Write(_ TraceRating, line []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	if f.shouldRotate(int64(len(line))) {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+func (f *fileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Sync()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	f.file = nil
+	return err
+}
+
+// shouldRotate the file is rotated before it exceeds the max size or it is opened longer than the rotation period,
+// the empty file is not rotated
+func (f *fileSink) shouldRotate(size int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+size > f.maxSize {
+		return true
+	}
+	return f.rotationPeriod > 0 && f.now().Sub(f.openedAt) >= f.rotationPeriod
+}
+
+func (f *fileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.filename), 0700); err != nil {
+		return fmt.Errorf("cannot create the directory of the audit log, err: %v", err)
+	}
+	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	f.file, f.size, f.openedAt = file, 0, f.now()
+	if info, err := os.Stat(f.filename); err == nil {
+		f.size = info.Size()
+	}
+	return nil
+}
+
+// rotate rename the current file with the rotated time, open the new one, and remove the expired rotated files
+func (f *fileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		klog.Warningf("cannot close the audit log file %s before rotating, err: %v", f.filename, err)
+	}
+	f.file = nil
+	// the rotated time is moved forward if the name is used, thus the rotated files in the same millisecond are kept
+	name := backupName(f.filename, f.now())
+	for rotatedAt := f.now(); fileExists(name); {
+		rotatedAt = rotatedAt.Add(time.Millisecond)
+		name = backupName(f.filename, rotatedAt)
+	}
+	if err := os.Rename(f.filename, name); err != nil {
+		return fmt.Errorf("cannot rotate the audit log file %s, err: %v", f.filename, err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.removeExpiredBackups()
+	return nil
+}
+
+func (f *fileSink) removeExpiredBackups() {
+	backups, err := listBackups(f.filename)
+	if err != nil {
+		klog.Warningf("cannot list the rotated audit log files, err: %v", err)
+		return
+	}
+	for i, backup := range backups {
+		// the backups are sorted from the oldest to the newest
+		expired := f.maxBackups > 0 && i < len(backups)-f.maxBackups
+		if !expired && f.maxBackupAge > 0 {
+			expired = f.now().Sub(backup.rotatedAt) > f.maxBackupAge
+		}
+		if !expired {
+			continue
+		}
+		if err = os.Remove(backup.path); err != nil {
+			klog.Warningf("cannot remove the expired audit log file %s, err: %v", backup.path, err)
+		}
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+type backupFile struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// backupName the name of the rotated file, such as audit-2022-01-02T15-04-05.000.log of audit.log
+func backupName(filename string, rotatedAt time.Time) string {
+	ext := filepath.Ext(filename)
+	prefix := strings.TrimSuffix(filename, ext)
+	return fmt.Sprintf("%s-%s%s", prefix, rotatedAt.UTC().Format(backupTimeFormat), ext)
+}
+
+// listBackups list the rotated files of the file from the oldest to the newest
+func listBackups(filename string) ([]backupFile, error) {
+	ext := filepath.Ext(filename)
+	prefix := strings.TrimSuffix(filepath.Base(filename), ext) + "-"
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		rotatedAt, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(filepath.Dir(filename), name), rotatedAt: rotatedAt})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotatedAt.Before(backups[j].rotatedAt) })
+	return backups, nil
+}
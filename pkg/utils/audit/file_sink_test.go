@@ -0,0 +1,100 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_fileSink_rotate(t *testing.T) {
+	start := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name        string
+		config      AuditLogConfig
+		writes      int
+		step        time.Duration
+		wantBackups int
+	}{
+		{
+			name:        "Test fileSink rotate by size",
+			config:      AuditLogConfig{MaxSize: 20},
+			writes:      5,
+			step:        time.Second,
+			wantBackups: 4,
+		},
+		{
+			name:        "Test fileSink rotate by time",
+			config:      AuditLogConfig{MaxSize: 1024, RotationPeriod: time.Minute},
+			writes:      5,
+			step:        30 * time.Second,
+			wantBackups: 2,
+		},
+		{
+			name:        "Test fileSink remove backups by count",
+			config:      AuditLogConfig{MaxSize: 20, MaxBackups: 2},
+			writes:      5,
+			step:        time.Second,
+			wantBackups: 2,
+		},
+		{
+			name:        "Test fileSink remove backups by age",
+			config:      AuditLogConfig{MaxSize: 20, MaxBackupAge: 30 * time.Second},
+			writes:      5,
+			step:        time.Minute,
+			wantBackups: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			tt.config.Filename = filepath.Join(t.TempDir(), "audit.log")
+			sink, err := newFileSinkFromConfig(tt.config)
+			if err != nil {
+				t.Fatalf("newFileSinkFromConfig() error = %v", err)
+			}
+			f := sink.(*fileSink)
+			f.now, f.openedAt = func() time.Time { return now }, now
+			for i := 0; i < tt.writes; i++ {
+				if err = f.Write(NormalRating, []byte("0123456789abcdef\n")); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				now = now.Add(tt.step)
+			}
+			if err = f.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			backups, err := listBackups(tt.config.Filename)
+			if err != nil {
+				t.Fatalf("listBackups() error = %v", err)
+			}
+			if len(backups) != tt.wantBackups {
+				t.Errorf("the number of backups = %d, want %d", len(backups), tt.wantBackups)
+			}
+		})
+	}
+}
+
+func Test_backupName(t *testing.T) {
+	rotatedAt := time.Date(2022, 1, 2, 3, 4, 5, 6e6, time.UTC)
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	if got, want := backupName(filename, rotatedAt), filepath.Join(filepath.Dir(filename),
+		"audit-2022-01-02T03-04-05.006.log"); got != want {
+		t.Errorf("backupName() = %s, want %s", got, want)
+	}
+}
@@ -28,6 +28,8 @@ type AuditLog interface {
 	info(info AuditLogInfo)
 	error(info AuditLogInfo)
 	fault(info AuditLogInfo)
+	query(filter QueryFilter) ([]Entry, error)
+	close() error
 }
 
 func init() {
@@ -61,7 +63,7 @@ func Error(info AuditLogInfo) {
 	}
 	info.Timestamp = time.Now().Unix()
 	info.TraceRating = WarningRating
-	cmd.info(info)
+	cmd.error(info)
 }
 
 // Fault log of audit
@@ -71,5 +73,15 @@ func Fault(info AuditLogInfo) {
 	}
 	info.Timestamp = time.Now().Unix()
 	info.TraceRating = IncidentRating
-	cmd.info(info)
+	cmd.fault(info)
+}
+
+// Query the audit log entries which match the filter, the newest entries are returned first
+func Query(filter QueryFilter) ([]Entry, error) {
+	return cmd.query(filter)
+}
+
+// Close the audit log, and flush the logs which are not written
+func Close() error {
+	return cmd.close()
 }
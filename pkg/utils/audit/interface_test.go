@@ -17,10 +17,7 @@
 package audit
 
 import (
-	"os"
 	"testing"
-
-	"github.com/agiledragon/gomonkey/v2"
 )
 
 func TestAuditLog(t *testing.T) {
@@ -31,8 +28,6 @@ func TestAuditLog(t *testing.T) {
 		t.Errorf("InitAuditLog error should be nil, but: %v", err)
 		return
 	}
-	p := gomonkey.ApplyFunc(os.Exit, func(_ int) {})
-	defer p.Reset()
 	Info(AuditLogInfo{})
 	Error(AuditLogInfo{})
 	Fault(AuditLogInfo{})
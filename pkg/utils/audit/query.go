@@ -0,0 +1,159 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultQueryLimit the number of the returned entries if the limit is not given
+	DefaultQueryLimit = 100
+	// MaxQueryLimit the max number of the returned entries
+	MaxQueryLimit = 1000
+
+	maxLineSize = 1024 * 1024
+)
+
+// ErrQueryUnsupported the audit log cannot be queried because it is not written into the file
+var ErrQueryUnsupported = errors.New("the audit log can be queried only when it is written into the file")
+
+// Entry of the audit log which is read from the file
+type Entry struct {
+	App          string      `json:"app"`
+	Level        string      `json:"level"`
+	Message      string      `json:"msg"`
+	Timestamp    int64       `json:"timestamp"`
+	SourceIP     string      `json:"source_ip"`
+	Principal    string      `json:"principal"`
+	AuthMethod   string      `json:"auth_method"`
+	RequestID    string      `json:"request_id,omitempty"`
+	ResourceType string      `json:"resource_type"`
+	ResourceName string      `json:"resource_name"`
+	TraceName    string      `json:"trace_name"`
+	TraceRating  TraceRating `json:"trace_rating"`
+	TraceType    TraceType   `json:"trace_type"`
+	PrevHash     string      `json:"prev_hash,omitempty"`
+	Signature    string      `json:"signature,omitempty"`
+}
+
+// QueryFilter of the audit log entries, the empty fields match all entries
+type QueryFilter struct {
+	// Since and Until the time range of the entries, both are included
+	Since time.Time
+	Until time.Time
+	// Principal the name of the principal which does the action
+	Principal string
+	// RequestID the id of the request, the API call and the system actions which are caused by it are matched
+	RequestID string
+	// ResourceType the type of the resource such as Deploy
+	ResourceType string
+	// ResourceName the part of the resource name
+	ResourceName string
+	// TraceType the source of the trace such as ApiCall
+	TraceType TraceType
+	// Limit the max number of the entries, the newest ones are returned
+	Limit int
+}
+
+// Match the entry with the filter
+func (q QueryFilter) Match(entry Entry) bool {
+	if !q.Since.IsZero() && entry.Timestamp < q.Since.Unix() {
+		return false
+	}
+	if !q.Until.IsZero() && entry.Timestamp > q.Until.Unix() {
+		return false
+	}
+	if len(q.Principal) != 0 && entry.Principal != q.Principal {
+		return false
+	}
+	if len(q.RequestID) != 0 && entry.RequestID != q.RequestID {
+		return false
+	}
+	if len(q.ResourceType) != 0 && entry.ResourceType != q.ResourceType {
+		return false
+	}
+	if len(q.ResourceName) != 0 && !strings.Contains(entry.ResourceName, q.ResourceName) {
+		return false
+	}
+	return len(q.TraceType) == 0 || entry.TraceType == q.TraceType
+}
+
+// queryFiles read the rotated files and the current file from the oldest to the newest, and return the newest
+// matched entries first
+func queryFiles(filename string, filter QueryFilter) ([]Entry, error) {
+	if filter.Limit <= 0 || filter.Limit > MaxQueryLimit {
+		filter.Limit = DefaultQueryLimit
+	}
+	backups, err := listBackups(filename)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(backups)+1)
+	for _, backup := range backups {
+		// all entries of the rotated file are written before it is rotated
+		if !filter.Since.IsZero() && backup.rotatedAt.Before(filter.Since) {
+			continue
+		}
+		files = append(files, backup.path)
+	}
+	files = append(files, filename)
+
+	var entries []Entry
+	for _, file := range files {
+		if entries, err = scanFile(file, filter, entries); err != nil {
+			return nil, err
+		}
+	}
+	// reverse the entries, thus the newest one is the first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// scanFile append the matched entries of the file, and keep the newest ones within the limit
+func scanFile(filename string, filter QueryFilter, entries []Entry) ([]Entry, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		// the file may be removed by the rotation
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		// the line which is being written or not in JSON is skipped
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || !filter.Match(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) > filter.Limit {
+			entries = entries[1:]
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_queryFiles(t *testing.T) {
+	start := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	d := &defaultLog{}
+	if err := d.initConfig(AuditLogConfig{AppName: "x", Filename: filename, MaxSize: 400}); err != nil {
+		t.Fatalf("initConfig() error = %v", err)
+	}
+	sink := d.sinks[FileSink].(*fileSink)
+	now := start
+	sink.now, sink.openedAt = func() time.Time { return now }, now
+	for i := 0; i < 10; i++ {
+		principal := "alice"
+		if i%2 == 1 {
+			principal = "bob"
+		}
+		d.info(AuditLogInfo{Timestamp: now.Unix(), Principal: principal, RequestID: fmt.Sprintf("req-%d", i/2),
+			ResourceType: "Deploy", ResourceName: "Instance [demo]", TraceType: APICallType, Message: "success"})
+		now = now.Add(time.Minute)
+	}
+	if backups, _ := listBackups(filename); len(backups) == 0 {
+		t.Fatalf("the audit log should be rotated")
+	}
+
+	tests := []struct {
+		name   string
+		filter QueryFilter
+		want   int
+		first  int64
+	}{
+		{name: "Test query all", filter: QueryFilter{}, want: 10, first: start.Add(9 * time.Minute).Unix()},
+		{name: "Test query by principal", filter: QueryFilter{Principal: "alice"}, want: 5,
+			first: start.Add(8 * time.Minute).Unix()},
+		{name: "Test query by request id", filter: QueryFilter{RequestID: "req-2"}, want: 2,
+			first: start.Add(5 * time.Minute).Unix()},
+		{name: "Test query by time range", filter: QueryFilter{Since: start.Add(2 * time.Minute),
+			Until: start.Add(4 * time.Minute)}, want: 3, first: start.Add(4 * time.Minute).Unix()},
+		{name: "Test query by resource", filter: QueryFilter{ResourceType: "Deploy", ResourceName: "demo"}, want: 10,
+			first: start.Add(9 * time.Minute).Unix()},
+		{name: "Test query by trace type", filter: QueryFilter{TraceType: SystemAction}, want: 0},
+		{name: "Test query with limit", filter: QueryFilter{Limit: 2}, want: 2, first: start.Add(9 * time.Minute).Unix()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := d.query(tt.filter)
+			if err != nil {
+				t.Fatalf("query() error = %v", err)
+			}
+			if len(entries) != tt.want {
+				t.Fatalf("query() returns %d entries, want %d", len(entries), tt.want)
+			}
+			if len(entries) > 0 && entries[0].Timestamp != tt.first {
+				t.Errorf("the first entry timestamp = %d, want %d", entries[0].Timestamp, tt.first)
+			}
+		})
+	}
+
+	if _, err := (&defaultLog{config: AuditLogConfig{Sinks: []string{StdoutSink}}}).query(QueryFilter{}); err == nil {
+		t.Errorf("query() without the file sink should return error")
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink writes the audit log entries to one destination, the entries are written in order
+type Sink interface {
+	// Write the entry which is formatted in one JSON line
+	Write(rating TraceRating, line []byte) error
+	// Close the sink and flush the entries which are not written
+	Close() error
+}
+
+// SinkFactory create the sink from the audit log config
+type SinkFactory func(config AuditLogConfig) (Sink, error)
+
+var (
+	sinkMu        sync.RWMutex
+	sinkFactories = map[string]SinkFactory{}
+)
+
+func init() {
+	RegisterSink(FileSink, newFileSinkFromConfig)
+	RegisterSink(StdoutSink, func(AuditLogConfig) (Sink, error) { return &writerSink{writer: os.Stdout}, nil })
+	RegisterSink(SyslogSink, newSyslogSink)
+	RegisterSink(WebhookSink, newWebhookSink)
+}
+
+// RegisterSink register the sink plugin by the name, the registered one is replaced if the name is used
+func RegisterSink(name string, factory SinkFactory) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	sinkFactories[name] = factory
+}
+
+func sinkRegistered(name string) bool {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	_, ok := sinkFactories[name]
+	return ok
+}
+
+// newSinks create the sinks of the config, the created ones are closed if any of them cannot be created
+func newSinks(config AuditLogConfig) (map[string]Sink, error) {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	sinks := make(map[string]Sink, len(config.Sinks))
+	for _, name := range config.Sinks {
+		if _, ok := sinks[name]; ok {
+			continue
+		}
+		factory, ok := sinkFactories[name]
+		if !ok {
+			closeSinks(sinks)
+			return nil, fmt.Errorf("unknown audit log sink %s", name)
+		}
+		sink, err := factory(config)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, fmt.Errorf("cannot create the audit log sink %s, err: %v", name, err)
+		}
+		sinks[name] = sink
+	}
+	return sinks, nil
+}
+
+func closeSinks(sinks map[string]Sink) {
+	for _, sink := range sinks {
+		_ = sink.Close()
+	}
+}
+
+// writerSink writes the entries into the writer which is not closed by the sink, such as the stdout
+type writerSink struct {
+	writer io.Writer
+}
+
+func (w *writerSink) Write(_ TraceRating, line []byte) error {
+	_, err := w.writer.Write(line)
+	return err
+}
+
+func (w *writerSink) Close() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"log/syslog"
+)
+
+// syslogSink writes the entries into the syslog with the auth facility, the severity follows the trace rating
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(config AuditLogConfig) (Sink, error) {
+	tag := config.SyslogTag
+	if len(tag) == 0 {
+		tag = config.AppName
+	}
+	writer, err := syslog.Dial(config.SyslogNetwork, config.SyslogAddress, syslog.LOG_AUTH|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Write(rating TraceRating, line []byte) error {
+	switch rating {
+	case IncidentRating:
+		return s.writer.Err(string(line))
+	case WarningRating:
+		return s.writer.Warning(string(line))
+	default:
+		return s.writer.Info(string(line))
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
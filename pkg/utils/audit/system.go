@@ -0,0 +1,47 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"github.com/kappital/kappital/pkg/apis"
+)
+
+// SystemActionInfo get the audit info of the system action which the processors do in the background for the
+// request of the initiator
+func SystemActionInfo(initiator apis.Initiator, traceName, resourceType, resourceName string) AuditLogInfo {
+	return AuditLogInfo{
+		Principal:    initiator.Principal,
+		AuthMethod:   initiator.AuthMethod,
+		RequestID:    initiator.RequestID,
+		ResourceType: resourceType,
+		ResourceName: resourceName,
+		TraceName:    traceName,
+		TraceType:    SystemAction,
+	}
+}
+
+// SystemActionLog write the audit log of the system action, and the detail dependents on the error
+func SystemActionLog(initiator apis.Initiator, traceName, resourceType, resourceName string, err error) {
+	info := SystemActionInfo(initiator, traceName, resourceType, resourceName)
+	if err != nil {
+		info.Message = err.Error()
+		Error(info)
+		return
+	}
+	info.Message = "success"
+	Info(info)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kappital/kappital/pkg/apis"
+)
+
+type recordLog struct {
+	fake
+	infos []AuditLogInfo
+}
+
+func (r *recordLog) info(info AuditLogInfo) { r.infos = append(r.infos, info) }
+
+func (r *recordLog) error(info AuditLogInfo) { r.infos = append(r.infos, info) }
+
+func TestSystemActionLog(t *testing.T) {
+	defer SetAuditLog(cmd)
+	initiator := apis.Initiator{Principal: "alice", AuthMethod: "token", RequestID: "0af7651916cd43dd8448eb211c80319c"}
+	tests := []struct {
+		name       string
+		err        error
+		wantRating TraceRating
+		wantMsg    string
+	}{
+		{name: "Test SystemActionLog (success)", wantRating: NormalRating, wantMsg: "success"},
+		{name: "Test SystemActionLog (failed)", err: errors.New("deploy failed"), wantRating: WarningRating,
+			wantMsg: "deploy failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recordLog{}
+			SetAuditLog(r)
+			SystemActionLog(initiator, "DeployServicePackage", "Deploy", "demo", tt.err)
+			if len(r.infos) != 1 {
+				t.Fatalf("SystemActionLog() wrote %d logs, want 1", len(r.infos))
+			}
+			got := r.infos[0]
+			want := AuditLogInfo{
+				Timestamp:    got.Timestamp,
+				SourceIP:     "localhost",
+				Principal:    "alice",
+				AuthMethod:   "token",
+				RequestID:    "0af7651916cd43dd8448eb211c80319c",
+				ResourceType: "Deploy",
+				ResourceName: "demo",
+				TraceName:    "DeployServicePackage",
+				TraceRating:  tt.wantRating,
+				TraceType:    SystemAction,
+				Message:      tt.wantMsg,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("SystemActionLog() got = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// webhookBufferSize the number of the entries which are waiting for posting, the new entries are dropped when the
+// buffer is full, thus the slow webhook cannot block the requests
+const webhookBufferSize = 1024
+
+// webhookSink posts each entry to the webhook in the background
+type webhookSink struct {
+	url     string
+	timeout time.Duration
+	client  *http.Client
+	entries chan []byte
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newWebhookSink(config AuditLogConfig) (Sink, error) {
+	if len(config.WebhookURL) == 0 {
+		return nil, fmt.Errorf("the webhook url is empty")
+	}
+	timeout := config.WebhookTimeout
+	if timeout <= 0 {
+		timeout = DefaultAuditLogConfig().WebhookTimeout
+	}
+	w := &webhookSink{
+		url:     config.WebhookURL,
+		timeout: timeout,
+		client:  &http.Client{Timeout: timeout},
+		entries: make(chan []byte, webhookBufferSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *webhookSink) Write(_ TraceRating, line []byte) error {
+	// the line is copied because the buffer of the formatter is reused
+	entry := append([]byte(nil), line...)
+	select {
+	case w.entries <- entry:
+		return nil
+	default:
+		return fmt.Errorf("the buffer of the webhook is full, the entry is dropped")
+	}
+}
+
+// Close stop accepting the entries, and wait for the buffered entries being posted until the timeout
+func (w *webhookSink) Close() error {
+	w.once.Do(func() { close(w.entries) })
+	select {
+	case <-w.done:
+		return nil
+	case <-time.After(w.timeout):
+		return fmt.Errorf("timeout when posting the buffered entries to the webhook")
+	}
+}
+
+func (w *webhookSink) run() {
+	defer close(w.done)
+	for entry := range w.entries {
+		if err := w.post(entry); err != nil {
+			klog.Errorf("cannot post the audit log to the webhook, err: %v", err)
+		}
+	}
+}
+
+func (w *webhookSink) post(entry []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(entry))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the webhook replies %s", resp.Status)
+	}
+	return nil
+}
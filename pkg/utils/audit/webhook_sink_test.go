@@ -0,0 +1,60 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_webhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	if _, err := newWebhookSink(AuditLogConfig{}); err == nil {
+		t.Errorf("newWebhookSink() without url should return error")
+	}
+	sink, err := newWebhookSink(AuditLogConfig{WebhookURL: server.URL, WebhookTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("newWebhookSink() error = %v", err)
+	}
+	line := []byte(`{"msg":"success"}`)
+	if err = sink.Write(NormalRating, line); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	// the sink should copy the line because the buffer is reused by the caller
+	line[2] = 'x'
+	if err = sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0] != `{"msg":"success"}` {
+		t.Errorf("the webhook received %v", received)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authentication
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MethodCertificate the principal is identified by the client certificate
+	MethodCertificate = "certificate"
+	// MethodToken the principal is identified by the static API token
+	MethodToken = "token"
+	// MethodOIDC the principal is identified by the OIDC ID token
+	MethodOIDC = "oidc"
+
+	// TokenPrefix the prefix of the static API tokens, it is used to distinguish them from the OIDC ID tokens
+	TokenPrefix = "kpt_"
+
+	bearerPrefix = "Bearer "
+	tokenBytes   = 32
+)
+
+var (
+	// ErrUnauthenticated the principal cannot be identified from the request
+	ErrUnauthenticated = errors.New("cannot identify the principal of the request")
+	// ErrTokenNotFound the static API token does not exist or has expired
+	ErrTokenNotFound = errors.New("the API token does not exist or has expired")
+
+	tokenStore TokenStore
+	verifiers  []*oidcVerifier
+	mu         sync.RWMutex
+)
+
+// Principal the identity of the caller
+type Principal struct {
+	// Name the common name of the client certificate, the user of the API token or the user claim of the ID token
+	Name string
+	// Groups the organizational units of the client certificate, the groups of the API token or the groups claim
+	// of the ID token
+	Groups []string
+	// Method how the principal is identified
+	Method string
+}
+
+// String the readable principal for logging
+func (p Principal) String() string {
+	if len(p.Groups) == 0 {
+		return fmt.Sprintf("user %q", p.Name)
+	}
+	return fmt.Sprintf("user %q (groups %s)", p.Name, strings.Join(p.Groups, ", "))
+}
+
+// TokenStore find the principal of the static API token by its SHA-256 digest
+type TokenStore interface {
+	LookupToken(ctx context.Context, hash string) (Principal, error)
+}
+
+// Config of the authentication
+type Config struct {
+	// OIDCConfigFile the path of the OIDC issuers config, the OIDC authentication is disabled if it is empty
+	OIDCConfigFile string
+}
+
+// DefaultAuthenticationConfig get the default authentication config, only the client certificates and the static
+// API tokens are accepted by default
+func DefaultAuthenticationConfig() *Config {
+	return &Config{}
+}
+
+// Init the authenticators, the static API tokens are looked up from the store, and the OIDC ID tokens are verified
+// by the issuers in the OIDC config file
+func Init(cfg *Config, store TokenStore) error {
+	var vs []*oidcVerifier
+	if cfg != nil && len(cfg.OIDCConfigFile) > 0 {
+		oidcCfg, err := LoadOIDCConfig(cfg.OIDCConfigFile)
+		if err != nil {
+			return err
+		}
+		for _, issuer := range oidcCfg.Issuers {
+			v, err := newOIDCVerifier(issuer)
+			if err != nil {
+				return err
+			}
+			vs = append(vs, v)
+		}
+		klog.Infof("OIDC authentication is enabled with %d issuers", len(vs))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	tokenStore, verifiers = store, vs
+	return nil
+}
+
+// Methods the enabled authentication methods, the verified client certificate is always accepted
+func Methods() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	methods := []string{MethodCertificate}
+	if tokenStore != nil {
+		methods = append(methods, MethodToken)
+	}
+	if len(verifiers) > 0 {
+		methods = append(methods, MethodOIDC)
+	}
+	return methods
+}
+
+// Authenticate identify the principal by the bearer token, or the verified client certificate. The bearer token is
+// the static API token if it has the prefix kpt_, otherwise it is verified as the OIDC ID token.
+func Authenticate(r *http.Request) (Principal, error) {
+	if r == nil {
+		return Principal{}, ErrUnauthenticated
+	}
+	return AuthenticateCredentials(r.Context(), r.Header.Get("Authorization"), r.TLS)
+}
+
+// AuthenticateCredentials identify the principal by the value of the Authorization header or metadata, or the
+// verified client certificate of the TLS connection, it is shared by the REST and gRPC APIs
+func AuthenticateCredentials(ctx context.Context, authorization string, state *tls.ConnectionState) (Principal,
+	error) {
+	if len(authorization) > 0 {
+		if !strings.HasPrefix(authorization, bearerPrefix) {
+			return Principal{}, fmt.Errorf("only the bearer token is supported in the Authorization header")
+		}
+		token := strings.TrimSpace(strings.TrimPrefix(authorization, bearerPrefix))
+		if strings.HasPrefix(token, TokenPrefix) {
+			return authenticateToken(ctx, token)
+		}
+		return authenticateIDToken(token)
+	}
+	// only the verified client certificate is trusted, the unverified one may be given by REQUEST_CLIENT_CERT
+	if state != nil && len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+		subject := state.VerifiedChains[0][0].Subject
+		if len(subject.CommonName) > 0 {
+			return Principal{Name: subject.CommonName, Groups: subject.OrganizationalUnit,
+				Method: MethodCertificate}, nil
+		}
+	}
+	return Principal{}, ErrUnauthenticated
+}
+
+func authenticateToken(ctx context.Context, token string) (Principal, error) {
+	mu.RLock()
+	store := tokenStore
+	mu.RUnlock()
+	if store == nil {
+		return Principal{}, ErrTokenNotFound
+	}
+	p, err := store.LookupToken(ctx, HashToken(token))
+	if err != nil {
+		return Principal{}, err
+	}
+	p.Method = MethodToken
+	return p, nil
+}
+
+func authenticateIDToken(token string) (Principal, error) {
+	mu.RLock()
+	vs := verifiers
+	mu.RUnlock()
+	if len(vs) == 0 {
+		return Principal{}, fmt.Errorf("the OIDC authentication is not enabled")
+	}
+	issuer, err := peekIssuer(token)
+	if err != nil {
+		return Principal{}, err
+	}
+	for _, v := range vs {
+		if v.config.Issuer == issuer {
+			return v.verify(token)
+		}
+	}
+	return Principal{}, fmt.Errorf("the issuer %q of the ID token is not trusted", issuer)
+}
+
+// GenerateToken generate a new static API token with the prefix kpt_
+func GenerateToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return TokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken get the hex encoded SHA-256 digest of the token, only the digest is stored
+func HashToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+type principalKey struct{}
+
+// WithPrincipal get a copy of ctx which carries the principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom get the principal carried by ctx
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return Principal{}, false
+	}
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
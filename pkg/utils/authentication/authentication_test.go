@@ -0,0 +1,201 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authentication
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer = "https://issuer.example.com"
+	oidcAlice  = "oidc:" + testIssuer + "#alice"
+)
+
+type fakeTokenStore map[string]Principal
+
+func (f fakeTokenStore) LookupToken(_ context.Context, hash string) (Principal, error) {
+	if p, ok := f[hash]; ok {
+		return p, nil
+	}
+	return Principal{}, ErrTokenNotFound
+}
+
+func encode(v interface{}) string {
+	buf, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encode(map[string]string{"alg": "RS256", "kid": kid}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encode(map[string]string{"alg": "ES256", "kid": kid}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeOIDCConfig(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	dir := t.TempDir()
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+			"y": base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32)))},
+	}}
+	buf, _ := json.Marshal(jwks)
+	jwksFile := filepath.Join(dir, "jwks.json")
+	if err := os.WriteFile(jwksFile, buf, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfgFile := filepath.Join(dir, "oidc.yaml")
+	cfg := fmt.Sprintf("issuers:\n- issuer: %s\n  audiences: [kappital]\n  jwksFile: %s\n", testIssuer, jwksFile)
+	if err := os.WriteFile(cfgFile, []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cfgFile
+}
+
+func TestAuthenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := fakeTokenStore{HashToken(token): {Name: "ci-bot", Groups: []string{"ci"}}}
+	if err = Init(&Config{OIDCConfigFile: writeOIDCConfig(t, rsaKey, ecKey)}, store); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = Init(nil, nil) }()
+
+	now := time.Now().Unix()
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"iss": testIssuer, "aud": "kappital", "sub": "alice",
+			"groups": []string{"team-a"}, "exp": now + 60}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+	bearer := func(token string) *http.Request {
+		return &http.Request{Header: http.Header{"Authorization": {"Bearer " + token}}}
+	}
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantName   string
+		wantMethod string
+		wantErr    bool
+	}{
+		{name: "client certificate", wantName: "Kappital - Client", wantMethod: MethodCertificate,
+			req: &http.Request{Header: http.Header{}, TLS: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{
+				{{Subject: pkix.Name{CommonName: "Kappital - Client"}}}}}}},
+		{name: "unverified client certificate", wantErr: true,
+			req: &http.Request{Header: http.Header{}, TLS: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{
+				{Subject: pkix.Name{CommonName: "Kappital - Client"}}}}}},
+		{name: "api token", req: bearer(token), wantName: "ci-bot", wantMethod: MethodToken},
+		{name: "unknown api token", req: bearer(TokenPrefix + "unknown"), wantErr: true},
+		{name: "rs256 id token", req: bearer(signRS256(t, rsaKey, "rsa", claims(nil))), wantName: oidcAlice,
+			wantMethod: MethodOIDC},
+		{name: "es256 id token", req: bearer(signES256(t, ecKey, "ec", claims(nil))), wantName: oidcAlice,
+			wantMethod: MethodOIDC},
+		{name: "audience array", wantName: oidcAlice, wantMethod: MethodOIDC,
+			req: bearer(signRS256(t, rsaKey, "rsa", claims(map[string]interface{}{"aud": []string{"x", "kappital"}})))},
+		{name: "expired id token", wantErr: true,
+			req: bearer(signRS256(t, rsaKey, "rsa", claims(map[string]interface{}{"exp": now - 3600})))},
+		{name: "untrusted audience", wantErr: true,
+			req: bearer(signRS256(t, rsaKey, "rsa", claims(map[string]interface{}{"aud": "other"})))},
+		{name: "untrusted issuer", wantErr: true,
+			req: bearer(signRS256(t, rsaKey, "rsa", claims(map[string]interface{}{"iss": "https://evil"})))},
+		{name: "wrong signing key", req: bearer(signRS256(t, otherKey, "rsa", claims(nil))), wantErr: true},
+		{name: "unknown key id", req: bearer(signRS256(t, rsaKey, "other", claims(nil))), wantErr: true},
+		{name: "none algorithm", wantErr: true,
+			req: bearer(encode(map[string]string{"alg": "none", "kid": "rsa"}) + "." + encode(claims(nil)) + ".")},
+		{name: "malformed id token", req: bearer("a.b"), wantErr: true},
+		{name: "basic auth", req: &http.Request{Header: http.Header{"Authorization": {"Basic YTpi"}}},
+			wantErr: true},
+		{name: "anonymous", req: &http.Request{Header: http.Header{}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Authenticate(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Name != tt.wantName || got.Method != tt.wantMethod {
+				t.Errorf("Authenticate() = %+v, want name %s and method %s", got, tt.wantName, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	a, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := GenerateToken()
+	if a == b || !strings.HasPrefix(a, TokenPrefix) {
+		t.Errorf("GenerateToken() = %s and %s, want the different tokens with the prefix %s", a, b, TokenPrefix)
+	}
+	if len(HashToken(a)) != 64 {
+		t.Errorf("HashToken() = %s, want the hex encoded SHA-256 digest", HashToken(a))
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authentication
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultUsernameClaim = "sub"
+	defaultGroupsClaim   = "groups"
+
+	// clockSkew the tolerance of the clock difference between the manager and the issuer
+	clockSkew = time.Minute
+)
+
+// signingAlgorithms the accepted signing algorithms of the ID token, the symmetric and none algorithms are refused
+var signingAlgorithms = map[string]bool{
+	string(jose.RS256): true, string(jose.RS384): true, string(jose.RS512): true,
+	string(jose.ES256): true, string(jose.ES384): true, string(jose.ES512): true,
+}
+
+// OIDCConfig the trusted OIDC issuers
+type OIDCConfig struct {
+	Issuers []OIDCIssuer `json:"issuers"`
+}
+
+// OIDCIssuer the OIDC issuer whose ID tokens are trusted. The signing keys are read from the local JWKS file, thus
+// the manager does not need to access the issuer, which is required by the offline environment.
+type OIDCIssuer struct {
+	// Issuer must be equal to the iss claim of the ID token
+	Issuer string `json:"issuer"`
+	// Audiences one of which must be in the aud claim of the ID token, such as the client id
+	Audiences []string `json:"audiences"`
+	// JWKSFile the path of the JSON Web Key Set file of the issuer
+	JWKSFile string `json:"jwksFile"`
+	// UsernameClaim the claim which is used as the principal name, default is sub
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+	// GroupsClaim the claim which is used as the principal groups, default is groups
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+}
+
+// LoadOIDCConfig read and validate the OIDC config file
+func LoadOIDCConfig(path string) (*OIDCConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the OIDC config file %s, err: %v", path, err)
+	}
+	cfg := &OIDCConfig{}
+	if err = yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("cannot parse the OIDC config file %s, err: %v", path, err)
+	}
+	for i, issuer := range cfg.Issuers {
+		if len(issuer.Issuer) == 0 || len(issuer.JWKSFile) == 0 || len(issuer.Audiences) == 0 {
+			return nil, fmt.Errorf("the issuer, audiences and jwksFile of the OIDC issuer %d cannot be empty", i)
+		}
+	}
+	return cfg, nil
+}
+
+type oidcVerifier struct {
+	config OIDCIssuer
+	// keys the public signing keys of the issuer
+	keys jose.JSONWebKeySet
+}
+
+func newOIDCVerifier(cfg OIDCIssuer) (*oidcVerifier, error) {
+	data, err := ioutil.ReadFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the JWKS file %s, err: %v", cfg.JWKSFile, err)
+	}
+	var set jose.JSONWebKeySet
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("cannot parse the JWKS file %s, err: %v", cfg.JWKSFile, err)
+	}
+	v := &oidcVerifier{config: cfg}
+	for _, key := range set.Keys {
+		if len(key.Use) > 0 && key.Use != "sig" {
+			continue
+		}
+		if !key.IsPublic() || !key.Valid() {
+			return nil, fmt.Errorf("the key %q in the JWKS file %s is not a valid public key", key.KeyID,
+				cfg.JWKSFile)
+		}
+		v.keys.Keys = append(v.keys.Keys, key)
+	}
+	if len(v.keys.Keys) == 0 {
+		return nil, fmt.Errorf("there is no signing key in the JWKS file %s", cfg.JWKSFile)
+	}
+	if len(v.config.UsernameClaim) == 0 {
+		v.config.UsernameClaim = defaultUsernameClaim
+	}
+	if len(v.config.GroupsClaim) == 0 {
+		v.config.GroupsClaim = defaultGroupsClaim
+	}
+	return v, nil
+}
+
+// peekIssuer get the iss claim without verifying, it is only used to choose the verifier
+func peekIssuer(token string) (string, error) {
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return "", fmt.Errorf("the ID token is not a valid JWT, err: %v", err)
+	}
+	var claims jwt.Claims
+	if err = tok.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return "", fmt.Errorf("cannot parse the claims of the ID token, err: %v", err)
+	}
+	return claims.Issuer, nil
+}
+
+// verify the signature and the claims of the ID token, and get the principal from the claims. The principal name is
+// prefixed by the issuer, thus the subjects of the different issuers cannot be confused with each other, or with the
+// names of the certificates and the API tokens.
+func (v *oidcVerifier) verify(token string) (Principal, error) {
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("the ID token is not a valid JWT, err: %v", err)
+	}
+	if len(tok.Headers) != 1 || !signingAlgorithms[tok.Headers[0].Algorithm] {
+		return Principal{}, fmt.Errorf("unsupported signing algorithm of the ID token")
+	}
+	key, err := v.signingKey(tok.Headers[0].KeyID)
+	if err != nil {
+		return Principal{}, err
+	}
+	var std jwt.Claims
+	claims := map[string]interface{}{}
+	if err = tok.Claims(key.Key, &std, &claims); err != nil {
+		return Principal{}, fmt.Errorf("the signature of the ID token is invalid")
+	}
+	if err = v.verifyClaims(std, time.Now()); err != nil {
+		return Principal{}, err
+	}
+	name, _ := claims[v.config.UsernameClaim].(string)
+	if len(name) == 0 {
+		return Principal{}, fmt.Errorf("the claim %s of the ID token is empty", v.config.UsernameClaim)
+	}
+	return Principal{Name: MethodOIDC + ":" + v.config.Issuer + "#" + name,
+		Groups: stringsClaim(claims[v.config.GroupsClaim]), Method: MethodOIDC}, nil
+}
+
+// signingKey find the key by the key id, the only key is used if the ID token does not have the key id
+func (v *oidcVerifier) signingKey(kid string) (jose.JSONWebKey, error) {
+	if len(kid) == 0 && len(v.keys.Keys) == 1 {
+		return v.keys.Keys[0], nil
+	}
+	if len(kid) > 0 {
+		if keys := v.keys.Key(kid); len(keys) > 0 {
+			return keys[0], nil
+		}
+	}
+	return jose.JSONWebKey{}, fmt.Errorf("cannot find the signing key %q of the ID token", kid)
+}
+
+func (v *oidcVerifier) verifyClaims(claims jwt.Claims, now time.Time) error {
+	if claims.Expiry == nil {
+		return fmt.Errorf("the ID token does not have the exp claim")
+	}
+	err := claims.ValidateWithLeeway(jwt.Expected{Issuer: v.config.Issuer, Time: now}, clockSkew)
+	switch err {
+	case nil:
+	case jwt.ErrInvalidIssuer:
+		return fmt.Errorf("the issuer %q of the ID token is not trusted", claims.Issuer)
+	case jwt.ErrExpired:
+		return fmt.Errorf("the ID token has expired")
+	case jwt.ErrNotValidYet, jwt.ErrIssuedInTheFuture:
+		return fmt.Errorf("the ID token is not valid yet")
+	default:
+		return fmt.Errorf("the claims of the ID token are invalid, err: %v", err)
+	}
+	for _, expected := range v.config.Audiences {
+		if claims.Audience.Contains(expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the audiences %v of the ID token are not trusted", []string(claims.Audience))
+}
+
+// stringsClaim the claim can be a string or an array of strings
+func stringsClaim(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var result []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
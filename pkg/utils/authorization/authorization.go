@@ -0,0 +1,153 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authorization
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/utils/authentication"
+)
+
+// Verb the action of the request
+type Verb string
+
+const (
+	// VerbGet get or list the resources
+	VerbGet Verb = "get"
+	// VerbCreate deploy the resources
+	VerbCreate Verb = "create"
+	// VerbUpdate upgrade or change the resources
+	VerbUpdate Verb = "update"
+	// VerbDelete uninstall the resources
+	VerbDelete Verb = "delete"
+	// VerbAdmin the administrative operations, only the admin role has it
+	VerbAdmin Verb = "admin"
+)
+
+// AcceptCertificateCommonName the common name of the only client certificate which passes the identity check when
+// the authorization is disabled
+const AcceptCertificateCommonName = "Kappital - Client"
+
+var (
+	policy *Policy
+	mu     sync.RWMutex
+)
+
+// Config of the authorization
+type Config struct {
+	// PolicyFile the path of the policy file, the authorization is disabled if it is empty
+	PolicyFile string
+}
+
+// DefaultAuthorizationConfig get the default authorization config, the authorization is disabled by default
+func DefaultAuthorizationConfig() *Config {
+	return &Config{}
+}
+
+// Init load the policy file and enable the authorization, the authorization is disabled if the policy file is empty
+func Init(cfg *Config) error {
+	if cfg == nil || len(cfg.PolicyFile) == 0 {
+		SetPolicy(nil)
+		return nil
+	}
+	p, err := LoadPolicy(cfg.PolicyFile)
+	if err != nil {
+		return err
+	}
+	SetPolicy(p)
+	klog.Infof("authorization is enabled with the policy file %s, %d bindings are loaded",
+		cfg.PolicyFile, len(p.Bindings))
+	return nil
+}
+
+// SetPolicy replace the policy, the nil policy disables the authorization
+func SetPolicy(p *Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	policy = p
+}
+
+// Enabled does the authorization is enabled
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return policy != nil
+}
+
+// Attributes of the request which are checked by the policy
+type Attributes struct {
+	Verb Verb
+	// Cluster the name of the cluster, empty means all clusters
+	Cluster string
+	// Namespace the namespace of the instances, empty means all namespaces or the cluster level resources
+	Namespace string
+	// Service the name of the service binding, empty means all services
+	Service string
+}
+
+// String the readable attributes for logging
+func (a Attributes) String() string {
+	return fmt.Sprintf("%s service %q in namespace %q of cluster %q", a.Verb, a.Service, a.Namespace, a.Cluster)
+}
+
+// ForbiddenError the principal is not allowed to do the request
+type ForbiddenError struct {
+	Principal  authentication.Principal
+	Attributes Attributes
+}
+
+// Error implements the error interface
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden: %s cannot %s", e.Principal, e.Attributes)
+}
+
+// IsForbidden does the error is returned because the principal is not allowed
+func IsForbidden(err error) bool {
+	var forbidden *ForbiddenError
+	return errors.As(err, &forbidden)
+}
+
+// Authorize check whether the principal is allowed to do the request, it always allows when the authorization is
+// disabled. The ForbiddenError is returned if no binding grants the request.
+func Authorize(p authentication.Principal, attrs Attributes) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	if policy == nil {
+		return nil
+	}
+	for _, binding := range policy.Bindings {
+		if binding.matchSubject(p) && binding.allow(attrs) {
+			klog.V(4).Infof("%s is allowed to %s by the binding %q", p, attrs, binding.Name)
+			return nil
+		}
+	}
+	return &ForbiddenError{Principal: p, Attributes: attrs}
+}
+
+// CheckIdentity does the principal pass the identity check. When the authorization is enabled, any authenticated
+// principal is accepted because the requests are authorized by the policy. Otherwise, only the certificate of the
+// accepted common name is trusted, the API tokens and the ID tokens cannot be used without the policy.
+func CheckIdentity(p authentication.Principal) bool {
+	if Enabled() {
+		return true
+	}
+	return p.Method == authentication.MethodCertificate && p.Name == AcceptCertificateCommonName
+}
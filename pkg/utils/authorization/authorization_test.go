@@ -0,0 +1,144 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authorization
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kappital/kappital/pkg/utils/authentication"
+)
+
+const testPolicy = `
+bindings:
+  - name: admins
+    role: admin
+    subjects:
+      - kind: User
+        name: "Kappital - Client"
+  - name: team-a
+    role: operator
+    subjects:
+      - kind: Group
+        name: team-a
+    scopes:
+      - clusters: ["default"]
+        namespaces: ["team-a"]
+        services: ["*"]
+  - name: ci
+    role: viewer
+    subjects:
+      - kind: Group
+        name: ci
+`
+
+func writePolicy(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInit(t *testing.T) {
+	defer SetPolicy(nil)
+	tests := []struct {
+		name    string
+		policy  string
+		enabled bool
+		wantErr bool
+	}{
+		{name: "disabled", policy: "", enabled: false},
+		{name: "valid policy", policy: writePolicy(t, testPolicy), enabled: true},
+		{name: "unknown role", policy: writePolicy(t, "bindings:\n- role: root\n  subjects:\n  - {kind: User, name: a}\n"),
+			wantErr: true},
+		{name: "unknown subject kind", policy: writePolicy(t, "bindings:\n- role: admin\n  subjects:\n  - {kind: Team, name: a}\n"),
+			wantErr: true},
+		{name: "empty subjects", policy: writePolicy(t, "bindings:\n- role: admin\n  subjects: []\n"),
+			wantErr: true},
+		{name: "unknown field", policy: writePolicy(t, "rules: []\n"), wantErr: true},
+		{name: "missing file", policy: filepath.Join(t.TempDir(), "missing.yaml"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPolicy(nil)
+			err := Init(&Config{PolicyFile: tt.policy})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && Enabled() != tt.enabled {
+				t.Errorf("Enabled() = %v, want %v", Enabled(), tt.enabled)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	defer SetPolicy(nil)
+	if err := Init(&Config{PolicyFile: writePolicy(t, testPolicy)}); err != nil {
+		t.Fatal(err)
+	}
+	admin := authentication.Principal{Name: "Kappital - Client"}
+	operator := authentication.Principal{Name: "alice", Groups: []string{"team-a"}}
+	viewer := authentication.Principal{Name: "ci-bot", Groups: []string{"ci"}}
+	tests := []struct {
+		name      string
+		principal authentication.Principal
+		attrs     Attributes
+		allowed   bool
+	}{
+		{name: "admin can do admin operations", principal: admin, attrs: Attributes{Verb: VerbAdmin}, allowed: true},
+		{name: "operator deletes in scope", principal: operator, allowed: true,
+			attrs: Attributes{Verb: VerbDelete, Cluster: "default", Namespace: "team-a", Service: "redis"}},
+		{name: "operator deletes out of namespace", principal: operator,
+			attrs: Attributes{Verb: VerbDelete, Cluster: "default", Namespace: "team-b", Service: "redis"}},
+		{name: "operator deletes out of cluster", principal: operator,
+			attrs: Attributes{Verb: VerbDelete, Cluster: "prod", Namespace: "team-a", Service: "redis"}},
+		{name: "operator deletes the service binding", principal: operator,
+			attrs: Attributes{Verb: VerbDelete, Cluster: "default", Service: "redis"}},
+		{name: "operator cannot do admin operations", principal: operator, attrs: Attributes{Verb: VerbAdmin}},
+		{name: "viewer gets", principal: viewer, attrs: Attributes{Verb: VerbGet, Cluster: "prod"}, allowed: true},
+		{name: "viewer cannot create", principal: viewer, attrs: Attributes{Verb: VerbCreate, Cluster: "prod"}},
+		{name: "unknown principal", principal: authentication.Principal{Name: "bob"}, attrs: Attributes{Verb: VerbGet}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.principal, tt.attrs)
+			if (err == nil) != tt.allowed {
+				t.Errorf("Authorize() error = %v, allowed %v", err, tt.allowed)
+			}
+			if err != nil && !IsForbidden(err) {
+				t.Errorf("Authorize() error = %v, want ForbiddenError", err)
+			}
+		})
+	}
+}
+
+func TestCheckIdentity(t *testing.T) {
+	defer SetPolicy(nil)
+	client := authentication.Principal{Name: AcceptCertificateCommonName, Method: authentication.MethodCertificate}
+	other := authentication.Principal{Name: "alice", Method: authentication.MethodCertificate}
+	token := authentication.Principal{Name: "alice", Method: authentication.MethodToken}
+	if !CheckIdentity(client) || CheckIdentity(other) || CheckIdentity(token) {
+		t.Errorf("CheckIdentity() is wrong when the authorization is disabled")
+	}
+	SetPolicy(&Policy{})
+	if !CheckIdentity(other) || !CheckIdentity(token) {
+		t.Errorf("CheckIdentity() rejects the authenticated principal when the authorization is enabled")
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package authorization
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kappital/kappital/pkg/utils/authentication"
+)
+
+// Role the set of the verbs which can be granted to the principals
+type Role string
+
+const (
+	// RoleViewer can only get the service bindings and the instances
+	RoleViewer Role = "viewer"
+	// RoleOperator can get, deploy, upgrade and delete the service bindings and the instances
+	RoleOperator Role = "operator"
+	// RoleAdmin can do anything, including the administrative operations
+	RoleAdmin Role = "admin"
+)
+
+// SubjectKind the kind of the subject in the role binding
+type SubjectKind string
+
+const (
+	// SubjectUser matches the principal name, which is the common name of the client certificate, the user of the
+	// API token or the user claim of the OIDC ID token
+	SubjectUser SubjectKind = "User"
+	// SubjectGroup matches the principal groups, which are the organizational units of the client certificate, the
+	// groups of the API token or the groups claim of the OIDC ID token
+	SubjectGroup SubjectKind = "Group"
+)
+
+// Wildcard matches any value in the scope
+const Wildcard = "*"
+
+var roleVerbs = map[Role][]string{
+	RoleViewer:   {string(VerbGet)},
+	RoleOperator: {string(VerbGet), string(VerbCreate), string(VerbUpdate), string(VerbDelete)},
+	RoleAdmin:    {Wildcard},
+}
+
+// Policy the authorization policy of the manager API, it is loaded from the policy file
+type Policy struct {
+	// Bindings grant the roles to the subjects
+	Bindings []RoleBinding `json:"bindings"`
+}
+
+// RoleBinding grants the role to the subjects in the scopes
+type RoleBinding struct {
+	// Name of the binding, it is only used for logging
+	Name string `json:"name,omitempty"`
+	// Role granted to the subjects
+	Role Role `json:"role"`
+	// Subjects the users and groups which are granted the role
+	Subjects []Subject `json:"subjects"`
+	// Scopes restrict where the role takes effect, the role takes effect everywhere if it is empty
+	Scopes []Scope `json:"scopes,omitempty"`
+}
+
+// Subject the user or group in the role binding
+type Subject struct {
+	Kind SubjectKind `json:"kind"`
+	Name string      `json:"name"`
+}
+
+// Scope of the role binding, the empty list or "*" matches any value
+type Scope struct {
+	Clusters   []string `json:"clusters,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Services   []string `json:"services,omitempty"`
+}
+
+// LoadPolicy read and validate the policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the authorization policy file %s, err: %v", path, err)
+	}
+	policy := &Policy{}
+	if err = yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("cannot parse the authorization policy file %s, err: %v", path, err)
+	}
+	if err = policy.Validate(); err != nil {
+		return nil, fmt.Errorf("the authorization policy file %s is invalid, err: %v", path, err)
+	}
+	return policy, nil
+}
+
+// Validate the roles and subjects of the policy
+func (p *Policy) Validate() error {
+	for i, binding := range p.Bindings {
+		if _, ok := roleVerbs[binding.Role]; !ok {
+			return fmt.Errorf("the role %q of the binding %d is unknown, it should be one of %s, %s and %s",
+				binding.Role, i, RoleViewer, RoleOperator, RoleAdmin)
+		}
+		if len(binding.Subjects) == 0 {
+			return fmt.Errorf("the binding %d has no subject", i)
+		}
+		for _, subject := range binding.Subjects {
+			if subject.Kind != SubjectUser && subject.Kind != SubjectGroup {
+				return fmt.Errorf("the subject kind %q of the binding %d is unknown, it should be %s or %s",
+					subject.Kind, i, SubjectUser, SubjectGroup)
+			}
+			if len(subject.Name) == 0 {
+				return fmt.Errorf("the subject name of the binding %d is empty", i)
+			}
+		}
+	}
+	return nil
+}
+
+// matchSubject does the principal is one of the subjects
+func (b RoleBinding) matchSubject(p authentication.Principal) bool {
+	for _, subject := range b.Subjects {
+		switch subject.Kind {
+		case SubjectUser:
+			if subject.Name == p.Name {
+				return true
+			}
+		case SubjectGroup:
+			for _, group := range p.Groups {
+				if subject.Name == group {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// allow does the binding grant the verb on the resource
+func (b RoleBinding) allow(attrs Attributes) bool {
+	if !matchValue(roleVerbs[b.Role], string(attrs.Verb)) {
+		return false
+	}
+	if len(b.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range b.Scopes {
+		if matchValue(scope.Clusters, attrs.Cluster) && matchValue(scope.Namespaces, attrs.Namespace) &&
+			matchValue(scope.Services, attrs.Service) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue the empty list or "*" matches any value, otherwise the value must be in the list. The empty value means
+// the request covers all values, thus it only matches the wildcard.
+func matchValue(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == Wildcard || (len(value) > 0 && item == value) {
+			return true
+		}
+	}
+	return false
+}
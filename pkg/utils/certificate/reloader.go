@@ -0,0 +1,201 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package certificate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/klog/v2"
+)
+
+// resyncPeriod the interval of checking the files without the file events, in case of the events are missed
+const resyncPeriod = time.Minute
+
+// GetCertificateFunc get the server certificate for the TLS handshake
+type GetCertificateFunc func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+
+// StaticCertificate the GetCertificateFunc of the certificate which will never be changed
+func StaticCertificate(cert, key []byte) (GetCertificateFunc, error) {
+	certificate, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+	return func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return &certificate, nil
+	}, nil
+}
+
+// Reloader keeps the server certificate and the client ca bundle loaded from the files, and reloads them when the
+// files are changed, thus the certificates rotated by such as cert-manager can be used without restarting
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	checksum  []byte
+}
+
+// NewReloader load the certificate, the key and the ca bundle, the caFile can be empty if the client certificates
+// are not verified
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload read the files again, and replace the certificate and the ca bundle if they are changed and valid. The
+// current ones are kept if any file is invalid, such as the certificate is rotated but the key is not yet.
+func (r *Reloader) Reload() (bool, error) {
+	files := []string{r.certFile, r.keyFile}
+	if len(r.caFile) > 0 {
+		files = append(files, r.caFile)
+	}
+	contents := make([][]byte, len(files))
+	hash := sha256.New()
+	for i, name := range files {
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return false, fmt.Errorf("cannot read the file %s, err: %v", name, err)
+		}
+		contents[i] = content
+		hash.Write(content)
+	}
+	checksum := hash.Sum(nil)
+	r.mu.RLock()
+	unchanged := bytes.Equal(checksum, r.checksum)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return false, fmt.Errorf("cannot load the key pair %s and %s, err: %v", r.certFile, r.keyFile, err)
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return false, fmt.Errorf("cannot parse the certificate %s, err: %v", r.certFile, err)
+	}
+	var pool *x509.CertPool
+	if len(r.caFile) > 0 {
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(contents[2]) {
+			return false, fmt.Errorf("cannot find any certificate in the ca bundle %s", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.checksum = &cert, pool, checksum
+	r.mu.Unlock()
+	klog.Infof("loaded the certificate %s which expires at %s", r.certFile, cert.Leaf.NotAfter.Format(time.RFC3339))
+	return true, nil
+}
+
+// GetCertificate get the current server certificate, it can be used as tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// ClientCAs get the current ca bundle of the client certificates
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clientCAs
+}
+
+// TLSConfig copy the base config, and use the current certificate and ca bundle for each handshake. The config of
+// the handshake is decided by GetConfigForClient, because http.Server.ListenAndServeTLS fills the Certificates with
+// the files read once, and the Certificates are preferred to GetCertificate if the client does not send the SNI.
+func (r *Reloader) TLSConfig(base *tls.Config) *tls.Config {
+	cfg := base.Clone()
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+	cfg.Certificates, cfg.GetCertificate = nil, r.GetCertificate
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := cfg.Clone()
+		handshake.Certificates, handshake.GetConfigForClient = nil, nil
+		if len(r.caFile) > 0 {
+			handshake.ClientCAs = r.ClientCAs()
+		}
+		return handshake, nil
+	}
+	return cfg
+}
+
+// Watch reload the files when they or their directories are changed until the stopCh is closed. The directories
+// are watched, because the mounted Secret is updated by replacing the symlink of its directory.
+func (r *Reloader) Watch(stopCh <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("cannot create the file watcher, err: %v", err)
+	}
+	defer watcher.Close()
+	dirs := map[string]struct{}{}
+	for _, name := range []string{r.certFile, r.keyFile, r.caFile} {
+		if len(name) == 0 {
+			continue
+		}
+		dir := filepath.Dir(name)
+		if _, ok := dirs[dir]; ok {
+			continue
+		}
+		if err = watcher.Add(dir); err != nil {
+			return fmt.Errorf("cannot watch the directory %s, err: %v", dir, err)
+		}
+		dirs[dir] = struct{}{}
+	}
+
+	ticker := time.NewTicker(resyncPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			klog.V(4).Infof("got the file event %s", event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			klog.Warningf("the certificate file watcher got error: %v", err)
+			continue
+		case <-ticker.C:
+		}
+		if _, err = r.Reload(); err != nil {
+			klog.Errorf("cannot reload the certificate, keep the current one, err: %v", err)
+		}
+	}
+}
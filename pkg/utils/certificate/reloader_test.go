@@ -0,0 +1,149 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package certificate
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kappital/kappital/pkg/utils/cryption"
+)
+
+func writeKeyPair(t *testing.T, dir string) []byte {
+	cert, key, err := cryption.GetSelfCertAndKey()
+	if err != nil {
+		t.Fatalf("cannot create the self certificate, err: %v", err)
+	}
+	for name, content := range map[string][]byte{"tls.crt": cert, "tls.key": key, "ca.crt": cert} {
+		if err = os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cert
+}
+
+func currentCertificate(t *testing.T, r *Reloader) []byte {
+	cert, err := r.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	return cert.Certificate[0]
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"),
+		filepath.Join(dir, "ca.crt")
+	r, err := NewReloader(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	first := currentCertificate(t, r)
+	if r.ClientCAs() == nil {
+		t.Errorf("ClientCAs() should not be nil")
+	}
+	if changed, err := r.Reload(); changed || err != nil {
+		t.Errorf("Reload() without changes got changed = %v, err = %v", changed, err)
+	}
+
+	writeKeyPair(t, dir)
+	if changed, err := r.Reload(); !changed || err != nil {
+		t.Errorf("Reload() with the rotated certificate got changed = %v, err = %v", changed, err)
+	}
+	second := currentCertificate(t, r)
+	if bytes.Equal(first, second) {
+		t.Errorf("the certificate should be replaced by the rotated one")
+	}
+
+	// the certificate is rotated but the key is not yet
+	cert, _, err := cryption.GetSelfCertAndKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(certFile, cert, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Reload(); err == nil {
+		t.Errorf("Reload() with the mismatched key pair should return error")
+	}
+	if !bytes.Equal(second, currentCertificate(t, r)) {
+		t.Errorf("the current certificate should be kept when the new one is invalid")
+	}
+
+	if _, err = NewReloader(certFile, keyFile, filepath.Join(dir, "missing.crt")); err == nil {
+		t.Errorf("NewReloader() with the missing ca bundle should return error")
+	}
+}
+
+func TestReloader_TLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir)
+	r, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	cfg := r.TLSConfig(&tls.Config{MinVersion: tls.VersionTLS12, ClientAuth: tls.RequireAndVerifyClientCert})
+	// the Certificates are filled by http.Server.ListenAndServeTLS
+	cfg.Certificates = []tls.Certificate{{}}
+	handshake, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetConfigForClient() error = %v", err)
+	}
+	if len(handshake.Certificates) != 0 || handshake.GetCertificate == nil || handshake.GetConfigForClient != nil {
+		t.Errorf("the handshake config should only use GetCertificate, got %+v", handshake)
+	}
+	if handshake.ClientCAs != r.ClientCAs() || handshake.ClientAuth != tls.RequireAndVerifyClientCert ||
+		handshake.MinVersion != tls.VersionTLS12 {
+		t.Errorf("the handshake config should keep the base config with the current ca bundle, got %+v", handshake)
+	}
+}
+
+func TestReloader_Watch(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir)
+	r, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), "")
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	stopCh, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := r.Watch(stopCh); err != nil {
+			t.Errorf("Watch() error = %v", err)
+		}
+	}()
+	defer func() {
+		close(stopCh)
+		<-done
+	}()
+	// wait for the watcher registering the directory
+	time.Sleep(100 * time.Millisecond)
+	block, _ := pem.Decode(writeKeyPair(t, dir))
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if bytes.Equal(currentCertificate(t, r), block.Bytes) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Errorf("the rotated certificate should be reloaded by the watcher")
+}
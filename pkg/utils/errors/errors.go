@@ -35,13 +35,44 @@ var (
 
 	// ErrDataUnmarshal will happen when translate the string to the struct, or cannot translate structure from one type to the other.
 	ErrDataUnmarshal = newKappError(commonErrCode, http.StatusBadRequest, 1, "Data unmarshal error.")
+	// ErrResourceConflict the resource has been modified by others, or the If-Match precondition is not satisfied.
+	ErrResourceConflict = newKappError(commonErrCode, http.StatusConflict, 2, "Resource version conflict.")
+	// ErrUnauthorized the caller cannot be identified by the client certificate or the bearer token.
+	ErrUnauthorized = newKappError(commonErrCode, http.StatusUnauthorized, 3, "Unauthorized.")
+	// ErrForbidden the caller is not allowed to do the request by the authorization policy.
+	ErrForbidden = newKappError(commonErrCode, http.StatusForbidden, 4, "Forbidden.")
+	// ErrInternal the unexpected failure of the manager, such as the database is unavailable.
+	ErrInternal = newKappError(commonErrCode, http.StatusInternalServerError, 5, "Internal server error.")
+	// ErrNotFound the requested resource or url does not exist.
+	ErrNotFound = newKappError(commonErrCode, http.StatusNotFound, 6, "Resource not found.")
+	// ErrTooManyRequests the request is rejected by the flow controller, please retry after the Retry-After seconds.
+	ErrTooManyRequests = newKappError(commonErrCode, http.StatusTooManyRequests, 7, "Too many requests.")
+	// ErrIllegalParameters the path or query parameters of the request are illegal.
+	ErrIllegalParameters = newKappError(commonErrCode, http.StatusBadRequest, 8, "Illegal parameters.")
+	// ErrMethodNotAllowed the http method is not supported by the manager.
+	ErrMethodNotAllowed = newKappError(commonErrCode, http.StatusMethodNotAllowed, 9, "Method not allowed.")
+	// ErrRequestTooLarge the request body exceeds the size limitation.
+	ErrRequestTooLarge = newKappError(commonErrCode, http.StatusRequestEntityTooLarge, 10, "Request body too large.")
+	// ErrUnsupportedMediaType the content type of the request body is not supported by the api.
+	ErrUnsupportedMediaType = newKappError(commonErrCode, http.StatusUnsupportedMediaType, 11, "Unsupported media type.")
+	// ErrInvalidBody the request body does not match the schema of the resource, or the patch cannot be applied.
+	ErrInvalidBody = newKappError(commonErrCode, http.StatusUnprocessableEntity, 12, "Request body is invalid.")
+	// ErrDeletionProtected the service binding or the instance cannot be deleted while its deletion protection is
+	// enabled, it must be disabled by updating the settings first.
+	ErrDeletionProtected = newKappError(commonErrCode, http.StatusConflict, 13, "Deletion protection is enabled.")
 
 	// ErrServiceInstall has some problem for CloudNativeService deploying failed. May because of cluster disconnection, or cluster limitation problems.
-	ErrServiceInstall = newKappError(serviceErrCode, http.StatusBadRequest, 2, "Service install error.")
+	ErrServiceInstall = newKappError(serviceErrCode, http.StatusInternalServerError, 2, "Service install error.")
 	// ErrServiceParam cannot analysis or get the param from request
 	ErrServiceParam = newKappError(serviceErrCode, http.StatusBadRequest, 3, "Parameter is invalid.")
 	// ErrServiceDelete cannot delete the service in cluster
-	ErrServiceDelete = newKappError(serviceErrCode, http.StatusBadRequest, 4, "ServiceBinding delete error.")
+	ErrServiceDelete = newKappError(serviceErrCode, http.StatusInternalServerError, 4, "ServiceBinding delete error.")
+	// ErrServiceHasInstances the service binding cannot be deleted while it has instances, unless it is deleted with
+	// cascade
+	ErrServiceHasInstances = newKappError(serviceErrCode, http.StatusConflict, 5, "ServiceBinding has instances.")
+	// ErrServiceDeleting the instances cannot be deployed into the service binding which is being deleted, they would
+	// be left without the service binding
+	ErrServiceDeleting = newKappError(serviceErrCode, http.StatusConflict, 6, "ServiceBinding is deleting.")
 
 	// ErrServiceInstanceCreate cannot deploy the user's instance into cluster
 	ErrServiceInstanceCreate = newKappError(serviceInstanceErrCode, http.StatusInternalServerError, 1, "Service Instance create error.")
@@ -62,6 +93,14 @@ type ErrorResp struct {
 	ErrorCode string `json:"errorCode"`
 	Message   string `json:"errorMsg"`
 	Reason    string `json:"reason,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// CatalogItem the registered error in the catalog
+type CatalogItem struct {
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"errorMsg"`
+	HTTPCode  int    `json:"httpCode"`
 }
 
 type errorImpl struct {
@@ -102,10 +141,12 @@ func (e *errorImpl) GetErrorCode() string {
 	return fmt.Sprintf("%s%04d%04d", errPrefix, e.module, e.errorIndex)
 }
 
-// WrapErrorReasonWith add the error reasons to the current error
+// WrapErrorReasonWith get a copy of the error with the reason, the registered error is not changed because it is
+// shared by the concurrent requests
 func (e *errorImpl) WrapErrorReasonWith(format string, args ...interface{}) KappError {
-	e.reason = fmt.Sprintf(format, args...)
-	return e
+	wrapped := *e
+	wrapped.reason = fmt.Sprintf(format, args...)
+	return &wrapped
 }
 
 // GetResp get the response message and error
@@ -121,3 +162,13 @@ func (e *errorImpl) GetResp() ErrorResp {
 func (e *errorImpl) TypeEqual(err KappError) bool {
 	return e.GetErrorCode() == err.GetErrorCode()
 }
+
+// Catalog get all registered errors, thus the clients can branch on the error codes
+func Catalog() []CatalogItem {
+	items := make([]CatalogItem, 0, len(errArray))
+	for _, err := range errArray {
+		items = append(items, CatalogItem{ErrorCode: err.GetErrorCode(), Message: err.errMsg,
+			HTTPCode: err.httpErrorCode})
+	}
+	return items
+}
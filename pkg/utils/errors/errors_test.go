@@ -0,0 +1,53 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestKappError_WrapErrorReasonWith(t *testing.T) {
+	wrapped := ErrNotFound.WrapErrorReasonWith("the instance %s is not found", "a")
+	if ErrNotFound.GetResp().Reason != "" {
+		t.Errorf("the registered error is changed, reason = %s", ErrNotFound.GetResp().Reason)
+	}
+	resp := wrapped.GetResp()
+	if resp.ErrorCode != "KAPPITAL.01000006" || resp.Reason != "the instance a is not found" {
+		t.Errorf("GetResp() = %+v, want the code KAPPITAL.01000006 with the reason", resp)
+	}
+	if !wrapped.TypeEqual(ErrNotFound) || wrapped.GetHTTPCode() != http.StatusNotFound {
+		t.Errorf("the wrapped error should keep the type and http code of %v", ErrNotFound)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	items := Catalog()
+	if len(items) != len(errArray) {
+		t.Fatalf("Catalog() has %d items, want %d", len(items), len(errArray))
+	}
+	codes := map[string]bool{}
+	for _, item := range items {
+		if codes[item.ErrorCode] {
+			t.Errorf("the error code %s is registered more than once", item.ErrorCode)
+		}
+		codes[item.ErrorCode] = true
+		if item.HTTPCode < http.StatusBadRequest || len(item.Message) == 0 {
+			t.Errorf("the error %+v should have the error http code and the message", item)
+		}
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"net"
 	"net/http"
 	"strings"
+
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 // RequestInfo of the http or https request
@@ -40,7 +42,12 @@ type RequestInfo struct {
 	CaCrt        string
 	ClientCrt    string
 	ClientKey    string
-	Skip         bool
+	// BearerToken the API token or the ID token which is sent in the Authorization header
+	BearerToken string
+	Skip        bool
+	// RequestID the X-Request-ID of the request, it is generated by CommonUtilRequest if it is empty, thus the caller
+	// can report it when the request failed
+	RequestID string
 }
 
 func (r RequestInfo) getRequest() (*http.Request, error) {
@@ -60,6 +67,12 @@ func (r RequestInfo) getRequest() (*http.Request, error) {
 	for k, v := range r.HeaderSetter {
 		req.Header.Set(k, v)
 	}
+	if len(r.BearerToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+r.BearerToken)
+	}
+	if len(r.RequestID) > 0 {
+		req.Header.Set(requestid.Header, r.RequestID)
+	}
 	return req, nil
 }
 
@@ -73,31 +86,34 @@ func (r RequestInfo) getClient() (*http.Client, error) {
 		return nil, err
 	}
 	pool.AppendCertsFromPEM(caCrt)
-	clientCrt, err := base64.StdEncoding.DecodeString(r.ClientCrt)
-	if err != nil {
-		return nil, err
+	tlsConfig := &tls.Config{
+		RootCAs:            pool,
+		InsecureSkipVerify: r.Skip, //nolint:gosec
 	}
-	clientKey, err := base64.StdEncoding.DecodeString(r.ClientKey)
-	if err != nil {
-		return nil, err
-	}
-	cliCrt, err := tls.X509KeyPair(clientCrt, clientKey)
-	if err != nil {
-		return nil, err
+	// the client certificate is optional when the bearer token is used
+	if len(r.BearerToken) == 0 || len(r.ClientCrt) > 0 || len(r.ClientKey) > 0 {
+		clientCrt, err := base64.StdEncoding.DecodeString(r.ClientCrt)
+		if err != nil {
+			return nil, err
+		}
+		clientKey, err := base64.StdEncoding.DecodeString(r.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		cliCrt, err := tls.X509KeyPair(clientCrt, clientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cliCrt}
 	}
-	return &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs:            pool,
-				Certificates:       []tls.Certificate{cliCrt},
-				InsecureSkipVerify: r.Skip, //nolint:gosec
-			},
-		},
-	}, nil
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}, nil
 }
 
 // CommonUtilRequest common get http/https request
 func CommonUtilRequest(info *RequestInfo) (int, []byte, error) {
+	if len(info.RequestID) == 0 {
+		info.RequestID = requestid.New()
+	}
 	req, err := info.getRequest()
 	if err != nil {
 		return 0, nil, err
@@ -108,7 +124,7 @@ func CommonUtilRequest(info *RequestInfo) (int, []byte, error) {
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, nil, fmt.Errorf("perform request failed, err: %w", err)
+		return 0, nil, fmt.Errorf("perform request failed, requestID: %s, err: %w", info.RequestID, err)
 	}
 	defer resp.Body.Close() // nolint:errcheck
 	code := resp.StatusCode
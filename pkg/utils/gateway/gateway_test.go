@@ -32,6 +32,8 @@ import (
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/smartystreets/goconvey/convey"
+
+	"github.com/kappital/kappital/pkg/utils/requestid"
 )
 
 func TestCommonUtilRequest(t *testing.T) {
@@ -64,7 +66,9 @@ func TestCommonUtilRequest(t *testing.T) {
 		})
 		convey.Convey("case 4:", func() {
 			var ts *httptest.Server
+			var gotRequestID string
 			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRequestID = r.Header.Get(requestid.Header)
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusOK)
 				_, err := w.Write([]byte("output"))
@@ -74,10 +78,13 @@ func TestCommonUtilRequest(t *testing.T) {
 				}
 			}))
 			defer ts.Close()
-			code, buf, err := CommonUtilRequest(&RequestInfo{Method: "GET", Path: ts.URL, HeaderAdder: map[string]string{"a": "b"}, HeaderSetter: map[string]string{"b": "c"}})
+			info := &RequestInfo{Method: "GET", Path: ts.URL, HeaderAdder: map[string]string{"a": "b"}, HeaderSetter: map[string]string{"b": "c"}}
+			code, buf, err := CommonUtilRequest(info)
 			convey.So(code, convey.ShouldEqual, http.StatusOK)
 			convey.So(buf, convey.ShouldNotBeNil)
 			convey.So(err, convey.ShouldBeNil)
+			convey.So(gotRequestID, convey.ShouldEqual, info.RequestID)
+			convey.So(info.RequestID, convey.ShouldNotBeEmpty)
 		})
 	})
 }
@@ -128,6 +135,18 @@ func TestRequestInfo_getClient(t *testing.T) {
 	})
 }
 
+func TestRequestInfo_BearerToken(t *testing.T) {
+	convey.Convey("Test RequestInfo with the bearer token", t, func() {
+		r := RequestInfo{Method: http.MethodGet, Path: "https://x.x.x.x", CaCrt: "eHh4", BearerToken: "kpt_xxx"}
+		client, err := r.getClient()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(client.Transport.(*http.Transport).TLSClientConfig.Certificates, convey.ShouldBeEmpty)
+		req, err := r.getRequest()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(req.Header.Get("Authorization"), convey.ShouldEqual, "Bearer kpt_xxx")
+	})
+}
+
 func TestGetLocalIP(t *testing.T) {
 	convey.Convey("Test GetLocalIP", t, func() {
 		p := gomonkey.ApplyFuncSeq(net.InterfaceAddrs, []gomonkey.OutputCell{
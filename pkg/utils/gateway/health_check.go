@@ -24,19 +24,17 @@ import (
 	"time"
 
 	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/utils/certificate"
 )
 
-// HealthAndReadinessProvider get and create the health and readiness provider
-func HealthAndReadinessProvider(address string, cert, key []byte) {
+// HealthAndReadinessProvider get and create the health and readiness provider, the server certificate is got by
+// getCertificate for each handshake, thus the rotated certificate is used without restarting
+func HealthAndReadinessProvider(address string, getCertificate certificate.GetCertificateFunc) {
 	http.HandleFunc("/healthz", setHTTPHeaderToOK)
 	http.HandleFunc("/readyz", setHTTPHeaderToOK)
-	server, err := constructServer(address, cert, key)
-	if err != nil {
-		klog.Errorf("cannot get server")
-		os.Exit(1)
-	}
-
-	if err = server.ListenAndServeTLS("", ""); err != nil {
+	server := constructServer(address, getCertificate)
+	if err := server.ListenAndServeTLS("", ""); err != nil {
 		klog.Errorf("cannot start health check")
 		os.Exit(1)
 	}
@@ -48,27 +46,18 @@ func setHTTPHeaderToOK(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func constructServer(address string, cert, key []byte) (*http.Server, error) {
-	if tlsConfig, err := constructTLSConfig(cert, key); err != nil {
-		klog.Errorf("cannot construct tls config, err: %s", err)
-		return nil, err
-	} else {
-		return &http.Server{
-			Addr:              address,
-			Handler:           http.DefaultServeMux,
-			TLSConfig:         tlsConfig,
-			ReadHeaderTimeout: time.Minute * 2,
-		}, nil
+func constructServer(address string, getCertificate certificate.GetCertificateFunc) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           http.DefaultServeMux,
+		TLSConfig:         constructTLSConfig(getCertificate),
+		ReadHeaderTimeout: time.Minute * 2,
 	}
 }
 
-func constructTLSConfig(cert, key []byte) (*tls.Config, error) {
-	certificate, err := tls.X509KeyPair(cert, key)
-	if err != nil {
-		return nil, err
-	}
+func constructTLSConfig(getCertificate certificate.GetCertificateFunc) *tls.Config {
 	return &tls.Config{
-		Certificates: []tls.Certificate{certificate},
+		GetCertificate: getCertificate,
 		CipherSuites: []uint16{
 			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
@@ -76,5 +65,5 @@ func constructTLSConfig(cert, key []byte) (*tls.Config, error) {
 			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
 		},
 		MinVersion: tls.VersionTLS12,
-	}, nil
+	}
 }
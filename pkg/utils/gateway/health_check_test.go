@@ -18,13 +18,12 @@ package gateway
 
 import (
 	"crypto/tls"
-	"net/http"
 	"os"
-	"reflect"
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
 
+	"github.com/kappital/kappital/pkg/utils/certificate"
 	"github.com/kappital/kappital/pkg/utils/cryption"
 )
 
@@ -33,66 +32,34 @@ func TestHealthAndReadinessProvider(t *testing.T) {
 	if err != nil {
 		t.Errorf("cannot create the self certificate and key, err: %s", err)
 	}
+	getCertificate, err := certificate.StaticCertificate(cert, key)
+	if err != nil {
+		t.Errorf("cannot load the self certificate and key, err: %s", err)
+	}
 	ip, err := GetLocalIP()
 	if err != nil {
 		t.Errorf("cannot get localhost ip")
 	}
 	p := gomonkey.ApplyFunc(os.Exit, func(_ int) {})
 	defer p.Reset()
-	HealthAndReadinessProvider(ip, cert, key)
-}
-
-func Test_constructTLSConfig(t *testing.T) {
-	type args struct {
-		cert []byte
-		key  []byte
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    *tls.Config
-		wantErr bool
-	}{
-		{name: "Test constructTLSConfig", wantErr: true},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := constructTLSConfig(tt.args.cert, tt.args.key)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("constructTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("constructTLSConfig() got = %v, want %v", got, tt.want)
-			}
-		})
-	}
+	HealthAndReadinessProvider(ip, getCertificate)
 }
 
 func Test_constructServer(t *testing.T) {
-	type args struct {
-		address string
-		cert    []byte
-		key     []byte
+	cert, key, err := cryption.GetSelfCertAndKey()
+	if err != nil {
+		t.Fatalf("cannot create the self certificate and key, err: %s", err)
+	}
+	getCertificate, err := certificate.StaticCertificate(cert, key)
+	if err != nil {
+		t.Fatalf("cannot load the self certificate and key, err: %s", err)
 	}
-	tests := []struct {
-		name    string
-		args    args
-		want    *http.Server
-		wantErr bool
-	}{
-		{name: "Test constructServer", wantErr: true},
+	server := constructServer(":8081", getCertificate)
+	if server.Addr != ":8081" || server.TLSConfig == nil || server.TLSConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("constructServer() got unexpected server %+v", server)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := constructServer(tt.args.address, tt.args.cert, tt.args.key)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("constructServer() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("constructServer() got = %v, want %v", got, tt.want)
-			}
-		})
+	got, err := server.TLSConfig.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil || got == nil {
+		t.Errorf("GetCertificate() got = %v, err = %v", got, err)
 	}
 }
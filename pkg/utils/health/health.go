@@ -0,0 +1,242 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// LivenessPath the path of the liveness probe
+	LivenessPath = "/healthz"
+	// ReadinessPath the path of the readiness probe
+	ReadinessPath = "/readyz"
+
+	// StatusOK the check is passed
+	StatusOK = "ok"
+	// StatusFailed the check is failed
+	StatusFailed = "failed"
+
+	defaultBindAddress       = ":8081"
+	defaultCheckTimeout      = 5 * time.Second
+	defaultWatcherSaturation = 0.8
+)
+
+// Config of the health probe server
+type Config struct {
+	// BindAddress the address which the liveness and readiness probes bind to, empty means disable the probes
+	BindAddress string
+	// CheckTimeout the longest duration of each check
+	CheckTimeout time.Duration
+	// WatcherSaturation the ratio of the used watcher channel buffer, the replica is not ready when it is reached
+	WatcherSaturation float64
+}
+
+// DefaultHealthConfig the default config of the health probe server
+func DefaultHealthConfig() *Config {
+	return &Config{
+		BindAddress:       defaultBindAddress,
+		CheckTimeout:      defaultCheckTimeout,
+		WatcherSaturation: defaultWatcherSaturation,
+	}
+}
+
+// Validate the health config
+func (c *Config) Validate() error {
+	if c.CheckTimeout <= 0 {
+		return fmt.Errorf("the health check timeout must be positive")
+	}
+	if c.WatcherSaturation <= 0 || c.WatcherSaturation > 1 {
+		return fmt.Errorf("the watcher saturation of the health check must be in (0, 1]")
+	}
+	return nil
+}
+
+// Checker checks one dependency of the service, the message describes the passed check and the error describes the
+// failed one
+type Checker func(ctx context.Context) (message string, err error)
+
+// Result of one check
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report of the probe, the status is ok only if all checks are passed
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Registry of the liveness and readiness checks
+type Registry struct {
+	mu        sync.RWMutex
+	timeout   time.Duration
+	liveness  map[string]Checker
+	readiness map[string]Checker
+}
+
+// NewRegistry create an empty Registry, each check will be canceled after the timeout
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	return &Registry{
+		timeout:   timeout,
+		liveness:  map[string]Checker{},
+		readiness: map[string]Checker{},
+	}
+}
+
+// AddLivenessCheck add the check to the liveness probe, the failed liveness will cause restarting the replica, thus
+// only the failures which cannot be recovered by itself should be checked
+func (r *Registry) AddLivenessCheck(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness[name] = checker
+}
+
+// AddReadinessCheck add the check to the readiness probe
+func (r *Registry) AddReadinessCheck(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness[name] = checker
+}
+
+// Liveness run the liveness checks
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return r.run(ctx, r.liveness)
+}
+
+// Readiness run the readiness checks
+func (r *Registry) Readiness(ctx context.Context) Report {
+	return r.run(ctx, r.readiness)
+}
+
+// run all checks concurrently, thus a hanging dependency cannot delay the others
+func (r *Registry) run(ctx context.Context, checks map[string]Checker) Report {
+	r.mu.RLock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := make([]Result, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string, checker Checker) {
+			defer wg.Done()
+			results[i] = r.check(ctx, name, checker)
+		}(i, name, checks[name])
+	}
+	r.mu.RUnlock()
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFailed
+		}
+	}
+	return report
+}
+
+func (r *Registry) check(ctx context.Context, name string, checker Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	start := time.Now()
+	// the channel is buffered, thus the checker which does not respect the ctx will not be leaked forever
+	ch := make(chan Result, 1)
+	go func() {
+		result := Result{Name: name, Status: StatusOK}
+		defer func() {
+			if e := recover(); e != nil {
+				result.Status, result.Message = StatusFailed, fmt.Sprintf("panic: %v", e)
+			}
+			ch <- result
+		}()
+		message, err := checker(ctx)
+		if err != nil {
+			result.Status, result.Message = StatusFailed, err.Error()
+			return
+		}
+		result.Message = message
+	}()
+	var result Result
+	select {
+	case result = <-ch:
+	case <-ctx.Done():
+		result = Result{Name: name, Status: StatusFailed, Message: fmt.Sprintf("timeout after %s", r.timeout)}
+	}
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	return result
+}
+
+// Handler reply the report of the probe in JSON, 200 if all checks are passed, otherwise 503. The failed checks are
+// logged, thus the reason of the restarted or not ready replica can be found.
+func (r *Registry) Handler(probe func(*Registry, context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := probe(r, req.Context())
+		code := http.StatusOK
+		if report.Status != StatusOK {
+			code = http.StatusServiceUnavailable
+			var failed []string
+			for _, result := range report.Checks {
+				if result.Status != StatusOK {
+					failed = append(failed, fmt.Sprintf("%s: %s", result.Name, result.Message))
+				}
+			}
+			klog.Warningf("the probe %s is failed, %s", req.URL.Path, strings.Join(failed, "; "))
+		}
+		buf, err := json.Marshal(report)
+		if err != nil {
+			klog.Errorf("cannot marshal the health report, err: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		_, _ = w.Write(buf)
+	})
+}
+
+// NewServer create the server of the liveness and readiness probes. The probes are served by the separated server,
+// because the kubelet cannot give the client certificate which is required by the API server. The server is served
+// with TLS if tlsConfig is not nil.
+func NewServer(address string, registry *Registry, tlsConfig *tls.Config) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(LivenessPath, registry.Handler((*Registry).Liveness))
+	mux.Handle(ReadinessPath, registry.Handler((*Registry).Readiness))
+	return &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
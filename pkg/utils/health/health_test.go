@@ -0,0 +1,126 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Handler(t *testing.T) {
+	passed := func(context.Context) (string, error) { return "connected", nil }
+	failed := func(context.Context) (string, error) { return "", fmt.Errorf("connection refused") }
+	hanging := func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return "late", nil
+	}
+	panicked := func(context.Context) (string, error) { panic("boom") }
+	tests := []struct {
+		name     string
+		checks   map[string]Checker
+		path     string
+		wantCode int
+		want     map[string]string
+	}{
+		{
+			name:     "all checks passed",
+			checks:   map[string]Checker{"database": passed, "watcher": passed},
+			path:     ReadinessPath,
+			wantCode: http.StatusOK,
+			want:     map[string]string{"database": StatusOK, "watcher": StatusOK},
+		},
+		{
+			name:     "one check failed",
+			checks:   map[string]Checker{"database": failed, "watcher": passed},
+			path:     ReadinessPath,
+			wantCode: http.StatusServiceUnavailable,
+			want:     map[string]string{"database": StatusFailed, "watcher": StatusOK},
+		},
+		{
+			name:     "check timeout",
+			checks:   map[string]Checker{"clusters": hanging},
+			path:     ReadinessPath,
+			wantCode: http.StatusServiceUnavailable,
+			want:     map[string]string{"clusters": StatusFailed},
+		},
+		{
+			name:     "check panic",
+			checks:   map[string]Checker{"processors": panicked},
+			path:     ReadinessPath,
+			wantCode: http.StatusServiceUnavailable,
+			want:     map[string]string{"processors": StatusFailed},
+		},
+		{
+			name:     "liveness without readiness checks",
+			checks:   map[string]Checker{"database": failed},
+			path:     LivenessPath,
+			wantCode: http.StatusOK,
+			want:     map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(50 * time.Millisecond)
+			for name, checker := range tt.checks {
+				registry.AddReadinessCheck(name, checker)
+			}
+			recorder := httptest.NewRecorder()
+			NewServer(":0", registry, nil).Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", recorder.Code, tt.wantCode)
+			}
+			var report Report
+			if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+				t.Fatalf("cannot unmarshal the report, err: %v", err)
+			}
+			if len(report.Checks) != len(tt.want) {
+				t.Fatalf("checks = %v, want %v", report.Checks, tt.want)
+			}
+			for _, result := range report.Checks {
+				if result.Status != tt.want[result.Name] {
+					t.Errorf("check %s status = %s, want %s", result.Name, result.Status, tt.want[result.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{name: "default config", cfg: DefaultHealthConfig()},
+		{name: "disabled probes", cfg: &Config{CheckTimeout: time.Second, WatcherSaturation: 1}},
+		{name: "zero timeout", cfg: &Config{WatcherSaturation: 0.5}, wantErr: true},
+		{name: "saturation out of range", cfg: &Config{CheckTimeout: time.Second, WatcherSaturation: 1.5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
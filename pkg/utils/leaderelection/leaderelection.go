@@ -0,0 +1,158 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/apis"
+	"github.com/kappital/kappital/pkg/utils/operations"
+	"github.com/kappital/kappital/pkg/utils/uuid"
+)
+
+const (
+	// namespaceEnv the env key of the namespace which the manager running in
+	namespaceEnv = "APP_NAMESPACE"
+
+	defaultLockName = "kappital-manager-leader"
+)
+
+var (
+	elector *leaderelection.LeaderElector
+	enabled bool
+	mu      sync.RWMutex
+)
+
+// Config of the leader election for the manager processors
+type Config struct {
+	// Enable the leader election, if disabled, the current replica is always the leader
+	Enable bool
+	// LeaseDuration is the duration that non-leader candidates will wait to force acquire leadership
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration that the acting leader will retry refreshing leadership before giving up
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration the candidates should wait between tries of actions
+	RetryPeriod time.Duration
+	// LockName the name of the Lease object
+	LockName string
+	// LockNamespace the namespace of the Lease object
+	LockNamespace string
+}
+
+// DefaultLeaderElectionConfig get the default leader election config
+func DefaultLeaderElectionConfig() *Config {
+	ns := os.Getenv(namespaceEnv)
+	if len(ns) == 0 {
+		ns = apis.KappitalSystemNamespace
+	}
+	return &Config{
+		Enable:        false,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+		LockName:      defaultLockName,
+		LockNamespace: ns,
+	}
+}
+
+// Run the leader election until the ctx is done. The onStartedLeading will be called when the current replica
+// becomes the leader, and the onStoppedLeading will be called when the leadership is lost.
+func Run(ctx context.Context, cfg *Config, onStartedLeading func(stopCh <-chan struct{}), onStoppedLeading func()) error {
+	if cfg == nil || !cfg.Enable {
+		onStartedLeading(ctx.Done())
+		return nil
+	}
+	le, err := newLeaderElector(cfg, onStartedLeading, onStoppedLeading)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	elector, enabled = le, true
+	mu.Unlock()
+	klog.Infof("start leader election with lease %s/%s", cfg.LockNamespace, cfg.LockName)
+	le.Run(ctx)
+	return nil
+}
+
+func newLeaderElector(cfg *Config, onStartedLeading func(stopCh <-chan struct{}),
+	onStoppedLeading func()) (*leaderelection.LeaderElector, error) {
+	config, err := operations.GetRestConfig()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the client config, err: %v", err)
+	}
+	cli, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the client, err: %v", err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the hostname, err: %v", err)
+	}
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, cfg.LockNamespace, cfg.LockName,
+		cli.CoreV1(), cli.CoordinationV1(), resourcelock.ResourceLockConfig{
+			Identity: fmt.Sprintf("%s_%s", hostname, uuid.NewUUID()),
+		})
+	if err != nil {
+		return nil, err
+	}
+	return leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: cfg.LeaseDuration,
+		RenewDeadline: cfg.RenewDeadline,
+		RetryPeriod:   cfg.RetryPeriod,
+		// release the lease when the ctx is canceled for the graceful shutdown, thus other replicas can take over
+		// the processors without waiting for the lease expired
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("became the leader with identity %s", lock.Identity())
+				onStartedLeading(ctx.Done())
+			},
+			OnStoppedLeading: func() {
+				klog.Warningf("lost the leadership with identity %s", lock.Identity())
+				onStoppedLeading()
+			},
+			OnNewLeader: func(identity string) {
+				klog.Infof("the current leader is %s", identity)
+			},
+		},
+		Name: cfg.LockName,
+	})
+}
+
+// IsLeader does the current replica hold the lease, and the lease has not expired since the last renew.
+// If the leader election is disabled, it always returns true.
+func IsLeader() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	if !enabled {
+		return true
+	}
+	if elector == nil || !elector.IsLeader() {
+		return false
+	}
+	return elector.Check(0) == nil
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package leaderelection
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var started bool
+	if err := Run(ctx, DefaultLeaderElectionConfig(), func(<-chan struct{}) { started = true }, func() {}); err != nil {
+		t.Errorf("Run() error = %v, wantErr false", err)
+	}
+	if !started {
+		t.Errorf("Run() should start leading when the leader election is disabled")
+	}
+	if !IsLeader() {
+		t.Errorf("IsLeader() should be true when the leader election is disabled")
+	}
+}
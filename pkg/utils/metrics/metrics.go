@@ -0,0 +1,134 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "kappital"
+
+	// UnmatchedRoute the route label of the requests which do not match any registered router
+	UnmatchedRoute = "unmatched"
+)
+
+// Registry of the manager metrics. It reuses the controller-runtime registry, because the work-queue metrics
+// (depth, adds, queue duration and etc.) of the processors have been registered into it by the controller-runtime.
+var Registry = ctrlmetrics.Registry
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of the HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the HTTP requests by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	flowControlRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "flow_control_rejected_total",
+		Help:      "Total number of the HTTP requests rejected by the flow controller by route class and limit scope.",
+	}, []string{"method", "route", "class", "scope"})
+
+	processorSyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of processing one item of the processor work queue by processor and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+	}, []string{"processor", "result"})
+
+	processorRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "retries_total",
+		Help:      "Total number of the items re-queued for retrying by processor.",
+	}, []string{"processor"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of the database operations by table and operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"table", "operation"})
+)
+
+func init() {
+	Registry.MustRegister(httpRequestsTotal, httpRequestDuration, flowControlRejectedTotal,
+		processorSyncDuration, processorRetriesTotal, dbQueryDuration)
+}
+
+// Handler of the metrics endpoint
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// MustRegister the collectors into the manager registry, it panics if any error occurs
+func MustRegister(cs ...prometheus.Collector) {
+	Registry.MustRegister(cs...)
+}
+
+// ObserveHTTPRequest record the count and latency of the HTTP request
+func ObserveHTTPRequest(method, route string, code int, start time.Time) {
+	if len(route) == 0 {
+		route = UnmatchedRoute
+	}
+	httpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+}
+
+// IncFlowControlRejected record the request rejected by the flow controller, the scope is global or client
+func IncFlowControlRejected(method, route, class, scope string) {
+	if len(route) == 0 {
+		route = UnmatchedRoute
+	}
+	flowControlRejectedTotal.WithLabelValues(method, route, class, scope).Inc()
+}
+
+// ObserveProcessorSync record the duration and result of processing one item, and the retry if need
+func ObserveProcessorSync(processor string, retry bool, err error, start time.Time) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	processorSyncDuration.WithLabelValues(processor, result).Observe(time.Since(start).Seconds())
+	if retry {
+		processorRetriesTotal.WithLabelValues(processor).Inc()
+	}
+}
+
+// ObserveDBQuery record the latency of the database operation
+func ObserveDBQuery(table, operation string, start time.Time) {
+	dbQueryDuration.WithLabelValues(table, operation).Observe(time.Since(start).Seconds())
+}
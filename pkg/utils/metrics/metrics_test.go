@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveHTTPRequest(t *testing.T) {
+	ObserveHTTPRequest(http.MethodGet, "/api/v1alpha1/servicebinding", http.StatusOK, time.Now())
+	ObserveHTTPRequest(http.MethodGet, "", http.StatusNotFound, time.Now())
+	if got := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet,
+		"/api/v1alpha1/servicebinding", "200")); got != 1 {
+		t.Errorf("requests of the matched route = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, UnmatchedRoute, "404")); got != 1 {
+		t.Errorf("requests of the unmatched route = %v, want 1", got)
+	}
+}
+
+func TestIncFlowControlRejected(t *testing.T) {
+	IncFlowControlRejected(http.MethodPost, "", "mutation", "client")
+	if got := testutil.ToFloat64(flowControlRejectedTotal.WithLabelValues(http.MethodPost, UnmatchedRoute,
+		"mutation", "client")); got != 1 {
+		t.Errorf("rejected requests = %v, want 1", got)
+	}
+}
+
+func TestObserveProcessorSync(t *testing.T) {
+	tests := []struct {
+		name       string
+		retry      bool
+		err        error
+		wantRetry  float64
+		wantResult string
+	}{
+		{name: "success", wantResult: "success"},
+		{name: "retry with error", retry: true, err: fmt.Errorf("mock error"), wantRetry: 1, wantResult: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := "test-" + strings.ReplaceAll(tt.name, " ", "-")
+			ObserveProcessorSync(processor, tt.retry, tt.err, time.Now())
+			if got := testutil.ToFloat64(processorRetriesTotal.WithLabelValues(processor)); got != tt.wantRetry {
+				t.Errorf("retries = %v, want %v", got, tt.wantRetry)
+			}
+			if got := testutil.CollectAndCount(processorSyncDuration, namespace+"_processor_sync_duration_seconds"); got == 0 {
+				t.Errorf("sync duration is not observed for result %s", tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveDBQuery("service_binding", "Get", time.Now())
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Handler() code = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), "kappital_db_query_duration_seconds") {
+		t.Errorf("Handler() body does not contain the db query metrics")
+	}
+}
@@ -0,0 +1,226 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version of the OpenAPI specification which the document follows
+const Version = "3.0.3"
+
+var (
+	routes      []Route
+	routesMutex sync.RWMutex
+)
+
+// Document the OpenAPI document of the REST API
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info the title and the version of the REST API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem the operations of the path by the lower case http methods
+type PathItem map[string]*Operation
+
+// Operation the http method of the path
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter of the operation in the path, query or header
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody the body of the operation by the content types
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response of the operation, the content is empty if it has no body
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header of the response
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType the schema of the body of the content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components the schemas referred by the operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Route the operation of the router pattern which is described in the document
+type Route struct {
+	// Method the http method, such as GET
+	Method string
+	// Pattern the router pattern, the path parameters are in the form of :name
+	Pattern string
+	// OperationID the name of the controller method which serves the route
+	OperationID string
+	// Summary the short description of the operation
+	Summary string
+	// Tag the group of the operation
+	Tag string
+	// Parameters the query and header parameters, the path parameters are derived from the pattern
+	Parameters []Parameter
+	// Request the type of the request body by the content types, nil if the operation has no body
+	Request map[string]interface{}
+	// Status the http code of the success response, default is 200
+	Status int
+	// Response the type of the success response body, nil if it is null
+	Response interface{}
+	// Headers the headers of the success response
+	Headers map[string]Header
+}
+
+// Query get the optional string query parameter
+func Query(name, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// HeaderParam get the optional string header parameter
+func HeaderParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// Register the routes which are described in the document
+func Register(rs ...Route) {
+	routesMutex.Lock()
+	defer routesMutex.Unlock()
+	routes = append(routes, rs...)
+}
+
+// Routes get the registered routes
+func Routes() []Route {
+	routesMutex.RLock()
+	defer routesMutex.RUnlock()
+	return append([]Route(nil), routes...)
+}
+
+// Spec build the document of the registered routes, the error responses are described by the errResp type
+func Spec(info Info, errResp interface{}) *Document {
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	g := newGenerator(doc.Components.Schemas)
+	errSchema := g.schemaOf(reflect.TypeOf(errResp))
+	for _, r := range Routes() {
+		path, params := ConvertPattern(r.Pattern)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		op := &Operation{
+			OperationID: r.OperationID,
+			Summary:     r.Summary,
+			Responses:   map[string]*Response{},
+		}
+		if len(r.Tag) > 0 {
+			op.Tags = []string{r.Tag}
+		}
+		for _, name := range params {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true,
+				Schema: &Schema{Type: "string"}})
+		}
+		op.Parameters = append(op.Parameters, r.Parameters...)
+		if len(r.Request) > 0 {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{}}
+			for contentType, body := range r.Request {
+				op.RequestBody.Content[contentType] = MediaType{Schema: g.schemaOf(reflect.TypeOf(body))}
+			}
+		}
+		status := r.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := &Response{Description: http.StatusText(status), Headers: r.Headers}
+		if r.Response != nil {
+			resp.Content = map[string]MediaType{"application/json": {Schema: g.schemaOf(reflect.TypeOf(r.Response))}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+		op.Responses["default"] = &Response{Description: "The error response.",
+			Content: map[string]MediaType{"application/json": {Schema: errSchema}}}
+		(*item)[strings.ToLower(r.Method)] = op
+	}
+	return doc
+}
+
+// ConvertPattern convert the router pattern into the path of the document, and get the names of the path parameters
+// in order, such as /a/:name into /a/{name}
+func ConvertPattern(pattern string) (string, []string) {
+	var params []string
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// Operations get the operations of the document in the form of "METHOD path", sorted
+func (d *Document) Operations() []string {
+	var ops []string
+	for path, item := range d.Paths {
+		for method := range *item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type testMeta struct {
+	Name string `json:"name"`
+}
+
+type testNode struct {
+	testMeta  `json:",inline"`
+	Children  []*testNode       `json:"children,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Created   time.Time         `json:"created"`
+	Updated   metav1.Time       `json:"updated"`
+	Size      resource.Quantity `json:"size"`
+	Raw       json.RawMessage   `json:"raw,omitempty"`
+	Data      []byte            `json:"data,omitempty"`
+	Any       interface{}       `json:"any,omitempty"`
+	Ignored   string            `json:"-"`
+	NoTag     int32
+	unexposed string
+}
+
+func TestConvertPattern(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		wantPath   string
+		wantParams []string
+	}{
+		{pattern: "/api/v1alpha1/servicebinding", wantPath: "/api/v1alpha1/servicebinding"},
+		{pattern: "/api/v1alpha1/servicebinding/:service_binding/instance/:instance",
+			wantPath:   "/api/v1alpha1/servicebinding/{service_binding}/instance/{instance}",
+			wantParams: []string{"service_binding", "instance"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			path, params := ConvertPattern(tt.pattern)
+			if path != tt.wantPath || !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("ConvertPattern() = %s %v, want %s %v", path, params, tt.wantPath, tt.wantParams)
+			}
+		})
+	}
+}
+
+func Test_generator_schemaOf(t *testing.T) {
+	schemas := map[string]*Schema{}
+	got := newGenerator(schemas).schemaOf(reflect.TypeOf(&testNode{}))
+	if got.Ref != "#/components/schemas/utils.openapi.testNode" {
+		t.Fatalf("schemaOf() ref = %s", got.Ref)
+	}
+	node := schemas["utils.openapi.testNode"]
+	want := map[string]*Schema{
+		"name":     {Type: "string"},
+		"children": {Type: "array", Items: &Schema{Ref: got.Ref}},
+		"labels":   {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+		"created":  {Type: "string", Format: "date-time"},
+		"updated":  {Type: "string", Format: "date-time"},
+		"size":     {Type: "string"},
+		"raw":      {},
+		"data":     {Type: "string", Format: "byte"},
+		"any":      {},
+		"NoTag":    {Type: "integer", Format: "int32"},
+	}
+	if !reflect.DeepEqual(node.Properties, want) {
+		buf, _ := json.Marshal(node.Properties)
+		t.Errorf("schemaOf() properties = %s", buf)
+	}
+}
+
+func TestSpec(t *testing.T) {
+	routes = nil
+	defer func() { routes = nil }()
+	Register(
+		Route{Method: http.MethodGet, Pattern: "/api/nodes/:node", OperationID: "GetNode",
+			Parameters: []Parameter{Query("depth", "The depth.")}, Response: testNode{}},
+		Route{Method: http.MethodPost, Pattern: "/api/nodes", OperationID: "CreateNode", Status: http.StatusCreated,
+			Request: map[string]interface{}{"application/json": testNode{}}, Response: testNode{}},
+		Route{Method: http.MethodDelete, Pattern: "/api/nodes/:node", OperationID: "DeleteNode"},
+	)
+	doc := Spec(Info{Title: "test", Version: "v1"}, testMeta{})
+	wantOps := []string{"DELETE /api/nodes/{node}", "GET /api/nodes/{node}", "POST /api/nodes"}
+	if got := doc.Operations(); !reflect.DeepEqual(got, wantOps) {
+		t.Errorf("Operations() = %v, want %v", got, wantOps)
+	}
+	get := (*doc.Paths["/api/nodes/{node}"])["get"]
+	if len(get.Parameters) != 2 || get.Parameters[0].In != "path" || !get.Parameters[0].Required {
+		t.Errorf("the parameters of GetNode = %+v", get.Parameters)
+	}
+	if _, ok := (*doc.Paths["/api/nodes"])["post"].Responses["201"]; !ok {
+		t.Errorf("the 201 response of CreateNode is missing")
+	}
+	if (*doc.Paths["/api/nodes/{node}"])["delete"].Responses["200"].Content != nil {
+		t.Errorf("the response of DeleteNode should have no content")
+	}
+	// all references are in the components
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("cannot marshal the document, err: %v", err)
+	}
+	for _, m := range regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllSubmatch(buf, -1) {
+		if _, ok := doc.Components.Schemas[string(m[1])]; !ok {
+			t.Errorf("the schema %s is not in the components", m[1])
+		}
+	}
+}
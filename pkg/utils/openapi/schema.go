@@ -0,0 +1,166 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// modulePrefix is trimmed from the package paths of the schema names
+const modulePrefix = "github.com/kappital/kappital/pkg/"
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Schema the JSON schema of the value, the empty schema accepts any value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// schemaTyper the types which declare their own schemas, such as the metav1.Time and the resource.Quantity of the
+// kubernetes, because they are marshaled by themselves
+type schemaTyper interface {
+	OpenAPISchemaType() []string
+	OpenAPISchemaFormat() string
+}
+
+// generator generate the schemas of the go types, the named structs are added into the components and referred by
+// their names, thus the recursive types are supported
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator(schemas map[string]*Schema) *generator {
+	return &generator{schemas: schemas, names: map[reflect.Type]string{}}
+}
+
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if s, ok := customSchema(t); ok {
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// customSchema get the schema of the types which are marshaled by themselves
+func customSchema(t reflect.Type) (*Schema, bool) {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}, true
+	}
+	if typer, ok := reflect.New(t).Interface().(schemaTyper); ok {
+		s := &Schema{Format: typer.OpenAPISchemaFormat()}
+		if types := typer.OpenAPISchemaType(); len(types) > 0 {
+			s.Type = types[0]
+		}
+		return s, true
+	}
+	// the json of the other marshalers is unknown, such as the json.RawMessage
+	if reflect.PtrTo(t).Implements(marshalerType) {
+		return &Schema{}, true
+	}
+	return nil, false
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	if len(t.Name()) == 0 {
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		g.addFields(t, s)
+		return s
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = schemaName(t)
+		g.names[t] = name
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		// add the schema before its fields, thus the recursive fields refer to it
+		g.schemas[name] = s
+		g.addFields(t, s)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// addFields add the exported fields of the struct into the properties by their json names, the fields of the
+// embedded structs without the json names are inlined, the same as encoding/json
+func (g *generator) addFields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && len(name) == 0 && fieldType.Kind() == reflect.Struct {
+			if _, ok := customSchema(fieldType); !ok {
+				g.addFields(fieldType, s)
+				continue
+			}
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		s.Properties[name] = g.schemaOf(field.Type)
+	}
+}
+
+// schemaName get the name of the named type in the components, such as apis.Timeouts or k8s.io.api.apps.v1.Deployment
+func schemaName(t reflect.Type) string {
+	return strings.ReplaceAll(strings.TrimPrefix(t.PkgPath(), modulePrefix), "/", ".") + "." + t.Name()
+}
@@ -17,8 +17,12 @@
 package operations
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -40,19 +44,28 @@ var operation ClusterOperation
 type ClusterOperation interface {
 	// GetServicePackageByName get the service package CR by its name. This method will return the ServicePackage
 	// if existed.
-	GetServicePackageByName(name, namespace string) (enginev1alpha1.ServicePackage, bool, error)
+	GetServicePackageByName(ctx context.Context, name, namespace string) (enginev1alpha1.ServicePackage, bool, error)
 	// DoesCustomResourceExist will use resource's schema.GroupVersion to find the cr in this cluster
-	DoesCustomResourceExist(gv schema.GroupVersion, plural, name, namespace string) (bool, error)
+	DoesCustomResourceExist(ctx context.Context, gv schema.GroupVersion, plural, name, namespace string) (bool, error)
 	// DeployCustomResource will install the custom resource into cluster
-	DeployCustomResource(gvr schema.GroupVersionResource, namespace string, resource interface{}) error
+	DeployCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string, resource interface{}) error
 	// UpdateCustomResource will update the custom resource into cluster
-	UpdateCustomResource(gvr schema.GroupVersionResource, namespace string, resource interface{}) error
+	UpdateCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string, resource interface{}) error
 	// DeleteCustomResource will delete the custom resource from cluster
-	DeleteCustomResource(gvr schema.GroupVersionResource, name, namespace string) error
+	DeleteCustomResource(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) error
+	// RemoveFinalizers will clear the finalizers of the custom resource, the deleting one is removed at once
+	RemoveFinalizers(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) error
 	// IsNamespaceExist will check cluster namespace is existed, true means is exists
-	IsNamespaceExist(namespace string) (bool, error)
+	IsNamespaceExist(ctx context.Context, namespace string) (bool, error)
+	// Ping check the API server of the cluster is reachable
+	Ping(ctx context.Context, cluster string) error
+	// ServerVersion get the git version of the API server of the cluster
+	ServerVersion(ctx context.Context, cluster string) (string, error)
 }
 
+// MultiCluster does the operation connect the clusters except the one which the manager is running in
+const MultiCluster = false
+
 // GetClusterOperation return an ClusterOperation of this interface
 func GetClusterOperation() ClusterOperation {
 	return operation
@@ -63,12 +76,51 @@ func SetClusterOperation(o ClusterOperation) {
 	operation = o
 }
 
+// ErrClusterUnreachable will be returned when the custom resource cannot be deleted by force, because its cluster
+// cannot be reached or is not connected by the manager, thus the custom resource may be left in the cluster
+var ErrClusterUnreachable = errors.New("the cluster is unreachable")
+
+// ForceDeleteCustomResource delete the custom resource from cluster, and clear its finalizers if removeFinalizers is
+// true, so that it is removed without waiting for the controller. The custom resource which is not found has been
+// deleted already. If the deletion fails and the cluster cannot be pinged, the ErrClusterUnreachable is returned.
+func ForceDeleteCustomResource(ctx context.Context, cluster string, gvr schema.GroupVersionResource, name,
+	namespace string, removeFinalizers bool) error {
+	err := forceDeleteCustomResource(ctx, gvr, name, namespace, removeFinalizers)
+	if err == nil || errors.Is(err, ErrFenced) {
+		return err
+	}
+	if pingErr := operation.Ping(ctx, cluster); pingErr != nil {
+		return fmt.Errorf("%w, ping err: %v, delete err: %v", ErrClusterUnreachable, pingErr, err)
+	}
+	return err
+}
+
+func forceDeleteCustomResource(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string,
+	removeFinalizers bool) error {
+	err := operation.DeleteCustomResource(ctx, gvr, name, namespace)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil || !removeFinalizers {
+		return err
+	}
+	if err = operation.RemoveFinalizers(ctx, gvr, name, namespace); apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
 // init defaultOperation will be used at beginning
 func init() {
 	operation = &defaultOperation{}
 	kubeConfigPath = os.Getenv("KubeConfig")
 }
 
+// GetRestConfig get the rest.Config of the cluster which the manager is running with
+func GetRestConfig() (*rest.Config, error) {
+	return getConfig()
+}
+
 // getConfig will try to get the rest.Config from the kubeconfig first. If kappital cannot get the config from kubeconfig, it
 // will try to get the rest.Config from the service account. If both ways cannot get the correct the rest.Config, will
 // return a non-nil error.
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// finalizerOperation record the custom resources whose finalizers are removed
+type finalizerOperation struct {
+	fakeOperation
+	deleteErr error
+	pingErr   error
+	removed   *[]string
+}
+
+func (f finalizerOperation) Ping(context.Context, string) error {
+	return f.pingErr
+}
+
+func (f finalizerOperation) DeleteCustomResource(context.Context, schema.GroupVersionResource, string, string) error {
+	return f.deleteErr
+}
+
+func (f finalizerOperation) RemoveFinalizers(_ context.Context, gvr schema.GroupVersionResource, name,
+	namespace string) error {
+	*f.removed = append(*f.removed, gvr.Resource+"/"+namespace+"/"+name)
+	return nil
+}
+
+func TestForceDeleteCustomResource(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "cache.example.com", Version: "v1", Resource: "redis"}
+	notFound := apierrors.NewNotFound(gvr.GroupResource(), "cache")
+	tests := []struct {
+		name             string
+		deleteErr        error
+		pingErr          error
+		removeFinalizers bool
+		wantRemoved      []string
+		wantErr          bool
+		wantUnreachable  bool
+	}{
+		{name: "Test ForceDeleteCustomResource (keep the finalizers)"},
+		{name: "Test ForceDeleteCustomResource (remove the finalizers)", removeFinalizers: true,
+			wantRemoved: []string{"redis/prod/cache"}},
+		{name: "Test ForceDeleteCustomResource (not found)", deleteErr: notFound, removeFinalizers: true},
+		{name: "Test ForceDeleteCustomResource (forbidden)", deleteErr: fmt.Errorf("forbidden"),
+			removeFinalizers: true, wantErr: true},
+		{name: "Test ForceDeleteCustomResource (cluster is gone)", deleteErr: fmt.Errorf("connection refused"),
+			pingErr: fmt.Errorf("connection refused"), removeFinalizers: true, wantErr: true, wantUnreachable: true},
+		{name: "Test ForceDeleteCustomResource (fenced)", deleteErr: ErrFenced, pingErr: fmt.Errorf("unknown"),
+			removeFinalizers: true, wantErr: true},
+	}
+	previous := GetClusterOperation()
+	defer SetClusterOperation(previous)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var removed []string
+			SetClusterOperation(finalizerOperation{deleteErr: tt.deleteErr, pingErr: tt.pingErr, removed: &removed})
+			err := ForceDeleteCustomResource(context.Background(), "default", gvr, "cache", "prod",
+				tt.removeFinalizers)
+			if (err != nil) != tt.wantErr || errors.Is(err, ErrClusterUnreachable) != tt.wantUnreachable {
+				t.Errorf("ForceDeleteCustomResource() error = %v, wantErr %v, wantUnreachable %v", err, tt.wantErr,
+					tt.wantUnreachable)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("ForceDeleteCustomResource() removed the finalizers of %v, want %v", removed,
+					tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ package operations
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,12 +27,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
+	"github.com/kappital/kappital/pkg/apis"
 	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
 )
 
@@ -38,7 +43,7 @@ type defaultOperation struct{}
 
 // GetServicePackageByName get the service package CR by its name. This method will return the ServicePackage
 // if existed.
-func (d *defaultOperation) GetServicePackageByName(name, namespace string) (enginev1alpha1.ServicePackage, bool, error) {
+func (d *defaultOperation) GetServicePackageByName(ctx context.Context, name, namespace string) (enginev1alpha1.ServicePackage, bool, error) {
 	config, err := getConfig()
 	if err != nil {
 		klog.Errorf("cannot get the client config, err: %v", err)
@@ -53,7 +58,7 @@ func (d *defaultOperation) GetServicePackageByName(name, namespace string) (engi
 		return enginev1alpha1.ServicePackage{}, false, err
 	}
 	sp := enginev1alpha1.ServicePackage{}
-	if err = cli.Get().Resource(servicePackageResource).Namespace(namespace).Name(name).Do(context.TODO()).
+	if err = cli.Get().Resource(servicePackageResource).Namespace(namespace).Name(name).Do(ctx).
 		Into(&sp); err != nil {
 		if errors.IsNotFound(err) {
 			klog.Warning("cannot find the service package")
@@ -66,7 +71,7 @@ func (d *defaultOperation) GetServicePackageByName(name, namespace string) (engi
 }
 
 // DoesCustomResourceExist will use resource's schema.GroupVersion to find the cr in this cluster
-func (d *defaultOperation) DoesCustomResourceExist(gv schema.GroupVersion,
+func (d *defaultOperation) DoesCustomResourceExist(ctx context.Context, gv schema.GroupVersion,
 	plural, name, namespace string) (bool, error) {
 	config, err := getConfig()
 	if err != nil {
@@ -81,7 +86,7 @@ func (d *defaultOperation) DoesCustomResourceExist(gv schema.GroupVersion,
 		klog.Errorf("cannot get the client, err: %v", err)
 		return false, err
 	}
-	err = cli.Get().Resource(plural).Name(name).Namespace(namespace).Do(context.TODO()).Error()
+	err = cli.Get().Resource(plural).Name(name).Namespace(namespace).Do(ctx).Error()
 	if errors.IsNotFound(err) {
 		return false, nil
 	}
@@ -89,41 +94,53 @@ func (d *defaultOperation) DoesCustomResourceExist(gv schema.GroupVersion,
 }
 
 // DeployCustomResource will install the custom resource into cluster
-func (d *defaultOperation) DeployCustomResource(gvr schema.GroupVersionResource, namespace string,
+func (d *defaultOperation) DeployCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string,
 	resource interface{}) error {
 	cli, obj, err := getCRClientAndObj(resource)
 	if err != nil {
 		return err
 	}
 	// create the custom resource
-	_, err = cli.Resource(gvr).Namespace(namespace).Create(context.TODO(), obj, metav1.CreateOptions{})
+	_, err = cli.Resource(gvr).Namespace(namespace).Create(ctx, obj, metav1.CreateOptions{})
 	return err
 }
 
 // UpdateCustomResource will update the custom resource into cluster
-func (d *defaultOperation) UpdateCustomResource(gvr schema.GroupVersionResource, namespace string,
+func (d *defaultOperation) UpdateCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string,
 	resource interface{}) error {
 	cli, obj, err := getCRClientAndObj(resource)
 	if err != nil {
 		return err
 	}
 	// update the custom resource
-	_, err = cli.Resource(gvr).Namespace(namespace).Update(context.TODO(), obj, metav1.UpdateOptions{})
+	_, err = cli.Resource(gvr).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{})
 	return err
 }
 
 // DeleteCustomResource will delete the custom resource from cluster
-func (d *defaultOperation) DeleteCustomResource(gvr schema.GroupVersionResource, name, namespace string) error {
+func (d *defaultOperation) DeleteCustomResource(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) error {
 	cli, err := getCustomResourceClient()
 	if err != nil {
 		return err
 	}
-	err = cli.Resource(gvr).Namespace(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	err = cli.Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	return err
+}
+
+// RemoveFinalizers will clear the finalizers of the custom resource in cluster by the JSON merge patch
+func (d *defaultOperation) RemoveFinalizers(ctx context.Context, gvr schema.GroupVersionResource, name,
+	namespace string) error {
+	cli, err := getCustomResourceClient()
+	if err != nil {
+		return err
+	}
+	patch := []byte(`{"metadata":{"finalizers":null}}`)
+	_, err = cli.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
 	return err
 }
 
 // IsNamespaceExist will check cluster namespace is exist, true means is exists
-func (d *defaultOperation) IsNamespaceExist(namespace string) (bool, error) {
+func (d *defaultOperation) IsNamespaceExist(ctx context.Context, namespace string) (bool, error) {
 	config, err := getConfig()
 	if err != nil {
 		klog.Errorf("cannot get the client config, err: %v", err)
@@ -135,7 +152,7 @@ func (d *defaultOperation) IsNamespaceExist(namespace string) (bool, error) {
 		return false, err
 	}
 
-	_, err = cli.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	_, err = cli.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	switch {
 	case errors.IsNotFound(err):
 		return false, nil
@@ -147,6 +164,47 @@ func (d *defaultOperation) IsNamespaceExist(namespace string) (bool, error) {
 	return true, nil
 }
 
+// Ping check the API server is reachable by its version endpoint, the manager only connects the cluster which it is
+// running with, thus the other clusters are not reachable
+func (d *defaultOperation) Ping(ctx context.Context, cluster string) error {
+	if cluster != apis.DefaultCluster {
+		return fmt.Errorf("the cluster %s is not connected by the manager", cluster)
+	}
+	config, err := getConfig()
+	if err != nil {
+		return fmt.Errorf("cannot get the client config, err: %v", err)
+	}
+	cli, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("cannot get the client, err: %v", err)
+	}
+	return cli.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+}
+
+// ServerVersion get the git version of the API server by its version endpoint
+func (d *defaultOperation) ServerVersion(ctx context.Context, cluster string) (string, error) {
+	if cluster != apis.DefaultCluster {
+		return "", fmt.Errorf("the cluster %s is not connected by the manager", cluster)
+	}
+	config, err := getConfig()
+	if err != nil {
+		return "", fmt.Errorf("cannot get the client config, err: %v", err)
+	}
+	cli, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("cannot get the client, err: %v", err)
+	}
+	body, err := cli.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return "", err
+	}
+	var info apimachineryversion.Info
+	if err = json.Unmarshal(body, &info); err != nil {
+		return "", fmt.Errorf("cannot unmarshal the version of the cluster %s, err: %v", cluster, err)
+	}
+	return info.GitVersion, nil
+}
+
 func getCRClientAndObj(resource interface{}) (dynamic.Interface, *unstructured.Unstructured, error) {
 	cli, err := getCustomResourceClient()
 	if err != nil {
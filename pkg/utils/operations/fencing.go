@@ -0,0 +1,81 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// ErrFenced will be returned when the cluster mutation is rejected by the fence, such as the current manager
+// replica is not the leader anymore
+var ErrFenced = errors.New("the current manager replica does not hold the lease, reject the cluster mutation")
+
+// fencedOperation check the fence before each cluster mutation, the query operations will not be fenced
+type fencedOperation struct {
+	ClusterOperation
+	fence func() bool
+}
+
+// NewFencedOperation wrap the ClusterOperation, and the fence will be checked before deploying, updating or
+// deleting the custom resource in cluster
+func NewFencedOperation(o ClusterOperation, fence func() bool) ClusterOperation {
+	return &fencedOperation{ClusterOperation: o, fence: fence}
+}
+
+// DeployCustomResource will install the custom resource into cluster if the fence is passed
+func (f *fencedOperation) DeployCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string,
+	resource interface{}) error {
+	if !f.fence() {
+		klog.Warningf("reject to deploy the custom resource %s in namespace %s, err: %s", gvr, namespace, ErrFenced)
+		return ErrFenced
+	}
+	return f.ClusterOperation.DeployCustomResource(ctx, gvr, namespace, resource)
+}
+
+// UpdateCustomResource will update the custom resource into cluster if the fence is passed
+func (f *fencedOperation) UpdateCustomResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string,
+	resource interface{}) error {
+	if !f.fence() {
+		klog.Warningf("reject to update the custom resource %s in namespace %s, err: %s", gvr, namespace, ErrFenced)
+		return ErrFenced
+	}
+	return f.ClusterOperation.UpdateCustomResource(ctx, gvr, namespace, resource)
+}
+
+// DeleteCustomResource will delete the custom resource from cluster if the fence is passed
+func (f *fencedOperation) DeleteCustomResource(ctx context.Context, gvr schema.GroupVersionResource, name, namespace string) error {
+	if !f.fence() {
+		klog.Warningf("reject to delete the custom resource %s %s/%s, err: %s", gvr, namespace, name, ErrFenced)
+		return ErrFenced
+	}
+	return f.ClusterOperation.DeleteCustomResource(ctx, gvr, name, namespace)
+}
+
+// RemoveFinalizers will clear the finalizers of the custom resource in cluster if the fence is passed
+func (f *fencedOperation) RemoveFinalizers(ctx context.Context, gvr schema.GroupVersionResource, name,
+	namespace string) error {
+	if !f.fence() {
+		klog.Warningf("reject to remove the finalizers of the custom resource %s %s/%s, err: %s", gvr, namespace,
+			name, ErrFenced)
+		return ErrFenced
+	}
+	return f.ClusterOperation.RemoveFinalizers(ctx, gvr, name, namespace)
+}
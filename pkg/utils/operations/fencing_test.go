@@ -0,0 +1,97 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
+)
+
+type fakeOperation struct{}
+
+func (f fakeOperation) GetServicePackageByName(context.Context, string, string) (enginev1alpha1.ServicePackage, bool, error) {
+	return enginev1alpha1.ServicePackage{}, true, nil
+}
+
+func (f fakeOperation) DoesCustomResourceExist(context.Context, schema.GroupVersion, string, string, string) (bool, error) {
+	return true, nil
+}
+
+func (f fakeOperation) DeployCustomResource(context.Context, schema.GroupVersionResource, string, interface{}) error {
+	return nil
+}
+
+func (f fakeOperation) UpdateCustomResource(context.Context, schema.GroupVersionResource, string, interface{}) error {
+	return nil
+}
+
+func (f fakeOperation) DeleteCustomResource(context.Context, schema.GroupVersionResource, string, string) error {
+	return nil
+}
+
+func (f fakeOperation) RemoveFinalizers(context.Context, schema.GroupVersionResource, string, string) error {
+	return nil
+}
+
+func (f fakeOperation) IsNamespaceExist(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+func (f fakeOperation) Ping(context.Context, string) error {
+	return nil
+}
+
+func (f fakeOperation) ServerVersion(context.Context, string) (string, error) {
+	return "v1.23.0", nil
+}
+
+func TestNewFencedOperation(t *testing.T) {
+	tests := []struct {
+		name    string
+		leader  bool
+		wantErr error
+	}{
+		{name: "Test fenced operation (leader)", leader: true},
+		{name: "Test fenced operation (not leader)", wantErr: ErrFenced},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewFencedOperation(fakeOperation{}, func() bool { return tt.leader })
+			ctx := context.Background()
+			if err := o.DeployCustomResource(ctx, schema.GroupVersionResource{}, "", nil); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeployCustomResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := o.UpdateCustomResource(ctx, schema.GroupVersionResource{}, "", nil); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateCustomResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := o.DeleteCustomResource(ctx, schema.GroupVersionResource{}, "", ""); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteCustomResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := o.RemoveFinalizers(ctx, schema.GroupVersionResource{}, "", ""); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RemoveFinalizers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := o.IsNamespaceExist(ctx, ""); err != nil {
+				t.Errorf("IsNamespaceExist() should not be fenced, but error = %v", err)
+			}
+		})
+	}
+}
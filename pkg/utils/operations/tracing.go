@@ -0,0 +1,107 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operations
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	enginev1alpha1 "github.com/kappital/kappital/pkg/apis/engine/v1alpha1"
+	"github.com/kappital/kappital/pkg/utils/tracing"
+)
+
+// tracedOperation create a span for each cluster operation
+type tracedOperation struct {
+	ClusterOperation
+}
+
+// NewTracedOperation wrap the ClusterOperation, and each operation will be recorded as a span
+func NewTracedOperation(o ClusterOperation) ClusterOperation {
+	return &tracedOperation{ClusterOperation: o}
+}
+
+// GetServicePackageByName get the service package CR by its name with a span
+func (t *tracedOperation) GetServicePackageByName(ctx context.Context, name,
+	namespace string) (sp enginev1alpha1.ServicePackage, find bool, err error) {
+	ctx, span := tracing.Start(ctx, "cluster.GetServicePackageByName",
+		attribute.String("name", name), attribute.String("namespace", namespace))
+	defer func() {
+		span.SetAttributes(attribute.Bool("found", find))
+		tracing.End(span, err)
+	}()
+	return t.ClusterOperation.GetServicePackageByName(ctx, name, namespace)
+}
+
+// DoesCustomResourceExist find the cr in this cluster with a span
+func (t *tracedOperation) DoesCustomResourceExist(ctx context.Context, gv schema.GroupVersion,
+	plural, name, namespace string) (find bool, err error) {
+	ctx, span := tracing.Start(ctx, "cluster.DoesCustomResourceExist", attribute.String("groupVersion", gv.String()),
+		attribute.String("resource", plural), attribute.String("name", name), attribute.String("namespace", namespace))
+	defer func() {
+		span.SetAttributes(attribute.Bool("found", find))
+		tracing.End(span, err)
+	}()
+	return t.ClusterOperation.DoesCustomResourceExist(ctx, gv, plural, name, namespace)
+}
+
+// DeployCustomResource install the custom resource into cluster with a span
+func (t *tracedOperation) DeployCustomResource(ctx context.Context, gvr schema.GroupVersionResource,
+	namespace string, resource interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "cluster.DeployCustomResource",
+		attribute.String("resource", gvr.String()), attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+	return t.ClusterOperation.DeployCustomResource(ctx, gvr, namespace, resource)
+}
+
+// UpdateCustomResource update the custom resource into cluster with a span
+func (t *tracedOperation) UpdateCustomResource(ctx context.Context, gvr schema.GroupVersionResource,
+	namespace string, resource interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "cluster.UpdateCustomResource",
+		attribute.String("resource", gvr.String()), attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+	return t.ClusterOperation.UpdateCustomResource(ctx, gvr, namespace, resource)
+}
+
+// DeleteCustomResource delete the custom resource from cluster with a span
+func (t *tracedOperation) DeleteCustomResource(ctx context.Context, gvr schema.GroupVersionResource, name,
+	namespace string) (err error) {
+	ctx, span := tracing.Start(ctx, "cluster.DeleteCustomResource", attribute.String("resource", gvr.String()),
+		attribute.String("name", name), attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+	return t.ClusterOperation.DeleteCustomResource(ctx, gvr, name, namespace)
+}
+
+// RemoveFinalizers clear the finalizers of the custom resource in cluster with a span
+func (t *tracedOperation) RemoveFinalizers(ctx context.Context, gvr schema.GroupVersionResource, name,
+	namespace string) (err error) {
+	ctx, span := tracing.Start(ctx, "cluster.RemoveFinalizers", attribute.String("resource", gvr.String()),
+		attribute.String("name", name), attribute.String("namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+	return t.ClusterOperation.RemoveFinalizers(ctx, gvr, name, namespace)
+}
+
+// IsNamespaceExist check cluster namespace is existed with a span
+func (t *tracedOperation) IsNamespaceExist(ctx context.Context, namespace string) (find bool, err error) {
+	ctx, span := tracing.Start(ctx, "cluster.IsNamespaceExist", attribute.String("namespace", namespace))
+	defer func() {
+		span.SetAttributes(attribute.Bool("found", find))
+		tracing.End(span, err)
+	}()
+	return t.ClusterOperation.IsNamespaceExist(ctx, namespace)
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+)
+
+const (
+	// JSONType the content type of the whole document of the PUT requests
+	JSONType = "application/json"
+	// MergePatchType the content type of the JSON merge patch, see RFC 7386
+	MergePatchType = "application/merge-patch+json"
+	// JSONPatchType the content type of the JSON patch, see RFC 6902
+	JSONPatchType = "application/json-patch+json"
+)
+
+// ErrUnsupportedMediaType the content type of the body is not supported
+var ErrUnsupportedMediaType = errors.New("the content type is not supported")
+
+// Validator checks the values of the schema after the body is decoded into it
+type Validator interface {
+	Validate() error
+}
+
+// MediaType get the media type of the Content-Type header without the parameters such as the charset
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// ValidateDocument decode the whole document strictly into the schema and validate it, the unknown fields and the
+// values of the wrong types are rejected
+func ValidateDocument(doc []byte, schema interface{}) error {
+	value, err := decode(doc, reflect.TypeOf(schema))
+	if err != nil {
+		return err
+	}
+	return validate(value.Interface())
+}
+
+// ValidatePatch validate the patch of the content type against the schema before it is applied. The fields of the
+// merge patch and the paths of the JSON patch must be in the schema, and the values must be valid for their fields.
+func ValidatePatch(contentType string, patch []byte, schema interface{}) error {
+	switch MediaType(contentType) {
+	case MergePatchType:
+		return ValidateDocument(patch, schema)
+	case JSONPatchType:
+		return validateJSONPatch(patch, reflect.TypeOf(schema))
+	default:
+		return fmt.Errorf("%w: %s, expected %s or %s", ErrUnsupportedMediaType, contentType, MergePatchType,
+			JSONPatchType)
+	}
+}
+
+// Apply the patch of the content type to the document, then decode the result strictly into the out pointer and
+// validate it
+func Apply(contentType string, doc interface{}, patch []byte, out interface{}) error {
+	original, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("cannot marshal the document, err: %v", err)
+	}
+	var patched []byte
+	switch MediaType(contentType) {
+	case MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case JSONPatchType:
+		var ops jsonpatch.Patch
+		if ops, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = ops.Apply(original)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot apply the patch, err: %v", err)
+	}
+	return Decode(patched, out)
+}
+
+// Decode the document strictly into the out pointer and validate it
+func Decode(doc []byte, out interface{}) error {
+	ptr := reflect.ValueOf(out)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("the out must be a non-nil pointer")
+	}
+	value, err := decode(doc, ptr.Elem().Type())
+	if err != nil {
+		return err
+	}
+	if err = validate(value.Interface()); err != nil {
+		return err
+	}
+	ptr.Elem().Set(value)
+	return nil
+}
+
+// Operation of the JSON patch, the value is nil if it is not given
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func validateJSONPatch(patch []byte, schema reflect.Type) error {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return fmt.Errorf("the json patch must be an array of the operations, err: %v", err)
+	}
+	if len(ops) == 0 {
+		return fmt.Errorf("the json patch is empty")
+	}
+	for i, op := range ops {
+		if err := validateOperation(op, schema); err != nil {
+			return fmt.Errorf("the operation %d is invalid, %v", i, err)
+		}
+	}
+	return nil
+}
+
+func validateOperation(op Operation, schema reflect.Type) error {
+	index, field, err := resolve(schema, op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("the value of %s is required", op.Op)
+		}
+		value, err := decode(op.Value, field)
+		if err != nil {
+			return fmt.Errorf("the value of %s is invalid, err: %v", op.Path, err)
+		}
+		if op.Op == "test" {
+			return nil
+		}
+		// validate the value in the empty document, thus the validator of the schema checks it
+		doc := reflect.New(schema).Elem()
+		doc.FieldByIndex(index).Set(value)
+		return validate(doc.Interface())
+	case "remove":
+		if len(index) == 0 {
+			return fmt.Errorf("cannot remove the whole document")
+		}
+		return nil
+	case "move", "copy":
+		fromIndex, fromField, err := resolve(schema, op.From)
+		if err != nil {
+			return err
+		}
+		if fromField != field {
+			return fmt.Errorf("cannot %s %s to %s, the types are different", op.Op, op.From, op.Path)
+		}
+		if op.Op == "move" && len(fromIndex) == 0 {
+			return fmt.Errorf("cannot move the whole document")
+		}
+		return nil
+	default:
+		return fmt.Errorf("the op %q is not supported", op.Op)
+	}
+}
+
+// resolve the JSON pointer to the index and the type of the struct field in the schema, the empty pointer is the
+// whole document
+func resolve(schema reflect.Type, pointer string) ([]int, reflect.Type, error) {
+	if len(pointer) == 0 {
+		return nil, schema, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, nil, fmt.Errorf("the path %q must start with /", pointer)
+	}
+	var index []int
+	current := schema
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if current.Kind() != reflect.Struct {
+			return nil, nil, fmt.Errorf("the path %q is not in the schema", pointer)
+		}
+		field, ok := fieldByJSONName(current, token)
+		if !ok {
+			return nil, nil, fmt.Errorf("the path %q is not in the schema", pointer)
+		}
+		index = append(index, field.Index...)
+		current = field.Type
+	}
+	return index, current, nil
+}
+
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == name || (len(tag) == 0 && field.Name == name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func decode(data []byte, t reflect.Type) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(ptr.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if decoder.More() {
+		return reflect.Value{}, fmt.Errorf("unexpected data after the document")
+	}
+	return ptr.Elem(), nil
+}
+
+func validate(value interface{}) error {
+	if v, ok := value.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
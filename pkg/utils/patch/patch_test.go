@@ -0,0 +1,139 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package patch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kappital/kappital/pkg/apis"
+)
+
+func TestValidatePatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		wantErr     bool
+		unsupported bool
+	}{
+		{name: "merge patch", contentType: MergePatchType, patch: `{"timeouts":{"installTimeout":"10m"}}`},
+		{name: "merge patch with charset", contentType: MergePatchType + "; charset=utf-8",
+			patch: `{"timeouts":{"deleteTimeout":null}}`},
+		{name: "merge patch with unknown field", contentType: MergePatchType, patch: `{"replicas":3}`, wantErr: true},
+		{name: "merge patch with wrong type", contentType: MergePatchType, patch: `{"timeouts":{"installTimeout":10}}`,
+			wantErr: true},
+		{name: "merge patch with invalid duration", contentType: MergePatchType,
+			patch: `{"timeouts":{"installTimeout":"-1m"}}`, wantErr: true},
+		{name: "json patch", contentType: JSONPatchType,
+			patch: `[{"op":"replace","path":"/timeouts/upgradeTimeout","value":"1h"},` +
+				`{"op":"remove","path":"/timeouts/deleteTimeout"}]`},
+		{name: "json patch copy", contentType: JSONPatchType,
+			patch: `[{"op":"copy","from":"/timeouts/installTimeout","path":"/timeouts/deleteTimeout"}]`},
+		{name: "json patch with unknown path", contentType: JSONPatchType,
+			patch: `[{"op":"add","path":"/timeouts/waitTimeout","value":"1m"}]`, wantErr: true},
+		{name: "json patch with invalid value", contentType: JSONPatchType,
+			patch: `[{"op":"add","path":"/timeouts/installTimeout","value":"forever"}]`, wantErr: true},
+		{name: "json patch without value", contentType: JSONPatchType,
+			patch: `[{"op":"replace","path":"/timeouts/installTimeout"}]`, wantErr: true},
+		{name: "json patch removes document", contentType: JSONPatchType, patch: `[{"op":"remove","path":""}]`,
+			wantErr: true},
+		{name: "json patch moves different types", contentType: JSONPatchType,
+			patch: `[{"op":"move","from":"/timeouts","path":"/timeouts/installTimeout"}]`, wantErr: true},
+		{name: "json patch with unknown op", contentType: JSONPatchType,
+			patch: `[{"op":"merge","path":"/timeouts"}]`, wantErr: true},
+		{name: "empty json patch", contentType: JSONPatchType, patch: `[]`, wantErr: true},
+		{name: "unsupported content type", contentType: "text/plain", patch: `{}`, wantErr: true, unsupported: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePatch(tt.contentType, []byte(tt.patch), apis.Settings{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrUnsupportedMediaType) != tt.unsupported {
+				t.Errorf("ValidatePatch() error = %v, unsupported %v", err, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	current := apis.Settings{Timeouts: apis.Timeouts{InstallTimeout: "5m", DeleteTimeout: "1m"}}
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		want        apis.Settings
+		wantErr     bool
+	}{
+		{name: "merge patch", contentType: MergePatchType,
+			patch: `{"timeouts":{"installTimeout":"10m","deleteTimeout":null}}`,
+			want:  apis.Settings{Timeouts: apis.Timeouts{InstallTimeout: "10m"}}},
+		{name: "json patch", contentType: JSONPatchType,
+			patch: `[{"op":"test","path":"/timeouts/installTimeout","value":"5m"},` +
+				`{"op":"move","from":"/timeouts/deleteTimeout","path":"/timeouts/upgradeTimeout"}]`,
+			want: apis.Settings{Timeouts: apis.Timeouts{InstallTimeout: "5m", UpgradeTimeout: "1m"}}},
+		{name: "json patch test failed", contentType: JSONPatchType,
+			patch: `[{"op":"test","path":"/timeouts/installTimeout","value":"1m"}]`, wantErr: true},
+		{name: "invalid result", contentType: MergePatchType, patch: `{"timeouts":{"installTimeout":"0s"}}`,
+			wantErr: true},
+		{name: "unsupported content type", contentType: JSONType, patch: `{}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got apis.Settings
+			err := Apply(tt.contentType, current, []byte(tt.patch), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		want    apis.Settings
+		wantErr bool
+	}{
+		{name: "whole document", doc: `{"timeouts":{"upgradeTimeout":"2h"}}`,
+			want: apis.Settings{Timeouts: apis.Timeouts{UpgradeTimeout: "2h"}}},
+		{name: "unknown field", doc: `{"timeouts":{},"name":"a"}`, wantErr: true},
+		{name: "trailing data", doc: `{"timeouts":{}} {}`, wantErr: true},
+		{name: "invalid duration", doc: `{"timeouts":{"deleteTimeout":"soon"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got apis.Settings
+			err := Decode([]byte(tt.doc), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
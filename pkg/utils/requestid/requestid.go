@@ -0,0 +1,105 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package requestid
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/utils/uuid"
+)
+
+const (
+	// Header of the request id in the requests and the responses
+	Header = "X-Request-ID"
+
+	maxLength = 128
+)
+
+type requestIDKey struct{}
+
+// New generate a request id
+func New() string {
+	return uuid.NewUUID()
+}
+
+// Valid does the id given by the client can be used as the request id, it must be no longer than 128 characters and
+// only contains the letters, digits, '-', '_', '.' and ':', thus it is safe to be written into the logs
+func Valid(id string) bool {
+	if len(id) == 0 || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Normalize get the id if it is valid, otherwise generate a new one
+func Normalize(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
+
+// NewContext put the request id into the context, the empty id is ignored
+func NewContext(ctx context.Context, id string) context.Context {
+	if len(id) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext get the request id from the context, it is empty if the context does not carry one
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Infof log the info message with the request id of the context
+func Infof(ctx context.Context, format string, args ...interface{}) {
+	klog.InfoDepth(1, withRequestID(ctx, format, args...))
+}
+
+// Warningf log the warning message with the request id of the context
+func Warningf(ctx context.Context, format string, args ...interface{}) {
+	klog.WarningDepth(1, withRequestID(ctx, format, args...))
+}
+
+// Errorf log the error message with the request id of the context
+func Errorf(ctx context.Context, format string, args ...interface{}) {
+	klog.ErrorDepth(1, withRequestID(ctx, format, args...))
+}
+
+func withRequestID(ctx context.Context, format string, args ...interface{}) string {
+	msg := fmt.Sprintf(format, args...)
+	if id := FromContext(ctx); len(id) > 0 {
+		return fmt.Sprintf("[request_id=%s] %s", id, msg)
+	}
+	return msg
+}
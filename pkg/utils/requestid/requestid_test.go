@@ -0,0 +1,73 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "Test Valid (uuid)", id: "6f1c5b3e-8d0a-11ed-a1eb-0242ac120002", want: true},
+		{name: "Test Valid (dotted)", id: "kappctl.req:42_a", want: true},
+		{name: "Test Valid (empty)"},
+		{name: "Test Valid (too long)", id: strings.Repeat("a", maxLength+1)},
+		{name: "Test Valid (space)", id: "a b"},
+		{name: "Test Valid (new line)", id: "a\nINFO fake log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.id); got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("abc-123"); got != "abc-123" {
+		t.Errorf("Normalize() = %v, want abc-123", got)
+	}
+	if got := Normalize("a b"); !Valid(got) || got == "a b" {
+		t.Errorf("Normalize() = %v, want a new valid id", got)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != "" {
+		t.Errorf("FromContext() = %v, want empty", got)
+	}
+	ctx := NewContext(context.Background(), "abc-123")
+	if got := FromContext(ctx); got != "abc-123" {
+		t.Errorf("FromContext() = %v, want abc-123", got)
+	}
+	if got := NewContext(ctx, ""); got != ctx {
+		t.Errorf("NewContext() with the empty id should return the same context")
+	}
+	if got := withRequestID(ctx, "delete %s", "demo"); got != "[request_id=abc-123] delete demo" {
+		t.Errorf("withRequestID() = %v", got)
+	}
+	if got := withRequestID(context.Background(), "delete %s", "demo"); got != "delete demo" {
+		t.Errorf("withRequestID() = %v", got)
+	}
+}
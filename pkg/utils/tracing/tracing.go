@@ -0,0 +1,206 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ExporterNone disable the tracing
+	ExporterNone = "none"
+	// ExporterOTLP export the spans to the OpenTelemetry collector with the OTLP gRPC protocol
+	ExporterOTLP = "otlp"
+	// ExporterStdout print the spans to the standard output
+	ExporterStdout = "stdout"
+	// ExporterFile write the spans to a file, it is used for the air-gapped environment
+	ExporterFile = "file"
+
+	instrumentationName = "github.com/kappital/kappital"
+)
+
+// Config of the tracing
+type Config struct {
+	// Exporter the kind of the span exporter, one of none, otlp, stdout and file
+	Exporter string
+	// Endpoint the address of the OTLP collector, such as localhost:4317
+	Endpoint string
+	// Insecure does connect the OTLP collector without TLS
+	Insecure bool
+	// FilePath the file which the spans will be written into when the exporter is file
+	FilePath string
+	// SampleRatio the ratio of the sampled root spans, the child spans follow their parents
+	SampleRatio float64
+}
+
+// DefaultTracingConfig get the default tracing config, the tracing is disabled by default
+func DefaultTracingConfig() *Config {
+	return &Config{
+		Exporter:    ExporterNone,
+		Endpoint:    "localhost:4317",
+		FilePath:    "/opt/kappital/log/traces.json",
+		SampleRatio: 1,
+	}
+}
+
+// Validate the tracing config
+func (c *Config) Validate() error {
+	switch c.Exporter {
+	case ExporterNone, ExporterStdout:
+	case ExporterOTLP:
+		if len(c.Endpoint) == 0 {
+			return fmt.Errorf("the tracing endpoint cannot be empty when the exporter is %s", ExporterOTLP)
+		}
+	case ExporterFile:
+		if len(c.FilePath) == 0 {
+			return fmt.Errorf("the tracing file cannot be empty when the exporter is %s", ExporterFile)
+		}
+	default:
+		return fmt.Errorf("unknown tracing exporter %s, it should be one of %s, %s, %s and %s", c.Exporter,
+			ExporterNone, ExporterOTLP, ExporterStdout, ExporterFile)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("the tracing sample ratio %v should be in [0, 1]", c.SampleRatio)
+	}
+	return nil
+}
+
+// Init the global tracer provider and the propagator. The returned function flushes the buffered spans and stops
+// the exporter, it should be called when the server is stopping.
+func Init(ctx context.Context, cfg *Config, serviceName string) (func(context.Context) error, error) {
+	// the trace context is always propagated, even though the current process does not export the spans
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{},
+		propagation.Baggage{}))
+	if cfg == nil || cfg.Exporter == ExporterNone || len(cfg.Exporter) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	klog.Infof("tracing is enabled with the %s exporter", cfg.Exporter)
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg *Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		return exporter, nil, err
+	case ExporterFile:
+		f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot open the tracing file %s, err: %v", cfg.FilePath, err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		return exporter, f, nil
+	default:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, nil, err
+	}
+}
+
+// Start a span as the child of the span in ctx, or a new root span if there is no span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartChild start a span only if there is a span in ctx, otherwise a non-recording span is returned. It is used
+// for the low level calls, such as the database queries, which are meaningless without their callers.
+func StartChild(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return Start(ctx, name, attrs...)
+}
+
+// End the span, and record the error if it is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject the trace context of ctx into a map, thus it can be carried by the events
+func Inject(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract the trace context from the map which is injected by Inject
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tracing
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "none", cfg: Config{Exporter: ExporterNone}},
+		{name: "otlp", cfg: Config{Exporter: ExporterOTLP, Endpoint: "localhost:4317", SampleRatio: 1}},
+		{name: "otlp without endpoint", cfg: Config{Exporter: ExporterOTLP}, wantErr: true},
+		{name: "file without path", cfg: Config{Exporter: ExporterFile}, wantErr: true},
+		{name: "unknown exporter", cfg: Config{Exporter: "jaeger"}, wantErr: true},
+		{name: "invalid sample ratio", cfg: Config{Exporter: ExporterStdout, SampleRatio: 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	cfg := &Config{Exporter: ExporterFile, FilePath: filepath.Join(t.TempDir(), "traces.json"), SampleRatio: 1}
+	shutdown, err := Init(context.Background(), cfg, "test")
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer func() {
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("shutdown() error = %v", err)
+		}
+	}()
+
+	if _, span := StartChild(context.Background(), "child"); span.SpanContext().IsValid() {
+		t.Errorf("StartChild() without parent should return a non-recording span")
+	}
+	ctx, parent := Start(context.Background(), "parent")
+	defer End(parent, nil)
+	carrier := Inject(ctx)
+	if len(carrier) == 0 {
+		t.Fatalf("Inject() got empty carrier")
+	}
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	if extracted.TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("Extract() trace id = %s, want %s", extracted.TraceID(), parent.SpanContext().TraceID())
+	}
+	_, child := StartChild(ctx, "child")
+	if child.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("StartChild() trace id = %s, want %s", child.SpanContext().TraceID(), parent.SpanContext().TraceID())
+	}
+	End(child, nil)
+}
@@ -21,6 +21,7 @@ import (
 	"runtime"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 )
 
 var (
@@ -39,6 +40,8 @@ const (
 	ServiceNameManager = "kappital-manager"
 	// ServiceNameEngine defines name of current engine service component
 	ServiceNameEngine = "kappital-engine"
+	// ServiceNameKappctl defines name of the command line client
+	ServiceNameKappctl = "kappctl"
 )
 
 // Info contains versioning information.
@@ -72,3 +75,20 @@ func Get(serviceName string) Info {
 		Platform:     fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
 }
+
+// CheckSkew check the client and the server have the same major and minor version, the error describes the skew
+func CheckSkew(clientVersion, serverVersion string) error {
+	c, err := utilversion.ParseGeneric(clientVersion)
+	if err != nil {
+		return fmt.Errorf("cannot parse the client version %q, err: %v", clientVersion, err)
+	}
+	s, err := utilversion.ParseGeneric(serverVersion)
+	if err != nil {
+		return fmt.Errorf("cannot parse the server version %q, err: %v", serverVersion, err)
+	}
+	if c.Major() != s.Major() || c.Minor() != s.Minor() {
+		return fmt.Errorf("the client version %s is different from the server version %s, some commands may not "+
+			"work as expected", clientVersion, serverVersion)
+	}
+	return nil
+}
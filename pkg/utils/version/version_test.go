@@ -0,0 +1,44 @@
+/*
+ * Copyright 2022 Huawei Cloud Computing Technologies Co., Ltd
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"testing"
+)
+
+func TestCheckSkew(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  string
+		server  string
+		wantErr bool
+	}{
+		{name: "Test CheckSkew (same version)", client: "v0.2.0", server: "v0.2.0"},
+		{name: "Test CheckSkew (patch skew)", client: "v0.2.1", server: "v0.2.0-rc.1"},
+		{name: "Test CheckSkew (minor skew)", client: "v0.3.0", server: "v0.2.0", wantErr: true},
+		{name: "Test CheckSkew (major skew)", client: "v1.2.0", server: "v0.2.0", wantErr: true},
+		{name: "Test CheckSkew (invalid client)", client: "unknown", server: "v0.2.0", wantErr: true},
+		{name: "Test CheckSkew (invalid server)", client: "v0.2.0", server: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckSkew(tt.client, tt.server); (err != nil) != tt.wantErr {
+				t.Errorf("CheckSkew() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
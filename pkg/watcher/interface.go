@@ -16,6 +16,8 @@
 
 package watcher
 
+import "context"
+
 // Watcher provides an interface for monitoring and calling back events
 type Watcher interface {
 	Watch(obj interface{}, channel string, processor Processor) error
@@ -27,5 +29,5 @@ type Watcher interface {
 // Processor Provides interfaces for traversing events and determining event types
 type Processor interface {
 	List() ([]interface{}, error)
-	Process(opType string, obj interface{})
+	Process(ctx context.Context, opType string, obj interface{})
 }
@@ -31,4 +31,6 @@ type channelConfig struct {
 type Notification struct {
 	OPType  string `json:"op_type"`
 	RawData string `json:"raw_data"`
+	// TraceContext the propagated trace context of the operation which adds the event
+	TraceContext map[string]string `json:"trace_context,omitempty"`
 }
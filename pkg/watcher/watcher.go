@@ -17,11 +17,17 @@
 package watcher
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
+
+	"github.com/kappital/kappital/pkg/utils/tracing"
 )
 
 const (
@@ -51,7 +57,12 @@ type NotifyInfo struct {
 type NotifyWatcher struct {
 	NotifyMsg      chan *NotifyInfo
 	listenChannels map[string]*channelConfig
-	stopCh         chan struct{}
+	// stopCh is created with the watcher and closed once, thus it is safe to stop the watcher which is starting by
+	// the leader election callback
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	// resyncPeriod of listing all objects again, and 0 means never resync
+	resyncPeriod time.Duration
 }
 
 // Watch the events
@@ -73,19 +84,32 @@ func (n *NotifyWatcher) Watch(obj interface{}, channel string, processor Process
 func (n *NotifyWatcher) StartProcessor() error {
 	notifyChannel = make(chan *NotifyInfo, channalBuffer)
 	n.NotifyMsg = notifyChannel
-	n.stopCh = make(chan struct{})
 	go n.dispatch()
 
+	if err := n.list(); err != nil {
+		return err
+	}
+	if n.resyncPeriod > 0 {
+		// the records may be changed by other manager replicas, thus list them periodically
+		go wait.Until(func() {
+			if err := n.list(); err != nil {
+				klog.Errorf("failed to resync the watching objects, err: %v", err)
+			}
+		}, n.resyncPeriod, n.stopCh)
+	}
+	return nil
+}
+
+func (n *NotifyWatcher) list() error {
 	for _, conf := range n.listenChannels {
 		objs, err := conf.processor.List()
 		if err != nil {
 			return err
 		}
 		for _, o := range objs {
-			conf.processor.Process(OPList, o)
+			conf.processor.Process(context.Background(), OPList, o)
 		}
 	}
-
 	return nil
 }
 
@@ -120,24 +144,26 @@ func (n *NotifyWatcher) dispatch() {
 				continue
 			}
 
-			channelConfig.processor.Process(m.Notify.OPType, newObj)
-		case _, ok := <-n.stopCh:
-			if ok {
-				klog.V(klogLevel).Infof("stopping database listen worker")
-				return
-			}
+			channelConfig.processor.Process(tracing.Extract(context.Background(), m.Notify.TraceContext),
+				m.Notify.OPType, newObj)
+		case <-n.stopCh:
+			klog.V(klogLevel).Infof("stopping database listen worker")
+			return
 		}
 	}
 }
 
-// Stop the synchronize
+// Stop the synchronize, it can be called even if the processors are not started because the current replica is not
+// the leader
 func (n *NotifyWatcher) Stop() {
-	close(n.stopCh)
-	klog.Infof("notify watcher stopped.")
+	n.stopOnce.Do(func() {
+		close(n.stopCh)
+		klog.Infof("notify watcher stopped.")
+	})
 }
 
-// AddEvent add the watching event into synchronizing list
-func AddEvent(obj interface{}, opType, channel string) error {
+// AddEvent add the watching event into synchronizing list, the trace context of ctx is carried by the event
+func AddEvent(ctx context.Context, obj interface{}, opType, channel string) error {
 	if notifyChannel == nil {
 		return nil
 	}
@@ -149,8 +175,9 @@ func AddEvent(obj interface{}, opType, channel string) error {
 
 	msg := NotifyInfo{
 		Notify: Notification{
-			OPType:  opType,
-			RawData: string(objByte),
+			OPType:       opType,
+			RawData:      string(objByte),
+			TraceContext: tracing.Inject(ctx),
 		},
 		ChannelName: channel,
 	}
@@ -159,9 +186,17 @@ func AddEvent(obj interface{}, opType, channel string) error {
 	return nil
 }
 
-// NewWatcher create a new NotifyWatcher
-func NewWatcher() Watcher {
+// ChannelUsage the length and the capacity of the event channel, both are 0 if the processors are not started
+func ChannelUsage() (length, capacity int) {
+	ch := notifyChannel
+	return len(ch), cap(ch)
+}
+
+// NewWatcher create a new NotifyWatcher, and it will list all watching objects every resyncPeriod
+func NewWatcher(resyncPeriod time.Duration) Watcher {
 	return &NotifyWatcher{
 		listenChannels: map[string]*channelConfig{},
+		stopCh:         make(chan struct{}),
+		resyncPeriod:   resyncPeriod,
 	}
 }